@@ -65,34 +65,33 @@ func (c *replCommand) Run(ctx context.Context, tc *terminal.Context) error {
 connected:
 	defer client.Disconnect()
 
+	// Independent engine subscriptions: one confirms the connection, the
+	// other feeds the REPL consumer, so neither steals the other's messages.
+	opened, cancelOpened := client.Subscribe(nil, 1, engine.WithRecvTypes(types.SIMCONNECT_RECV_ID_OPEN))
+	defer cancelOpened()
+	stream, cancelStream := client.Subscribe(nil, 64, engine.WithRecvTypes(
+		types.SIMCONNECT_RECV_ID_EVENT,
+		types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA,
+		types.SIMCONNECT_RECV_ID_EXCEPTION,
+	))
+	defer cancelStream()
+
 	// Wait for OPEN message to confirm connection
-	stream := client.Stream()
 	connTimer := time.NewTimer(time.Duration(c.timeout) * time.Second)
 	defer connTimer.Stop()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-connTimer.C:
-			return fmt.Errorf("timeout waiting for connection confirmation after %ds", c.timeout)
-		case msg, ok := <-stream:
-			if !ok {
-				return fmt.Errorf("stream closed unexpectedly")
-			}
-			if msg.Err != nil {
-				msg.Release()
-				continue
-			}
-			if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_OPEN {
-				msg.Release()
-				goto ready
-			}
-			msg.Release()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-connTimer.C:
+		return fmt.Errorf("timeout waiting for connection confirmation after %ds", c.timeout)
+	case msg, ok := <-opened:
+		if !ok {
+			return fmt.Errorf("stream closed unexpectedly")
 		}
+		msg.Release()
 	}
 
-ready:
 	fmt.Fprintf(tc.Stderr, "Connected. Type 'help' for commands, 'exit' or 'quit' to leave.\n")
 
 	// Pending requests map: reqID -> pendingRequest
@@ -115,10 +114,6 @@ ready:
 				if !ok {
 					return
 				}
-				if msg.Err != nil {
-					msg.Release()
-					continue
-				}
 
 				switch types.SIMCONNECT_RECV_ID(msg.DwID) {
 				case types.SIMCONNECT_RECV_ID_EVENT:
@@ -57,14 +57,27 @@ var (
 	pprof    = flag.Bool("pprof", false, "Enable net/http/pprof on :6060")
 	interval = flag.Duration("interval", 5*time.Second, "Stats reporting interval")
 	buffer   = flag.Int("buffer", 512, "Engine buffer size")
+	control  = flag.Int("control-buffer", engine.DEFAULT_CONTROL_BUFFER_SIZE, "Engine control lane buffer size")
+	coalesce = flag.Bool("coalesce", false, "Enable latest-value coalescing on the engine data lane for the periodic data requests")
+	probe    = flag.Duration("probe", 250*time.Millisecond, "Control latency probe interval")
+	batch    = flag.Bool("batch", true, "Consume the engine stream in batches (false = per-message path)")
+	batchMax = flag.Int("batch-size", engine.DEFAULT_BATCH_SIZE, "Maximum messages per batch")
+	batchLat = flag.Duration("batch-latency", engine.DEFAULT_BATCH_LATENCY, "Batch latency budget")
 )
 
+// probeRequestID is the RequestSystemState ID used to measure control lane latency
+const probeRequestID = 6000
+
 // Atomic counters for tracking
 var (
 	messagesReceived  atomic.Uint64
 	stateChanges      atomic.Uint64
 	subDeliveries     atomic.Uint64
 	facilityResponses atomic.Uint64
+	probeSentAt       atomic.Int64 // UnixNano of the outstanding probe, 0 when none
+	probeCount        atomic.Uint64
+	probeTotalNs      atomic.Uint64
+	probeMaxNs        atomic.Uint64
 )
 
 // Peak tracking (accessed from single goroutine, no atomics needed)
@@ -114,6 +127,55 @@ func setupDataDefinitions(client engine.Client) {
 	slog.Info("Data definitions registered", "camera", 2000, "aircraft", 3000, "traffic_radius_m", 50000)
 }
 
+// controlProber periodically requests a system state and records how long the
+// SYSTEM_STATE reply takes to reach the message handler. The reply travels on
+// the engine control lane, so this measures control latency under data load.
+func controlProber(ctx context.Context, mgr manager.Manager) {
+	ticker := time.NewTicker(*probe)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if mgr.ConnectionState() != manager.StateAvailable || probeSentAt.Load() != 0 {
+				continue
+			}
+			probeSentAt.Store(time.Now().UnixNano())
+			if err := mgr.RequestSystemState(probeRequestID, types.SIMCONNECT_SYSTEM_STATE_SIM); err != nil {
+				probeSentAt.Store(0)
+			}
+		}
+	}
+}
+
+// recordProbe completes the outstanding control latency probe, if any
+func recordProbe() {
+	sent := probeSentAt.Swap(0)
+	if sent == 0 {
+		return
+	}
+	latency := uint64(time.Now().UnixNano() - sent)
+	probeCount.Add(1)
+	probeTotalNs.Add(latency)
+	for {
+		peak := probeMaxNs.Load()
+		if latency <= peak || probeMaxNs.CompareAndSwap(peak, latency) {
+			return
+		}
+	}
+}
+
+// probeStats returns average and maximum control latency in milliseconds
+func probeStats() (avgMS, maxMS float64) {
+	n := probeCount.Load()
+	if n == 0 {
+		return 0, 0
+	}
+	return float64(probeTotalNs.Load()) / float64(n) / 1e6, float64(probeMaxNs.Load()) / 1e6
+}
+
 // statsReporter periodically prints benchmark statistics
 func statsReporter(ctx context.Context, startTime time.Time) {
 	ticker := time.NewTicker(*interval)
@@ -148,13 +210,17 @@ func collectAndPrintStats(startTime time.Time) {
 		peakGoroutines = goroutines
 	}
 
+	ctlAvg, ctlMax := probeStats()
+
 	// Machine-parseable output format
-	fmt.Fprintf(os.Stderr, "[BENCH] t=%ds msgs=%d state=%d subs=%d fac=%d heap=%.1fMB sys=%.1fMB objs=%d gc=%d pause=%.1fms goroutines=%d\n",
+	fmt.Fprintf(os.Stderr, "[BENCH] t=%ds msgs=%d state=%d subs=%d fac=%d ctl_avg=%.2fms ctl_max=%.2fms heap=%.1fMB sys=%.1fMB objs=%d gc=%d pause=%.1fms goroutines=%d\n",
 		int(elapsed.Seconds()),
 		messagesReceived.Load(),
 		stateChanges.Load(),
 		subDeliveries.Load(),
 		facilityResponses.Load(),
+		ctlAvg,
+		ctlMax,
 		heapMB,
 		sysMB,
 		m.HeapObjects,
@@ -178,6 +244,8 @@ func printFinalSummary(startTime time.Time, totalAlloc uint64, finalGC uint32, f
 	fmt.Fprintf(os.Stderr, "State Changes:   %d (%.1f/s)\n", state, float64(state)/elapsed)
 	fmt.Fprintf(os.Stderr, "Sub Deliveries:  %d (%.1f/s)\n", subs, float64(subs)/elapsed)
 	fmt.Fprintf(os.Stderr, "Facility Resp:   %d (%.1f/s)\n", fac, float64(fac)/elapsed)
+	ctlAvg, ctlMax := probeStats()
+	fmt.Fprintf(os.Stderr, "Control Latency: avg %.2f ms, max %.2f ms (%d probes)\n", ctlAvg, ctlMax, probeCount.Load())
 	fmt.Fprintf(os.Stderr, "Peak Heap:       %.1f MB\n", peakHeapMB)
 	fmt.Fprintf(os.Stderr, "Total Alloc:     %.1f MB\n", float64(totalAlloc)/1024/1024)
 	fmt.Fprintf(os.Stderr, "GC Cycles:       %d\n", finalGC)
@@ -217,10 +285,25 @@ func main() {
 	slog.Info("Starting SimConnect benchmark",
 		"duration", *duration,
 		"buffer", *buffer,
+		"control_buffer", *control,
+		"coalesce", *coalesce,
+		"batch", *batch,
+		"batch_size", *batchMax,
+		"batch_latency", *batchLat,
 		"interval", *interval,
 		"pprof", *pprof,
 	)
 
+	// Engine lane configuration under test
+	engineOpts := []engine.Option{
+		engine.WithControlBufferSize(*control),
+		engine.WithBatchSize(*batchMax),
+		engine.WithBatchLatency(*batchLat),
+	}
+	if *coalesce {
+		engineOpts = append(engineOpts, engine.WithDataCoalescing(2001, 3001, 4001))
+	}
+
 	// Create manager with auto-detection and high buffer size
 	mgr := manager.New("GO Benchmark - SimConnect",
 		manager.WithContext(ctx),
@@ -228,6 +311,8 @@ func main() {
 		manager.WithAutoReconnect(true),
 		manager.WithBufferSize(*buffer),
 		manager.WithHeartbeat("6Hz"),
+		manager.WithEngineOptions(engineOpts...),
+		manager.WithBatchDispatch(*batch),
 	)
 
 	// Track start time for elapsed calculations
@@ -271,6 +356,10 @@ func main() {
 	mgr.OnMessage(func(msg engine.Message) {
 		messagesReceived.Add(1)
 
+		if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_SYSTEM_STATE {
+			recordProbe()
+		}
+
 		// Track facility responses separately
 		if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_FACILITY_DATA ||
 			types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_AIRPORT_LIST {
@@ -289,7 +378,8 @@ func main() {
 			select {
 			case <-msgSub.Done():
 				return
-			case <-msgSub.Messages():
+			case msg := <-msgSub.Messages():
+				msg.Release()
 				subDeliveries.Add(1)
 			}
 		}
@@ -317,8 +407,9 @@ func main() {
 		}
 	}()
 
-	// Start periodic stats reporting
+	// Start periodic stats reporting and control latency probing
 	go statsReporter(ctx, startTime)
+	go controlProber(ctx, mgr)
 
 	// Start the manager - blocks until context is cancelled
 	if err := mgr.Start(); err != nil {
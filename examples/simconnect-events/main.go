@@ -161,6 +161,7 @@ func main() {
 						if ev := msg.AsEvent(); ev != nil {
 							fmt.Printf("[custom] 1sec tick: data=%d\n", ev.DwData)
 						}
+						msg.Release()
 					case <-sub.Done():
 						return
 					}
@@ -214,35 +215,42 @@ loop:
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] Pause event: data=%d (1=paused, 0=unpaused)\n", ev.DwData)
 			}
+			msg.Release()
 
 		case msg := <-subSim.Messages():
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] Sim event: data=%d (1=running, 0=stopped)\n", ev.DwData)
 			}
+			msg.Release()
 
 		// Crash / Sound
 		case msg := <-subCrash.Messages():
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] Crashed event data=%d\n", ev.DwData)
 			}
+			msg.Release()
 
 		case msg := <-subReset.Messages():
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] CrashReset event data=%d\n", ev.DwData)
 			}
+			msg.Release()
 
 		case msg := <-subSound.Messages():
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] Sound event id=%d data=%d\n", ev.UEventID, ev.DwData)
 			}
+			msg.Release()
 
 		// View / FlightPlanDeactivated
 		case msg := <-subView.Messages():
 			if ev := msg.AsEvent(); ev != nil {
 				fmt.Printf("[sub] View changed: viewID=%d\n", ev.DwData)
 			}
+			msg.Release()
 
-		case <-subDeactivated.Messages():
+		case msg := <-subDeactivated.Messages():
+			msg.Release()
 			fmt.Println("[sub] Flight plan deactivated")
 
 		// Signals
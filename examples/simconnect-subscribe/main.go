@@ -264,8 +264,10 @@ func main() {
 					fmt.Println("📭 Subscription channel closed")
 					return
 				}
-				// Process the message using the same handler
+				// Process the message using the same handler, then hand
+				// the subscription's reference back to the pool
 				handleMessage(msg)
+				msg.Release()
 			case <-sub.Done():
 				// Subscription was cancelled
 				fmt.Println("📭 Subscription cancelled")
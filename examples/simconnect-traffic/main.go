@@ -10,7 +10,6 @@ import (
 	"os/signal"
 	"time"
 
-	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager"
 	"github.com/mrlm-net/simconnect/pkg/traffic"
 	"github.com/mrlm-net/simconnect/pkg/types"
@@ -46,31 +45,31 @@ func main() {
 		manager.WithHeartbeat("6Hz"),
 	)
 
-	// Register message handler — watch for ASSIGNED_OBJECT_ID to acknowledge spawns.
-	_ = mgr.OnMessage(func(msg engine.Message) {
-		if msg.Err != nil {
-			fmt.Fprintln(os.Stderr, "message error:", msg.Err)
-			return
-		}
-		switch types.SIMCONNECT_RECV_ID(msg.DwID) {
-		case types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID:
-			assigned := msg.AsAssignedObjectID()
-			a, ok := mgr.Fleet().Acknowledge(uint32(assigned.DwRequestID), uint32(assigned.DwObjectID))
-			if !ok {
-				return // not our request
-			}
-			fmt.Printf("✅ spawned  tail=%-10s objectID=%d\n", a.Tail, a.ObjectID)
-
-			// NonATC aircraft need control released before waypoints are accepted.
-			if a.Kind == traffic.KindNonATC {
-				if err := mgr.TrafficReleaseControl(a.ObjectID, reqRelease); err != nil {
-					fmt.Fprintln(os.Stderr, "release control failed:", err)
-					return
+	// Watch fleet events — the manager acknowledges spawns, fails rejected or
+	// timed-out creations and drops aircraft the simulator removes.
+	fleetEvents := mgr.Fleet().Subscribe(32)
+	go func() {
+		for ev := range fleetEvents.Events() {
+			switch ev.Kind {
+			case traffic.FleetSpawned:
+				a := ev.Aircraft
+				fmt.Printf("✅ spawned  tail=%-10s objectID=%d\n", a.Tail, a.ObjectID)
+
+				// NonATC aircraft need control released before waypoints are accepted.
+				if a.Kind == traffic.KindNonATC {
+					if err := mgr.TrafficReleaseControl(a.ObjectID, reqRelease); err != nil {
+						fmt.Fprintln(os.Stderr, "release control failed:", err)
+						continue
+					}
+					sendWaypoints(mgr, a.ObjectID)
 				}
-				sendWaypoints(mgr, a.ObjectID)
+			case traffic.FleetSpawnFailed:
+				fmt.Printf("❌ failed   tail=%-10s %v\n", ev.Pending.Tail, ev.Err)
+			case traffic.FleetRemoved:
+				fmt.Printf("🗑  removed  tail=%-10s objectID=%d\n", ev.Aircraft.Tail, ev.Aircraft.ObjectID)
 			}
 		}
-	})
+	}()
 
 	// On connection, register the waypoint data definition and spawn aircraft.
 	_ = mgr.OnConnectionStateChange(func(old, new manager.ConnectionState) {
@@ -101,6 +100,7 @@ func main() {
 		}
 		// Brief pause so removal requests reach the sim before disconnect.
 		time.Sleep(500 * time.Millisecond)
+		fleetEvents.Unsubscribe()
 		mgr.Stop()
 		cancel()
 	}()
@@ -34,6 +34,7 @@ type API interface {
 	SubscribeToSystemEvent(eventID uint32, eventName string) error
 	UnsubscribeFromSystemEvent(eventID uint32) error
 	SetSystemEventState(eventID uint32, state types.SIMCONNECT_STATE) error
+	GetLastSentPacketID() (uint32, error)
 
 	SubscribeToFlowEvent() error
 	UnsubscribeFromFlowEvent() error
@@ -74,6 +74,30 @@ func ClientWithBufferSize(size int) engine.Option {
 	return engine.WithBufferSize(size)
 }
 
+// ClientWithControlBufferSize sets the capacity of the engine's control lane.
+// Default is 64.
+func ClientWithControlBufferSize(size int) engine.Option {
+	return engine.WithControlBufferSize(size)
+}
+
+// ClientWithDataCoalescing enables latest-value coalescing on the engine's
+// data lane for the given request IDs.
+func ClientWithDataCoalescing(requestIDs ...uint32) engine.Option {
+	return engine.WithDataCoalescing(requestIDs...)
+}
+
+// ClientWithBatchSize sets the maximum number of messages per StreamBatches
+// slice. Default is 64.
+func ClientWithBatchSize(size int) engine.Option {
+	return engine.WithBatchSize(size)
+}
+
+// ClientWithBatchLatency sets the latency budget for StreamBatches.
+// Default is 2ms.
+func ClientWithBatchLatency(d time.Duration) engine.Option {
+	return engine.WithBatchLatency(d)
+}
+
 // ClientWithLogger sets the logger for the client
 func ClientWithLogger(logger *slog.Logger) engine.Option {
 	return engine.WithLogger(logger)
@@ -172,6 +196,12 @@ func WithAutoReconnect(enabled bool) manager.Option {
 	return manager.WithAutoReconnect(enabled)
 }
 
+// WithBatchDispatch selects whether the manager consumes the engine stream in
+// batches. Default is true.
+func WithBatchDispatch(enabled bool) manager.Option {
+	return manager.WithBatchDispatch(enabled)
+}
+
 // WithSimStatePeriod sets the update frequency for internal SimState data requests.
 // Controls how often the manager polls simulator state variables.
 //
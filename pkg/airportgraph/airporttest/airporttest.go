@@ -0,0 +1,58 @@
+// Package airporttest builds small airports for tests of code working on
+// taxi networks, such as airportgraph and the traffic planners.
+//
+// Positions are east/north offsets in meters from the reference point of
+// the airport, as in facility.TaxiPoint. Each test lays out its own network
+// and draws it next to its fixture.
+package airporttest
+
+import "github.com/mrlm-net/simconnect/pkg/facility"
+
+// Reference point of the test airport.
+const (
+	ICAO      = "TEST"
+	Latitude  = 50.0
+	Longitude = 14.0
+)
+
+// Point returns a taxi point x meters east and z meters north of the
+// reference point.
+func Point(x, z float64) facility.TaxiPoint {
+	return facility.TaxiPoint{Type: 1, BiasX: x, BiasZ: z}
+}
+
+// Hold returns a hold-short taxi point at x, z.
+func Hold(x, z float64) facility.TaxiPoint {
+	return facility.TaxiPoint{Type: 2, BiasX: x, BiasZ: z}
+}
+
+// Taxi returns a segment of taxiway name from taxi point a to b.
+func Taxi(name string, a, b int32) facility.TaxiPath {
+	return facility.TaxiPath{Type: facility.TaxiPathTaxi, Name: name, Start: a, End: b}
+}
+
+// Runway returns a segment of runway 09/27 from taxi point a to b.
+func Runway(a, b int32) facility.TaxiPath {
+	return facility.TaxiPath{Type: facility.TaxiPathRunway, RunwayNumber: 9, Start: a, End: b}
+}
+
+// Airport returns the airport TEST at 50N 14E with the taxi network points
+// and paths and one runway, 09/27, length meters long along the x axis
+// through the reference point. Set Parking and Altitude as needed.
+func Airport(length float64, points []facility.TaxiPoint, paths []facility.TaxiPath) *facility.Airport {
+	return &facility.Airport{
+		ICAO:      ICAO,
+		Latitude:  Latitude,
+		Longitude: Longitude,
+		Runways: []facility.Runway{{
+			Latitude:  Latitude,
+			Longitude: Longitude,
+			Heading:   90,
+			Length:    length,
+			Primary:   facility.RunwayEnd{Number: 9},
+			Secondary: facility.RunwayEnd{Number: 27},
+		}},
+		TaxiPoints: points,
+		TaxiPaths:  paths,
+	}
+}
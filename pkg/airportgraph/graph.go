@@ -0,0 +1,240 @@
+// Package airportgraph builds a routable ground network from decoded
+// airport facility data and plans taxi routes over it.
+//
+// A Graph is built from a *facility.Airport fetched with at least
+// facility.IncludeRunways, IncludeParking and IncludeTaxiways. Its nodes are
+// the airport's taxi points followed by its parking spots; its edges are the
+// taxi paths that aircraft can use. Positions are kept as east/north offsets
+// in meters from the airport reference point, the frame SimConnect uses for
+// BiasX/BiasZ, and converted to latitude/longitude on request.
+//
+// The package has no simulator dependency.
+package airportgraph
+
+import (
+	"errors"
+	"math"
+
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+var (
+	// ErrNoRoute is returned when two nodes are not connected under the
+	// given constraints.
+	ErrNoRoute = errors.New("airportgraph: no route")
+
+	// ErrUnknownNode is returned for a node ID outside the graph.
+	ErrUnknownNode = errors.New("airportgraph: unknown node")
+
+	// ErrUnknownRunway is returned for a runway ident the airport does not
+	// have, or that no runway path of the taxi network belongs to.
+	ErrUnknownRunway = errors.New("airportgraph: unknown runway")
+)
+
+// DefaultMaxSegment is the longest taxi path kept in the graph, in meters.
+// MSFS taxi networks contain long "phantom" PATH connections, sometimes over
+// 2 km, spanning the whole airport. They are useful to the simulator's own
+// AI but send a waypoint-following aircraft straight across grass and
+// buildings.
+const DefaultMaxSegment = 500.0
+
+// NodeKind tells whether a node is a taxi point or a parking spot.
+type NodeKind uint8
+
+const (
+	NodeTaxiPoint NodeKind = iota
+	NodeParking
+)
+
+// holdShort reports whether a facility.TaxiPoint.Type marks a hold-short
+// position: HOLD_SHORT, ILS_HOLD_SHORT or one of their NO_DRAW variants.
+func holdShort(t int32) bool { return t >= 2 && t <= 5 }
+
+// Node is a vertex of the ground network.
+type Node struct {
+	ID        int
+	Kind      NodeKind
+	Index     int     // index into Airport.TaxiPoints or Airport.Parking
+	X         float64 // meters east of the airport reference point
+	Z         float64 // meters north of the airport reference point
+	Heading   float64 // parking spots only: heading of a parked aircraft
+	HoldShort bool    // taxi point marked as a hold-short position
+	OnRunway  bool    // endpoint of a runway path
+}
+
+// Edge is an undirected taxi path between two nodes.
+type Edge struct {
+	ID     int
+	A, B   int
+	Type   facility.TaxiPathType
+	Name   string // taxiway name, empty if unnamed
+	Runway string // runway ident of a runway path, e.g. "09L"
+	Length float64
+}
+
+// Other returns the endpoint of e that is not n.
+func (e Edge) Other(n int) int {
+	if e.A == n {
+		return e.B
+	}
+	return e.A
+}
+
+// Graph is the ground network of one airport.
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+
+	latitude  float64
+	longitude float64
+	points    int     // number of taxi point nodes; parking nodes follow
+	adj       [][]int // node → incident edge IDs
+	runways   []facility.Runway
+}
+
+// Option configures Build.
+type Option func(*config)
+
+type config struct {
+	maxSegment float64
+}
+
+// WithMaxSegment drops taxi paths longer than meters from the graph. Zero
+// keeps every path. Default is DefaultMaxSegment.
+func WithMaxSegment(meters float64) Option {
+	return func(c *config) {
+		c.maxSegment = meters
+	}
+}
+
+// routable reports whether aircraft may use taxi paths of type t. Closed
+// paths, vehicle service roads, public roads and painted lines are left out.
+func routable(t facility.TaxiPathType) bool {
+	switch t {
+	case facility.TaxiPathTaxi, facility.TaxiPathRunway, facility.TaxiPathParking, facility.TaxiPathPath:
+		return true
+	}
+	return false
+}
+
+// Build returns the ground network of ap. Paths whose endpoints do not
+// exist are skipped, so a partially fetched airport still builds.
+func Build(ap *facility.Airport, opts ...Option) *Graph {
+	cfg := config{maxSegment: DefaultMaxSegment}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	g := &Graph{
+		latitude:  ap.Latitude,
+		longitude: ap.Longitude,
+		points:    len(ap.TaxiPoints),
+		runways:   ap.Runways,
+	}
+	for i, p := range ap.TaxiPoints {
+		g.Nodes = append(g.Nodes, Node{
+			ID:        i,
+			Kind:      NodeTaxiPoint,
+			Index:     i,
+			X:         p.BiasX,
+			Z:         p.BiasZ,
+			HoldShort: holdShort(p.Type),
+		})
+	}
+	for i, p := range ap.Parking {
+		g.Nodes = append(g.Nodes, Node{
+			ID:      g.points + i,
+			Kind:    NodeParking,
+			Index:   i,
+			X:       p.BiasX,
+			Z:       p.BiasZ,
+			Heading: p.Heading,
+		})
+	}
+	g.adj = make([][]int, len(g.Nodes))
+
+	for _, p := range ap.TaxiPaths {
+		if !routable(p.Type) {
+			continue
+		}
+		a, b := int(p.Start), int(p.End)
+		if p.Type == facility.TaxiPathParking {
+			b += g.points
+		} else if b >= g.points {
+			continue
+		}
+		if a < 0 || a >= g.points || b < 0 || b >= len(g.Nodes) || a == b {
+			continue
+		}
+		length := math.Hypot(g.Nodes[a].X-g.Nodes[b].X, g.Nodes[a].Z-g.Nodes[b].Z)
+		if cfg.maxSegment > 0 && length > cfg.maxSegment {
+			continue
+		}
+		e := Edge{ID: len(g.Edges), A: a, B: b, Type: p.Type, Name: p.Name, Length: length}
+		if p.Type == facility.TaxiPathRunway {
+			e.Name = ""
+			e.Runway = facility.RunwayIdent(p.RunwayNumber, p.RunwayDesignator)
+			g.Nodes[a].OnRunway = true
+			g.Nodes[b].OnRunway = true
+		}
+		g.Edges = append(g.Edges, e)
+		g.adj[a] = append(g.adj[a], e.ID)
+		g.adj[b] = append(g.adj[b], e.ID)
+	}
+	return g
+}
+
+// ParkingNode returns the node of parking spot i of the airport.
+func (g *Graph) ParkingNode(i int) (int, bool) {
+	if i < 0 || g.points+i >= len(g.Nodes) {
+		return 0, false
+	}
+	return g.points + i, true
+}
+
+// Incident returns the edges touching node n.
+func (g *Graph) Incident(n int) []Edge {
+	if !g.valid(n) {
+		return nil
+	}
+	out := make([]Edge, len(g.adj[n]))
+	for i, id := range g.adj[n] {
+		out[i] = g.Edges[id]
+	}
+	return out
+}
+
+// LatLon returns the position of node n, or false if n is not a node of
+// the graph.
+func (g *Graph) LatLon(n int) (lat, lon float64, ok bool) {
+	if !g.valid(n) {
+		return 0, 0, false
+	}
+	node := g.Nodes[n]
+	lat, lon = convert.OffsetToLatLon(g.latitude, g.longitude, node.X, node.Z)
+	return lat, lon, true
+}
+
+// Offset converts a position to meters east and north of the airport
+// reference point.
+func (g *Graph) Offset(lat, lon float64) (x, z float64) {
+	return convert.LatLonToOffset(g.latitude, g.longitude, lat, lon)
+}
+
+// NearestNode returns the node closest to the offset x/z that satisfies
+// keep, or false if none does. A nil keep accepts every node.
+func (g *Graph) NearestNode(x, z float64, keep func(Node) bool) (int, bool) {
+	best, bestDist := -1, math.MaxFloat64
+	for _, n := range g.Nodes {
+		if keep != nil && !keep(n) {
+			continue
+		}
+		if d := math.Hypot(n.X-x, n.Z-z); d < bestDist {
+			best, bestDist = n.ID, d
+		}
+	}
+	return best, best >= 0
+}
+
+func (g *Graph) valid(n int) bool { return n >= 0 && n < len(g.Nodes) }
@@ -0,0 +1,136 @@
+package airportgraph
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph/airporttest"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+// Fixture taxi point indices. The airport has one runway, 09/27, 1600 m
+// long along the x axis through the reference point:
+//
+//	         P1        P0
+//	         C1 --C--- C0
+//	        /          |
+//	A0 --A- A1 --A--- A2 --A--- A3 --A- A4
+//	|                  |                 |
+//	H0 (B1)           H2 (B3)            H4 (B5)
+//	|                  |                 |
+//	R0 ---- R1 ------ R2 ------ R3 ---- R4     runway 09/27
+const (
+	r0 = iota
+	r1
+	r2
+	r3
+	r4
+	a0
+	a1
+	a2
+	a3
+	a4
+	h0
+	h2
+	h4
+	c0
+	c1
+	fixturePoints
+)
+
+const (
+	p0 = fixturePoints + iota
+	p1
+)
+
+// fixtureAirport returns a small airport at 50N 14E with a runway, a
+// parallel taxiway, three hold-short connectors and two parking spots.
+func fixtureAirport() *facility.Airport {
+	pt, hold, taxi, runway := airporttest.Point, airporttest.Hold, airporttest.Taxi, airporttest.Runway
+	points := []facility.TaxiPoint{
+		r0: pt(-800, 0), r1: pt(-400, 0), r2: pt(0, 0), r3: pt(400, 0), r4: pt(800, 0),
+		a0: pt(-800, 200), a1: pt(-400, 200), a2: pt(0, 300), a3: pt(400, 200), a4: pt(800, 200),
+		h0: hold(-800, 100), h2: hold(0, 100), h4: hold(800, 100),
+		c0: pt(0, 400), c1: pt(-300, 400),
+	}
+	paths := []facility.TaxiPath{
+		runway(r0, r1), runway(r1, r2), runway(r2, r3), runway(r3, r4),
+		taxi("A", a0, a1), taxi("A", a1, a2), taxi("A", a2, a3), taxi("A", a3, a4),
+		taxi("B1", a0, h0), taxi("B1", h0, r0),
+		taxi("B3", a2, h2), taxi("B3", h2, r2),
+		taxi("B5", a4, h4), taxi("B5", h4, r4),
+		taxi("C", a2, c0), taxi("C", c1, c0),
+		taxi("D", c1, a1),
+		{Type: facility.TaxiPathParking, Start: c0, End: 0},
+		{Type: facility.TaxiPathParking, Start: c1, End: 1},
+		// Left out of the graph: closed, and a phantom path across the field.
+		{Type: facility.TaxiPathClosed, Start: a0, End: a4},
+		{Type: facility.TaxiPathPath, Start: c1, End: r4},
+		// Dangling endpoint.
+		taxi("E", a4, 99),
+	}
+	ap := airporttest.Airport(1600, points, paths)
+	ap.Parking = []facility.Parking{
+		{Type: facility.ParkingGateMedium, Heading: 0, BiasX: 0, BiasZ: 450},
+		{Type: facility.ParkingRampGA, Heading: 0, BiasX: -300, BiasZ: 450},
+	}
+	return ap
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	if len(g.Nodes) != fixturePoints+2 {
+		t.Fatalf("nodes = %d, want %d", len(g.Nodes), fixturePoints+2)
+	}
+	if len(g.Edges) != 19 {
+		t.Errorf("edges = %d, want 19", len(g.Edges))
+	}
+	if n, ok := g.ParkingNode(1); !ok || n != p1 || g.Nodes[n].Kind != NodeParking {
+		t.Errorf("ParkingNode(1) = %d, %v", n, ok)
+	}
+	if _, ok := g.ParkingNode(2); ok {
+		t.Error("ParkingNode(2) found a spot the airport does not have")
+	}
+	if !g.Nodes[h2].HoldShort || g.Nodes[a2].HoldShort {
+		t.Error("hold-short flags not taken from taxi point types")
+	}
+	if !g.Nodes[r1].OnRunway || g.Nodes[h0].OnRunway {
+		t.Error("on-runway flags not taken from runway paths")
+	}
+	for _, e := range g.Incident(r0) {
+		if e.Type == facility.TaxiPathRunway && (e.Runway != "09" || e.Name != "") {
+			t.Errorf("runway edge = %+v", e)
+		}
+	}
+
+	if got := len(Build(fixtureAirport(), WithMaxSegment(0)).Edges); got != 20 {
+		t.Errorf("WithMaxSegment(0) edges = %d, want 20", got)
+	}
+}
+
+func TestLatLon(t *testing.T) {
+	g := Build(fixtureAirport())
+	lat, lon, ok := g.LatLon(r4)
+	if !ok || math.Abs(lat-50) > 1e-6 || lon <= 14 {
+		t.Fatalf("LatLon(R4) = %f, %f, %v", lat, lon, ok)
+	}
+	for _, n := range []int{-1, len(g.Nodes)} {
+		if _, _, ok := g.LatLon(n); ok {
+			t.Errorf("LatLon(%d) ok for a node outside the graph", n)
+		}
+	}
+	x, z := g.Offset(lat, lon)
+	if math.Abs(x-800) > 0.01 || math.Abs(z) > 0.01 {
+		t.Errorf("Offset round trip = %f, %f", x, z)
+	}
+
+	n, ok := g.NearestNode(-390, 190, nil)
+	if !ok || n != a1 {
+		t.Errorf("NearestNode = %d, want A1", n)
+	}
+	n, ok = g.NearestNode(-700, 190, func(n Node) bool { return n.HoldShort })
+	if !ok || n != h0 {
+		t.Errorf("NearestNode(hold short) = %d, want H0", n)
+	}
+}
@@ -0,0 +1,167 @@
+package airportgraph
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Pushback describes how an aircraft leaves a parking spot: pushed back
+// from Parking onto the taxi network at Entry, then lined up toward Start,
+// where taxiing begins.
+type Pushback struct {
+	Parking int // parking node
+	Entry   int // first taxi point behind the parking spot
+	Start   int // taxi point the aircraft faces after the push
+	// SpawnHeading is the heading of an aircraft placed at Entry facing
+	// away from Start, i.e. nose toward the gate, as after a pushback.
+	SpawnHeading float64
+}
+
+// GateExit returns the pushback from parking node n. The entry point is the
+// taxi point joined to the spot that lies most directly behind a parked
+// aircraft; if the spot has no taxi path, it is the nearest taxi point
+// behind it.
+func (g *Graph) GateExit(n int) (Pushback, error) {
+	if !g.valid(n) || g.Nodes[n].Kind != NodeParking {
+		return Pushback{}, fmt.Errorf("%w: %d is not a parking spot", ErrUnknownNode, n)
+	}
+	p := g.Nodes[n]
+	rad := math.Mod(p.Heading+180, 360) * math.Pi / 180
+	pushX, pushZ := math.Sin(rad), math.Cos(rad)
+	along := func(from, to Node) float64 {
+		dx, dz := to.X-from.X, to.Z-from.Z
+		d := math.Hypot(dx, dz)
+		if d == 0 {
+			return 0
+		}
+		return (dx*pushX + dz*pushZ) / d
+	}
+
+	entry, best := -1, math.Inf(-1)
+	for _, id := range g.adj[n] {
+		next := g.Edges[id].Other(n)
+		if g.Nodes[next].Kind != NodeTaxiPoint {
+			continue
+		}
+		if a := along(p, g.Nodes[next]); a > best {
+			entry, best = next, a
+		}
+	}
+	if entry < 0 {
+		var ok bool
+		entry, ok = g.NearestNode(p.X, p.Z, func(c Node) bool {
+			return c.Kind == NodeTaxiPoint && along(p, c) > 0
+		})
+		if !ok {
+			return Pushback{}, fmt.Errorf("%w: parking %d has no taxi point behind it", ErrNoRoute, n)
+		}
+	}
+
+	start, best := entry, 0.0
+	for _, id := range g.adj[entry] {
+		next := g.Edges[id].Other(entry)
+		if g.Nodes[next].Kind != NodeTaxiPoint {
+			continue
+		}
+		if a := along(g.Nodes[entry], g.Nodes[next]); a > best {
+			start, best = next, a
+		}
+	}
+
+	pb := Pushback{Parking: n, Entry: entry, Start: start, SpawnHeading: p.Heading}
+	if start != entry {
+		pb.SpawnHeading = math.Mod(g.Heading(entry, start)+180, 360)
+	}
+	return pb, nil
+}
+
+// Departure is a taxi route from a parking spot to a runway.
+type Departure struct {
+	Pushback  Pushback
+	Route     Route // from Pushback.Start to Access.HoldShort
+	Access    RunwayAccess
+	Threshold Threshold
+}
+
+// DepartureRoute plans the taxi from parking node n to the hold-short point
+// of runway end ident. Of the runway entries reachable under c, the one
+// closest to the threshold is used, so the full runway length is available.
+func (g *Graph) DepartureRoute(n int, ident string, c Constraints) (Departure, error) {
+	pb, err := g.GateExit(n)
+	if err != nil {
+		return Departure{}, err
+	}
+	t, err := g.Threshold(ident)
+	if err != nil {
+		return Departure{}, err
+	}
+	r, a, err := g.RouteToRunway(pb.Start, ident, c)
+	if err != nil {
+		return Departure{}, err
+	}
+	return Departure{Pushback: pb, Route: r, Access: a, Threshold: t}, nil
+}
+
+// RouteToRunway plans the taxi from node from to the hold-short point of
+// runway end ident, using the entry closest to the threshold that is
+// reachable under c.
+func (g *Graph) RouteToRunway(from int, ident string, c Constraints) (Route, RunwayAccess, error) {
+	access, err := g.RunwayAccess(ident)
+	if err != nil {
+		return Route{}, RunwayAccess{}, err
+	}
+	for _, a := range access {
+		r, err := g.ShortestPath(from, a.HoldShort, c)
+		if errors.Is(err, ErrUnknownNode) {
+			return Route{}, RunwayAccess{}, err
+		}
+		if err == nil {
+			return r, a, nil
+		}
+	}
+	return Route{}, RunwayAccess{}, fmt.Errorf("%w: node %d -> runway %s", ErrNoRoute, from, normalizeRunway(ident))
+}
+
+// Arrival is a taxi route from a runway exit to a parking spot.
+type Arrival struct {
+	Threshold Threshold
+	Access    RunwayAccess // runway exit
+	Route     Route        // from Access.Entry to the parking spot
+}
+
+// ArrivalRoute plans the taxi after landing on runway end ident to parking
+// node n. The exit used is the first one at least rollout meters past the
+// threshold that leads to n, or the farthest one if none does. The route
+// never follows the runway; c.AvoidRunways is forced on.
+func (g *Graph) ArrivalRoute(ident string, rollout float64, n int, c Constraints) (Arrival, error) {
+	if !g.valid(n) || g.Nodes[n].Kind != NodeParking {
+		return Arrival{}, fmt.Errorf("%w: %d is not a parking spot", ErrUnknownNode, n)
+	}
+	t, err := g.Threshold(ident)
+	if err != nil {
+		return Arrival{}, err
+	}
+	access, err := g.RunwayAccess(ident)
+	if err != nil {
+		return Arrival{}, err
+	}
+	c.AvoidRunways = true
+
+	var fallback *Arrival
+	for i := len(access) - 1; i >= 0; i-- {
+		a := access[i]
+		r, err := g.ShortestPath(a.Entry, n, c)
+		if err != nil {
+			continue
+		}
+		arr := Arrival{Threshold: t, Access: a, Route: r}
+		if fallback == nil || a.Distance >= rollout {
+			fallback = &arr
+		}
+	}
+	if fallback == nil {
+		return Arrival{}, fmt.Errorf("%w: runway %s -> parking %d", ErrNoRoute, t.Runway, n)
+	}
+	return *fallback, nil
+}
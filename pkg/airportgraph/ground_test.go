@@ -0,0 +1,113 @@
+package airportgraph
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGateExit(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	pb, err := g.GateExit(p0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Pushback{Parking: p0, Entry: c0, Start: a2, SpawnHeading: 0}); pb != want {
+		t.Errorf("GateExit(P0) = %+v, want %+v", pb, want)
+	}
+
+	// A spot without a parking path uses the nearest taxi point behind it.
+	ap := fixtureAirport()
+	ap.TaxiPaths = ap.TaxiPaths[:len(ap.TaxiPaths)-5]
+	pb, err = Build(ap).GateExit(p0)
+	if err != nil || pb.Entry != c0 {
+		t.Errorf("unlinked GateExit(P0) = %+v, %v", pb, err)
+	}
+
+	if _, err := g.GateExit(a0); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("taxi point: err = %v, want ErrUnknownNode", err)
+	}
+}
+
+func TestDepartureRoute(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	d, err := g.DepartureRoute(p0, "09", Constraints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Access.Entry != r0 || d.Access.HoldShort != h0 || d.Threshold.Runway != "09" {
+		t.Errorf("access = %+v, threshold = %+v", d.Access, d.Threshold)
+	}
+	if got := g.Taxiways(d.Route); !reflect.DeepEqual(got, []string{"A", "B1"}) {
+		t.Errorf("taxiways = %v", got)
+	}
+	if d.Route.Nodes[0] != d.Pushback.Start {
+		t.Errorf("route starts at %d, want %d", d.Route.Nodes[0], d.Pushback.Start)
+	}
+
+	d, err = g.DepartureRoute(p0, "09", Constraints{PreferTaxiways: []string{"C", "D"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Taxiways(d.Route); !reflect.DeepEqual(got, []string{"C", "D", "A", "B1"}) {
+		t.Errorf("preferred taxiways = %v", got)
+	}
+
+	// With B1 closed the next entry down the runway is used.
+	d, err = g.DepartureRoute(p0, "09", Constraints{AvoidTaxiways: []string{"B1"}, AvoidRunways: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Access.Entry != r2 {
+		t.Errorf("entry = %d, want R2", d.Access.Entry)
+	}
+
+	if _, err := g.DepartureRoute(p0, "18", Constraints{}); !errors.Is(err, ErrUnknownRunway) {
+		t.Errorf("err = %v, want ErrUnknownRunway", err)
+	}
+	if _, err := g.DepartureRoute(p0, "09", Constraints{AvoidTaxiways: []string{"B1", "B3", "B5"}}); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("err = %v, want ErrNoRoute", err)
+	}
+}
+
+func TestRouteToRunway(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	r, a, err := g.RouteToRunway(a0, "27", Constraints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Entry != r4 || r.Nodes[len(r.Nodes)-1] != h4 {
+		t.Errorf("access = %+v, route = %v", a, r.Nodes)
+	}
+	if _, _, err := g.RouteToRunway(-1, "27", Constraints{}); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("err = %v, want ErrUnknownNode", err)
+	}
+}
+
+func TestArrivalRoute(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	a, err := g.ArrivalRoute("27", 600, p0, Constraints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Access.Entry != r2 {
+		t.Errorf("exit = %d, want R2", a.Access.Entry)
+	}
+	if want := []int{r2, h2, a2, c0, p0}; !reflect.DeepEqual(a.Route.Nodes, want) {
+		t.Errorf("route = %v, want %v", a.Route.Nodes, want)
+	}
+
+	// Past every exit: the farthest one is used.
+	a, err = g.ArrivalRoute("27", 5000, p0, Constraints{})
+	if err != nil || a.Access.Entry != r0 {
+		t.Errorf("long rollout: exit = %d, %v, want R0", a.Access.Entry, err)
+	}
+
+	if _, err := g.ArrivalRoute("27", 0, a0, Constraints{}); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("err = %v, want ErrUnknownNode", err)
+	}
+}
@@ -0,0 +1,220 @@
+package airportgraph
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+// DefaultPreferFactor scales the cost of preferred taxiways when
+// Constraints.PreferFactor is zero.
+const DefaultPreferFactor = 0.5
+
+// Constraints shape the routes ShortestPath finds. The zero value routes
+// over every usable path by distance.
+type Constraints struct {
+	// AvoidRunways excludes runway paths, so the route never rolls along a
+	// runway. Crossing one at an intersection of taxiways is still possible.
+	AvoidRunways bool
+	// PreferTaxiways lists taxiway names whose paths cost PreferFactor times
+	// their length.
+	PreferTaxiways []string
+	// PreferFactor scales preferred taxiways, in (0, 1]. Zero means
+	// DefaultPreferFactor.
+	PreferFactor float64
+	// AvoidTaxiways lists taxiway names that are not used at all.
+	AvoidTaxiways []string
+}
+
+// cost returns the routing cost of e, or false if e may not be used.
+func (c Constraints) cost(e Edge) (float64, bool) {
+	if c.AvoidRunways && e.Type == facility.TaxiPathRunway {
+		return 0, false
+	}
+	if e.Name != "" {
+		for _, name := range c.AvoidTaxiways {
+			if strings.EqualFold(name, e.Name) {
+				return 0, false
+			}
+		}
+		for _, name := range c.PreferTaxiways {
+			if strings.EqualFold(name, e.Name) {
+				f := c.PreferFactor
+				if f <= 0 || f > 1 {
+					f = DefaultPreferFactor
+				}
+				return e.Length * f, true
+			}
+		}
+	}
+	return e.Length, true
+}
+
+// Route is a path through the graph.
+type Route struct {
+	Nodes  []int   // node IDs from start to end
+	Edges  []int   // edge IDs; Edges[i] joins Nodes[i] and Nodes[i+1]
+	Length float64 // meters
+}
+
+// Taxiways returns the taxiway names along r with consecutive repeats and
+// unnamed paths removed, e.g. ["C", "A", "B1"]. Runway paths appear as their
+// runway ident.
+func (g *Graph) Taxiways(r Route) []string {
+	var out []string
+	for _, id := range r.Edges {
+		e := g.Edges[id]
+		name := e.Name
+		if e.Runway != "" {
+			name = e.Runway
+		}
+		if name == "" || (len(out) > 0 && out[len(out)-1] == name) {
+			continue
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+// Heading returns the true heading from node a toward node b.
+func (g *Graph) Heading(a, b int) float64 {
+	return calc.BearingFromOffsets(g.Nodes[b].X-g.Nodes[a].X, g.Nodes[b].Z-g.Nodes[a].Z)
+}
+
+// ShortestPath returns the lowest-cost route from one node to another.
+// Parking spots are only used as the start or end of a route, never passed
+// through. Returns ErrNoRoute if to cannot be reached under c.
+func (g *Graph) ShortestPath(from, to int, c Constraints) (Route, error) {
+	if !g.valid(from) || !g.valid(to) {
+		return Route{}, fmt.Errorf("%w: %d -> %d", ErrUnknownNode, from, to)
+	}
+	if from == to {
+		return Route{Nodes: []int{from}}, nil
+	}
+
+	via := g.search(from, c, func(n int, _ float64) bool { return n == to })
+	if via[to] < 0 {
+		return Route{}, fmt.Errorf("%w: %d -> %d", ErrNoRoute, from, to)
+	}
+
+	var r Route
+	for n := to; n != from; {
+		e := g.Edges[via[n]]
+		r.Nodes = append(r.Nodes, n)
+		r.Edges = append(r.Edges, e.ID)
+		r.Length += e.Length
+		n = e.Other(n)
+	}
+	r.Nodes = append(r.Nodes, from)
+	reverse(r.Nodes)
+	reverse(r.Edges)
+	return r, nil
+}
+
+// search runs Dijkstra from node from under c and calls visit for every
+// node in order of increasing cost, until visit returns true. It returns,
+// for each node reached, the edge it was reached by, or -1.
+func (g *Graph) search(from int, c Constraints, visit func(n int, cost float64) bool) []int {
+	dist := make([]float64, len(g.Nodes))
+	via := make([]int, len(g.Nodes))
+	for i := range dist {
+		dist[i] = math.Inf(1)
+		via[i] = -1
+	}
+	dist[from] = 0
+	q := &queue{{node: from}}
+	for q.Len() > 0 {
+		cur := heap.Pop(q).(item)
+		if cur.cost > dist[cur.node] {
+			continue // stale entry
+		}
+		if visit(cur.node, cur.cost) {
+			break
+		}
+		if cur.node != from && g.Nodes[cur.node].Kind == NodeParking {
+			continue
+		}
+		for _, id := range g.adj[cur.node] {
+			e := g.Edges[id]
+			w, ok := c.cost(e)
+			if !ok {
+				continue
+			}
+			next := e.Other(cur.node)
+			if d := cur.cost + w; d < dist[next] {
+				dist[next] = d
+				via[next] = id
+				heap.Push(q, item{node: next, cost: d})
+			}
+		}
+	}
+	return via
+}
+
+// Reachable returns, for every node, whether it can be reached from node
+// from under c. Parking spots other than from are reachable but not passed
+// through.
+func (g *Graph) Reachable(from int, c Constraints) []bool {
+	seen := make([]bool, len(g.Nodes))
+	if !g.valid(from) {
+		return seen
+	}
+	seen[from] = true
+	stack := []int{from}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n != from && g.Nodes[n].Kind == NodeParking {
+			continue
+		}
+		for _, id := range g.adj[n] {
+			e := g.Edges[id]
+			if _, ok := c.cost(e); !ok {
+				continue
+			}
+			if next := e.Other(n); !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return seen
+}
+
+// NearestReachable returns the taxi point reachable from node from under c
+// that is closest to the offset x/z, or false if from reaches nothing.
+func (g *Graph) NearestReachable(from int, x, z float64, c Constraints) (int, bool) {
+	seen := g.Reachable(from, c)
+	return g.NearestNode(x, z, func(n Node) bool {
+		return seen[n.ID] && n.Kind == NodeTaxiPoint
+	})
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// item is a node in the Dijkstra priority queue.
+type item struct {
+	node int
+	cost float64
+}
+
+type queue []item
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(item)) }
+func (q *queue) Pop() any {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
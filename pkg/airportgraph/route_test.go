@@ -0,0 +1,90 @@
+package airportgraph
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	tests := []struct {
+		name     string
+		from, to int
+		c        Constraints
+		nodes    []int
+		taxiways []string
+	}{
+		{"runway is shortest", h0, h4, Constraints{},
+			[]int{h0, r0, r1, r2, r3, r4, h4}, []string{"B1", "09", "B5"}},
+		{"avoid runways", h0, h4, Constraints{AvoidRunways: true},
+			[]int{h0, a0, a1, a2, a3, a4, h4}, []string{"B1", "A", "B5"}},
+		{"shortest taxiway", a2, a0, Constraints{},
+			[]int{a2, a1, a0}, []string{"A"}},
+		{"prefer taxiways", a2, a0, Constraints{PreferTaxiways: []string{"c", "d"}},
+			[]int{a2, c0, c1, a1, a0}, []string{"C", "D", "A"}},
+		{"avoid taxiway", a2, a0, Constraints{AvoidTaxiways: []string{"A"}},
+			[]int{a2, h2, r2, r1, r0, h0, a0}, []string{"B3", "09", "B1"}},
+		{"from parking", p0, p1, Constraints{},
+			[]int{p0, c0, c1, p1}, []string{"C"}},
+	}
+	for _, tt := range tests {
+		r, err := g.ShortestPath(tt.from, tt.to, tt.c)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(r.Nodes, tt.nodes) {
+			t.Errorf("%s: nodes = %v, want %v", tt.name, r.Nodes, tt.nodes)
+		}
+		if got := g.Taxiways(r); !reflect.DeepEqual(got, tt.taxiways) {
+			t.Errorf("%s: taxiways = %v, want %v", tt.name, got, tt.taxiways)
+		}
+		if len(r.Edges) != len(r.Nodes)-1 {
+			t.Errorf("%s: %d edges for %d nodes", tt.name, len(r.Edges), len(r.Nodes))
+		}
+	}
+
+	r, _ := g.ShortestPath(h0, h4, Constraints{})
+	if math.Abs(r.Length-1800) > 1e-9 {
+		t.Errorf("length = %f, want 1800", r.Length)
+	}
+}
+
+func TestShortestPathErrors(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	if _, err := g.ShortestPath(p1, p0, Constraints{AvoidTaxiways: []string{"C", "D"}}); !errors.Is(err, ErrNoRoute) {
+		t.Errorf("cut off: err = %v, want ErrNoRoute", err)
+	}
+	if _, err := g.ShortestPath(a0, len(g.Nodes), Constraints{}); !errors.Is(err, ErrUnknownNode) {
+		t.Errorf("bad node: err = %v, want ErrUnknownNode", err)
+	}
+	if r, err := g.ShortestPath(a0, a0, Constraints{}); err != nil || len(r.Nodes) != 1 || r.Length != 0 {
+		t.Errorf("same node: %+v, %v", r, err)
+	}
+}
+
+func TestReachable(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	seen := g.Reachable(p1, Constraints{AvoidTaxiways: []string{"C", "D"}})
+	for n, ok := range seen {
+		if want := n == p1 || n == c1; ok != want {
+			t.Errorf("node %d reachable = %v, want %v", n, ok, want)
+		}
+	}
+
+	// Parking spots are not passed through: from C1 without taxiway C or D,
+	// nothing beyond P1 is reachable.
+	if n, ok := g.NearestReachable(c1, 800, 0, Constraints{AvoidTaxiways: []string{"C", "D"}}); !ok || n != c1 {
+		t.Errorf("NearestReachable = %d, %v, want C1", n, ok)
+	}
+	if n, ok := g.NearestReachable(c1, 800, 0, Constraints{}); !ok || n != r4 {
+		t.Errorf("NearestReachable = %d, %v, want R4", n, ok)
+	}
+	if h := g.Heading(a0, h0); h != 180 {
+		t.Errorf("Heading(A0, H0) = %f, want 180", h)
+	}
+}
@@ -0,0 +1,196 @@
+package airportgraph
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+// holdShortSearch is how far from a runway entry, in meters of taxi path,
+// a marked hold-short point is looked for.
+const holdShortSearch = 150.0
+
+// Threshold is one end of a runway in graph coordinates.
+type Threshold struct {
+	Runway  string  // ident of this end, e.g. "24"
+	X, Z    float64 // meters east and north of the airport reference point
+	Heading float64 // true heading of takeoffs and landings from this end
+	Length  float64 // runway length in meters
+}
+
+// Along returns how far the offset x/z lies down the runway from the
+// threshold, in meters. Negative values are behind the threshold.
+func (t Threshold) Along(x, z float64) float64 {
+	rad := t.Heading * math.Pi / 180
+	return (x-t.X)*math.Sin(rad) + (z-t.Z)*math.Cos(rad)
+}
+
+// RunwayAccess is a place where the taxi network joins a runway.
+type RunwayAccess struct {
+	Entry     int     // node on the runway
+	HoldShort int     // node where aircraft wait before entering at Entry
+	Distance  float64 // along the runway from the threshold, in meters
+}
+
+// normalizeRunway returns ident in the form facility.RunwayIdent produces,
+// so "9l" and "09L" match.
+func normalizeRunway(ident string) string {
+	ident = strings.ToUpper(strings.TrimSpace(ident))
+	if ident != "" && isDigit(ident[0]) && (len(ident) == 1 || !isDigit(ident[1])) {
+		ident = "0" + ident
+	}
+	return ident
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// runway returns the airport runway with an end named ident and whether
+// ident is its primary end.
+func (g *Graph) runway(ident string) (facility.Runway, bool, error) {
+	ident = normalizeRunway(ident)
+	for _, r := range g.runways {
+		switch ident {
+		case r.Primary.Ident():
+			return r, true, nil
+		case r.Secondary.Ident():
+			return r, false, nil
+		}
+	}
+	return facility.Runway{}, false, fmt.Errorf("%w: %s", ErrUnknownRunway, ident)
+}
+
+// Threshold returns the threshold of runway end ident, e.g. "24" or "06L".
+func (g *Graph) Threshold(ident string) (Threshold, error) {
+	r, primary, err := g.runway(ident)
+	if err != nil {
+		return Threshold{}, err
+	}
+	cx, cz := g.Offset(r.Latitude, r.Longitude)
+	rad := r.Heading * math.Pi / 180
+	half := r.Length / 2
+	t := Threshold{Runway: r.Primary.Ident(), Heading: r.Heading, Length: r.Length}
+	if primary {
+		t.X, t.Z = cx-half*math.Sin(rad), cz-half*math.Cos(rad)
+	} else {
+		t.Runway = r.Secondary.Ident()
+		t.Heading = math.Mod(r.Heading+180, 360)
+		t.X, t.Z = cx+half*math.Sin(rad), cz+half*math.Cos(rad)
+	}
+	return t, nil
+}
+
+// RunwayAccess returns every place where the taxi network joins runway end
+// ident, ordered by distance from its threshold. Each entry pairs the node
+// on the runway with the hold-short node in front of it: the nearest taxi
+// point marked hold-short within 150 m of taxi path, or else the first
+// taxi point off the runway.
+func (g *Graph) RunwayAccess(ident string) ([]RunwayAccess, error) {
+	r, _, err := g.runway(ident)
+	if err != nil {
+		return nil, err
+	}
+	t, err := g.Threshold(ident)
+	if err != nil {
+		return nil, err
+	}
+	ends := map[string]bool{r.Primary.Ident(): true, r.Secondary.Ident(): true}
+
+	onRunway := make(map[int]bool)
+	for _, e := range g.Edges {
+		if e.Type == facility.TaxiPathRunway && ends[e.Runway] {
+			onRunway[e.A] = true
+			onRunway[e.B] = true
+		}
+	}
+	if len(onRunway) == 0 {
+		return nil, fmt.Errorf("%w: %s has no runway paths", ErrUnknownRunway, t.Runway)
+	}
+
+	var out []RunwayAccess
+	for n := range onRunway {
+		hold, ok := g.holdShortFor(n)
+		if !ok {
+			continue
+		}
+		out = append(out, RunwayAccess{Entry: n, HoldShort: hold, Distance: t.Along(g.Nodes[n].X, g.Nodes[n].Z)})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Distance != out[j].Distance {
+			return out[i].Distance < out[j].Distance
+		}
+		return out[i].Entry < out[j].Entry
+	})
+	return out, nil
+}
+
+// holdShortFor returns the hold-short node of runway node entry, or false
+// if entry has no taxi path off the runway.
+func (g *Graph) holdShortFor(entry int) (int, bool) {
+	first, marked := -1, -1
+	g.search(entry, Constraints{AvoidRunways: true}, func(n int, cost float64) bool {
+		if cost > holdShortSearch {
+			return true
+		}
+		node := g.Nodes[n]
+		if node.Kind != NodeTaxiPoint || node.OnRunway {
+			return false
+		}
+		if first < 0 {
+			first = n
+		}
+		if node.HoldShort {
+			marked = n
+			return true
+		}
+		return false
+	})
+	if marked >= 0 {
+		return marked, true
+	}
+	if first >= 0 {
+		return first, true
+	}
+	// Nothing off the runway within the search radius: take any neighbour.
+	for _, id := range g.adj[entry] {
+		e := g.Edges[id]
+		if next := e.Other(entry); e.Type != facility.TaxiPathRunway && g.Nodes[next].Kind == NodeTaxiPoint {
+			return next, true
+		}
+	}
+	return 0, false
+}
+
+// RunwayOf returns the runway node n lies on, named by both ends such as
+// "09/27", or false if no runway path touches n. Both ends of a runway give
+// the same name, so it identifies the runway as a whole.
+func (g *Graph) RunwayOf(n int) (string, bool) {
+	if !g.valid(n) {
+		return "", false
+	}
+	for _, id := range g.adj[n] {
+		e := g.Edges[id]
+		if e.Type != facility.TaxiPathRunway {
+			continue
+		}
+		name, err := g.RunwayName(e.Runway)
+		if err != nil {
+			return e.Runway, true
+		}
+		return name, true
+	}
+	return "", false
+}
+
+// RunwayName returns the name RunwayOf gives the runway with end ident,
+// e.g. "09/27" for "27". Returns ErrUnknownRunway if the airport has no
+// such runway end.
+func (g *Graph) RunwayName(ident string) (string, error) {
+	r, _, err := g.runway(ident)
+	if err != nil {
+		return "", err
+	}
+	return r.Primary.Ident() + "/" + r.Secondary.Ident(), nil
+}
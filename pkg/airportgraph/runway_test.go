@@ -0,0 +1,99 @@
+package airportgraph
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestThreshold(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	tests := []struct {
+		ident   string
+		runway  string
+		x       float64
+		heading float64
+	}{
+		{"09", "09", -800, 90},
+		{"9", "09", -800, 90},
+		{"27", "27", 800, 270},
+	}
+	for _, tt := range tests {
+		th, err := g.Threshold(tt.ident)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.ident, err)
+		}
+		if th.Runway != tt.runway || th.Heading != tt.heading || th.Length != 1600 {
+			t.Errorf("%s: %+v", tt.ident, th)
+		}
+		if math.Abs(th.X-tt.x) > 0.5 || math.Abs(th.Z) > 0.5 {
+			t.Errorf("%s: position = %f, %f, want %f, 0", tt.ident, th.X, th.Z, tt.x)
+		}
+	}
+
+	if _, err := g.Threshold("18"); !errors.Is(err, ErrUnknownRunway) {
+		t.Errorf("err = %v, want ErrUnknownRunway", err)
+	}
+}
+
+func TestRunwayAccess(t *testing.T) {
+	g := Build(fixtureAirport())
+
+	tests := []struct {
+		ident string
+		want  []RunwayAccess
+	}{
+		{"09", []RunwayAccess{{r0, h0, 0}, {r2, h2, 800}, {r4, h4, 1600}}},
+		{"27", []RunwayAccess{{r4, h4, 0}, {r2, h2, 800}, {r0, h0, 1600}}},
+	}
+	for _, tt := range tests {
+		got, err := g.RunwayAccess(tt.ident)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.ident, err)
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %+v", tt.ident, got)
+		}
+		for i, w := range tt.want {
+			if got[i].Entry != w.Entry || got[i].HoldShort != w.HoldShort || math.Abs(got[i].Distance-w.Distance) > 0.5 {
+				t.Errorf("%s[%d] = %+v, want %+v", tt.ident, i, got[i], w)
+			}
+		}
+	}
+
+	// Without hold-short markings the first taxi point off the runway is used.
+	ap := fixtureAirport()
+	for i := range ap.TaxiPoints {
+		ap.TaxiPoints[i].Type = 1
+	}
+	got, err := Build(ap).RunwayAccess("09")
+	if err != nil || len(got) == 0 || got[0].HoldShort != h0 {
+		t.Errorf("unmarked: %+v, %v", got, err)
+	}
+
+	ap.TaxiPaths = ap.TaxiPaths[4:]
+	if _, err := Build(ap).RunwayAccess("09"); !errors.Is(err, ErrUnknownRunway) {
+		t.Errorf("no runway paths: err = %v, want ErrUnknownRunway", err)
+	}
+}
+
+func TestRunwayOf(t *testing.T) {
+	g := Build(fixtureAirport())
+	for _, n := range []int{r0, r2, r4} {
+		if got, ok := g.RunwayOf(n); !ok || got != "09/27" {
+			t.Errorf("RunwayOf(%d) = %q, %v, want 09/27", n, got, ok)
+		}
+	}
+	if got, ok := g.RunwayOf(h2); ok {
+		t.Errorf("RunwayOf(h2) = %q, want none", got)
+	}
+	for _, ident := range []string{"9", "27"} {
+		if got, err := g.RunwayName(ident); err != nil || got != "09/27" {
+			t.Errorf("RunwayName(%s) = %q, %v, want 09/27", ident, got, err)
+		}
+	}
+	if _, err := g.RunwayName("18"); !errors.Is(err, ErrUnknownRunway) {
+		t.Errorf("RunwayName(18): err = %v, want ErrUnknownRunway", err)
+	}
+}
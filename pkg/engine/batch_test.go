@@ -0,0 +1,91 @@
+//go:build windows
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+func TestStreamBatchesGathersAvailable(t *testing.T) {
+	e := newLaneTestEngine(4, 16)
+	e.config.BatchSize = 8
+	e.config.BatchLatency = time.Second
+	defer e.cancel()
+
+	for i := 0; i < 10; i++ {
+		e.data <- newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, uint32(i), 0)
+	}
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EXCEPTION, 0, 0)
+	e.mergeBatches()
+
+	first := <-e.batches
+	if len(first) != 8 {
+		t.Fatalf("first batch len = %d, want 8", len(first))
+	}
+	if types.SIMCONNECT_RECV_ID(first[0].DwID) != types.SIMCONNECT_RECV_ID_EXCEPTION {
+		t.Fatalf("control message not at the head of the batch")
+	}
+	second := <-e.batches
+	if len(second) != 3 {
+		t.Fatalf("second batch len = %d, want 3", len(second))
+	}
+}
+
+func TestStreamBatchesStopsAtQuit(t *testing.T) {
+	e := newLaneTestEngine(4, 16)
+	e.config.BatchSize = 8
+	e.config.BatchLatency = time.Second
+
+	e.data <- newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 1, 0)
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_QUIT, 0, 0)
+	e.mergeBatches()
+
+	batch := <-e.batches
+	if len(batch) != 1 || !isQuit(batch[0]) {
+		t.Fatalf("expected a batch holding only QUIT, got %d messages", len(batch))
+	}
+	if _, ok := <-e.batches; ok {
+		t.Fatalf("batches channel still open after QUIT")
+	}
+}
+
+// BenchmarkStreamDelivery compares per-message delivery with batch delivery
+// for a saturated data lane.
+func BenchmarkStreamDelivery(b *testing.B) {
+	produce := func(e *Engine) {
+		for i := uint32(0); e.ctx.Err() == nil; i++ {
+			e.pushData(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, i%8, 0))
+		}
+	}
+
+	b.Run("messages", func(b *testing.B) {
+		e := newLaneTestEngine(DEFAULT_CONTROL_BUFFER_SIZE, DEFAULT_BUFFER_SIZE)
+		defer e.cancel()
+		e.merge()
+		go produce(e)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			msg := <-e.queue
+			msg.Release()
+		}
+	})
+
+	b.Run("batches", func(b *testing.B) {
+		e := newLaneTestEngine(DEFAULT_CONTROL_BUFFER_SIZE, DEFAULT_BUFFER_SIZE)
+		e.config.BatchSize = DEFAULT_BATCH_SIZE
+		e.config.BatchLatency = DEFAULT_BATCH_LATENCY
+		defer e.cancel()
+		e.mergeBatches()
+		go produce(e)
+		b.ResetTimer()
+		for n := 0; n < b.N; {
+			for _, msg := range <-e.batches {
+				msg.Release()
+				n++
+			}
+		}
+	})
+}
@@ -0,0 +1,67 @@
+//go:build windows
+
+package engine
+
+import "sync/atomic"
+
+// poisonByte is written over released buffers in debug builds so stale
+// readers see obviously invalid data instead of a recycled packet.
+const poisonByte = 0xDE
+
+// messageBuffer is the reference-counted storage shared by every reference
+// to a single dispatched message.
+type messageBuffer struct {
+	refs atomic.Int32
+	data []byte
+	put  func() // Returns data to its pool, nil for unpooled buffers
+}
+
+// newMessageBuffer wraps data in a buffer holding a single reference.
+func newMessageBuffer(data []byte, put func()) *messageBuffer {
+	b := &messageBuffer{data: data, put: put}
+	b.refs.Store(1)
+	return b
+}
+
+// retain adds a reference. Retaining a buffer that was already returned to
+// the pool is always a bug, so it panics regardless of build mode.
+//
+// The count is only incremented while it is positive, so the panic leaves
+// it untouched.
+func (b *messageBuffer) retain() {
+	for {
+		n := b.refs.Load()
+		if n <= 0 {
+			panic("engine: Retain called on a released Message")
+		}
+		if b.refs.CompareAndSwap(n, n+1) {
+			return
+		}
+	}
+}
+
+// release drops a reference and recycles the storage when none remain.
+// Debug builds poison the storage and keep it out of the pool instead, so
+// any late reader keeps observing the poison.
+func (b *messageBuffer) release() {
+	n := b.refs.Add(-1)
+	switch {
+	case n == 0:
+		if debugMessages {
+			for i := range b.data {
+				b.data[i] = poisonByte
+			}
+			return
+		}
+		if b.put != nil {
+			b.put()
+		}
+	case n < 0 && debugMessages:
+		panic("engine: Message released more times than it was retained")
+	}
+}
+
+// live reports whether at least one reference is still held.
+func (b *messageBuffer) live() bool {
+	return b.refs.Load() > 0
+}
@@ -21,6 +21,9 @@ type Client interface {
 	RequestSystemState(requestID uint32, state types.SIMCONNECT_SYSTEM_STATE) error
 	SubscribeToSystemEvent(eventID uint32, eventName string) error
 	UnsubscribeFromSystemEvent(eventID uint32) error
+	// GetLastSentPacketID returns the send ID of the last request, which
+	// SIMCONNECT_RECV_EXCEPTION reports back in DwSendID.
+	GetLastSentPacketID() (uint32, error)
 
 	// SubscribeToFlowEvent subscribes to all simulator flow events (MSFS 2024 only).
 	SubscribeToFlowEvent() error
@@ -6,6 +6,7 @@ package engine
 import (
 	"context"
 	"strings"
+	"time"
 
 	"log/slog"
 
@@ -13,8 +14,11 @@ import (
 )
 
 const (
-	DEFAULT_BUFFER_SIZE = 256
-	DEFAULT_DLL_PATH    = "C:/MSFS 2024 SDK/SimConnect SDK/lib/SimConnect.dll"
+	DEFAULT_BUFFER_SIZE         = 256
+	DEFAULT_CONTROL_BUFFER_SIZE = 64
+	DEFAULT_BATCH_SIZE          = 64
+	DEFAULT_BATCH_LATENCY       = 2 * time.Millisecond
+	DEFAULT_DLL_PATH            = "C:/MSFS 2024 SDK/SimConnect SDK/lib/SimConnect.dll"
 )
 
 type Option func(*Config)
@@ -27,6 +31,18 @@ type Config struct {
 	// a default logger. If `Logger` is provided via `WithLogger`, that
 	// logger takes precedence.
 	LogLevel slog.Level
+	// ControlBufferSize is the capacity of the control lane, which carries
+	// lifecycle, exception and event messages. BufferSize sizes the data lane.
+	ControlBufferSize int
+	// CoalesceRequestIDs lists the request IDs whose data-lane samples are
+	// coalesced: only the latest queued sample per request and object is kept
+	// when the consumer falls behind. Other requests are never coalesced.
+	CoalesceRequestIDs map[uint32]struct{}
+	// BatchSize caps the number of messages per StreamBatches slice.
+	BatchSize int
+	// BatchLatency bounds how long StreamBatches keeps gathering messages
+	// into a batch once the first one is available.
+	BatchLatency time.Duration
 }
 
 func WithBufferSize(size int) Option {
@@ -35,6 +51,58 @@ func WithBufferSize(size int) Option {
 	}
 }
 
+// WithControlBufferSize sets the capacity of the control lane. Sizes below
+// one fall back to DEFAULT_CONTROL_BUFFER_SIZE.
+func WithControlBufferSize(size int) Option {
+	return func(c *Config) {
+		if size < 1 {
+			size = DEFAULT_CONTROL_BUFFER_SIZE
+		}
+		c.ControlBufferSize = size
+	}
+}
+
+// WithDataCoalescing enables latest-value coalescing on the data lane for
+// the given request IDs. While the consumer lags, a newer SIMOBJECT_DATA,
+// SIMOBJECT_DATA_BYTYPE or CLIENT_DATA packet for the same request and object
+// replaces the queued one, so slow consumers see fresh values instead of a
+// growing backlog. Requests not listed keep every sample. Repeated calls add
+// to the set.
+func WithDataCoalescing(requestIDs ...uint32) Option {
+	return func(c *Config) {
+		if c.CoalesceRequestIDs == nil {
+			c.CoalesceRequestIDs = make(map[uint32]struct{}, len(requestIDs))
+		}
+		for _, id := range requestIDs {
+			c.CoalesceRequestIDs[id] = struct{}{}
+		}
+	}
+}
+
+// WithBatchSize sets the maximum number of messages delivered per
+// StreamBatches slice. Sizes below one fall back to DEFAULT_BATCH_SIZE.
+func WithBatchSize(size int) Option {
+	return func(c *Config) {
+		if size < 1 {
+			size = DEFAULT_BATCH_SIZE
+		}
+		c.BatchSize = size
+	}
+}
+
+// WithBatchLatency sets the latency budget for StreamBatches: a batch is
+// delivered once this much time has passed since its first message, even if
+// more messages are waiting. Non-positive values fall back to
+// DEFAULT_BATCH_LATENCY.
+func WithBatchLatency(d time.Duration) Option {
+	return func(c *Config) {
+		if d <= 0 {
+			d = DEFAULT_BATCH_LATENCY
+		}
+		c.BatchLatency = d
+	}
+}
+
 func WithDLLPath(path string) Option {
 	return func(c *Config) {
 		c.DLLPath = path
@@ -92,7 +160,10 @@ func defaultConfig() *Config {
 			Context:    context.Background(),
 			DLLPath:    DEFAULT_DLL_PATH,
 		},
-		Heartbeat: HEARTBEAT_6HZ,
+		Heartbeat:         HEARTBEAT_6HZ,
+		ControlBufferSize: DEFAULT_CONTROL_BUFFER_SIZE,
+		BatchSize:         DEFAULT_BATCH_SIZE,
+		BatchLatency:      DEFAULT_BATCH_LATENCY,
 		// Defer creating the concrete logger until constructor time so
 		// options that set `LogLevel` or `Logger` are applied in the
 		// expected order. Default to INFO when no option is provided.
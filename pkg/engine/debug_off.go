@@ -0,0 +1,7 @@
+//go:build windows && !simconnect_debug
+
+package engine
+
+// debugMessages enables message ownership checks. Build with the
+// simconnect_debug tag to turn them on.
+const debugMessages = false
@@ -0,0 +1,6 @@
+//go:build windows && simconnect_debug
+
+package engine
+
+// debugMessages enables message ownership checks. See Message for details.
+const debugMessages = true
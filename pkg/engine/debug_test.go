@@ -0,0 +1,22 @@
+//go:build windows && simconnect_debug
+
+package engine
+
+import "testing"
+
+func TestMessageUseAfterReleasePanics(t *testing.T) {
+	var puts int
+	msg := newTestMessage(t, &puts)
+	stale := msg
+	msg.Release()
+
+	if b := msg.buf.data[0]; b != poisonByte {
+		t.Fatalf("released buffer not poisoned, got %#x", b)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected access after Release to panic")
+		}
+	}()
+	stale.AsEvent()
+}
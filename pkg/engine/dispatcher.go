@@ -47,10 +47,11 @@ const (
 	HEARTBEAT_EVENT_ID types.DWORD = 999999999 // SimConnect_SystemState_6Hz ID
 )
 
-// closeQueue safely closes the queue channel exactly once
+// closeQueue safely closes the queue and batches channels exactly once
 func (e *Engine) closeQueue() {
 	e.closeOnce.Do(func() {
 		close(e.queue)
+		close(e.batches)
 	})
 }
 
@@ -59,11 +60,7 @@ func (e *Engine) dispatch() error {
 	// Subscribe to a system event to receive regular updates about the simulator connection state
 	e.api.SubscribeToSystemEvent(uint32(HEARTBEAT_EVENT_ID), string(e.config.Heartbeat)) // SimConnect_SystemState_6Hz
 	e.sync.Go(func() {
-		defer func() {
-			e.logger.Debug("[dispatcher] Exiting dispatcher goroutine")
-			// Ensure queue is closed on all exit paths
-			e.closeQueue()
-		}()
+		defer e.logger.Debug("[dispatcher] Exiting dispatcher goroutine")
 
 		// Adaptive sleep for backoff when no messages available
 		sleepDuration := minSleep
@@ -78,14 +75,11 @@ func (e *Engine) dispatch() error {
 
 				if err != nil {
 					e.logger.Error("[dispatcher] Error", "error", err)
-					select {
-					case <-e.ctx.Done():
+					if !e.pushControl(Message{Err: err}) {
 						e.logger.Debug("[dispatcher] Context cancelled, stopping dispatcher")
 						return
-					case e.queue <- Message{Err: err}:
-						continue
 					}
-
+					continue
 				}
 
 				if recv == nil {
@@ -128,10 +122,10 @@ func (e *Engine) dispatch() error {
 
 				if recvID == types.SIMCONNECT_RECV_ID_QUIT {
 					e.logger.Debug("[dispatcher] Received SIMCONNECT_RECV_ID_QUIT, simulator is closing the connection")
-					// Send message that simulator is quitting
-					e.queue <- newMessage(recvCopy, size, err, dataCopy, release)
-					e.cancel()
-					return // closeQueue called by defer
+					// Hand QUIT to the control lane; the merger cancels the
+					// engine once the consumer has received it.
+					e.pushControl(newMessage(recvCopy, size, err, dataCopy, release))
+					return
 				}
 
 				if recvID == types.SIMCONNECT_RECV_ID_EXCEPTION {
@@ -141,13 +135,15 @@ func (e *Engine) dispatch() error {
 
 				if size > 0 {
 					e.logger.Debug("[dispatcher] Message received", "recvID", types.SIMCONNECT_RECV_ID(recvCopy.DwID))
-					// Send the copied message to the queue, respecting context cancellation
-					select {
-					case <-e.ctx.Done():
+					// Route the copied message to its lane, respecting context cancellation
+					msg := newMessage(recvCopy, size, err, dataCopy, release)
+					push := e.pushData
+					if isControlMessage(msg) {
+						push = e.pushControl
+					}
+					if !push(msg) {
 						e.logger.Debug("[dispatcher] Context cancelled, stopping dispatcher")
-						release() // Return buffer to pool on early exit
 						return
-					case e.queue <- newMessage(recvCopy, size, err, dataCopy, release):
 					}
 				} else {
 					// No data to send, release buffer
@@ -56,3 +56,43 @@ func (e *Engine) UnsubscribeToFacilitiesEX1(listType types.SIMCONNECT_FACILITY_L
 func (e *Engine) RequestAllFacilities(listType types.SIMCONNECT_FACILITY_LIST_TYPE, requestID uint32) error {
 	return e.api.RequestAllFacilities(listType, requestID)
 }
+
+// FacilityDataBytes returns the packed field data of a FACILITY_DATA message,
+// sized from the message rather than from the definition. The slice aliases
+// the receive buffer and is only valid until msg is released.
+func FacilityDataBytes(msg *Message) []byte {
+	fd := msg.AsFacilityData()
+	if fd == nil {
+		return nil
+	}
+	offset := unsafe.Offsetof(fd.Data)
+	size := uintptr(msg.Size)
+	if size == 0 {
+		size = uintptr(fd.DwSize)
+	}
+	if size <= offset {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(fd)), size)[offset:]
+}
+
+// FacilityListBytes returns the entry array of an AIRPORT, VOR, NDB or
+// WAYPOINT list message and the number of entries in it. Entry strides
+// differ between simulator versions, so the array is returned as bytes for
+// facility.DecodeList rather than as typed structs. The slice aliases the
+// receive buffer and is only valid until msg is released.
+func FacilityListBytes(msg *Message) ([]byte, int) {
+	list := msg.AsFacilityList()
+	if list == nil {
+		return nil, 0
+	}
+	header := unsafe.Sizeof(*list)
+	size := uintptr(msg.Size)
+	if size == 0 {
+		size = uintptr(list.DwSize)
+	}
+	if size <= header || list.DwArraySize == 0 {
+		return nil, 0
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(list)), size)[header:], int(list.DwArraySize)
+}
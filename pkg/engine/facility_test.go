@@ -0,0 +1,52 @@
+//go:build windows
+
+package engine
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+func TestFacilityDataBytes(t *testing.T) {
+	payload := []byte("LKPR\x00\x00\x00\x00packed-fields")
+	header := int(unsafe.Offsetof(types.SIMCONNECT_RECV_FACILITY_DATA{}.Data))
+	data := make([]byte, header+len(payload))
+	fd := (*types.SIMCONNECT_RECV_FACILITY_DATA)(unsafe.Pointer(&data[0]))
+	fd.DwSize = types.DWORD(len(data))
+	fd.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FACILITY_DATA)
+	copy(data[header:], payload)
+
+	msg := Message{SIMCONNECT_RECV: &fd.SIMCONNECT_RECV}
+	if got := FacilityDataBytes(&msg); !bytes.Equal(got, payload) {
+		t.Fatalf("FacilityDataBytes = %q, want %q", got, payload)
+	}
+
+	fd.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FACILITY_DATA_END)
+	if got := FacilityDataBytes(&msg); got != nil {
+		t.Fatalf("FacilityDataBytes on DATA_END = %q, want nil", got)
+	}
+}
+
+func TestFacilityListBytes(t *testing.T) {
+	entries := []byte("LKPR\x00\x00\x00\x00\x00LK\x00entry-one.LKVO\x00\x00\x00\x00\x00LK\x00entry-two.")
+	header := int(unsafe.Sizeof(types.SIMCONNECT_RECV_FACILITIES_LIST{}))
+	data := make([]byte, header+len(entries))
+	list := (*types.SIMCONNECT_RECV_FACILITIES_LIST)(unsafe.Pointer(&data[0]))
+	list.DwSize = types.DWORD(len(data))
+	list.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_AIRPORT_LIST)
+	list.DwArraySize = 2
+	copy(data[header:], entries)
+
+	msg := Message{SIMCONNECT_RECV: &list.SIMCONNECT_RECV}
+	if got, n := FacilityListBytes(&msg); !bytes.Equal(got, entries) || n != 2 {
+		t.Fatalf("FacilityListBytes = %q, %d, want %q, 2", got, n, entries)
+	}
+
+	list.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FACILITY_DATA)
+	if got, n := FacilityListBytes(&msg); got != nil || n != 0 {
+		t.Fatalf("FacilityListBytes on FACILITY_DATA = %q, %d, want nil, 0", got, n)
+	}
+}
@@ -88,3 +88,50 @@ func SubscribeInputEventValueAsString(recv *types.SIMCONNECT_RECV_SUBSCRIBE_INPU
 	}
 	return BytesToString(recv.Value[:]), true
 }
+
+// InputEventDescriptor is a decoded entry of an ENUMERATE_INPUT_EVENTS page.
+type InputEventDescriptor struct {
+	Name      string
+	Hash      uint64
+	Type      types.SIMCONNECT_INPUT_EVENT_TYPE
+	NodeNames string
+}
+
+// InputEventDescriptors decodes the descriptors of an ENUMERATE_INPUT_EVENTS page.
+// The entry stride is derived from the message size, so pages from simulator
+// builds without the NodeNames field are decoded as well.
+func InputEventDescriptors(msg *Message) []InputEventDescriptor {
+	list := msg.AsEnumerateInputEvents()
+	if list == nil || list.DwArraySize == 0 {
+		return nil
+	}
+	headerSize := unsafe.Offsetof(list.RgData)
+	size := uintptr(msg.Size)
+	if size == 0 {
+		size = uintptr(list.DwSize)
+	}
+	if size <= headerSize {
+		return nil
+	}
+	stride := (size - headerSize) / uintptr(list.DwArraySize)
+	const typeEnd = 76 // Name[64] + UINT64 Hash + eType
+	if stride < typeEnd {
+		return nil
+	}
+
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(list)), size)[headerSize:]
+	out := make([]InputEventDescriptor, 0, list.DwArraySize)
+	for i := uintptr(0); i < uintptr(list.DwArraySize); i++ {
+		entry := raw[i*stride : (i+1)*stride]
+		d := InputEventDescriptor{
+			Name: BytesToString(entry[:64]),
+			Hash: binary.LittleEndian.Uint64(entry[64:72]),
+			Type: types.SIMCONNECT_INPUT_EVENT_TYPE(binary.LittleEndian.Uint32(entry[72:typeEnd])),
+		}
+		if stride > typeEnd {
+			d.NodeNames = BytesToString(entry[typeEnd:])
+		}
+		out = append(out, d)
+	}
+	return out
+}
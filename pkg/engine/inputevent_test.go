@@ -0,0 +1,66 @@
+//go:build windows
+
+package engine
+
+import (
+	"encoding/binary"
+	"testing"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// newEnumerateInputEventsMessage builds an unpooled ENUMERATE_INPUT_EVENTS
+// page whose entries are stride bytes apart.
+func newEnumerateInputEventsMessage(stride int, names []string, hashes []uint64) Message {
+	header := int(unsafe.Offsetof(types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS{}.RgData))
+	size := header + stride*len(names)
+	// Back the whole struct, whose RgData holds one full entry, even when
+	// the entries are packed tighter.
+	data := make([]byte, max(size, int(unsafe.Sizeof(types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS{}))))
+	list := (*types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS)(unsafe.Pointer(&data[0]))
+	list.DwSize = types.DWORD(size)
+	list.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS)
+	list.DwArraySize = types.DWORD(len(names))
+	list.DwOutOf = 1
+	for i, name := range names {
+		entry := data[header+i*stride:]
+		copy(entry[:63], name)
+		binary.LittleEndian.PutUint64(entry[64:], hashes[i])
+		binary.LittleEndian.PutUint32(entry[72:], uint32(types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE))
+		if stride > 76 {
+			copy(entry[76:], "NODE_A;NODE_B")
+		}
+	}
+	return Message{SIMCONNECT_RECV: (*types.SIMCONNECT_RECV)(unsafe.Pointer(&data[0])), Size: uint32(size)}
+}
+
+func TestInputEventDescriptorsLayout(t *testing.T) {
+	if got := unsafe.Sizeof(types.SIMCONNECT_INPUT_EVENT_DESCRIPTOR{}); got != 1100 {
+		t.Fatalf("descriptor size = %d, want wire stride 1100", got)
+	}
+}
+
+func TestInputEventDescriptors(t *testing.T) {
+	hash := uint64(0x1122334455667788)
+	for _, stride := range []int{1100, 76} {
+		msg := newEnumerateInputEventsMessage(stride, []string{"LIGHTING_LANDING_1", "AP_MASTER"}, []uint64{hash, 7})
+		got := InputEventDescriptors(&msg)
+		if len(got) != 2 {
+			t.Fatalf("stride %d: decoded %d descriptors, want 2", stride, len(got))
+		}
+		if got[0].Name != "LIGHTING_LANDING_1" || got[0].Hash != hash || got[0].Type != types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE {
+			t.Fatalf("stride %d: got %+v", stride, got[0])
+		}
+		if got[1].Name != "AP_MASTER" || got[1].Hash != 7 {
+			t.Fatalf("stride %d: got %+v", stride, got[1])
+		}
+		wantNodes := ""
+		if stride == 1100 {
+			wantNodes = "NODE_A;NODE_B"
+		}
+		if got[0].NodeNames != wantNodes {
+			t.Fatalf("stride %d: NodeNames = %q, want %q", stride, got[0].NodeNames, wantNodes)
+		}
+	}
+}
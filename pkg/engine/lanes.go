@@ -0,0 +1,358 @@
+//go:build windows
+
+package engine
+
+import (
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// isControlMessage reports whether a message belongs to the control lane.
+// Control messages are low-rate and latency sensitive (connection lifecycle,
+// exceptions, system events), so they must never queue behind bulk data.
+// Anything that is part of a multi-packet response (facility data and its
+// END marker, list pages, enumerations) stays on the data lane so its
+// ordering is preserved.
+func isControlMessage(msg Message) bool {
+	if msg.Err != nil {
+		return true
+	}
+	switch types.SIMCONNECT_RECV_ID(msg.DwID) {
+	case types.SIMCONNECT_RECV_ID_OPEN,
+		types.SIMCONNECT_RECV_ID_QUIT,
+		types.SIMCONNECT_RECV_ID_EXCEPTION,
+		types.SIMCONNECT_RECV_ID_EVENT,
+		types.SIMCONNECT_RECV_ID_EVENT_EX1,
+		types.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE,
+		types.SIMCONNECT_RECV_ID_EVENT_FILENAME,
+		types.SIMCONNECT_RECV_ID_EVENT_WEATHER_MODE,
+		types.SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED,
+		types.SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED,
+		types.SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED,
+		types.SIMCONNECT_RECV_ID_EVENT_RACE_END,
+		types.SIMCONNECT_RECV_ID_EVENT_RACE_LAP,
+		types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID,
+		types.SIMCONNECT_RECV_ID_RESERVED_KEY,
+		types.SIMCONNECT_RECV_ID_CUSTOM_ACTION,
+		types.SIMCONNECT_RECV_ID_SYSTEM_STATE,
+		types.SIMCONNECT_RECV_ID_ACTION_CALLBACK,
+		types.SIMCONNECT_RECV_ID_FLOW_EVENT:
+		return true
+	}
+	return false
+}
+
+// coalesceKey identifies a periodic data stream whose samples supersede
+// each other.
+type coalesceKey struct {
+	recvID    types.SIMCONNECT_RECV_ID
+	requestID types.DWORD
+	objectID  types.DWORD
+}
+
+// coalesceKeyOf returns the coalescing key for periodic data messages of
+// the request IDs in requests. Only SimObject and client data responses are
+// coalesced; every other message is delivered as-is.
+func coalesceKeyOf(msg Message, requests map[uint32]struct{}) (coalesceKey, bool) {
+	if msg.SIMCONNECT_RECV == nil || len(requests) == 0 {
+		return coalesceKey{}, false
+	}
+	id := types.SIMCONNECT_RECV_ID(msg.DwID)
+	switch id {
+	case types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA,
+		types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE,
+		types.SIMCONNECT_RECV_ID_CLIENT_DATA:
+		data := (*types.SIMCONNECT_RECV_SIMOBJECT_DATA)(unsafe.Pointer(msg.SIMCONNECT_RECV))
+		if _, ok := requests[uint32(data.DwRequestID)]; !ok {
+			return coalesceKey{}, false
+		}
+		return coalesceKey{recvID: id, requestID: data.DwRequestID, objectID: data.DwObjectID}, true
+	}
+	return coalesceKey{}, false
+}
+
+// dataBacklog is the FIFO of data-lane messages the merger has taken off the
+// data channel but not yet delivered. For the request IDs in coalesce, a
+// newer sample for the same key replaces the queued one in place, so the
+// stream keeps its position while only the latest value is delivered.
+type dataBacklog struct {
+	items    []Message
+	head     int
+	coalesce map[uint32]struct{} // Request IDs to coalesce, read-only
+	index    map[coalesceKey]int // Absolute position in items, coalescing only
+}
+
+func (b *dataBacklog) len() int {
+	return len(b.items) - b.head
+}
+
+func (b *dataBacklog) push(msg Message) {
+	if key, ok := coalesceKeyOf(msg, b.coalesce); ok {
+		if b.index == nil {
+			b.index = make(map[coalesceKey]int)
+		}
+		if i, found := b.index[key]; found {
+			b.items[i].Release()
+			b.items[i] = msg
+			return
+		}
+		b.index[key] = len(b.items)
+	}
+	b.items = append(b.items, msg)
+}
+
+func (b *dataBacklog) peek() Message {
+	return b.items[b.head]
+}
+
+func (b *dataBacklog) pop() {
+	if key, ok := coalesceKeyOf(b.items[b.head], b.coalesce); ok && b.index != nil {
+		if b.index[key] == b.head {
+			delete(b.index, key)
+		}
+	}
+	b.items[b.head] = Message{}
+	b.head++
+	switch {
+	case b.head == len(b.items):
+		b.items = b.items[:0]
+		b.head = 0
+	case b.head >= 64 && b.head*2 >= len(b.items):
+		// A backlog that never fully drains would otherwise grow forever;
+		// shift the live entries to the front and rebase the index.
+		n := copy(b.items, b.items[b.head:])
+		clear(b.items[n:])
+		b.items = b.items[:n]
+		for k, i := range b.index {
+			b.index[k] = i - b.head
+		}
+		b.head = 0
+	}
+}
+
+// releaseAll drops every queued message, used when the engine shuts down.
+func (b *dataBacklog) releaseAll() {
+	for i := b.head; i < len(b.items); i++ {
+		b.items[i].Release()
+	}
+	b.items = nil
+	b.head = 0
+	b.index = nil
+}
+
+// fill moves messages that are already waiting on the data channel into the
+// backlog without blocking, up to limit queued entries. It only takes what
+// was queued on entry so a fast producer cannot keep the merger busy.
+func (b *dataBacklog) fill(data <-chan Message, limit int) {
+	for n := len(data); n > 0 && b.len() < limit; n-- {
+		select {
+		case msg := <-data:
+			b.push(msg)
+		default:
+			return
+		}
+	}
+}
+
+// pushControl sends a message to the control lane, giving up when the
+// engine context is cancelled.
+func (e *Engine) pushControl(msg Message) bool {
+	select {
+	case e.control <- msg:
+		return true
+	case <-e.ctx.Done():
+		msg.Release()
+		return false
+	}
+}
+
+// pushData sends a message to the data lane, giving up when the engine
+// context is cancelled.
+func (e *Engine) pushData(msg Message) bool {
+	select {
+	case e.data <- msg:
+		return true
+	case <-e.ctx.Done():
+		msg.Release()
+		return false
+	}
+}
+
+// deliver hands a message to the Stream consumer. It reports false when the
+// merger should stop, either because the engine was cancelled or because
+// the message was the simulator's QUIT notification.
+func (e *Engine) deliver(msg Message) bool {
+	// Decide before handing over; the consumer may release msg right away.
+	quit := isQuit(msg)
+	select {
+	case e.queue <- msg:
+	case <-e.ctx.Done():
+		msg.Release()
+		return false
+	}
+	if quit {
+		e.logger.Debug("[dispatcher] QUIT delivered, closing stream")
+		e.cancel()
+		return false
+	}
+	return true
+}
+
+// merge interleaves the control and data lanes into the Stream channel.
+// A pending control message always wins over queued data.
+func (e *Engine) merge() {
+	e.sync.Go(func() {
+		defer func() {
+			e.logger.Debug("[dispatcher] Exiting lane merger goroutine")
+			e.closeQueue()
+		}()
+
+		backlog := dataBacklog{coalesce: e.config.CoalesceRequestIDs}
+		defer backlog.releaseAll()
+
+		coalesce := len(backlog.coalesce) > 0
+		limit := cap(e.data)
+		if limit < 1 {
+			limit = 1
+		}
+
+		for {
+			select {
+			case msg := <-e.control:
+				if !e.deliver(msg) {
+					return
+				}
+				continue
+			default:
+			}
+
+			if backlog.len() == 0 {
+				select {
+				case <-e.ctx.Done():
+					return
+				case msg := <-e.control:
+					if !e.deliver(msg) {
+						return
+					}
+				case msg := <-e.data:
+					backlog.push(msg)
+				}
+				continue
+			}
+
+			if coalesce {
+				backlog.fill(e.data, limit)
+			}
+
+			select {
+			case <-e.ctx.Done():
+				return
+			case msg := <-e.control:
+				if !e.deliver(msg) {
+					return
+				}
+			case e.queue <- backlog.peek():
+				backlog.pop()
+			}
+		}
+	})
+}
+
+// isQuit reports whether msg is the simulator's QUIT notification.
+func isQuit(msg Message) bool {
+	return msg.SIMCONNECT_RECV != nil && types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_QUIT
+}
+
+// mergeBatches is the StreamBatches counterpart of merge. It waits for the
+// first message, then gathers whatever else is immediately available into
+// the same batch, control lane first.
+func (e *Engine) mergeBatches() {
+	e.sync.Go(func() {
+		defer func() {
+			e.logger.Debug("[dispatcher] Exiting batch merger goroutine")
+			e.closeQueue()
+		}()
+
+		backlog := dataBacklog{coalesce: e.config.CoalesceRequestIDs}
+		defer backlog.releaseAll()
+
+		size := e.config.BatchSize
+		if size < 1 {
+			size = DEFAULT_BATCH_SIZE
+		}
+		latency := e.config.BatchLatency
+		if latency <= 0 {
+			latency = DEFAULT_BATCH_LATENCY
+		}
+
+		for {
+			batch := make([]Message, 0, size)
+			if backlog.len() == 0 {
+				select {
+				case <-e.ctx.Done():
+					return
+				case msg := <-e.control:
+					batch = append(batch, msg)
+				case msg := <-e.data:
+					backlog.push(msg)
+				}
+			}
+			batch = e.gather(batch, &backlog, size, time.Now().Add(latency))
+			// Decide before handing over; the receiver owns the batch afterwards.
+			quit := len(batch) > 0 && isQuit(batch[len(batch)-1])
+
+			select {
+			case e.batches <- batch:
+			case <-e.ctx.Done():
+				for i := range batch {
+					batch[i].Release()
+				}
+				return
+			}
+			if quit {
+				e.logger.Debug("[dispatcher] QUIT delivered, closing stream")
+				e.cancel()
+				return
+			}
+		}
+	})
+}
+
+// gather appends immediately available messages to batch until it is full,
+// the lanes are empty, the deadline passes or QUIT is seen.
+func (e *Engine) gather(batch []Message, backlog *dataBacklog, size int, deadline time.Time) []Message {
+	coalesce := len(backlog.coalesce) > 0
+	for len(batch) < size {
+		if len(batch) > 0 && isQuit(batch[len(batch)-1]) {
+			break
+		}
+		select {
+		case msg := <-e.control:
+			batch = append(batch, msg)
+			continue
+		default:
+		}
+
+		if coalesce {
+			backlog.fill(e.data, max(cap(e.data), 1))
+		} else if backlog.len() == 0 {
+			select {
+			case msg := <-e.data:
+				backlog.push(msg)
+			default:
+			}
+		}
+		if backlog.len() == 0 {
+			break
+		}
+		batch = append(batch, backlog.peek())
+		backlog.pop()
+
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+	return batch
+}
@@ -0,0 +1,196 @@
+//go:build windows
+
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// newLaneTestEngine builds an Engine with lanes but no SimConnect API, which
+// is enough to exercise the merger. Data of the coalesce request IDs is
+// coalesced.
+func newLaneTestEngine(controlSize, dataSize int, coalesce ...uint32) *Engine {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := &Config{ControlBufferSize: controlSize}
+	if len(coalesce) > 0 {
+		WithDataCoalescing(coalesce...)(config)
+	}
+	return &Engine{
+		ctx:     ctx,
+		cancel:  cancel,
+		config:  config,
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		control: make(chan Message, controlSize),
+		data:    make(chan Message, dataSize),
+		queue:   make(chan Message),
+		batches: make(chan []Message),
+	}
+}
+
+func newRecvMessage(id types.SIMCONNECT_RECV_ID, requestID, objectID uint32) Message {
+	size := uint32(unsafe.Sizeof(types.SIMCONNECT_RECV_SIMOBJECT_DATA{}))
+	data := make([]byte, size)
+	recv := (*types.SIMCONNECT_RECV_SIMOBJECT_DATA)(unsafe.Pointer(&data[0]))
+	recv.DwID = types.DWORD(id)
+	recv.DwSize = types.DWORD(size)
+	recv.DwRequestID = types.DWORD(requestID)
+	recv.DwObjectID = types.DWORD(objectID)
+	return newMessage(&recv.SIMCONNECT_RECV, size, nil, data, nil)
+}
+
+func TestIsControlMessage(t *testing.T) {
+	tests := []struct {
+		id   types.SIMCONNECT_RECV_ID
+		want bool
+	}{
+		{types.SIMCONNECT_RECV_ID_QUIT, true},
+		{types.SIMCONNECT_RECV_ID_EXCEPTION, true},
+		{types.SIMCONNECT_RECV_ID_EVENT, true},
+		{types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, false},
+		{types.SIMCONNECT_RECV_ID_FACILITY_DATA, false},
+		{types.SIMCONNECT_RECV_ID_FACILITY_DATA_END, false},
+	}
+	for _, tt := range tests {
+		if got := isControlMessage(newRecvMessage(tt.id, 0, 0)); got != tt.want {
+			t.Errorf("isControlMessage(%d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if !isControlMessage(Message{Err: io.EOF}) {
+		t.Errorf("error messages must use the control lane")
+	}
+}
+
+func TestDataBacklogCoalesce(t *testing.T) {
+	b := dataBacklog{coalesce: map[uint32]struct{}{1: {}, 2: {}}}
+	b.push(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 1, 0))
+	b.push(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 2, 0))
+	latest := newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 1, 0)
+	b.push(latest)
+
+	if b.len() != 2 {
+		t.Fatalf("backlog len = %d, want 2", b.len())
+	}
+	if b.peek().SIMCONNECT_RECV != latest.SIMCONNECT_RECV {
+		t.Fatalf("coalesced sample did not replace the queued one in place")
+	}
+	b.pop()
+	next := b.peek()
+	if got := next.AsSimObjectData().DwRequestID; got != 2 {
+		t.Fatalf("second entry request = %d, want 2", got)
+	}
+	b.pop()
+	if b.len() != 0 || len(b.index) != 0 {
+		t.Fatalf("backlog not empty after draining")
+	}
+}
+
+func TestDataBacklogCoalesceOnlyListedRequests(t *testing.T) {
+	b := dataBacklog{coalesce: map[uint32]struct{}{1: {}}}
+	for i := 0; i < 3; i++ {
+		b.push(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 1, 0))
+		b.push(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 2, 0))
+	}
+
+	// Request 1 collapses to one entry; every sample of request 2 is kept.
+	if b.len() != 4 {
+		t.Fatalf("backlog len = %d, want 4", b.len())
+	}
+	counts := map[types.DWORD]int{}
+	for b.len() > 0 {
+		msg := b.peek()
+		counts[msg.AsSimObjectData().DwRequestID]++
+		b.pop()
+	}
+	if counts[1] != 1 || counts[2] != 3 {
+		t.Fatalf("delivered per request = %v, want 1:1 and 2:3", counts)
+	}
+}
+
+func TestMergeDeliversControlFirst(t *testing.T) {
+	e := newLaneTestEngine(4, 16)
+	defer e.cancel()
+
+	for i := 0; i < 8; i++ {
+		e.data <- newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 1, 0)
+	}
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EVENT, 0, 0)
+	e.merge()
+
+	first := <-e.queue
+	if types.SIMCONNECT_RECV_ID(first.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
+		t.Fatalf("first message = %d, want EVENT", first.DwID)
+	}
+}
+
+func TestMergeClosesAfterQuit(t *testing.T) {
+	e := newLaneTestEngine(4, 16)
+	e.merge()
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_QUIT, 0, 0)
+
+	if msg := <-e.queue; types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_QUIT {
+		t.Fatalf("expected QUIT, got %d", msg.DwID)
+	}
+	select {
+	case _, ok := <-e.queue:
+		if ok {
+			t.Fatalf("stream delivered a message after QUIT")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("stream not closed after QUIT")
+	}
+	if e.ctx.Err() == nil {
+		t.Fatalf("engine context not cancelled after QUIT")
+	}
+}
+
+// BenchmarkControlLatencyUnderLoad measures how long a control message waits
+// while the data lane is saturated and the consumer drains one message at a
+// time.
+func BenchmarkControlLatencyUnderLoad(b *testing.B) {
+	for _, coalesce := range []bool{false, true} {
+		name := "fifo"
+		var requests []uint32
+		if coalesce {
+			name = "coalesce"
+			requests = []uint32{0, 1, 2, 3, 4, 5, 6, 7}
+		}
+		b.Run(name, func(b *testing.B) {
+			e := newLaneTestEngine(DEFAULT_CONTROL_BUFFER_SIZE, DEFAULT_BUFFER_SIZE, requests...)
+			defer e.cancel()
+			e.merge()
+
+			go func() {
+				for i := uint32(0); e.ctx.Err() == nil; i++ {
+					e.pushData(newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, i%8, 0))
+					// Yield like the real dispatcher does between polls, so
+					// a coalescing run is not starved on small machines.
+					runtime.Gosched()
+				}
+			}()
+
+			var worst time.Duration
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EVENT, 0, 0)
+				for msg := range e.queue {
+					if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EVENT {
+						break
+					}
+				}
+				if d := time.Since(start); d > worst {
+					worst = d
+				}
+			}
+			b.ReportMetric(float64(worst.Microseconds()), "worst-µs")
+		})
+	}
+}
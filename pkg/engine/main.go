@@ -41,8 +41,12 @@ func New(name string, options ...Option) *Engine {
 		config: config,
 		ctx:    ctx,
 		logger: config.Logger,
-		// Initialize queue here to prevent race condition between Stream() and dispatch()
-		queue: make(chan Message, config.BufferSize),
+		// Initialize lanes here to prevent race condition between Stream() and dispatch().
+		// The output queue is unbuffered so lane priority is decided at receive time.
+		control: make(chan Message, config.ControlBufferSize),
+		data:    make(chan Message, config.BufferSize),
+		queue:   make(chan Message),
+		batches: make(chan []Message),
 	}
 }
 
@@ -54,8 +58,15 @@ type Engine struct {
 	dispatchOnce sync.Once
 	logger       *slog.Logger
 	queue        chan Message
+	control      chan Message // High-priority lane (lifecycle, exceptions, events)
+	data         chan Message // Bulk lane (SimObject, facility and list data)
+	batches      chan []Message
 	sync         sync.WaitGroup
-	closeOnce    sync.Once // Ensures queue is closed only once
+	closeOnce    sync.Once // Ensures queue and batches are closed only once
+	fanoutOnce   sync.Once // Starts the Subscribe fan-out goroutine
+	subsMu       sync.RWMutex
+	subscribers  []*subscriber
+	fanoutDone   bool // Set once the fan-out has stopped, guarded by subsMu
 }
 
 // HeartbeatFrequency represents the valid heartbeat frequencies for SimConnect system events.
@@ -43,13 +43,28 @@ func PackWaypoints(wps []types.SIMCONNECT_DATA_WAYPOINT) []byte {
 // WaypointWireSize is the packed wire size per SIMCONNECT_DATA_WAYPOINT element.
 const WaypointWireSize = waypointWireSize
 
+// Message is a single dispatched SimConnect packet.
+//
+// Messages produced by the engine are backed by pooled buffers that are
+// shared between every reference to the same packet. Ownership rules:
+//
+//   - Every reference obtained from Stream or from Retain must be released
+//     exactly once with Release. Copying the Message value does not create a
+//     new reference.
+//   - The typed views returned by the As* helpers (and any pointer derived
+//     from them) are only valid until the last reference is released.
+//   - Use Retain to hand the same buffer to another goroutine, or Clone to
+//     obtain an independent copy that never touches the pool.
+//
+// Building with the simconnect_debug tag poisons buffers on final release and
+// panics when a released Message is accessed through its As* helpers.
 type Message struct {
 	*types.SIMCONNECT_RECV
 	Size uint32
 	Err  error
 
-	data    []byte // Internal field to keep the copied data alive
-	release func() // Internal function to return buffer to pool
+	buf      *messageBuffer // Shared, reference-counted backing storage
+	released bool           // Set once this reference has been released
 }
 
 // newMessage creates a new Message with pooled buffer.
@@ -61,18 +76,57 @@ func newMessage(recv *types.SIMCONNECT_RECV, size uint32, err error, data []byte
 		SIMCONNECT_RECV: recv,
 		Size:            size,
 		Err:             err,
-		data:            data,
-		release:         release,
+		buf:             newMessageBuffer(data, release),
 	}
 }
 
-// Release returns the message's buffer to the appropriate pool.
-// Call this when the message is no longer needed for best performance.
-// Safe to call multiple times (no-op after first call).
+// Release drops this reference to the message buffer. The buffer is returned
+// to its pool once every reference has been released.
+// Safe to call multiple times on the same reference (no-op after first call).
 func (m *Message) Release() {
-	if m.release != nil {
-		m.release()
-		m.release = nil // Prevent double-release
+	if m.buf == nil || m.released {
+		return
+	}
+	m.released = true
+	m.buf.release()
+}
+
+// Retain adds a reference to the message buffer and returns it as a new
+// Message. The returned Message must be released independently of m.
+func (m *Message) Retain() Message {
+	m.mustBeLive()
+	if m.buf != nil {
+		m.buf.retain()
+	}
+	r := *m
+	r.released = false
+	return r
+}
+
+// Clone returns a deep copy of the message backed by freshly allocated
+// memory. The clone is independent of the pool and of m; calling Release on
+// it is allowed but not required.
+func (m *Message) Clone() Message {
+	m.mustBeLive()
+	c := Message{Size: m.Size, Err: m.Err}
+	if m.SIMCONNECT_RECV == nil || m.Size == 0 {
+		return c
+	}
+	data := make([]byte, m.Size)
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(m.SIMCONNECT_RECV)), m.Size))
+	c.SIMCONNECT_RECV = (*types.SIMCONNECT_RECV)(unsafe.Pointer(&data[0]))
+	c.buf = newMessageBuffer(data, nil)
+	return c
+}
+
+// mustBeLive panics in debug builds when the message is accessed after this
+// reference, or the shared buffer, has been released.
+func (m *Message) mustBeLive() {
+	if !debugMessages || m.buf == nil {
+		return
+	}
+	if m.released || !m.buf.live() {
+		panic("engine: Message accessed after Release")
 	}
 }
 
@@ -101,13 +155,23 @@ func BytesToString(data []byte) string {
 }
 
 func (m *Message) AsEvent() *types.SIMCONNECT_RECV_EVENT {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
 		return nil
 	}
 	return (*types.SIMCONNECT_RECV_EVENT)(unsafe.Pointer(m.SIMCONNECT_RECV))
 }
 
+func (m *Message) AsEventEx1() *types.SIMCONNECT_RECV_EVENT_EX1 {
+	m.mustBeLive()
+	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EVENT_EX1 {
+		return nil
+	}
+	return (*types.SIMCONNECT_RECV_EVENT_EX1)(unsafe.Pointer(m.SIMCONNECT_RECV))
+}
+
 func (m *Message) AsEventFrame() *types.SIMCONNECT_RECV_EVENT_FRAME {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EVENT_FRAME {
 		return nil
 	}
@@ -115,6 +179,7 @@ func (m *Message) AsEventFrame() *types.SIMCONNECT_RECV_EVENT_FRAME {
 }
 
 func (m *Message) AsEventFilename() *types.SIMCONNECT_RECV_EVENT_FILENAME {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EVENT_FILENAME {
 		return nil
 	}
@@ -122,6 +187,7 @@ func (m *Message) AsEventFilename() *types.SIMCONNECT_RECV_EVENT_FILENAME {
 }
 
 func (m *Message) AsEventObjectAddRemove() *types.SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE {
 		return nil
 	}
@@ -129,6 +195,7 @@ func (m *Message) AsEventObjectAddRemove() *types.SIMCONNECT_RECV_EVENT_OBJECT_A
 }
 
 func (m *Message) AsOpen() *types.SIMCONNECT_RECV_OPEN {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_OPEN {
 		return nil
 	}
@@ -136,6 +203,7 @@ func (m *Message) AsOpen() *types.SIMCONNECT_RECV_OPEN {
 }
 
 func (m *Message) AsSimObjectData() *types.SIMCONNECT_RECV_SIMOBJECT_DATA {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA {
 		return nil
 	}
@@ -143,6 +211,7 @@ func (m *Message) AsSimObjectData() *types.SIMCONNECT_RECV_SIMOBJECT_DATA {
 }
 
 func (m *Message) AsSimObjectDataBType() *types.SIMCONNECT_RECV_SIMOBJECT_DATA_BTYPE {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE {
 		return nil
 	}
@@ -150,6 +219,7 @@ func (m *Message) AsSimObjectDataBType() *types.SIMCONNECT_RECV_SIMOBJECT_DATA_B
 }
 
 func (m *Message) AsSimObjectAndLiveryEnumeration() *types.SIMCONNECT_RECV_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST {
 		return nil
 	}
@@ -157,6 +227,7 @@ func (m *Message) AsSimObjectAndLiveryEnumeration() *types.SIMCONNECT_RECV_ENUME
 }
 
 func (m *Message) AsFacilityData() *types.SIMCONNECT_RECV_FACILITY_DATA {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_FACILITY_DATA {
 		return nil
 	}
@@ -164,6 +235,7 @@ func (m *Message) AsFacilityData() *types.SIMCONNECT_RECV_FACILITY_DATA {
 }
 
 func (m *Message) AsFacilityList() *types.SIMCONNECT_RECV_FACILITIES_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) == types.SIMCONNECT_RECV_ID_AIRPORT_LIST ||
 		types.SIMCONNECT_RECV_ID(m.DwID) == types.SIMCONNECT_RECV_ID_VOR_LIST ||
 		types.SIMCONNECT_RECV_ID(m.DwID) == types.SIMCONNECT_RECV_ID_NDB_LIST ||
@@ -175,6 +247,7 @@ func (m *Message) AsFacilityList() *types.SIMCONNECT_RECV_FACILITIES_LIST {
 }
 
 func (m *Message) AsAirportList() *types.SIMCONNECT_RECV_AIRPORT_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_AIRPORT_LIST {
 		return nil
 	}
@@ -182,6 +255,7 @@ func (m *Message) AsAirportList() *types.SIMCONNECT_RECV_AIRPORT_LIST {
 }
 
 func (m *Message) AsNDBList() *types.SIMCONNECT_RECV_NDB_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_NDB_LIST {
 		return nil
 	}
@@ -189,6 +263,7 @@ func (m *Message) AsNDBList() *types.SIMCONNECT_RECV_NDB_LIST {
 }
 
 func (m *Message) AsVORList() *types.SIMCONNECT_RECV_VOR_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_VOR_LIST {
 		return nil
 	}
@@ -196,6 +271,7 @@ func (m *Message) AsVORList() *types.SIMCONNECT_RECV_VOR_LIST {
 }
 
 func (m *Message) AsWaypointList() *types.SIMCONNECT_RECV_WAYPOINT_LIST {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_WAYPOINT_LIST {
 		return nil
 	}
@@ -203,6 +279,7 @@ func (m *Message) AsWaypointList() *types.SIMCONNECT_RECV_WAYPOINT_LIST {
 }
 
 func (m *Message) AsAssignedObjectID() *types.SIMCONNECT_RECV_ASSIGNED_OBJECT_ID {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID {
 		return nil
 	}
@@ -210,6 +287,7 @@ func (m *Message) AsAssignedObjectID() *types.SIMCONNECT_RECV_ASSIGNED_OBJECT_ID
 }
 
 func (m *Message) AsFacilityDataEnd() *types.SIMCONNECT_RECV_FACILITY_DATA_END {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_FACILITY_DATA_END {
 		return nil
 	}
@@ -217,6 +295,7 @@ func (m *Message) AsFacilityDataEnd() *types.SIMCONNECT_RECV_FACILITY_DATA_END {
 }
 
 func (m *Message) AsException() *types.SIMCONNECT_RECV_EXCEPTION {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_EXCEPTION {
 		return nil
 	}
@@ -227,6 +306,7 @@ func (m *Message) AsException() *types.SIMCONNECT_RECV_EXCEPTION {
 // Returns nil if the message is not a flow event.
 // Note: MSFS 2024 only.
 func (m *Message) AsFlowEvent() *types.SIMCONNECT_RECV_FLOW_EVENT {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_FLOW_EVENT {
 		return nil
 	}
@@ -237,6 +317,7 @@ func (m *Message) AsFlowEvent() *types.SIMCONNECT_RECV_FLOW_EVENT {
 // Returns nil if the message is not an enumerate input events response.
 // Note: MSFS 2024 only.
 func (m *Message) AsEnumerateInputEvents() *types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS {
 		return nil
 	}
@@ -247,6 +328,7 @@ func (m *Message) AsEnumerateInputEvents() *types.SIMCONNECT_RECV_ENUMERATE_INPU
 // Returns nil if the message is not a get input event response.
 // Note: MSFS 2024 only.
 func (m *Message) AsGetInputEvent() *types.SIMCONNECT_RECV_GET_INPUT_EVENT {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_GET_INPUT_EVENT {
 		return nil
 	}
@@ -257,6 +339,7 @@ func (m *Message) AsGetInputEvent() *types.SIMCONNECT_RECV_GET_INPUT_EVENT {
 // Returns nil if the message is not a subscribe input event notification.
 // Note: MSFS 2024 only.
 func (m *Message) AsSubscribeInputEvent() *types.SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT {
 		return nil
 	}
@@ -266,6 +349,7 @@ func (m *Message) AsSubscribeInputEvent() *types.SIMCONNECT_RECV_SUBSCRIBE_INPUT
 // AsClientData casts the message to SIMCONNECT_RECV_CLIENT_DATA.
 // Returns nil if the message is not a client data notification.
 func (m *Message) AsClientData() *types.SIMCONNECT_RECV_CLIENT_DATA {
+	m.mustBeLive()
 	if types.SIMCONNECT_RECV_ID(m.DwID) != types.SIMCONNECT_RECV_ID_CLIENT_DATA {
 		return nil
 	}
@@ -0,0 +1,79 @@
+//go:build windows
+
+package engine
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+func newTestMessage(t *testing.T, puts *int) Message {
+	t.Helper()
+	size := uint32(unsafe.Sizeof(types.SIMCONNECT_RECV_EVENT{}))
+	data := make([]byte, size)
+	ev := (*types.SIMCONNECT_RECV_EVENT)(unsafe.Pointer(&data[0]))
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EVENT)
+	ev.DwSize = types.DWORD(size)
+	ev.UEventID = 42
+	recv := (*types.SIMCONNECT_RECV)(unsafe.Pointer(&data[0]))
+	return newMessage(recv, size, nil, data, func() { *puts++ })
+}
+
+func TestMessageRetainRelease(t *testing.T) {
+	var puts int
+	msg := newTestMessage(t, &puts)
+	ref := msg.Retain()
+
+	msg.Release()
+	msg.Release() // no-op on the same reference
+	if puts != 0 {
+		t.Fatalf("buffer returned to pool while still referenced")
+	}
+	if ev := ref.AsEvent(); ev == nil || ev.UEventID != 42 {
+		t.Fatalf("retained reference lost its data")
+	}
+
+	ref.Release()
+	if debugMessages {
+		if puts != 0 {
+			t.Fatalf("debug build must not recycle released buffers")
+		}
+	} else if puts != 1 {
+		t.Fatalf("expected buffer to be returned once, got %d", puts)
+	}
+}
+
+func TestMessageClone(t *testing.T) {
+	var puts int
+	msg := newTestMessage(t, &puts)
+	clone := msg.Clone()
+	msg.Release()
+
+	ev := clone.AsEvent()
+	if ev == nil || ev.UEventID != 42 {
+		t.Fatalf("clone lost its data")
+	}
+	if unsafe.Pointer(clone.SIMCONNECT_RECV) == unsafe.Pointer(msg.SIMCONNECT_RECV) {
+		t.Fatalf("clone shares memory with the original")
+	}
+	clone.Release()
+}
+
+func TestMessageRetainAfterReleasePanics(t *testing.T) {
+	var puts int
+	msg := newTestMessage(t, &puts)
+	stale := msg
+	msg.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected Retain on a released message to panic")
+		}
+		if n := stale.buf.refs.Load(); n != 0 {
+			t.Fatalf("panicking Retain left %d references", n)
+		}
+	}()
+	stale.Retain()
+}
@@ -2,9 +2,30 @@
 
 package engine
 
+// Stream starts the dispatcher and returns the channel delivering messages
+// from the simulator. Control messages (connection lifecycle, exceptions,
+// system events) are always delivered before any queued data messages.
+// The channel is closed once the engine stops or after QUIT is delivered.
+//
+// Stream and StreamBatches are mutually exclusive: whichever is called first
+// starts the dispatcher, and the other channel only reports closure.
 func (e *Engine) Stream() <-chan Message {
 	e.dispatchOnce.Do(func() {
+		e.merge()
 		e.dispatch()
 	})
 	return e.queue
 }
+
+// StreamBatches starts the dispatcher and returns a channel delivering
+// messages in batches. Each batch holds every message that was immediately
+// available, up to the configured batch size (WithBatchSize) and latency
+// budget (WithBatchLatency). Control messages precede data messages within
+// a batch. The receiver owns every message in the slice and the slice itself.
+func (e *Engine) StreamBatches() <-chan []Message {
+	e.dispatchOnce.Do(func() {
+		e.mergeBatches()
+		e.dispatch()
+	})
+	return e.batches
+}
@@ -0,0 +1,188 @@
+//go:build windows
+
+package engine
+
+import (
+	"slices"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// DEFAULT_SUBSCRIBE_BUFFER_SIZE is the channel capacity used by Subscribe when
+// the requested buffer size is not positive.
+const DEFAULT_SUBSCRIBE_BUFFER_SIZE = 16
+
+// SubscribeOption configures a subscription created with Engine.Subscribe.
+type SubscribeOption func(*subscriber)
+
+// WithRecvTypes restricts a subscription to messages whose DwID is one of
+// the given SIMCONNECT_RECV_ID values. It is checked before the filter, so
+// the filter only sees messages of the listed types.
+func WithRecvTypes(recvIDs ...types.SIMCONNECT_RECV_ID) SubscribeOption {
+	return func(s *subscriber) {
+		if s.recvTypes == nil {
+			s.recvTypes = make(map[types.SIMCONNECT_RECV_ID]struct{}, len(recvIDs))
+		}
+		for _, id := range recvIDs {
+			s.recvTypes[id] = struct{}{}
+		}
+	}
+}
+
+// WithDropHandler registers a callback invoked when a message is dropped
+// because the subscription buffer is full. It must not block, as it runs on
+// the engine's fan-out goroutine.
+func WithDropHandler(fn func(dropped int)) SubscribeOption {
+	return func(s *subscriber) {
+		s.onDrop = fn
+	}
+}
+
+// subscriber is a single consumer registered with Engine.Subscribe.
+type subscriber struct {
+	ch        chan Message
+	filter    func(Message) bool
+	recvTypes map[types.SIMCONNECT_RECV_ID]struct{}
+	onDrop    func(dropped int)
+	closed    bool // Guarded by Engine.subsMu
+}
+
+// Subscribe registers an independent consumer of the engine's messages and
+// returns its channel together with a cancel function.
+//
+// Every subscriber sees every message accepted by its filter (nil accepts
+// all) and its WithRecvTypes option; consumers no longer steal messages from
+// each other. Each delivered message is a retained reference owned by the
+// receiver, who must Release it. Delivery never blocks: when the buffer
+// (DEFAULT_SUBSCRIBE_BUFFER_SIZE if buffer <= 0) is full the message is
+// dropped and the WithDropHandler callback is invoked.
+//
+// The first call starts the dispatcher and makes the engine consume its own
+// stream, so Subscribe must not be combined with Stream or StreamBatches.
+// Channels are closed when cancel is called or when the engine stops; after
+// QUIT the remaining buffered messages can still be read.
+func (e *Engine) Subscribe(filter func(Message) bool, buffer int, opts ...SubscribeOption) (<-chan Message, func()) {
+	if buffer <= 0 {
+		buffer = DEFAULT_SUBSCRIBE_BUFFER_SIZE
+	}
+	sub := &subscriber{ch: make(chan Message, buffer), filter: filter}
+	for _, opt := range opts {
+		opt(sub)
+	}
+
+	e.subsMu.Lock()
+	if e.fanoutDone {
+		e.subsMu.Unlock()
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	e.subscribers = append(e.subscribers, sub)
+	e.subsMu.Unlock()
+
+	e.fanoutOnce.Do(e.fanout)
+	return sub.ch, func() { e.unsubscribe(sub) }
+}
+
+// unsubscribe removes a subscriber, closes its channel and releases any
+// messages it did not consume.
+func (e *Engine) unsubscribe(sub *subscriber) {
+	e.subsMu.Lock()
+	if sub.closed {
+		e.subsMu.Unlock()
+		return
+	}
+	sub.closed = true
+	e.subscribers = slices.DeleteFunc(e.subscribers, func(s *subscriber) bool { return s == sub })
+	close(sub.ch)
+	e.subsMu.Unlock()
+
+	for msg := range sub.ch {
+		msg.Release()
+	}
+}
+
+// fanout consumes the engine stream and publishes every batch to the
+// registered subscribers until the stream closes.
+func (e *Engine) fanout() {
+	batches := e.StreamBatches()
+	e.sync.Go(func() {
+		defer e.closeSubscribers()
+		for batch := range batches {
+			e.publish(batch)
+		}
+	})
+}
+
+// publish delivers a batch to all matching subscribers and drops the
+// fan-out's own references.
+func (e *Engine) publish(batch []Message) {
+	e.subsMu.RLock()
+	for i := range batch {
+		for _, sub := range e.subscribers {
+			if !e.accepts(sub, batch[i]) {
+				continue
+			}
+			ref := batch[i].Retain()
+			select {
+			case sub.ch <- ref:
+			default:
+				ref.Release()
+				if sub.onDrop != nil {
+					e.callDropHandler(sub.onDrop)
+				}
+			}
+		}
+	}
+	e.subsMu.RUnlock()
+
+	for i := range batch {
+		batch[i].Release()
+	}
+}
+
+// accepts applies the subscriber's type set and filter to msg. A panicking
+// filter rejects the message instead of taking down the fan-out goroutine.
+func (e *Engine) accepts(sub *subscriber, msg Message) (ok bool) {
+	if len(sub.recvTypes) > 0 {
+		if msg.SIMCONNECT_RECV == nil {
+			return false
+		}
+		if _, found := sub.recvTypes[types.SIMCONNECT_RECV_ID(msg.DwID)]; !found {
+			return false
+		}
+	}
+	if sub.filter == nil {
+		return true
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Error("[engine] Subscription filter panic", "panic", r)
+			ok = false
+		}
+	}()
+	return sub.filter(msg)
+}
+
+// callDropHandler invokes a drop callback, shielding the fan-out goroutine
+// from panics.
+func (e *Engine) callDropHandler(fn func(int)) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Error("[engine] Subscription drop handler panic", "panic", r)
+		}
+	}()
+	fn(1)
+}
+
+// closeSubscribers closes every subscriber channel once the stream ends.
+// Buffered messages stay readable so consumers can still observe QUIT.
+func (e *Engine) closeSubscribers() {
+	e.subsMu.Lock()
+	defer e.subsMu.Unlock()
+	for _, sub := range e.subscribers {
+		sub.closed = true
+		close(sub.ch)
+	}
+	e.subscribers = nil
+	e.fanoutDone = true
+}
@@ -0,0 +1,125 @@
+//go:build windows
+
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// newSubscribeTestEngine returns a lane test engine whose dispatcher is
+// already "started", so Subscribe only attaches the fan-out.
+func newSubscribeTestEngine() *Engine {
+	e := newLaneTestEngine(4, 16)
+	e.config.BatchSize = DEFAULT_BATCH_SIZE
+	e.config.BatchLatency = DEFAULT_BATCH_LATENCY
+	e.dispatchOnce.Do(e.mergeBatches)
+	return e
+}
+
+func receive(t *testing.T, ch <-chan Message) Message {
+	t.Helper()
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			t.Fatalf("subscription channel closed")
+		}
+		return msg
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for message")
+	}
+	return Message{}
+}
+
+func TestSubscribeFanOut(t *testing.T) {
+	e := newSubscribeTestEngine()
+	defer e.cancel()
+
+	var puts int
+	all, cancelAll := e.Subscribe(nil, 4)
+	defer cancelAll()
+	events, cancelEvents := e.Subscribe(nil, 4, WithRecvTypes(types.SIMCONNECT_RECV_ID_EVENT))
+	defer cancelEvents()
+	filtered, cancelFiltered := e.Subscribe(func(msg Message) bool {
+		data := msg.AsSimObjectData()
+		return data != nil && data.DwRequestID == 7
+	}, 4)
+	defer cancelFiltered()
+
+	data := newRecvMessage(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, 7, 0)
+	data.buf.put = func() { puts++ }
+	e.data <- data
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EVENT, 0, 0)
+
+	first, second := receive(t, all), receive(t, all)
+	if types.SIMCONNECT_RECV_ID(first.DwID) != types.SIMCONNECT_RECV_ID_EVENT &&
+		types.SIMCONNECT_RECV_ID(second.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
+		t.Fatalf("unfiltered subscriber missed the event")
+	}
+	if ev := receive(t, events); types.SIMCONNECT_RECV_ID(ev.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
+		t.Fatalf("type-filtered subscriber got %d", ev.DwID)
+	}
+	got := receive(t, filtered)
+	if types.SIMCONNECT_RECV_ID(got.DwID) != types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA {
+		t.Fatalf("predicate subscriber got %d", got.DwID)
+	}
+
+	// The data buffer returns to the pool only after every subscriber released it.
+	first.Release()
+	second.Release()
+	if puts != 0 {
+		t.Fatalf("buffer recycled while a subscriber still holds it")
+	}
+	got.Release()
+	if !debugMessages && puts != 1 {
+		t.Fatalf("buffer not recycled after the last release, puts = %d", puts)
+	}
+}
+
+func TestSubscribeDropAndCancel(t *testing.T) {
+	e := newSubscribeTestEngine()
+	defer e.cancel()
+
+	dropped := make(chan int, 8)
+	ch, cancel := e.Subscribe(nil, 1, WithDropHandler(func(n int) { dropped <- n }))
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EVENT, 0, 0)
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_EVENT, 0, 0)
+
+	select {
+	case <-dropped:
+	case <-time.After(time.Second):
+		t.Fatalf("drop handler not invoked for a full buffer")
+	}
+
+	cancel()
+	cancel() // idempotent
+	if _, ok := <-ch; ok {
+		t.Fatalf("channel still delivering after cancel")
+	}
+}
+
+func TestSubscribeClosedAfterQuit(t *testing.T) {
+	e := newSubscribeTestEngine()
+	ch, cancel := e.Subscribe(nil, 4)
+	defer cancel()
+
+	e.control <- newRecvMessage(types.SIMCONNECT_RECV_ID_QUIT, 0, 0)
+	if msg := receive(t, ch); !isQuit(msg) {
+		t.Fatalf("expected QUIT, got %d", msg.DwID)
+	}
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("message delivered after QUIT")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("subscription not closed after QUIT")
+	}
+
+	late, _ := e.Subscribe(nil, 1)
+	if _, ok := <-late; ok {
+		t.Fatalf("subscription on a stopped engine must be closed")
+	}
+}
@@ -26,6 +26,13 @@ func (e *Engine) SetSystemEventState(eventID uint32, state types.SIMCONNECT_STAT
 	return e.api.SetSystemEventState(eventID, state)
 }
 
+// GetLastSentPacketID returns the send ID of the last request made on the
+// connection. SIMCONNECT_RECV_EXCEPTION reports the send ID of the request
+// that failed in DwSendID.
+func (e *Engine) GetLastSentPacketID() (uint32, error) {
+	return e.api.GetLastSentPacketID()
+}
+
 // SystemStateFloat64 extracts the float64 value from a SYSTEM_STATE receive struct.
 // FFloatBytes is stored as [8]byte at wire offset 20 to avoid Go alignment padding
 // (float64 after 12+4+4 bytes would be padded to offset 24 by Go).
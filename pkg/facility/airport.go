@@ -0,0 +1,324 @@
+// Package facility decodes SimConnect facility data into typed values.
+//
+// A facility request returns a flat stream of SIMCONNECT_RECV_FACILITY_DATA
+// packets: one per record, linked to their parent record through
+// ParentUniqueRequestId, followed by SIMCONNECT_RECV_FACILITY_DATA_END. An
+// Assembler collects those packets and rebuilds the record tree as an
+// Airport. The package has no simulator dependency, so the decoding can be
+// tested and reused on any platform.
+//
+// Units follow the simulator: positions in degrees, altitudes, lengths and
+// biases in meters, headings in degrees true.
+package facility
+
+import "fmt"
+
+// Airport is a fully decoded airport facility.
+type Airport struct {
+	ICAO               string
+	Region             string
+	Name               string
+	Latitude           float64
+	Longitude          float64
+	Altitude           float64
+	MagVar             float64
+	TowerLatitude      float64
+	TowerLongitude     float64
+	TowerAltitude      float64
+	TransitionAltitude float64
+	TransitionLevel    float64
+
+	Runways     []Runway
+	Parking     []Parking
+	TaxiPoints  []TaxiPoint
+	TaxiPaths   []TaxiPath
+	TaxiNames   []string
+	Frequencies []Frequency
+	Helipads    []Helipad
+	Jetways     []Jetway
+	Departures  []Procedure
+	Arrivals    []Procedure
+	Approaches  []Approach
+}
+
+// RunwayDesignator is the suffix of a runway number.
+type RunwayDesignator int32
+
+const (
+	RunwayDesignatorNone RunwayDesignator = iota
+	RunwayDesignatorLeft
+	RunwayDesignatorRight
+	RunwayDesignatorCenter
+	RunwayDesignatorWater
+	RunwayDesignatorA
+	RunwayDesignatorB
+)
+
+// String returns the designator suffix used in runway idents, such as "L".
+func (d RunwayDesignator) String() string {
+	switch d {
+	case RunwayDesignatorLeft:
+		return "L"
+	case RunwayDesignatorRight:
+		return "R"
+	case RunwayDesignatorCenter:
+		return "C"
+	case RunwayDesignatorWater:
+		return "W"
+	case RunwayDesignatorA:
+		return "A"
+	case RunwayDesignatorB:
+		return "B"
+	}
+	return ""
+}
+
+// cardinalRunways names runway numbers 37-44, which SimConnect uses for
+// runways designated by compass direction.
+var cardinalRunways = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// RunwayIdent formats a runway number and designator, e.g. "09L".
+func RunwayIdent(number int32, designator RunwayDesignator) string {
+	switch {
+	case number >= 1 && number <= 36:
+		return fmt.Sprintf("%02d%s", number, designator)
+	case number >= 37 && number <= 44:
+		return cardinalRunways[number-37] + designator.String()
+	}
+	return ""
+}
+
+// Runway is a runway with both of its ends.
+type Runway struct {
+	Latitude        float64 // center
+	Longitude       float64 // center
+	Altitude        float64
+	Heading         float64 // primary end heading
+	Length          float64
+	Width           float64
+	PatternAltitude float64
+	Slope           float64
+	TrueSlope       float64
+	Surface         int32
+	Primary         RunwayEnd
+	Secondary       RunwayEnd
+}
+
+// RunwayEnd is one landing direction of a runway.
+type RunwayEnd struct {
+	Number     int32
+	Designator RunwayDesignator
+	ILSICAO    string
+	ILSRegion  string
+	ILSType    int32
+}
+
+// Ident returns the runway end ident, e.g. "27R".
+func (e RunwayEnd) Ident() string { return RunwayIdent(e.Number, e.Designator) }
+
+// ParkingType is the kind of a parking spot.
+type ParkingType uint32
+
+const (
+	ParkingNone ParkingType = iota
+	ParkingRampGA
+	ParkingRampGASmall
+	ParkingRampGAMedium
+	ParkingRampGALarge
+	ParkingRampCargo
+	ParkingRampMilCargo
+	ParkingRampMilCombat
+	ParkingGateSmall
+	ParkingGateMedium
+	ParkingGateHeavy
+	ParkingDockGA
+	ParkingFuel
+	ParkingVehicle
+	ParkingRampGAExtra
+	ParkingGateExtra
+)
+
+// IsGate reports whether the parking spot is a gate.
+func (t ParkingType) IsGate() bool {
+	switch t {
+	case ParkingGateSmall, ParkingGateMedium, ParkingGateHeavy, ParkingGateExtra:
+		return true
+	}
+	return false
+}
+
+// Parking name values of the NAME field of a parking record.
+const (
+	parkingNameGate  = 10
+	parkingNameDock  = 11
+	parkingNameGateA = 12
+	parkingNameGateZ = 37
+)
+
+// Parking is a parking spot, positioned by its bias from the airport
+// reference point.
+type Parking struct {
+	Type          ParkingType
+	TaxiPointType int32
+	Name          int32 // SIMCONNECT_FACILITY_TAXI_PARKING_NAME
+	Suffix        int32
+	Number        uint32
+	Orientation   int32
+	Heading       float64
+	Radius        float64
+	BiasX         float64 // east
+	BiasZ         float64 // north
+}
+
+// Ident returns a readable designation such as "A12", "GATE 3" or
+// "PARKING 7".
+func (p Parking) Ident() string {
+	switch {
+	case p.Name >= parkingNameGateA && p.Name <= parkingNameGateZ:
+		return fmt.Sprintf("%c%d", 'A'+p.Name-parkingNameGateA, p.Number)
+	case p.Name == parkingNameGate:
+		return fmt.Sprintf("GATE %d", p.Number)
+	case p.Name == parkingNameDock:
+		return fmt.Sprintf("DOCK %d", p.Number)
+	}
+	return fmt.Sprintf("PARKING %d", p.Number)
+}
+
+// TaxiPoint is a node of the taxiway network.
+type TaxiPoint struct {
+	Type        int32
+	Orientation int32
+	BiasX       float64 // east
+	BiasZ       float64 // north
+}
+
+// TaxiPathType is the kind of a taxi path.
+type TaxiPathType int32
+
+const (
+	TaxiPathNone TaxiPathType = iota
+	TaxiPathTaxi
+	TaxiPathRunway
+	TaxiPathParking // End indexes Parking instead of TaxiPoints
+	TaxiPathPath
+	TaxiPathClosed
+	TaxiPathVehicle
+	TaxiPathRoad
+	TaxiPathPaintedLine
+)
+
+// TaxiPath is an edge of the taxiway network. Start and End index
+// TaxiPoints, except End of a TaxiPathParking path, which indexes Parking.
+type TaxiPath struct {
+	Type             TaxiPathType
+	Width            float64
+	RunwayNumber     int32
+	RunwayDesignator RunwayDesignator
+	Start            int32
+	End              int32
+	NameIndex        uint32
+	Name             string // resolved from TaxiNames
+}
+
+// Frequency is a radio frequency of the airport.
+type Frequency struct {
+	Type int32   // SIMCONNECT_FACILITY_FREQUENCY_TYPE
+	MHz  float64 // e.g. 118.7
+	Name string
+}
+
+// Helipad is a helicopter landing pad.
+type Helipad struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Heading   float64
+	Length    float64
+	Width     float64
+	Surface   int32
+	Type      int32
+}
+
+// Jetway is a jetway and the parking spot it serves.
+type Jetway struct {
+	ParkingGate   int32
+	ParkingSuffix int32
+	ParkingSpot   int32
+}
+
+// Procedure is a departure (SID) or arrival (STAR).
+type Procedure struct {
+	Name               string
+	Legs               []Leg // common route
+	RunwayTransitions  []RunwayTransition
+	EnrouteTransitions []EnrouteTransition
+}
+
+// RunwayTransition is the runway-specific part of a procedure.
+type RunwayTransition struct {
+	RunwayNumber     int32
+	RunwayDesignator RunwayDesignator
+	Legs             []Leg
+}
+
+// Runway returns the ident of the transition runway, e.g. "27R".
+func (t RunwayTransition) Runway() string { return RunwayIdent(t.RunwayNumber, t.RunwayDesignator) }
+
+// EnrouteTransition is the enroute-specific part of a procedure.
+type EnrouteTransition struct {
+	Name string
+	Legs []Leg
+}
+
+// Approach is an instrument approach.
+type Approach struct {
+	Type             int32 // SIMCONNECT_FACILITY_APPROACH_TYPE
+	Suffix           int32
+	RunwayNumber     int32
+	RunwayDesignator RunwayDesignator
+	FAFICAO          string
+	FAFRegion        string
+	FAFHeading       float64
+	FAFAltitude      float64
+	MissedAltitude   float64
+	Transitions      []ApproachTransition
+	FinalLegs        []Leg
+	MissedLegs       []Leg
+}
+
+// Runway returns the ident of the approach runway, e.g. "27R".
+func (a Approach) Runway() string { return RunwayIdent(a.RunwayNumber, a.RunwayDesignator) }
+
+// ApproachTransition is an entry into an approach.
+type ApproachTransition struct {
+	Type        int32
+	IAFICAO     string
+	IAFRegion   string
+	IAFAltitude float64
+	Name        string
+	Legs        []Leg
+}
+
+// Leg is a procedure leg.
+type Leg struct {
+	Type                int32 // ARINC 424 path terminator
+	FixICAO             string
+	FixRegion           string
+	FixType             int32
+	FixLatitude         float64
+	FixLongitude        float64
+	FixAltitude         float64
+	FlyOver             bool
+	TurnDirection       int32
+	Course              float64
+	RouteDistance       float64
+	AltitudeDescription int32
+	Altitude1           float64
+	Altitude2           float64
+	SpeedLimit          float64
+	VerticalAngle       float64
+	IsIAF               bool
+	IsIF                bool
+	IsFAF               bool
+	IsMAP               bool
+}
@@ -0,0 +1,399 @@
+package facility
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	// ErrNotFound is returned when a request completed without an airport
+	// record, typically because the ICAO code is unknown.
+	ErrNotFound = errors.New("facility: not found")
+
+	// ErrShortRecord is returned when a packet carries fewer bytes than its
+	// record type requires.
+	ErrShortRecord = errors.New("facility: record shorter than its definition")
+
+	// ErrUnsupportedType is returned for packets of a record type the
+	// Assembler does not decode.
+	ErrUnsupportedType = errors.New("facility: unsupported record type")
+
+	// ErrIncomplete is returned when fewer list items arrived than their
+	// lists announced, e.g. because packets were dropped.
+	ErrIncomplete = errors.New("facility: incomplete record lists")
+)
+
+// Packet is one SIMCONNECT_RECV_FACILITY_DATA message, detached from the
+// receive buffer.
+type Packet struct {
+	Type       DataType
+	UniqueID   uint32 // UniqueRequestId
+	ParentID   uint32 // ParentUniqueRequestId
+	IsListItem bool
+	ItemIndex  uint32
+	ListSize   uint32
+	Data       []byte // packed field values
+}
+
+// Assembler rebuilds a facility record tree from its packets. Packets must
+// be added in the order they were received; the result is read once the
+// request has ended. An Assembler is not safe for concurrent use.
+type Assembler struct {
+	root  *node
+	nodes map[uint32]*node
+	lists map[listKey]*listCount
+	order int
+}
+
+// listKey identifies one list of child records.
+type listKey struct {
+	parent uint32
+	typ    DataType
+}
+
+type listCount struct {
+	want, got uint32
+}
+
+type node struct {
+	typ      DataType
+	index    uint32
+	order    int
+	rec      record
+	children []*node
+}
+
+// NewAssembler returns an empty Assembler.
+func NewAssembler() *Assembler {
+	return &Assembler{nodes: make(map[uint32]*node), lists: make(map[listKey]*listCount)}
+}
+
+// Add decodes a packet and links it to its parent record. Data is copied.
+// A packet whose parent is unknown is attached to the airport.
+func (a *Assembler) Add(p Packet) error {
+	s, ok := schemas[p.Type]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, p.Type)
+	}
+	if len(p.Data) < s.size {
+		return fmt.Errorf("%w: %s has %d bytes, want %d", ErrShortRecord, p.Type, len(p.Data), s.size)
+	}
+	n := &node{
+		typ:   p.Type,
+		index: p.ItemIndex,
+		order: a.order,
+		rec:   record{s: s, data: bytes.Clone(p.Data[:s.size])},
+	}
+	a.order++
+
+	if p.Type == DataAirport && a.root == nil {
+		a.root = n
+		a.nodes[p.UniqueID] = n
+		return nil
+	}
+	parent, ok := a.nodes[p.ParentID]
+	if !ok {
+		parent = a.root
+	}
+	if parent == nil {
+		return fmt.Errorf("facility: %s record before its airport", p.Type)
+	}
+	parent.children = append(parent.children, n)
+	a.nodes[p.UniqueID] = n
+
+	if p.IsListItem {
+		key := listKey{parent: p.ParentID, typ: p.Type}
+		c, ok := a.lists[key]
+		if !ok {
+			c = &listCount{want: p.ListSize}
+			a.lists[key] = c
+		}
+		c.got++
+	}
+	return nil
+}
+
+// Airport returns the assembled airport. It returns ErrNotFound when no
+// airport record was added and ErrIncomplete when a list misses items.
+func (a *Assembler) Airport() (*Airport, error) {
+	if a.root == nil {
+		return nil, ErrNotFound
+	}
+	for key, c := range a.lists {
+		if c.got < c.want {
+			return nil, fmt.Errorf("%w: %d of %d %s records", ErrIncomplete, c.got, c.want, key.typ)
+		}
+	}
+	r := a.root.rec
+	ap := &Airport{
+		ICAO:               r.str("ICAO"),
+		Region:             r.str("REGION"),
+		Name:               r.str("NAME64"),
+		Latitude:           r.f64("LATITUDE"),
+		Longitude:          r.f64("LONGITUDE"),
+		Altitude:           r.f64("ALTITUDE"),
+		MagVar:             r.f32("MAGVAR"),
+		TowerLatitude:      r.f64("TOWER_LATITUDE"),
+		TowerLongitude:     r.f64("TOWER_LONGITUDE"),
+		TowerAltitude:      r.f64("TOWER_ALTITUDE"),
+		TransitionAltitude: r.f32("TRANSITION_ALTITUDE"),
+		TransitionLevel:    r.f32("TRANSITION_LEVEL"),
+	}
+	if ap.Name == "" {
+		ap.Name = r.str("NAME")
+	}
+
+	for _, c := range a.root.sortedChildren() {
+		r := c.rec
+		switch c.typ {
+		case DataRunway:
+			ap.Runways = append(ap.Runways, Runway{
+				Latitude:        r.f64("LATITUDE"),
+				Longitude:       r.f64("LONGITUDE"),
+				Altitude:        r.f64("ALTITUDE"),
+				Heading:         r.f32("HEADING"),
+				Length:          r.f32("LENGTH"),
+				Width:           r.f32("WIDTH"),
+				PatternAltitude: r.f32("PATTERN_ALTITUDE"),
+				Slope:           r.f32("SLOPE"),
+				TrueSlope:       r.f32("TRUE_SLOPE"),
+				Surface:         r.i32("SURFACE"),
+				Primary:         r.runwayEnd("PRIMARY"),
+				Secondary:       r.runwayEnd("SECONDARY"),
+			})
+		case DataTaxiParking:
+			ap.Parking = append(ap.Parking, Parking{
+				Type:          ParkingType(r.i32("TYPE")),
+				TaxiPointType: r.i32("TAXI_POINT_TYPE"),
+				Name:          r.i32("NAME"),
+				Suffix:        r.i32("SUFFIX"),
+				Number:        r.u32("NUMBER"),
+				Orientation:   r.i32("ORIENTATION"),
+				Heading:       r.f32("HEADING"),
+				Radius:        r.f32("RADIUS"),
+				BiasX:         r.f32("BIAS_X"),
+				BiasZ:         r.f32("BIAS_Z"),
+			})
+		case DataTaxiPoint:
+			ap.TaxiPoints = append(ap.TaxiPoints, TaxiPoint{
+				Type:        r.i32("TYPE"),
+				Orientation: r.i32("ORIENTATION"),
+				BiasX:       r.f32("BIAS_X"),
+				BiasZ:       r.f32("BIAS_Z"),
+			})
+		case DataTaxiPath:
+			ap.TaxiPaths = append(ap.TaxiPaths, TaxiPath{
+				Type:             TaxiPathType(r.i32("TYPE")),
+				Width:            r.f32("WIDTH"),
+				RunwayNumber:     r.i32("RUNWAY_NUMBER"),
+				RunwayDesignator: RunwayDesignator(r.i32("RUNWAY_DESIGNATOR")),
+				Start:            r.i32("START"),
+				End:              r.i32("END"),
+				NameIndex:        r.u32("NAME_INDEX"),
+			})
+		case DataTaxiName:
+			ap.TaxiNames = append(ap.TaxiNames, r.str("NAME"))
+		case DataFrequency:
+			ap.Frequencies = append(ap.Frequencies, Frequency{
+				Type: r.i32("TYPE"),
+				MHz:  float64(r.i32("FREQUENCY")) / 1e6,
+				Name: r.str("NAME"),
+			})
+		case DataHelipad:
+			ap.Helipads = append(ap.Helipads, Helipad{
+				Latitude:  r.f64("LATITUDE"),
+				Longitude: r.f64("LONGITUDE"),
+				Altitude:  r.f64("ALTITUDE"),
+				Heading:   r.f32("HEADING"),
+				Length:    r.f32("LENGTH"),
+				Width:     r.f32("WIDTH"),
+				Surface:   r.i32("SURFACE"),
+				Type:      r.i32("TYPE"),
+			})
+		case DataJetway:
+			ap.Jetways = append(ap.Jetways, Jetway{
+				ParkingGate:   r.i32("PARKING_GATE"),
+				ParkingSuffix: r.i32("PARKING_SUFFIX"),
+				ParkingSpot:   r.i32("PARKING_SPOT"),
+			})
+		case DataDeparture:
+			ap.Departures = append(ap.Departures, c.procedure())
+		case DataArrival:
+			ap.Arrivals = append(ap.Arrivals, c.procedure())
+		case DataApproach:
+			ap.Approaches = append(ap.Approaches, c.approach())
+		}
+	}
+
+	for i := range ap.TaxiPaths {
+		if idx := int(ap.TaxiPaths[i].NameIndex); idx < len(ap.TaxiNames) {
+			ap.TaxiPaths[i].Name = ap.TaxiNames[idx]
+		}
+	}
+	return ap, nil
+}
+
+// sortedChildren returns the children ordered by list index, keeping the
+// arrival order for records of different lists.
+func (n *node) sortedChildren() []*node {
+	out := make([]*node, len(n.children))
+	copy(out, n.children)
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].typ != out[j].typ {
+			return out[i].order < out[j].order
+		}
+		return out[i].index < out[j].index
+	})
+	return out
+}
+
+// legs returns the decoded children of type t.
+func (n *node) legs(t DataType) []Leg {
+	var out []Leg
+	for _, c := range n.sortedChildren() {
+		if c.typ == t {
+			out = append(out, c.rec.leg())
+		}
+	}
+	return out
+}
+
+func (n *node) procedure() Procedure {
+	p := Procedure{Name: n.rec.str("NAME"), Legs: n.legs(DataApproachLeg)}
+	for _, c := range n.sortedChildren() {
+		switch c.typ {
+		case DataRunwayTransition:
+			p.RunwayTransitions = append(p.RunwayTransitions, RunwayTransition{
+				RunwayNumber:     c.rec.i32("RUNWAY_NUMBER"),
+				RunwayDesignator: RunwayDesignator(c.rec.i32("RUNWAY_DESIGNATOR")),
+				Legs:             c.legs(DataApproachLeg),
+			})
+		case DataEnrouteTransition:
+			p.EnrouteTransitions = append(p.EnrouteTransitions, EnrouteTransition{
+				Name: c.rec.str("NAME"),
+				Legs: c.legs(DataApproachLeg),
+			})
+		}
+	}
+	return p
+}
+
+func (n *node) approach() Approach {
+	r := n.rec
+	a := Approach{
+		Type:             r.i32("TYPE"),
+		Suffix:           r.i32("SUFFIX"),
+		RunwayNumber:     r.i32("RUNWAY_NUMBER"),
+		RunwayDesignator: RunwayDesignator(r.i32("RUNWAY_DESIGNATOR")),
+		FAFICAO:          r.str("FAF_ICAO"),
+		FAFRegion:        r.str("FAF_REGION"),
+		FAFHeading:       r.f32("FAF_HEADING"),
+		FAFAltitude:      r.f32("FAF_ALTITUDE"),
+		MissedAltitude:   r.f32("MISSED_ALTITUDE"),
+		FinalLegs:        n.legs(DataFinalApproachLeg),
+		MissedLegs:       n.legs(DataMissedApproachLeg),
+	}
+	for _, c := range n.sortedChildren() {
+		if c.typ != DataApproachTransition {
+			continue
+		}
+		a.Transitions = append(a.Transitions, ApproachTransition{
+			Type:        c.rec.i32("TYPE"),
+			IAFICAO:     c.rec.str("IAF_ICAO"),
+			IAFRegion:   c.rec.str("IAF_REGION"),
+			IAFAltitude: c.rec.f32("IAF_ALTITUDE"),
+			Name:        c.rec.str("NAME"),
+			Legs:        c.legs(DataApproachLeg),
+		})
+	}
+	return a
+}
+
+// record is the packed field data of one packet.
+type record struct {
+	s    *schema
+	data []byte
+}
+
+// field returns the bytes of the named field, or nil if the schema has no
+// such field.
+func (r record) field(name string) []byte {
+	f, ok := r.s.slots[name]
+	if !ok {
+		return nil
+	}
+	return r.data[f.offset : f.offset+f.kind.size()]
+}
+
+func (r record) u32(name string) uint32 {
+	b := r.field(name)
+	if len(b) < 4 {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r record) i32(name string) int32 { return int32(r.u32(name)) }
+
+func (r record) flag(name string) bool { return r.u32(name) != 0 }
+
+func (r record) f32(name string) float64 {
+	return float64(math.Float32frombits(r.u32(name)))
+}
+
+func (r record) f64(name string) float64 {
+	b := r.field(name)
+	if len(b) < 8 {
+		return 0
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func (r record) str(name string) string { return cString(r.field(name)) }
+
+// cString returns the NUL-terminated string at the start of b.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func (r record) runwayEnd(prefix string) RunwayEnd {
+	return RunwayEnd{
+		Number:     r.i32(prefix + "_NUMBER"),
+		Designator: RunwayDesignator(r.i32(prefix + "_DESIGNATOR")),
+		ILSICAO:    r.str(prefix + "_ILS_ICAO"),
+		ILSRegion:  r.str(prefix + "_ILS_REGION"),
+		ILSType:    r.i32(prefix + "_ILS_TYPE"),
+	}
+}
+
+func (r record) leg() Leg {
+	return Leg{
+		Type:                r.i32("TYPE"),
+		FixICAO:             r.str("FIX_ICAO"),
+		FixRegion:           r.str("FIX_REGION"),
+		FixType:             r.i32("FIX_TYPE"),
+		FixLatitude:         r.f64("FIX_LATITUDE"),
+		FixLongitude:        r.f64("FIX_LONGITUDE"),
+		FixAltitude:         r.f64("FIX_ALTITUDE"),
+		FlyOver:             r.flag("FLY_OVER"),
+		TurnDirection:       r.i32("TURN_DIRECTION"),
+		Course:              r.f32("COURSE"),
+		RouteDistance:       r.f32("ROUTE_DISTANCE"),
+		AltitudeDescription: r.i32("APPROACH_ALT_DESC"),
+		Altitude1:           r.f32("ALTITUDE1"),
+		Altitude2:           r.f32("ALTITUDE2"),
+		SpeedLimit:          r.f32("SPEED_LIMIT"),
+		VerticalAngle:       r.f32("VERTICAL_ANGLE"),
+		IsIAF:               r.flag("IS_IAF"),
+		IsIF:                r.flag("IS_IF"),
+		IsFAF:               r.flag("IS_FAF"),
+		IsMAP:               r.flag("IS_MAP"),
+	}
+}
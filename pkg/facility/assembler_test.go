@@ -0,0 +1,197 @@
+package facility
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// encode packs values into the wire layout of record type t, the way the
+// simulator fills SIMCONNECT_RECV_FACILITY_DATA.Data. Missing fields are zero.
+func encode(t *testing.T, typ DataType, values map[string]any) []byte {
+	t.Helper()
+	s := schemas[typ]
+	buf := make([]byte, s.size)
+	for name, v := range values {
+		f, ok := s.slots[name]
+		if !ok {
+			t.Fatalf("%s has no field %s", typ, name)
+		}
+		b := buf[f.offset : f.offset+f.kind.size()]
+		switch f.kind {
+		case kindInt32, kindUint32:
+			binary.LittleEndian.PutUint32(b, uint32(v.(int)))
+		case kindFloat32:
+			binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v.(float64))))
+		case kindFloat64:
+			binary.LittleEndian.PutUint64(b, math.Float64bits(v.(float64)))
+		default:
+			copy(b, v.(string))
+		}
+	}
+	return buf
+}
+
+// capture is the packet sequence of a small airport, in simulator order.
+func capture(t *testing.T) []Packet {
+	return []Packet{
+		{Type: DataAirport, UniqueID: 1, Data: encode(t, DataAirport, map[string]any{
+			"ICAO": "LKPR", "REGION": "LK", "NAME": "Ruzyne", "NAME64": "Vaclav Havel Airport Prague",
+			"LATITUDE": 50.1008, "LONGITUDE": 14.26, "ALTITUDE": 380.0, "TRANSITION_ALTITUDE": 1524.0,
+		})},
+		{Type: DataRunway, UniqueID: 2, ParentID: 1, IsListItem: true, ListSize: 1, Data: encode(t, DataRunway, map[string]any{
+			"HEADING": 243.0, "LENGTH": 3715.0, "PRIMARY_NUMBER": 24, "SECONDARY_NUMBER": 6,
+			"PRIMARY_ILS_ICAO": "IRP",
+		})},
+		// Taxi names arrive before the paths that reference them.
+		{Type: DataTaxiName, UniqueID: 3, ParentID: 1, IsListItem: true, ItemIndex: 0, ListSize: 2, Data: encode(t, DataTaxiName, map[string]any{"NAME": "A"})},
+		{Type: DataTaxiName, UniqueID: 4, ParentID: 1, IsListItem: true, ItemIndex: 1, ListSize: 2, Data: encode(t, DataTaxiName, map[string]any{"NAME": "B"})},
+		// List items out of order must be placed by ItemIndex.
+		{Type: DataTaxiPath, UniqueID: 6, ParentID: 1, IsListItem: true, ItemIndex: 1, ListSize: 2, Data: encode(t, DataTaxiPath, map[string]any{
+			"TYPE": int(TaxiPathParking), "START": 1, "END": 0,
+		})},
+		{Type: DataTaxiPath, UniqueID: 5, ParentID: 1, IsListItem: true, ItemIndex: 0, ListSize: 2, Data: encode(t, DataTaxiPath, map[string]any{
+			"TYPE": int(TaxiPathTaxi), "START": 0, "END": 1, "NAME_INDEX": 1,
+		})},
+		{Type: DataTaxiParking, UniqueID: 7, ParentID: 1, IsListItem: true, ListSize: 1, Data: encode(t, DataTaxiParking, map[string]any{
+			"TYPE": int(ParkingGateHeavy), "NAME": parkingNameGateA + 2, "NUMBER": 12, "BIAS_X": -120.5, "BIAS_Z": 44.0,
+		})},
+		{Type: DataFrequency, UniqueID: 8, ParentID: 1, IsListItem: true, ListSize: 1, Data: encode(t, DataFrequency, map[string]any{
+			"TYPE": 6, "FREQUENCY": 118100000, "NAME": "RUZYNE TOWER",
+		})},
+		{Type: DataDeparture, UniqueID: 9, ParentID: 1, IsListItem: true, ListSize: 1, Data: encode(t, DataDeparture, map[string]any{"NAME": "VOZ1B"})},
+		{Type: DataApproachLeg, UniqueID: 10, ParentID: 9, IsListItem: true, ListSize: 1, Data: encode(t, DataApproachLeg, map[string]any{"FIX_ICAO": "VOZ"})},
+		{Type: DataRunwayTransition, UniqueID: 11, ParentID: 9, IsListItem: true, ListSize: 1, Data: encode(t, DataRunwayTransition, map[string]any{
+			"RUNWAY_NUMBER": 24, "RUNWAY_DESIGNATOR": int(RunwayDesignatorNone),
+		})},
+		{Type: DataApproachLeg, UniqueID: 12, ParentID: 11, IsListItem: true, ListSize: 2, Data: encode(t, DataApproachLeg, map[string]any{"FIX_ICAO": "PR521", "FLY_OVER": 1})},
+		{Type: DataApproachLeg, UniqueID: 13, ParentID: 11, IsListItem: true, ItemIndex: 1, ListSize: 2, Data: encode(t, DataApproachLeg, map[string]any{"FIX_ICAO": "PR522"})},
+		{Type: DataApproach, UniqueID: 14, ParentID: 1, IsListItem: true, ListSize: 1, Data: encode(t, DataApproach, map[string]any{
+			"RUNWAY_NUMBER": 24, "RUNWAY_DESIGNATOR": int(RunwayDesignatorLeft), "FAF_ICAO": "RAPET",
+		})},
+		{Type: DataApproachTransition, UniqueID: 15, ParentID: 14, IsListItem: true, ListSize: 1, Data: encode(t, DataApproachTransition, map[string]any{"NAME": "LOMKI"})},
+		{Type: DataApproachLeg, UniqueID: 16, ParentID: 15, IsListItem: true, ListSize: 1, Data: encode(t, DataApproachLeg, map[string]any{"FIX_ICAO": "LOMKI", "IS_IAF": 1})},
+		{Type: DataFinalApproachLeg, UniqueID: 17, ParentID: 14, IsListItem: true, ListSize: 1, Data: encode(t, DataFinalApproachLeg, map[string]any{"FIX_ICAO": "RAPET", "IS_FAF": 1})},
+		{Type: DataMissedApproachLeg, UniqueID: 18, ParentID: 14, IsListItem: true, ListSize: 1, Data: encode(t, DataMissedApproachLeg, map[string]any{"ALTITUDE1": 914.4})},
+	}
+}
+
+func TestAssemblerAirport(t *testing.T) {
+	a := NewAssembler()
+	for _, p := range capture(t) {
+		if err := a.Add(p); err != nil {
+			t.Fatalf("Add(%s): %v", p.Type, err)
+		}
+	}
+	ap, err := a.Airport()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ap.ICAO != "LKPR" || ap.Region != "LK" || ap.Name != "Vaclav Havel Airport Prague" {
+		t.Errorf("airport identity = %q %q %q", ap.ICAO, ap.Region, ap.Name)
+	}
+	if ap.Latitude != 50.1008 || ap.TransitionAltitude != 1524 {
+		t.Errorf("airport position = %v, transition altitude %v", ap.Latitude, ap.TransitionAltitude)
+	}
+
+	if len(ap.Runways) != 1 {
+		t.Fatalf("got %d runways, want 1", len(ap.Runways))
+	}
+	rwy := ap.Runways[0]
+	if rwy.Primary.Ident() != "24" || rwy.Secondary.Ident() != "06" || rwy.Length != 3715 || rwy.Primary.ILSICAO != "IRP" {
+		t.Errorf("runway = %+v", rwy)
+	}
+
+	if len(ap.TaxiPaths) != 2 || ap.TaxiPaths[0].Type != TaxiPathTaxi || ap.TaxiPaths[1].Type != TaxiPathParking {
+		t.Fatalf("taxi paths not ordered by item index: %+v", ap.TaxiPaths)
+	}
+	if ap.TaxiPaths[0].Name != "B" {
+		t.Errorf("taxi path name = %q, want B", ap.TaxiPaths[0].Name)
+	}
+
+	if len(ap.Parking) != 1 || ap.Parking[0].Ident() != "C12" || !ap.Parking[0].Type.IsGate() || ap.Parking[0].BiasX != -120.5 {
+		t.Errorf("parking = %+v", ap.Parking)
+	}
+	if len(ap.Frequencies) != 1 || ap.Frequencies[0].MHz != 118.1 || ap.Frequencies[0].Name != "RUZYNE TOWER" {
+		t.Errorf("frequencies = %+v", ap.Frequencies)
+	}
+
+	if len(ap.Departures) != 1 {
+		t.Fatalf("got %d departures, want 1", len(ap.Departures))
+	}
+	dep := ap.Departures[0]
+	if dep.Name != "VOZ1B" || len(dep.Legs) != 1 || dep.Legs[0].FixICAO != "VOZ" {
+		t.Errorf("departure = %+v", dep)
+	}
+	if len(dep.RunwayTransitions) != 1 || dep.RunwayTransitions[0].Runway() != "24" {
+		t.Fatalf("runway transitions = %+v", dep.RunwayTransitions)
+	}
+	if legs := dep.RunwayTransitions[0].Legs; len(legs) != 2 || legs[0].FixICAO != "PR521" || !legs[0].FlyOver {
+		t.Errorf("runway transition legs = %+v", legs)
+	}
+
+	if len(ap.Approaches) != 1 {
+		t.Fatalf("got %d approaches, want 1", len(ap.Approaches))
+	}
+	app := ap.Approaches[0]
+	if app.Runway() != "24L" || app.FAFICAO != "RAPET" {
+		t.Errorf("approach = %+v", app)
+	}
+	if len(app.Transitions) != 1 || len(app.Transitions[0].Legs) != 1 || !app.Transitions[0].Legs[0].IsIAF {
+		t.Errorf("approach transitions = %+v", app.Transitions)
+	}
+	if len(app.FinalLegs) != 1 || !app.FinalLegs[0].IsFAF || len(app.MissedLegs) != 1 {
+		t.Errorf("approach legs final=%+v missed=%+v", app.FinalLegs, app.MissedLegs)
+	}
+}
+
+func TestAssemblerErrors(t *testing.T) {
+	a := NewAssembler()
+	if _, err := a.Airport(); !errors.Is(err, ErrNotFound) {
+		t.Errorf("empty assembler: err = %v, want ErrNotFound", err)
+	}
+	if err := a.Add(Packet{Type: DataVOR}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("VOR packet: err = %v, want ErrUnsupportedType", err)
+	}
+	if err := a.Add(Packet{Type: DataAirport, Data: make([]byte, 10)}); !errors.Is(err, ErrShortRecord) {
+		t.Errorf("short packet: err = %v, want ErrShortRecord", err)
+	}
+	if err := a.Add(Packet{Type: DataRunway, Data: encode(t, DataRunway, nil)}); err == nil {
+		t.Error("runway before airport: want error")
+	}
+
+	// Drop one taxi path from an otherwise complete capture.
+	a = NewAssembler()
+	for _, p := range capture(t) {
+		if p.UniqueID == 6 {
+			continue
+		}
+		if err := a.Add(p); err != nil {
+			t.Fatalf("Add(%s): %v", p.Type, err)
+		}
+	}
+	if _, err := a.Airport(); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("dropped packet: err = %v, want ErrIncomplete", err)
+	}
+}
+
+func TestRunwayIdent(t *testing.T) {
+	tests := []struct {
+		number int32
+		des    RunwayDesignator
+		want   string
+	}{
+		{9, RunwayDesignatorLeft, "09L"},
+		{36, RunwayDesignatorNone, "36"},
+		{37, RunwayDesignatorNone, "N"},
+		{44, RunwayDesignatorWater, "NWW"},
+		{0, RunwayDesignatorNone, ""},
+	}
+	for _, tt := range tests {
+		if got := RunwayIdent(tt.number, tt.des); got != tt.want {
+			t.Errorf("RunwayIdent(%d, %d) = %q, want %q", tt.number, tt.des, got, tt.want)
+		}
+	}
+}
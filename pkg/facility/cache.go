@@ -0,0 +1,443 @@
+package facility
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached entries stay valid unless WithTTL
+// says otherwise. Scenery changes with simulator updates, which the build
+// key already catches, so entries can live long.
+const DefaultCacheTTL = 30 * 24 * time.Hour
+
+// cacheVersion is the on-disk format version. Files of another version are
+// discarded on open.
+const cacheVersion = 1
+
+// ErrCacheVersion is returned by OpenCache in read-only mode when the file
+// was written in a different format.
+var ErrCacheVersion = errors.New("facility: unsupported cache file version")
+
+// Cache stores decoded airports, waypoints, NDBs, VORs and facility lists in
+// a local JSON file so they can be served without asking the simulator
+// again, or with no simulator at all.
+//
+// Airports are keyed by ICAO and region, other facilities by kind, ICAO and
+// region, lists by facility kind, and every entry records the simulator
+// build it was fetched from. Once SetBuild is
+// called, entries from other builds are misses; a cache whose build is
+// never set, such as an offline reader, accepts any build.
+//
+// A Cache is safe for concurrent use. Changes are kept in memory until Save.
+type Cache struct {
+	path     string
+	ttl      time.Duration
+	readOnly bool
+	now      func() time.Time
+
+	mu    sync.Mutex
+	build string
+	file  cacheFile
+	dirty bool
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithTTL sets how long entries stay valid after they are stored. Zero
+// disables expiry. Default is DefaultCacheTTL.
+func WithTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithReadOnly opens the cache for lookups only. Puts, invalidation and
+// Save become no-ops, and the file must already exist.
+func WithReadOnly() CacheOption {
+	return func(c *Cache) {
+		c.readOnly = true
+	}
+}
+
+type cacheFile struct {
+	Version    int                       `json:"version"`
+	Airports   map[string]*airportEntry  `json:"airports"`
+	Facilities map[string]*facilityEntry `json:"facilities,omitempty"`
+	Lists      map[string]*listEntry     `json:"lists"`
+}
+
+type cacheEntry struct {
+	Build  string    `json:"build"`
+	Stored time.Time `json:"stored"`
+}
+
+type airportEntry struct {
+	cacheEntry
+	Include Include         `json:"include"`
+	Airport json.RawMessage `json:"airport"` // decoded again on every hit, so callers own the result
+}
+
+type facilityEntry struct {
+	cacheEntry
+	Facility Summary `json:"facility"`
+}
+
+type listEntry struct {
+	cacheEntry
+	Items []Summary `json:"items"`
+}
+
+// OpenCache loads the cache stored at path. A missing file starts an empty
+// cache, except in read-only mode, where it is an error. A file written in
+// another format version is discarded.
+func OpenCache(path string, opts ...CacheOption) (*Cache, error) {
+	c := &Cache{
+		path: path,
+		ttl:  DefaultCacheTTL,
+		now:  time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.file = emptyCacheFile()
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !c.readOnly:
+		return c, nil
+	case err != nil:
+		return nil, fmt.Errorf("facility: open cache: %w", err)
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("facility: read cache %s: %w", path, err)
+	}
+	if file.Version != cacheVersion {
+		if c.readOnly {
+			return nil, fmt.Errorf("%w: %d", ErrCacheVersion, file.Version)
+		}
+		return c, nil
+	}
+	if file.Airports != nil {
+		c.file.Airports = file.Airports
+	}
+	if file.Facilities != nil {
+		c.file.Facilities = file.Facilities
+	}
+	if file.Lists != nil {
+		c.file.Lists = file.Lists
+	}
+	return c, nil
+}
+
+func emptyCacheFile() cacheFile {
+	return cacheFile{
+		Version:    cacheVersion,
+		Airports:   make(map[string]*airportEntry),
+		Facilities: make(map[string]*facilityEntry),
+		Lists:      make(map[string]*listEntry),
+	}
+}
+
+// Path returns the file the cache is stored in.
+func (c *Cache) Path() string { return c.path }
+
+// SetBuild sets the simulator build that lookups must match and that new
+// entries are stored under, e.g. "12.1.282174.999". An empty build accepts
+// entries of any build.
+func (c *Cache) SetBuild(build string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.build = build
+}
+
+// Build returns the simulator build set by SetBuild.
+func (c *Cache) Build() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.build
+}
+
+// Airport returns the cached airport icao with at least the child records
+// selected by include. An empty region matches any region, and misses when
+// icao is cached in more than one.
+func (c *Cache) Airport(icao, region string, include Include) (*Airport, bool) {
+	include = AirportOptions{Include: include}.Normalize()
+	c.mu.Lock()
+	e := c.lookupAirportLocked(icao, region)
+	if e == nil || !c.validLocked(e.cacheEntry) || e.Include&include != include {
+		c.mu.Unlock()
+		return nil, false
+	}
+	raw := e.Airport
+	c.mu.Unlock()
+
+	ap := &Airport{}
+	if err := json.Unmarshal(raw, ap); err != nil {
+		return nil, false
+	}
+	return ap, true
+}
+
+// lookupAirportLocked finds the entry of icao in region, or in any region
+// when region is empty. Caller must hold c.mu.
+func (c *Cache) lookupAirportLocked(icao, region string) *airportEntry {
+	return lookupEntry(c.file.Airports, airportKey(icao, region))
+}
+
+// PutAirport stores ap, fetched with the child records selected by include.
+func (c *Cache) PutAirport(ap *Airport, include Include) error {
+	if c.readOnly || ap == nil {
+		return nil
+	}
+	raw, err := json.Marshal(ap)
+	if err != nil {
+		return fmt.Errorf("facility: encode airport %s: %w", ap.ICAO, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.Airports[airportKey(ap.ICAO, ap.Region)] = &airportEntry{
+		cacheEntry: c.entryLocked(),
+		Include:    AirportOptions{Include: include}.Normalize(),
+		Airport:    raw,
+	}
+	c.dirty = true
+	return nil
+}
+
+// Facility returns the cached waypoint, NDB or VOR icao of kind k, stored
+// by PutFacility or found in the cached list of k. An empty region matches
+// any region, and misses when icao is cached in more than one. Airports are
+// never found here; use Airport.
+func (c *Cache) Facility(k Kind, icao, region string) (Summary, bool) {
+	if k == KindAirport {
+		return Summary{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := lookupEntry(c.file.Facilities, facilityKey(k, icao, region)); e != nil && c.validLocked(e.cacheEntry) {
+		return e.Facility, true
+	}
+	e := c.file.Lists[k.String()]
+	if e == nil || !c.validLocked(e.cacheEntry) {
+		return Summary{}, false
+	}
+	icao = strings.ToUpper(strings.TrimSpace(icao))
+	var found *Summary
+	for i := range e.Items {
+		s := &e.Items[i]
+		if !strings.EqualFold(s.ICAO, icao) || region != "" && !strings.EqualFold(s.Region, region) {
+			continue
+		}
+		if found != nil {
+			return Summary{}, false
+		}
+		found = s
+	}
+	if found == nil {
+		return Summary{}, false
+	}
+	return *found, true
+}
+
+// PutFacility stores the waypoint, NDB or VOR s under its kind, ICAO and
+// region. Airports return ErrUnsupportedType; use PutAirport.
+func (c *Cache) PutFacility(s Summary) error {
+	switch s.Kind {
+	case KindWaypoint, KindNDB, KindVOR:
+	default:
+		return fmt.Errorf("%w: facility %s", ErrUnsupportedType, s.Kind)
+	}
+	if c.readOnly {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.Facilities[facilityKey(s.Kind, s.ICAO, s.Region)] = &facilityEntry{
+		cacheEntry: c.entryLocked(),
+		Facility:   s,
+	}
+	c.dirty = true
+	return nil
+}
+
+// List returns a copy of the cached facility list of kind k.
+func (c *Cache) List(k Kind) ([]Summary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.file.Lists[k.String()]
+	if e == nil || !c.validLocked(e.cacheEntry) {
+		return nil, false
+	}
+	return append([]Summary(nil), e.Items...), true
+}
+
+// PutList stores the complete facility list of kind k.
+func (c *Cache) PutList(k Kind, items []Summary) {
+	if c.readOnly {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.Lists[k.String()] = &listEntry{
+		cacheEntry: c.entryLocked(),
+		Items:      append([]Summary(nil), items...),
+	}
+	c.dirty = true
+}
+
+// InvalidateAirport removes the airport icao. An empty region removes it
+// from every region.
+func (c *Cache) InvalidateAirport(icao, region string) {
+	if c.readOnly {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if invalidateEntries(c.file.Airports, airportKey(icao, region)) {
+		c.dirty = true
+	}
+}
+
+// InvalidateFacility removes the facility icao of kind k stored by
+// PutFacility. An empty region removes it from every region. The cached
+// list of k is left alone; use InvalidateList.
+func (c *Cache) InvalidateFacility(k Kind, icao, region string) {
+	if c.readOnly {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if invalidateEntries(c.file.Facilities, facilityKey(k, icao, region)) {
+		c.dirty = true
+	}
+}
+
+// InvalidateList removes the facility list of kind k.
+func (c *Cache) InvalidateList(k Kind) {
+	if c.readOnly {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.file.Lists[k.String()]; ok {
+		delete(c.file.Lists, k.String())
+		c.dirty = true
+	}
+}
+
+// Clear removes every entry.
+func (c *Cache) Clear() {
+	if c.readOnly {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file = emptyCacheFile()
+	c.dirty = true
+}
+
+// Save writes the cache to its file if it changed since it was opened or
+// last saved. The file is replaced atomically, so a crash never leaves a
+// truncated cache behind.
+func (c *Cache) Save() error {
+	if c.readOnly {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return nil
+	}
+	data, err := json.Marshal(c.file)
+	if err != nil {
+		return fmt.Errorf("facility: encode cache: %w", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("facility: save cache: %w", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("facility: save cache: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("facility: save cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// entryLocked returns the metadata for an entry stored now.
+// Caller must hold c.mu.
+func (c *Cache) entryLocked() cacheEntry {
+	return cacheEntry{Build: c.build, Stored: c.now()}
+}
+
+// validLocked reports whether e is neither expired nor from another build.
+// Caller must hold c.mu.
+func (c *Cache) validLocked(e cacheEntry) bool {
+	if c.build != "" && e.Build != c.build {
+		return false
+	}
+	return c.ttl == 0 || c.now().Sub(e.Stored) < c.ttl
+}
+
+// Entries are keyed "ICAO/REGION" for airports and "kind/ICAO/REGION" for
+// other facilities. A key with an empty region names the ICAO in any
+// region.
+func airportKey(icao, region string) string {
+	return strings.ToUpper(strings.TrimSpace(icao)) + "/" + strings.ToUpper(region)
+}
+
+func facilityKey(k Kind, icao, region string) string {
+	return k.String() + "/" + airportKey(icao, region)
+}
+
+// lookupEntry returns the entry of key in m. A key without a region matches
+// the ICAO in any region, and returns nil when more than one region has it,
+// rather than whichever one map iteration reaches first.
+func lookupEntry[E any](m map[string]*E, key string) *E {
+	if !strings.HasSuffix(key, "/") {
+		return m[key]
+	}
+	var found *E
+	for k, e := range m {
+		if strings.HasPrefix(k, key) && !strings.Contains(k[len(key):], "/") {
+			if found != nil {
+				return nil
+			}
+			found = e
+		}
+	}
+	return found
+}
+
+// invalidateEntries deletes the entries of key from m, every region's when
+// key has no region, and reports whether any was deleted.
+func invalidateEntries[E any](m map[string]*E, key string) bool {
+	deleted := false
+	for k := range m {
+		if k == key || strings.HasSuffix(key, "/") && strings.HasPrefix(k, key) && !strings.Contains(k[len(key):], "/") {
+			delete(m, k)
+			deleted = true
+		}
+	}
+	return deleted
+}
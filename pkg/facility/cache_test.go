@@ -0,0 +1,188 @@
+package facility
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheAirport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facilities.json")
+	c, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBuild("1.0.0.0")
+
+	ap := &Airport{ICAO: "LKPR", Region: "LK", Runways: []Runway{{Length: 3715}}}
+	if err := c.PutAirport(ap, IncludeRunways); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.Airport("lkpr", "", IncludeRunways)
+	if !ok || got.ICAO != "LKPR" || len(got.Runways) != 1 || got.Runways[0].Length != 3715 {
+		t.Fatalf("Airport = %+v, %v", got, ok)
+	}
+	got.Runways[0].Length = 0
+	if again, _ := c.Airport("LKPR", "LK", IncludeRunways); again.Runways[0].Length != 3715 {
+		t.Fatal("cached airport shared with caller")
+	}
+	if _, ok := c.Airport("LKPR", "LK", IncludeRunways|IncludeParking); ok {
+		t.Error("hit for child records that were not cached")
+	}
+	if _, ok := c.Airport("LKPR", "ED", IncludeRunways); ok {
+		t.Error("hit in another region")
+	}
+
+	c.SetBuild("2.0.0.0")
+	if _, ok := c.Airport("LKPR", "", IncludeRunways); ok {
+		t.Error("hit from another simulator build")
+	}
+	c.SetBuild("1.0.0.0")
+
+	// The same ICAO in two regions is ambiguous without a region.
+	if err := c.PutAirport(&Airport{ICAO: "LKPR", Region: "ED"}, IncludeRunways); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Airport("LKPR", "", IncludeRunways); ok {
+		t.Error("hit for an ICAO cached in two regions")
+	}
+	if got, ok := c.Airport("LKPR", "ed", IncludeRunways); !ok || got.Region != "ED" {
+		t.Errorf("Airport in region ED = %+v, %v", got, ok)
+	}
+
+	c.InvalidateAirport("LKPR", "")
+	if _, ok := c.Airport("LKPR", "LK", IncludeRunways); ok {
+		t.Error("hit after invalidation")
+	}
+}
+
+func TestCacheFacility(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "facilities.json")
+	c, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vor := Summary{Kind: KindVOR, ICAO: "OKL", Region: "LK", Latitude: 50.1, Frequency: 112600000}
+	if err := c.PutFacility(vor); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutFacility(Summary{Kind: KindNDB, ICAO: "OKL", Region: "LK", Frequency: 356000}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutFacility(Summary{Kind: KindAirport, ICAO: "LKPR"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("PutFacility of an airport: err = %v, want ErrUnsupportedType", err)
+	}
+
+	if got, ok := c.Facility(KindVOR, "okl", ""); !ok || got != vor {
+		t.Fatalf("Facility = %+v, %v, want %+v", got, ok, vor)
+	}
+	if got, ok := c.Facility(KindNDB, "OKL", "LK"); !ok || got.Frequency != 356000 {
+		t.Errorf("NDB = %+v, %v, want it keyed apart from the VOR", got, ok)
+	}
+	if _, ok := c.Facility(KindWaypoint, "OKL", ""); ok {
+		t.Error("hit for a kind that was not cached")
+	}
+
+	// Facilities not stored one by one are found in the list of their kind.
+	c.PutList(KindWaypoint, []Summary{
+		{Kind: KindWaypoint, ICAO: "VOZ", Region: "LK", Latitude: 49.9},
+		{Kind: KindWaypoint, ICAO: "ERMEL", Region: "LK"},
+		{Kind: KindWaypoint, ICAO: "ERMEL", Region: "ED"},
+	})
+	if got, ok := c.Facility(KindWaypoint, "VOZ", ""); !ok || got.Latitude != 49.9 {
+		t.Errorf("waypoint from list = %+v, %v", got, ok)
+	}
+	if _, ok := c.Facility(KindWaypoint, "ERMEL", ""); ok {
+		t.Error("hit for a listed waypoint in two regions")
+	}
+	if got, ok := c.Facility(KindWaypoint, "ERMEL", "ED"); !ok || got.Region != "ED" {
+		t.Errorf("waypoint ERMEL in ED = %+v, %v", got, ok)
+	}
+
+	if err := c.PutFacility(Summary{Kind: KindVOR, ICAO: "OKL", Region: "ED"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Facility(KindVOR, "OKL", ""); ok {
+		t.Error("hit for a VOR cached in two regions")
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := OpenCache(path, WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := r.Facility(KindVOR, "OKL", "LK"); !ok || got != vor {
+		t.Errorf("VOR after reload = %+v, %v", got, ok)
+	}
+
+	c.InvalidateFacility(KindVOR, "OKL", "")
+	if _, ok := c.Facility(KindVOR, "OKL", "LK"); ok {
+		t.Error("hit after invalidation")
+	}
+	if _, ok := c.Facility(KindNDB, "OKL", "LK"); !ok {
+		t.Error("invalidating the VOR removed the NDB")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c, err := OpenCache(filepath.Join(t.TempDir(), "facilities.json"), WithTTL(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.now = func() time.Time { return now }
+	c.PutList(KindVOR, []Summary{{Kind: KindVOR, ICAO: "OKL"}})
+
+	now = now.Add(59 * time.Minute)
+	if _, ok := c.List(KindVOR); !ok {
+		t.Fatal("miss before expiry")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.List(KindVOR); ok {
+		t.Fatal("hit after expiry")
+	}
+}
+
+func TestCacheSaveAndOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "facilities.json")
+	if _, err := OpenCache(path, WithReadOnly()); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("read-only open of missing file: err = %v, want os.ErrNotExist", err)
+	}
+
+	c, err := OpenCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBuild("1.0.0.0")
+	c.PutList(KindAirport, []Summary{{Kind: KindAirport, ICAO: "LKPR", Latitude: 50.1}})
+	if err := c.PutAirport(&Airport{ICAO: "LKPR", Region: "LK"}, IncludeAll); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An offline reader has no build and accepts whatever was stored.
+	r, err := OpenCache(path, WithReadOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, ok := r.List(KindAirport); !ok || len(list) != 1 || list[0].ICAO != "LKPR" {
+		t.Fatalf("List = %+v, %v", list, ok)
+	}
+	if _, ok := r.Airport("LKPR", "", IncludeRunways); !ok {
+		t.Fatal("airport missing after reload")
+	}
+
+	r.Clear()
+	r.PutList(KindNDB, nil)
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.List(KindAirport); !ok {
+		t.Fatal("read-only cache was modified")
+	}
+}
@@ -0,0 +1,148 @@
+package facility
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+// Kind is the facility type of a facility list. The values match
+// SIMCONNECT_FACILITY_LIST_TYPE.
+type Kind uint32
+
+const (
+	KindAirport Kind = iota
+	KindWaypoint
+	KindNDB
+	KindVOR
+)
+
+// String returns the lower-case name of the kind, e.g. "airport".
+func (k Kind) String() string {
+	switch k {
+	case KindAirport:
+		return "airport"
+	case KindWaypoint:
+		return "waypoint"
+	case KindNDB:
+		return "ndb"
+	case KindVOR:
+		return "vor"
+	}
+	return fmt.Sprintf("Kind(%d)", uint32(k))
+}
+
+// Summary is one entry of a facility list: the identity and position of a
+// facility plus the few kind-specific values the list carries.
+type Summary struct {
+	Kind      Kind
+	ICAO      string
+	Region    string
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+
+	MagVar    float64 // waypoints, NDBs and VORs
+	Frequency uint32  // NDBs and VORs, in Hz
+
+	// VORs only.
+	Flags           uint32
+	Localizer       float64
+	GlideLatitude   float64
+	GlideLongitude  float64
+	GlideAltitude   float64
+	GlideSlopeAngle float64
+}
+
+// listTail is the size of the fields following the ident and region of a
+// list entry of each kind.
+var listTail = map[Kind]int{
+	KindAirport:  24, // latitude, longitude, altitude
+	KindWaypoint: 32, // + magvar
+	KindNDB:      36, // + frequency
+	KindVOR:      80, // + flags, localizer, glide slope position and angle
+}
+
+// DecodeList decodes the count entries of a facility list message body,
+// the bytes following the SIMCONNECT_RECV_FACILITIES_LIST header.
+//
+// The entry stride is derived from the data rather than from the Go
+// structs: MSFS 2020 packs a 6-byte ident, MSFS 2024 a 9-byte one and may
+// append trailing bytes, so casting RgData entries reads garbage.
+func DecodeList(k Kind, data []byte, count int) ([]Summary, error) {
+	tail, ok := listTail[k]
+	if !ok {
+		return nil, fmt.Errorf("%w: list %s", ErrUnsupportedType, k)
+	}
+	if count <= 0 {
+		return nil, nil
+	}
+	stride := len(data) / count
+	var prefix int // ident + region
+	switch {
+	case stride >= 12+tail:
+		prefix = 12
+	case stride >= 9+tail:
+		prefix = 9
+	default:
+		return nil, fmt.Errorf("%w: %s list entry of %d bytes", ErrShortRecord, k, stride)
+	}
+
+	out := make([]Summary, count)
+	for i := range out {
+		e := data[i*stride : (i+1)*stride]
+		f64 := func(off int) float64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(e[prefix+off:]))
+		}
+		s := Summary{
+			Kind:      k,
+			ICAO:      cString(e[:prefix-3]),
+			Region:    cString(e[prefix-3 : prefix]),
+			Latitude:  f64(0),
+			Longitude: f64(8),
+			Altitude:  f64(16),
+		}
+		if k != KindAirport {
+			s.MagVar = f64(24)
+		}
+		if k == KindNDB || k == KindVOR {
+			s.Frequency = binary.LittleEndian.Uint32(e[prefix+32:])
+		}
+		if k == KindVOR {
+			s.Flags = binary.LittleEndian.Uint32(e[prefix+36:])
+			s.Localizer = f64(40)
+			s.GlideLatitude = f64(48)
+			s.GlideLongitude = f64(56)
+			s.GlideAltitude = f64(64)
+			s.GlideSlopeAngle = f64(72)
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// Nearest returns up to n entries of list closest to lat/lon, nearest
+// first. A negative n returns every entry sorted by distance. The list
+// itself is not modified.
+func Nearest(list []Summary, lat, lon float64, n int) []Summary {
+	type ranked struct {
+		s Summary
+		d float64
+	}
+	r := make([]ranked, len(list))
+	for i, s := range list {
+		r[i] = ranked{s, calc.HaversineMeters(lat, lon, s.Latitude, s.Longitude)}
+	}
+	sort.SliceStable(r, func(i, j int) bool { return r[i].d < r[j].d })
+	if n < 0 || n > len(r) {
+		n = len(r)
+	}
+	out := make([]Summary, n)
+	for i := range out {
+		out[i] = r[i].s
+	}
+	return out
+}
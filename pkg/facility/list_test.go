@@ -0,0 +1,92 @@
+package facility
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// packList packs summaries the way a facility list message lays out its
+// entries: ident of identLen bytes, region[3], the kind's fields, then pad
+// trailing bytes.
+func packList(k Kind, identLen, pad int, items ...Summary) []byte {
+	stride := identLen + 3 + listTail[k] + pad
+	data := make([]byte, stride*len(items))
+	for i, s := range items {
+		e := data[i*stride:]
+		copy(e, s.ICAO)
+		copy(e[identLen:], s.Region)
+		v := e[identLen+3:]
+		put := func(off int, f float64) { binary.LittleEndian.PutUint64(v[off:], math.Float64bits(f)) }
+		put(0, s.Latitude)
+		put(8, s.Longitude)
+		put(16, s.Altitude)
+		if k != KindAirport {
+			put(24, s.MagVar)
+		}
+		if k == KindNDB || k == KindVOR {
+			binary.LittleEndian.PutUint32(v[32:], s.Frequency)
+		}
+		if k == KindVOR {
+			binary.LittleEndian.PutUint32(v[36:], s.Flags)
+			put(72, s.GlideSlopeAngle)
+		}
+	}
+	return data
+}
+
+func TestDecodeList(t *testing.T) {
+	lkpr := Summary{Kind: KindAirport, ICAO: "LKPR", Region: "LK", Latitude: 50.1008, Longitude: 14.26, Altitude: 380}
+	lkvo := Summary{Kind: KindAirport, ICAO: "LKVO", Region: "LK", Latitude: 50.2166, Longitude: 14.3955, Altitude: 200}
+
+	tests := []struct {
+		name     string
+		identLen int
+		pad      int
+	}{
+		{"MSFS 2020", 6, 0},
+		{"MSFS 2024", 9, 0},
+		{"MSFS 2024 padded", 9, 5},
+	}
+	for _, tt := range tests {
+		got, err := DecodeList(KindAirport, packList(KindAirport, tt.identLen, tt.pad, lkpr, lkvo), 2)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(got) != 2 || got[0] != lkpr || got[1] != lkvo {
+			t.Errorf("%s: got %+v", tt.name, got)
+		}
+	}
+
+	vor := Summary{Kind: KindVOR, ICAO: "OKL", Region: "LK", Latitude: 50.1, MagVar: 4, Frequency: 112600000, Flags: 3, GlideSlopeAngle: 3}
+	got, err := DecodeList(KindVOR, packList(KindVOR, 9, 0, vor), 1)
+	if err != nil || len(got) != 1 || got[0] != vor {
+		t.Errorf("VOR: got %+v, %v", got, err)
+	}
+
+	if _, err := DecodeList(KindWaypoint, make([]byte, 20), 1); !errors.Is(err, ErrShortRecord) {
+		t.Errorf("short entry: err = %v, want ErrShortRecord", err)
+	}
+	if _, err := DecodeList(Kind(9), nil, 1); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("unknown kind: err = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	list := []Summary{
+		{ICAO: "EGLL", Latitude: 51.47, Longitude: -0.4543},
+		{ICAO: "LKPR", Latitude: 50.1008, Longitude: 14.26},
+		{ICAO: "LKVO", Latitude: 50.2166, Longitude: 14.3955},
+	}
+	got := Nearest(list, 50.2, 14.4, 2)
+	if len(got) != 2 || got[0].ICAO != "LKVO" || got[1].ICAO != "LKPR" {
+		t.Fatalf("Nearest = %+v", got)
+	}
+	if all := Nearest(list, 51.5, 0, -1); len(all) != 3 || all[0].ICAO != "EGLL" {
+		t.Fatalf("Nearest(-1) = %+v", all)
+	}
+	if list[0].ICAO != "EGLL" || list[1].ICAO != "LKPR" {
+		t.Fatal("Nearest reordered its input")
+	}
+}
@@ -0,0 +1,377 @@
+package facility
+
+import "fmt"
+
+// DataType identifies the kind of record carried by a facility data packet.
+// The values match SIMCONNECT_FACILITY_DATA_TYPE, so the Type field of a
+// SIMCONNECT_RECV_FACILITY_DATA message converts directly.
+type DataType uint32
+
+const (
+	DataAirport DataType = iota
+	DataRunway
+	DataStart
+	DataFrequency
+	DataHelipad
+	DataApproach
+	DataApproachTransition
+	DataApproachLeg
+	DataFinalApproachLeg
+	DataMissedApproachLeg
+	DataDeparture
+	DataArrival
+	DataRunwayTransition
+	DataEnrouteTransition
+	DataTaxiPoint
+	DataTaxiParking
+	DataTaxiPath
+	DataTaxiName
+	DataJetway
+	DataVOR
+	DataNDB
+	DataWaypoint
+	DataRoute
+	DataPavement
+	DataApproachLights
+	DataVASI
+)
+
+// Include selects the child records requested along with an airport.
+type Include uint32
+
+const (
+	IncludeRunways Include = 1 << iota
+	IncludeParking
+	IncludeTaxiways // taxi points, paths and names
+	IncludeFrequencies
+	IncludeHelipads
+	IncludeJetways
+	IncludeDepartures
+	IncludeArrivals
+	IncludeApproaches
+
+	IncludeProcedures = IncludeDepartures | IncludeArrivals | IncludeApproaches
+	IncludeAll        = IncludeRunways | IncludeParking | IncludeTaxiways | IncludeFrequencies |
+		IncludeHelipads | IncludeJetways | IncludeProcedures
+)
+
+// AirportOptions configures an airport request.
+type AirportOptions struct {
+	// Region is the two-letter ICAO region. Empty matches any region.
+	Region string
+	// Include selects the child records to request. Zero means IncludeAll.
+	Include Include
+}
+
+// Normalize returns the include mask to request, resolving zero to IncludeAll.
+func (o AirportOptions) Normalize() Include {
+	if o.Include == 0 {
+		return IncludeAll
+	}
+	return o.Include & IncludeAll
+}
+
+// kind is the wire type of a facility field.
+type kind uint8
+
+const (
+	kindInt32 kind = iota
+	kindUint32
+	kindFloat32
+	kindFloat64
+	kindString8
+	kindString32
+	kindString64
+)
+
+// size returns the number of bytes the kind occupies in a packed record.
+func (k kind) size() int {
+	switch k {
+	case kindFloat64, kindString8:
+		return 8
+	case kindString32:
+		return 32
+	case kindString64:
+		return 64
+	default:
+		return 4
+	}
+}
+
+type field struct {
+	name string
+	kind kind
+}
+
+// schema describes the fields requested for one record type. SimConnect
+// packs the fields of a record back to back in definition order, without
+// alignment padding.
+type schema struct {
+	block    string // name used in the OPEN/CLOSE markers
+	fields   []field
+	children []DataType
+	slots    map[string]slot
+	size     int
+}
+
+// slot locates a field within a packed record.
+type slot struct {
+	offset int
+	kind   kind
+}
+
+// legFields are shared by approach, final approach and missed approach legs.
+var legFields = []field{
+	{"TYPE", kindInt32},
+	{"FIX_ICAO", kindString8},
+	{"FIX_REGION", kindString8},
+	{"FIX_TYPE", kindInt32},
+	{"FIX_LATITUDE", kindFloat64},
+	{"FIX_LONGITUDE", kindFloat64},
+	{"FIX_ALTITUDE", kindFloat64},
+	{"FLY_OVER", kindInt32},
+	{"TURN_DIRECTION", kindInt32},
+	{"COURSE", kindFloat32},
+	{"ROUTE_DISTANCE", kindFloat32},
+	{"APPROACH_ALT_DESC", kindInt32},
+	{"ALTITUDE1", kindFloat32},
+	{"ALTITUDE2", kindFloat32},
+	{"SPEED_LIMIT", kindFloat32},
+	{"VERTICAL_ANGLE", kindFloat32},
+	{"IS_IAF", kindInt32},
+	{"IS_IF", kindInt32},
+	{"IS_FAF", kindInt32},
+	{"IS_MAP", kindInt32},
+}
+
+// runwayEndFields returns the fields of the primary or secondary runway end.
+func runwayEndFields(prefix string) []field {
+	return []field{
+		{prefix + "_ILS_ICAO", kindString8},
+		{prefix + "_ILS_REGION", kindString8},
+		{prefix + "_ILS_TYPE", kindInt32},
+		{prefix + "_NUMBER", kindInt32},
+		{prefix + "_DESIGNATOR", kindInt32},
+	}
+}
+
+var schemas = map[DataType]*schema{
+	DataAirport: {
+		block: "AIRPORT",
+		fields: []field{
+			{"LATITUDE", kindFloat64},
+			{"LONGITUDE", kindFloat64},
+			{"ALTITUDE", kindFloat64},
+			{"MAGVAR", kindFloat32},
+			{"NAME", kindString32},
+			{"NAME64", kindString64},
+			{"ICAO", kindString8},
+			{"REGION", kindString8},
+			{"TOWER_LATITUDE", kindFloat64},
+			{"TOWER_LONGITUDE", kindFloat64},
+			{"TOWER_ALTITUDE", kindFloat64},
+			{"TRANSITION_ALTITUDE", kindFloat32},
+			{"TRANSITION_LEVEL", kindFloat32},
+		},
+	},
+	DataRunway: {
+		block: "RUNWAY",
+		fields: append([]field{
+			{"LATITUDE", kindFloat64},
+			{"LONGITUDE", kindFloat64},
+			{"ALTITUDE", kindFloat64},
+			{"HEADING", kindFloat32},
+			{"LENGTH", kindFloat32},
+			{"WIDTH", kindFloat32},
+			{"PATTERN_ALTITUDE", kindFloat32},
+			{"SLOPE", kindFloat32},
+			{"TRUE_SLOPE", kindFloat32},
+			{"SURFACE", kindInt32},
+		}, append(runwayEndFields("PRIMARY"), runwayEndFields("SECONDARY")...)...),
+	},
+	DataFrequency: {
+		block: "FREQUENCY",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"FREQUENCY", kindInt32},
+			{"NAME", kindString64},
+		},
+	},
+	DataHelipad: {
+		block: "HELIPAD",
+		fields: []field{
+			{"LATITUDE", kindFloat64},
+			{"LONGITUDE", kindFloat64},
+			{"ALTITUDE", kindFloat64},
+			{"HEADING", kindFloat32},
+			{"LENGTH", kindFloat32},
+			{"WIDTH", kindFloat32},
+			{"SURFACE", kindInt32},
+			{"TYPE", kindInt32},
+		},
+	},
+	DataApproach: {
+		block: "APPROACH",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"SUFFIX", kindInt32},
+			{"RUNWAY_NUMBER", kindInt32},
+			{"RUNWAY_DESIGNATOR", kindInt32},
+			{"FAF_ICAO", kindString8},
+			{"FAF_REGION", kindString8},
+			{"FAF_HEADING", kindFloat32},
+			{"FAF_ALTITUDE", kindFloat32},
+			{"MISSED_ALTITUDE", kindFloat32},
+		},
+		children: []DataType{DataApproachTransition, DataFinalApproachLeg, DataMissedApproachLeg},
+	},
+	DataApproachTransition: {
+		block: "APPROACH_TRANSITION",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"IAF_ICAO", kindString8},
+			{"IAF_REGION", kindString8},
+			{"IAF_ALTITUDE", kindFloat32},
+			{"NAME", kindString8},
+		},
+		children: []DataType{DataApproachLeg},
+	},
+	DataApproachLeg:       {block: "APPROACH_LEG", fields: legFields},
+	DataFinalApproachLeg:  {block: "FINAL_APPROACH_LEG", fields: legFields},
+	DataMissedApproachLeg: {block: "MISSED_APPROACH_LEG", fields: legFields},
+	DataDeparture: {
+		block:    "DEPARTURE",
+		fields:   []field{{"NAME", kindString8}},
+		children: []DataType{DataApproachLeg, DataRunwayTransition, DataEnrouteTransition},
+	},
+	DataArrival: {
+		block:    "ARRIVAL",
+		fields:   []field{{"NAME", kindString8}},
+		children: []DataType{DataApproachLeg, DataRunwayTransition, DataEnrouteTransition},
+	},
+	DataRunwayTransition: {
+		block: "RUNWAY_TRANSITION",
+		fields: []field{
+			{"RUNWAY_NUMBER", kindInt32},
+			{"RUNWAY_DESIGNATOR", kindInt32},
+		},
+		children: []DataType{DataApproachLeg},
+	},
+	DataEnrouteTransition: {
+		block:    "ENROUTE_TRANSITION",
+		fields:   []field{{"NAME", kindString8}},
+		children: []DataType{DataApproachLeg},
+	},
+	DataTaxiPoint: {
+		block: "TAXI_POINT",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"ORIENTATION", kindInt32},
+			{"BIAS_X", kindFloat32},
+			{"BIAS_Z", kindFloat32},
+		},
+	},
+	DataTaxiParking: {
+		block: "TAXI_PARKING",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"TAXI_POINT_TYPE", kindInt32},
+			{"NAME", kindInt32},
+			{"SUFFIX", kindInt32},
+			{"NUMBER", kindUint32},
+			{"ORIENTATION", kindInt32},
+			{"HEADING", kindFloat32},
+			{"RADIUS", kindFloat32},
+			{"BIAS_X", kindFloat32},
+			{"BIAS_Z", kindFloat32},
+		},
+	},
+	DataTaxiPath: {
+		block: "TAXI_PATH",
+		fields: []field{
+			{"TYPE", kindInt32},
+			{"WIDTH", kindFloat32},
+			{"RUNWAY_NUMBER", kindInt32},
+			{"RUNWAY_DESIGNATOR", kindInt32},
+			{"START", kindInt32},
+			{"END", kindInt32},
+			{"NAME_INDEX", kindUint32},
+		},
+	},
+	DataTaxiName: {
+		block:  "TAXI_NAME",
+		fields: []field{{"NAME", kindString32}},
+	},
+	DataJetway: {
+		block: "JETWAY",
+		fields: []field{
+			{"PARKING_GATE", kindInt32},
+			{"PARKING_SUFFIX", kindInt32},
+			{"PARKING_SPOT", kindInt32},
+		},
+	},
+}
+
+func init() {
+	for _, s := range schemas {
+		s.slots = make(map[string]slot, len(s.fields))
+		for _, f := range s.fields {
+			s.slots[f.name] = slot{offset: s.size, kind: f.kind}
+			s.size += f.kind.size()
+		}
+	}
+}
+
+// airportChildren maps include flags to the airport child records they request.
+var airportChildren = []struct {
+	include Include
+	types   []DataType
+}{
+	{IncludeRunways, []DataType{DataRunway}},
+	{IncludeParking, []DataType{DataTaxiParking}},
+	{IncludeTaxiways, []DataType{DataTaxiPoint, DataTaxiPath, DataTaxiName}},
+	{IncludeFrequencies, []DataType{DataFrequency}},
+	{IncludeHelipads, []DataType{DataHelipad}},
+	{IncludeJetways, []DataType{DataJetway}},
+	{IncludeDepartures, []DataType{DataDeparture}},
+	{IncludeArrivals, []DataType{DataArrival}},
+	{IncludeApproaches, []DataType{DataApproach}},
+}
+
+// AirportDefinition returns the facility definition fields, in registration
+// order, for an airport with the child records selected by include. The
+// result is meant for AddToFacilityDefinition or a datasets.FacilityDataSet;
+// an Assembler decodes the packets the simulator returns for it.
+func AirportDefinition(include Include) []string {
+	var children []DataType
+	for _, c := range airportChildren {
+		if include&c.include != 0 {
+			children = append(children, c.types...)
+		}
+	}
+	var out []string
+	appendBlock(&out, DataAirport, children)
+	return out
+}
+
+// appendBlock appends the OPEN marker, fields, child blocks and CLOSE marker
+// of record type t.
+func appendBlock(out *[]string, t DataType, children []DataType) {
+	s := schemas[t]
+	*out = append(*out, "OPEN "+s.block)
+	for _, f := range s.fields {
+		*out = append(*out, f.name)
+	}
+	for _, c := range children {
+		appendBlock(out, c, schemas[c].children)
+	}
+	*out = append(*out, "CLOSE "+s.block)
+}
+
+// String returns the SimConnect block name of the data type.
+func (t DataType) String() string {
+	if s, ok := schemas[t]; ok {
+		return s.block
+	}
+	return fmt.Sprintf("DataType(%d)", uint32(t))
+}
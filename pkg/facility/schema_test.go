@@ -0,0 +1,67 @@
+package facility
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAirportDefinitionNesting(t *testing.T) {
+	def := AirportDefinition(IncludeRunways | IncludeDepartures)
+
+	if def[0] != "OPEN AIRPORT" || def[len(def)-1] != "CLOSE AIRPORT" {
+		t.Fatalf("definition not wrapped in AIRPORT block: %q ... %q", def[0], def[len(def)-1])
+	}
+	for _, block := range []string{"RUNWAY", "DEPARTURE", "RUNWAY_TRANSITION", "ENROUTE_TRANSITION", "APPROACH_LEG"} {
+		if !slices.Contains(def, "OPEN "+block) {
+			t.Errorf("definition misses block %s", block)
+		}
+	}
+	for _, block := range []string{"TAXI_PARKING", "APPROACH", "ARRIVAL"} {
+		if slices.Contains(def, "OPEN "+block) {
+			t.Errorf("definition includes unrequested block %s", block)
+		}
+	}
+
+	// Every OPEN must be closed in LIFO order.
+	var stack []string
+	for _, f := range def {
+		switch {
+		case len(f) > 5 && f[:5] == "OPEN ":
+			stack = append(stack, f[5:])
+		case len(f) > 6 && f[:6] == "CLOSE ":
+			if len(stack) == 0 || stack[len(stack)-1] != f[6:] {
+				t.Fatalf("unbalanced %q, open blocks %v", f, stack)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) != 0 {
+		t.Fatalf("unclosed blocks %v", stack)
+	}
+}
+
+func TestAirportOptionsNormalize(t *testing.T) {
+	if got := (AirportOptions{}).Normalize(); got != IncludeAll {
+		t.Errorf("zero Include normalized to %b, want IncludeAll", got)
+	}
+	if got := (AirportOptions{Include: IncludeRunways | 1<<30}).Normalize(); got != IncludeRunways {
+		t.Errorf("unknown bits not masked: %b", got)
+	}
+}
+
+func TestSchemaSizes(t *testing.T) {
+	tests := []struct {
+		typ  DataType
+		want int
+	}{
+		{DataAirport, 3*8 + 4 + 32 + 64 + 8 + 8 + 3*8 + 2*4},
+		{DataTaxiPoint, 16},
+		{DataTaxiName, 32},
+		{DataRunway, 3*8 + 7*4 + 2*(8+8+3*4)},
+	}
+	for _, tt := range tests {
+		if got := schemas[tt.typ].size; got != tt.want {
+			t.Errorf("%s size = %d, want %d", tt.typ, got, tt.want)
+		}
+	}
+}
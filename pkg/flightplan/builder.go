@@ -0,0 +1,207 @@
+package flightplan
+
+// HighAltitude is the cruise altitude, in feet, from which Builder picks
+// RouteHighAlt over RouteLowAlt for IFR plans.
+const HighAltitude = 18000
+
+// leg is a run of en-route waypoints added by one Builder call.
+type leg struct {
+	airway    string
+	waypoints []Waypoint
+}
+
+// Builder provides a fluent API for assembling a Plan: departure, SID,
+// en-route waypoints and airways, STAR, approach and destination, in the
+// order they are flown. All mutating methods return the receiver to allow
+// method chaining. Build() is non-destructive and may be called multiple
+// times; each call returns an independent Plan.
+//
+// Builder is not safe for concurrent use by multiple goroutines.
+type Builder struct {
+	plan Plan
+
+	departureRunway string
+	arrivalRunway   string
+	sid, star       string
+	sidWps, starWps []Waypoint
+	route           []leg
+	approach        string
+	approachSuffix  string
+}
+
+// NewBuilder returns a Builder for an IFR plan.
+func NewBuilder() *Builder {
+	return &Builder{plan: Plan{Type: IFR}}
+}
+
+// Title sets the plan title. Build defaults it to "DEP to DEST".
+// Returns the builder for chaining.
+func (b *Builder) Title(title string) *Builder {
+	b.plan.Title = title
+	return b
+}
+
+// Description sets the plan description. Build defaults it to
+// "DEP, DEST". Returns the builder for chaining.
+func (b *Builder) Description(descr string) *Builder {
+	b.plan.Description = descr
+	return b
+}
+
+// Type sets the flight rules. Returns the builder for chaining.
+func (b *Builder) Type(t Type) *Builder {
+	b.plan.Type = t
+	return b
+}
+
+// RouteType sets the route type. Build defaults it to RouteHighAlt or
+// RouteLowAlt by the cruise altitude for IFR plans and to RouteDirect for
+// VFR plans. Returns the builder for chaining.
+func (b *Builder) RouteType(rt RouteType) *Builder {
+	b.plan.RouteType = rt
+	return b
+}
+
+// Cruise sets the cruise altitude in feet. Returns the builder for
+// chaining.
+func (b *Builder) Cruise(feet float64) *Builder {
+	b.plan.CruisingAltitude = feet
+	return b
+}
+
+// AppVersion sets the version of the writing application. Returns the
+// builder for chaining.
+func (b *Builder) AppVersion(v AppVersion) *Builder {
+	b.plan.AppVersion = v
+	return b
+}
+
+// Departure sets the departure airport; pos.Altitude is its elevation.
+// Returns the builder for chaining.
+func (b *Builder) Departure(icao, name string, pos Position) *Builder {
+	b.plan.Departure = Endpoint{ICAO: icao, Name: name, Position: pos}
+	return b
+}
+
+// DepartureRunway sets the runway to depart from, e.g. "24" or "06L", and
+// starts the flight on it. Returns the builder for chaining.
+func (b *Builder) DepartureRunway(rwy string) *Builder {
+	b.departureRunway = rwy
+	return b
+}
+
+// SID sets the departure procedure and its waypoints. Returns the builder
+// for chaining.
+func (b *Builder) SID(name string, wps ...Waypoint) *Builder {
+	b.sid = name
+	b.sidWps = append([]Waypoint(nil), wps...)
+	return b
+}
+
+// Direct appends waypoints flown direct. Returns the builder for chaining.
+func (b *Builder) Direct(wps ...Waypoint) *Builder {
+	return b.Airway("", wps...)
+}
+
+// Airway appends waypoints flown along airway name, e.g. "UL984", up to
+// the last of them. Returns the builder for chaining.
+func (b *Builder) Airway(name string, wps ...Waypoint) *Builder {
+	b.route = append(b.route, leg{airway: name, waypoints: append([]Waypoint(nil), wps...)})
+	return b
+}
+
+// STAR sets the arrival procedure and its waypoints. Returns the builder
+// for chaining.
+func (b *Builder) STAR(name string, wps ...Waypoint) *Builder {
+	b.star = name
+	b.starWps = append([]Waypoint(nil), wps...)
+	return b
+}
+
+// Approach sets the approach type, e.g. "ILS" or "RNAV", and its suffix,
+// which may be empty. Returns the builder for chaining.
+func (b *Builder) Approach(typ, suffix string) *Builder {
+	b.approach, b.approachSuffix = typ, suffix
+	return b
+}
+
+// ArrivalRunway sets the runway to land on. Returns the builder for
+// chaining.
+func (b *Builder) ArrivalRunway(rwy string) *Builder {
+	b.arrivalRunway = rwy
+	return b
+}
+
+// Destination sets the destination airport; pos.Altitude is its
+// elevation. Returns the builder for chaining.
+func (b *Builder) Destination(icao, name string, pos Position) *Builder {
+	b.plan.Destination = Endpoint{ICAO: icao, Name: name, Position: pos}
+	return b
+}
+
+// Build returns the plan. The departure and destination airports become
+// the first and last waypoints, carrying the runways and approach; SID and
+// STAR waypoints are tagged with their procedure and runway. Build does not
+// validate the plan; call Plan.Validate for that.
+func (b *Builder) Build() Plan {
+	p := b.plan
+	dep, dest := p.Departure, p.Destination
+	if p.Title == "" {
+		p.Title = dep.ICAO + " to " + dest.ICAO
+	}
+	if p.Description == "" {
+		p.Description = dep.ICAO + ", " + dest.ICAO
+	}
+	if p.RouteType == "" {
+		switch {
+		case p.Type == VFR:
+			p.RouteType = RouteDirect
+		case p.CruisingAltitude >= HighAltitude:
+			p.RouteType = RouteHighAlt
+		default:
+			p.RouteType = RouteLowAlt
+		}
+	}
+	if p.DeparturePosition == "" {
+		p.DeparturePosition = b.departureRunway
+	}
+
+	p.Waypoints = []Waypoint{{
+		ID:       dep.ICAO,
+		Type:     WaypointAirport,
+		Position: dep.Position,
+		Ident:    dep.ICAO,
+		Runway:   b.departureRunway,
+	}}
+	for _, w := range b.sidWps {
+		w.Departure, w.Runway = b.sid, b.departureRunway
+		p.Waypoints = append(p.Waypoints, w)
+	}
+	for _, l := range b.route {
+		for _, w := range l.waypoints {
+			if w.Airway == "" {
+				w.Airway = l.airway
+			}
+			p.Waypoints = append(p.Waypoints, w)
+		}
+	}
+	for _, w := range b.starWps {
+		w.Arrival, w.Runway = b.star, b.arrivalRunway
+		p.Waypoints = append(p.Waypoints, w)
+	}
+	p.Waypoints = append(p.Waypoints, Waypoint{
+		ID:             dest.ICAO,
+		Type:           WaypointAirport,
+		Position:       dest.Position,
+		Ident:          dest.ICAO,
+		Approach:       b.approach,
+		ApproachSuffix: b.approachSuffix,
+		Runway:         b.arrivalRunway,
+	})
+	for i := range p.Waypoints {
+		if p.Waypoints[i].ID == "" {
+			p.Waypoints[i].ID = p.Waypoints[i].Ident
+		}
+	}
+	return p
+}
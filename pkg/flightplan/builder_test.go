@@ -0,0 +1,89 @@
+package flightplan
+
+import (
+	"reflect"
+	"testing"
+)
+
+var (
+	lkpr = Position{Latitude: 50.100833, Longitude: 14.26, Altitude: 1234}
+	lfpg = Position{Latitude: 49.009722, Longitude: 2.547778, Altitude: 392}
+)
+
+func fix(ident, region string, lat, lon, alt float64) Waypoint {
+	return Waypoint{
+		Type:     WaypointIntersection,
+		Ident:    ident,
+		Region:   region,
+		Position: Position{Latitude: lat, Longitude: lon, Altitude: alt},
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder().
+		Cruise(36000).
+		Departure("LKPR", "Ruzyne", lkpr).
+		DepartureRunway("24").
+		SID("BALT5A", fix("BALTU", "LK", 50.089461, 13.326522, 19900)).
+		Airway("Z93", fix("UNAVI", "ED", 50.188056, 11.822222, 31100)).
+		Direct(fix("RAPOR", "LF", 49.591389, 5.213056, 37000)).
+		STAR("VEDU9H", fix("VEDUS", "LF", 49.594722, 4.781389, 37000)).
+		Approach("ILS", "").
+		ArrivalRunway("27R").
+		Destination("LFPG", "Charles-de-gaulle", lfpg)
+	p := b.Build()
+
+	if p.Title != "LKPR to LFPG" || p.Description != "LKPR, LFPG" || p.Type != IFR || p.RouteType != RouteHighAlt {
+		t.Errorf("header = %q %q %s %s", p.Title, p.Description, p.Type, p.RouteType)
+	}
+	if p.DeparturePosition != "24" {
+		t.Errorf("DeparturePosition = %q, want 24", p.DeparturePosition)
+	}
+	var idents []string
+	for _, w := range p.Waypoints {
+		idents = append(idents, w.ID)
+	}
+	if want := []string{"LKPR", "BALTU", "UNAVI", "RAPOR", "VEDUS", "LFPG"}; !reflect.DeepEqual(idents, want) {
+		t.Fatalf("waypoints = %v, want %v", idents, want)
+	}
+	if w := p.Waypoints[2]; w.Airway != "Z93" {
+		t.Errorf("UNAVI airway = %q, want Z93", w.Airway)
+	}
+	if w := p.Waypoints[3]; w.Airway != "" {
+		t.Errorf("RAPOR airway = %q, want direct", w.Airway)
+	}
+	want := Procedures{DepartureRunway: "24", SID: "BALT5A", STAR: "VEDU9H", Approach: "ILS", ArrivalRunway: "27R"}
+	if got := p.Procedures(); got != want {
+		t.Errorf("Procedures = %+v, want %+v", got, want)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	// Build is non-destructive.
+	p.Waypoints[1].Ident = "XXXXX"
+	if again := b.Build(); again.Waypoints[1].Ident != "BALTU" {
+		t.Error("Build shares waypoints between plans")
+	}
+}
+
+func TestBuilderRouteType(t *testing.T) {
+	tests := []struct {
+		typ    Type
+		cruise float64
+		want   RouteType
+	}{
+		{IFR, 36000, RouteHighAlt},
+		{IFR, 9000, RouteLowAlt},
+		{VFR, 4500, RouteDirect},
+	}
+	for _, tt := range tests {
+		p := NewBuilder().Type(tt.typ).Cruise(tt.cruise).Build()
+		if p.RouteType != tt.want {
+			t.Errorf("%s at %v ft: RouteType = %s, want %s", tt.typ, tt.cruise, p.RouteType, tt.want)
+		}
+	}
+	if p := NewBuilder().RouteType(RouteVOR).Cruise(36000).Build(); p.RouteType != RouteVOR {
+		t.Errorf("RouteType = %s, want VOR as set", p.RouteType)
+	}
+}
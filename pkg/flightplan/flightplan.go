@@ -0,0 +1,197 @@
+// Package flightplan reads, writes and builds MSFS flight plans: the .PLN
+// files (AceXML SimBase.Document) that AISetAircraftFlightPlan,
+// AICreateEnrouteATCAircraft and FlightPlanLoad take by path.
+//
+// A Plan holds the departure and destination, the cruise altitude and the
+// ATC waypoints, with the departure, arrival and approach procedures they
+// belong to. Parse and Load read a plan, Write and Save write one, and
+// Builder assembles one in code. Validate checks positions, identifiers,
+// runways and altitudes, and Distance measures the route.
+//
+// The package has no simulator dependency.
+package flightplan
+
+import (
+	"errors"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+var (
+	// ErrNotFlightPlan is returned by Parse for an XML document without a
+	// FlightPlan.FlightPlan element.
+	ErrNotFlightPlan = errors.New("flightplan: not a flight plan document")
+
+	// ErrInvalidPosition is returned for a malformed position string or a
+	// coordinate out of range.
+	ErrInvalidPosition = errors.New("flightplan: invalid position")
+
+	// ErrInvalidIdent is returned for a malformed airport, waypoint or
+	// region identifier.
+	ErrInvalidIdent = errors.New("flightplan: invalid identifier")
+
+	// ErrInvalidRunway is returned for a runway that is not a number from
+	// 01 to 36 with an optional L, R, C, W, A or B designator.
+	ErrInvalidRunway = errors.New("flightplan: invalid runway")
+
+	// ErrInvalidAltitude is returned for an altitude that does not parse or
+	// is out of range.
+	ErrInvalidAltitude = errors.New("flightplan: invalid altitude")
+)
+
+// Type is the flight rules of a plan.
+type Type string
+
+const (
+	IFR Type = "IFR"
+	VFR Type = "VFR"
+)
+
+// RouteType is how the route of a plan was built.
+type RouteType string
+
+const (
+	RouteDirect  RouteType = "Direct"
+	RouteVOR     RouteType = "VOR"
+	RouteLowAlt  RouteType = "LowAlt"  // low-altitude airways
+	RouteHighAlt RouteType = "HighAlt" // high-altitude airways
+)
+
+// WaypointType is the kind of an ATC waypoint.
+type WaypointType string
+
+const (
+	WaypointAirport      WaypointType = "Airport"
+	WaypointIntersection WaypointType = "Intersection"
+	WaypointVOR          WaypointType = "VOR"
+	WaypointNDB          WaypointType = "NDB"
+	WaypointUser         WaypointType = "User"
+	WaypointATC          WaypointType = "ATC"
+)
+
+// Position is a point of a flight plan.
+type Position struct {
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Altitude  float64 // feet MSL
+}
+
+// Endpoint is the departure or destination airport of a plan.
+type Endpoint struct {
+	ICAO     string
+	Name     string
+	Position Position
+}
+
+// AppVersion is the version of the application that wrote a plan.
+type AppVersion struct {
+	Major int
+	Build int
+}
+
+// Waypoint is an ATC waypoint of a plan.
+type Waypoint struct {
+	// ID is the waypoint's id attribute, usually its identifier.
+	ID       string
+	Type     WaypointType
+	Position Position
+
+	// Ident and Region identify the waypoint in the navigation data, e.g.
+	// "BALTU" in "LK". Airport is the airport of a terminal waypoint.
+	Ident   string
+	Region  string
+	Airport string
+
+	// Airway is the airway the leg to this waypoint follows.
+	Airway string
+	// Departure and Arrival name the SID or STAR the waypoint belongs to.
+	Departure string
+	Arrival   string
+	// Approach is the approach type, e.g. "ILS" or "RNAV", and
+	// ApproachSuffix its suffix, for waypoints of an approach.
+	Approach       string
+	ApproachSuffix string
+	// Runway is the runway of the procedure or of an airport waypoint,
+	// e.g. "27R".
+	Runway string
+}
+
+// Plan is a flight plan.
+type Plan struct {
+	Title            string
+	Type             Type
+	RouteType        RouteType
+	CruisingAltitude float64 // feet
+	Description      string
+
+	Departure   Endpoint
+	Destination Endpoint
+	// DeparturePosition is where the flight starts: a runway such as "24",
+	// or a parking spot. Empty leaves the choice to the simulator.
+	DeparturePosition string
+
+	AppVersion AppVersion
+	Waypoints  []Waypoint
+}
+
+// Procedures are the procedures a plan flies.
+type Procedures struct {
+	DepartureRunway string
+	SID             string
+	STAR            string
+	Approach        string // type and suffix, e.g. "ILS" or "RNAV Z"
+	ArrivalRunway   string
+}
+
+// Procedures collects the procedures from the plan's waypoints. The
+// departure runway is that of the first airport waypoint or of the SID, the
+// arrival runway that of the last airport waypoint, the approach or the
+// STAR.
+func (p *Plan) Procedures() Procedures {
+	var pr Procedures
+	for i, w := range p.Waypoints {
+		switch {
+		case w.Departure != "":
+			if pr.SID == "" {
+				pr.SID = w.Departure
+			}
+			if pr.DepartureRunway == "" {
+				pr.DepartureRunway = w.Runway
+			}
+		case w.Arrival != "":
+			if pr.STAR == "" {
+				pr.STAR = w.Arrival
+			}
+			pr.ArrivalRunway = w.Runway
+		case w.Approach != "":
+			pr.Approach = w.Approach
+			if w.ApproachSuffix != "" {
+				pr.Approach += " " + w.ApproachSuffix
+			}
+			pr.ArrivalRunway = w.Runway
+		case w.Type == WaypointAirport && w.Runway != "":
+			if i == 0 {
+				pr.DepartureRunway = w.Runway
+			} else if i == len(p.Waypoints)-1 {
+				pr.ArrivalRunway = w.Runway
+			}
+		}
+	}
+	return pr
+}
+
+// Distance returns the length of the route through the waypoints along
+// great circles, in nautical miles. A plan without at least two waypoints
+// measures from departure to destination.
+func (p *Plan) Distance() float64 {
+	if len(p.Waypoints) < 2 {
+		a, b := p.Departure.Position, p.Destination.Position
+		return calc.HaversineNM(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	}
+	var nm float64
+	for i := 1; i < len(p.Waypoints); i++ {
+		a, b := p.Waypoints[i-1].Position, p.Waypoints[i].Position
+		nm += calc.HaversineNM(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
+	}
+	return nm
+}
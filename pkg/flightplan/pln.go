@@ -0,0 +1,277 @@
+package flightplan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// plnDocument is the XML layout of a .PLN file.
+type plnDocument struct {
+	XMLName xml.Name `xml:"SimBase.Document"`
+	Type    string   `xml:"Type,attr"`
+	Version string   `xml:"version,attr"`
+	Descr   string   `xml:"Descr"`
+	Plan    *plnPlan `xml:"FlightPlan.FlightPlan"`
+}
+
+type plnPlan struct {
+	Title             string         `xml:"Title"`
+	FPType            string         `xml:"FPType"`
+	RouteType         string         `xml:"RouteType,omitempty"`
+	CruisingAlt       string         `xml:"CruisingAlt"`
+	DepartureID       string         `xml:"DepartureID"`
+	DepartureLLA      string         `xml:"DepartureLLA"`
+	DestinationID     string         `xml:"DestinationID"`
+	DestinationLLA    string         `xml:"DestinationLLA"`
+	Descr             string         `xml:"Descr"`
+	DeparturePosition string         `xml:"DeparturePosition,omitempty"`
+	DepartureName     string         `xml:"DepartureName"`
+	DestinationName   string         `xml:"DestinationName"`
+	AppVersion        *plnAppVersion `xml:"AppVersion"`
+	Waypoints         []plnWaypoint  `xml:"ATCWaypoint"`
+}
+
+type plnAppVersion struct {
+	Major int `xml:"AppVersionMajor"`
+	Build int `xml:"AppVersionBuild"`
+}
+
+type plnWaypoint struct {
+	ID                 string  `xml:"id,attr"`
+	Type               string  `xml:"ATCWaypointType"`
+	WorldPosition      string  `xml:"WorldPosition"`
+	ATCAirway          string  `xml:"ATCAirway,omitempty"`
+	DepartureFP        string  `xml:"DepartureFP,omitempty"`
+	ArrivalFP          string  `xml:"ArrivalFP,omitempty"`
+	ApproachTypeFP     string  `xml:"ApproachTypeFP,omitempty"`
+	SuffixFP           string  `xml:"SuffixFP,omitempty"`
+	RunwayNumberFP     string  `xml:"RunwayNumberFP,omitempty"`
+	RunwayDesignatorFP string  `xml:"RunwayDesignatorFP,omitempty"`
+	ICAO               plnICAO `xml:"ICAO"`
+}
+
+type plnICAO struct {
+	Region  string `xml:"ICAORegion,omitempty"`
+	Ident   string `xml:"ICAOIdent"`
+	Airport string `xml:"ICAOAirport,omitempty"`
+}
+
+// designators maps runway designator suffixes to their .PLN names.
+var designators = map[string]string{
+	"L": "LEFT",
+	"R": "RIGHT",
+	"C": "CENTER",
+	"W": "WATER",
+	"A": "A",
+	"B": "B",
+}
+
+// splitRunway splits a runway such as "27R" into its .PLN number and
+// designator.
+func splitRunway(rwy string) (number, designator string) {
+	i := 0
+	for i < len(rwy) && rwy[i] >= '0' && rwy[i] <= '9' {
+		i++
+	}
+	number, suffix := rwy[:i], rwy[i:]
+	if n, err := strconv.Atoi(number); err == nil {
+		number = strconv.Itoa(n)
+	}
+	if suffix != "" {
+		designator = designators[suffix]
+		if designator == "" {
+			designator = suffix
+		}
+	}
+	return number, designator
+}
+
+// joinRunway joins a .PLN runway number and designator, e.g. "27" and
+// "RIGHT" to "27R".
+func joinRunway(number, designator string) string {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return ""
+	}
+	if n, err := strconv.Atoi(number); err == nil {
+		number = fmt.Sprintf("%02d", n)
+	}
+	designator = strings.ToUpper(strings.TrimSpace(designator))
+	for suffix, name := range designators {
+		if designator == name {
+			return number + suffix
+		}
+	}
+	return number
+}
+
+// Parse reads a .PLN flight plan. A position or cruising altitude that does
+// not parse fails with an error wrapping ErrInvalidPosition or
+// ErrInvalidAltitude; a missing one is left zero.
+func Parse(r io.Reader) (*Plan, error) {
+	br := bufio.NewReader(r)
+	// Skip the byte order mark many planners write.
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		br.Discard(3)
+	}
+	var doc plnDocument
+	if err := xml.NewDecoder(br).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("flightplan: decoding: %w", err)
+	}
+	if doc.Plan == nil {
+		return nil, ErrNotFlightPlan
+	}
+	fp := doc.Plan
+	p := &Plan{
+		Title:             fp.Title,
+		Type:              Type(fp.FPType),
+		RouteType:         RouteType(fp.RouteType),
+		Description:       fp.Descr,
+		DeparturePosition: fp.DeparturePosition,
+		Departure:         Endpoint{ICAO: fp.DepartureID, Name: fp.DepartureName},
+		Destination:       Endpoint{ICAO: fp.DestinationID, Name: fp.DestinationName},
+	}
+	var err error
+	if p.CruisingAltitude, err = parseOptionalAltitude(fp.CruisingAlt); err != nil {
+		return nil, fmt.Errorf("cruising altitude: %w", err)
+	}
+	if p.Departure.Position, err = parseOptionalPosition(fp.DepartureLLA); err != nil {
+		return nil, fmt.Errorf("departure: %w", err)
+	}
+	if p.Destination.Position, err = parseOptionalPosition(fp.DestinationLLA); err != nil {
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+	if fp.AppVersion != nil {
+		p.AppVersion = AppVersion(*fp.AppVersion)
+	}
+	for i, w := range fp.Waypoints {
+		pos, err := parseOptionalPosition(w.WorldPosition)
+		if err != nil {
+			return nil, fmt.Errorf("waypoint %d (%s): %w", i, w.ICAO.Ident, err)
+		}
+		p.Waypoints = append(p.Waypoints, Waypoint{
+			ID:             w.ID,
+			Type:           WaypointType(w.Type),
+			Position:       pos,
+			Ident:          w.ICAO.Ident,
+			Region:         w.ICAO.Region,
+			Airport:        w.ICAO.Airport,
+			Airway:         w.ATCAirway,
+			Departure:      w.DepartureFP,
+			Arrival:        w.ArrivalFP,
+			Approach:       w.ApproachTypeFP,
+			ApproachSuffix: w.SuffixFP,
+			Runway:         joinRunway(w.RunwayNumberFP, w.RunwayDesignatorFP),
+		})
+	}
+	return p, nil
+}
+
+// parseOptionalPosition parses s, returning the zero Position for an empty
+// string.
+func parseOptionalPosition(s string) (Position, error) {
+	if strings.TrimSpace(s) == "" {
+		return Position{}, nil
+	}
+	return ParsePosition(s)
+}
+
+// parseOptionalAltitude parses s as feet, returning 0 for an empty string.
+func parseOptionalAltitude(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	ft, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAltitude, s)
+	}
+	return ft, nil
+}
+
+// Load reads the .PLN flight plan at path.
+func Load(path string) (*Plan, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Write writes p as a .PLN flight plan.
+func (p *Plan) Write(w io.Writer) error {
+	fp := &plnPlan{
+		Title:             p.Title,
+		FPType:            string(p.Type),
+		RouteType:         string(p.RouteType),
+		CruisingAlt:       strconv.FormatFloat(p.CruisingAltitude, 'f', 3, 64),
+		DepartureID:       p.Departure.ICAO,
+		DepartureLLA:      FormatPosition(p.Departure.Position),
+		DestinationID:     p.Destination.ICAO,
+		DestinationLLA:    FormatPosition(p.Destination.Position),
+		Descr:             p.Description,
+		DeparturePosition: p.DeparturePosition,
+		DepartureName:     p.Departure.Name,
+		DestinationName:   p.Destination.Name,
+	}
+	if p.AppVersion != (AppVersion{}) {
+		fp.AppVersion = (*plnAppVersion)(&p.AppVersion)
+	}
+	for _, wp := range p.Waypoints {
+		number, designator := splitRunway(wp.Runway)
+		id := wp.ID
+		if id == "" {
+			id = wp.Ident
+		}
+		fp.Waypoints = append(fp.Waypoints, plnWaypoint{
+			ID:                 id,
+			Type:               string(wp.Type),
+			WorldPosition:      FormatPosition(wp.Position),
+			ATCAirway:          wp.Airway,
+			DepartureFP:        wp.Departure,
+			ArrivalFP:          wp.Arrival,
+			ApproachTypeFP:     wp.Approach,
+			SuffixFP:           wp.ApproachSuffix,
+			RunwayNumberFP:     number,
+			RunwayDesignatorFP: designator,
+			ICAO:               plnICAO{Region: wp.Region, Ident: wp.Ident, Airport: wp.Airport},
+		})
+	}
+	doc := plnDocument{Type: "AceXML", Version: "1,0", Descr: "AceXML Document", Plan: fp}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Save writes p to path as a .PLN flight plan. SimConnect takes plan paths
+// without the .pln extension, so pass the path without it there.
+func (p *Plan) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,199 @@
+package flightplan
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// samplePLN is a SimBrief plan LKPR–LFPG trimmed to its procedures, with
+// the byte order mark and CRLF line ends it was written with.
+const samplePLN = "\xef\xbb\xbf" + `<?xml version="1.0" encoding="UTF-8"?>
+<SimBase.Document Type="AceXML" version="1,0">
+    <Descr>AceXML Document</Descr>
+    <FlightPlan.FlightPlan>
+        <Title>LKPR to LFPG</Title>
+        <FPType>IFR</FPType>
+        <RouteType>HighAlt</RouteType>
+        <CruisingAlt>36000.000</CruisingAlt>
+        <DepartureID>LKPR</DepartureID>
+        <DepartureLLA>N50° 6' 3.00",E14° 15' 36.00",+001234.00</DepartureLLA>
+        <DestinationID>LFPG</DestinationID>
+        <DestinationLLA>N49° 0' 35.00",E2° 32' 52.00",+000392.00</DestinationLLA>
+        <Descr>LKPR to LFPG created by SimBrief</Descr>
+        <DepartureName>Ruzyne</DepartureName>
+        <DestinationName>Charles-de-gaulle</DestinationName>
+        <AppVersion>
+            <AppVersionMajor>11</AppVersionMajor>
+            <AppVersionBuild>282174</AppVersionBuild>
+        </AppVersion>
+        <ATCWaypoint id="LKPR">
+            <ATCWaypointType>Airport</ATCWaypointType>
+            <WorldPosition>N50° 6' 3.00",E14° 15' 36.00",+001234.00</WorldPosition>
+            <RunwayNumberFP>24</RunwayNumberFP>
+            <ICAO>
+                <ICAOIdent>LKPR</ICAOIdent>
+            </ICAO>
+        </ATCWaypoint>
+        <ATCWaypoint id="BALTU">
+            <ATCWaypointType>Intersection</ATCWaypointType>
+            <WorldPosition>N50° 5' 22.06",E13° 19' 35.48",+019900.00</WorldPosition>
+            <DepartureFP>BALT5A</DepartureFP>
+            <RunwayNumberFP>24</RunwayNumberFP>
+            <ICAO>
+                <ICAORegion>LK</ICAORegion>
+                <ICAOIdent>BALTU</ICAOIdent>
+            </ICAO>
+        </ATCWaypoint>
+        <ATCWaypoint id="UNAVI">
+            <ATCWaypointType>Intersection</ATCWaypointType>
+            <WorldPosition>N50° 11' 17.00",E11° 49' 20.00",+031100.00</WorldPosition>
+            <ATCAirway>Z93</ATCAirway>
+            <ICAO>
+                <ICAORegion>ED</ICAORegion>
+                <ICAOIdent>UNAVI</ICAOIdent>
+            </ICAO>
+        </ATCWaypoint>
+        <ATCWaypoint id="VEDUS">
+            <ATCWaypointType>Intersection</ATCWaypointType>
+            <WorldPosition>N49° 35' 41.00",E4° 46' 53.00",+037000.00</WorldPosition>
+            <ArrivalFP>VEDU9H</ArrivalFP>
+            <RunwayNumberFP>27</RunwayNumberFP>
+            <RunwayDesignatorFP>RIGHT</RunwayDesignatorFP>
+            <ICAO>
+                <ICAORegion>LF</ICAORegion>
+                <ICAOIdent>VEDUS</ICAOIdent>
+            </ICAO>
+        </ATCWaypoint>
+        <ATCWaypoint id="LFPG">
+            <ATCWaypointType>Airport</ATCWaypointType>
+            <WorldPosition>N49° 0' 35.00",E2° 32' 52.00",+000392.00</WorldPosition>
+            <RunwayNumberFP>27</RunwayNumberFP>
+            <RunwayDesignatorFP>RIGHT</RunwayDesignatorFP>
+            <ICAO>
+                <ICAOIdent>LFPG</ICAOIdent>
+            </ICAO>
+        </ATCWaypoint>
+    </FlightPlan.FlightPlan>
+</SimBase.Document>`
+
+func parseSample(t *testing.T) *Plan {
+	t.Helper()
+	p, err := Parse(strings.NewReader(strings.ReplaceAll(samplePLN, "\n", "\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParse(t *testing.T) {
+	p := parseSample(t)
+	if p.Title != "LKPR to LFPG" || p.Type != IFR || p.RouteType != RouteHighAlt || p.CruisingAltitude != 36000 {
+		t.Errorf("header = %q %s %s %v", p.Title, p.Type, p.RouteType, p.CruisingAltitude)
+	}
+	if p.Departure.ICAO != "LKPR" || p.Departure.Name != "Ruzyne" || p.Departure.Position.Altitude != 1234 {
+		t.Errorf("departure = %+v", p.Departure)
+	}
+	if p.AppVersion != (AppVersion{Major: 11, Build: 282174}) {
+		t.Errorf("app version = %+v", p.AppVersion)
+	}
+	if len(p.Waypoints) != 5 {
+		t.Fatalf("%d waypoints, want 5", len(p.Waypoints))
+	}
+	w := p.Waypoints[2]
+	if w.Ident != "UNAVI" || w.Region != "ED" || w.Airway != "Z93" || w.Position.Altitude != 31100 {
+		t.Errorf("waypoint 2 = %+v", w)
+	}
+
+	want := Procedures{DepartureRunway: "24", SID: "BALT5A", STAR: "VEDU9H", ArrivalRunway: "27R"}
+	if got := p.Procedures(); got != want {
+		t.Errorf("Procedures = %+v, want %+v", got, want)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	p := parseSample(t)
+	var buf bytes.Buffer
+	if err := p.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"<CruisingAlt>36000.000</CruisingAlt>",
+		"<RunwayDesignatorFP>RIGHT</RunwayDesignatorFP>",
+		"<AppVersionBuild>282174</AppVersionBuild>",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("written plan lacks %s", s)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.pln")
+	if err := p.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("Load(Save(p)) = %+v, want %+v", got, p)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(strings.NewReader(`<SimBase.Document><Descr>x</Descr></SimBase.Document>`)); !errors.Is(err, ErrNotFlightPlan) {
+		t.Errorf("no plan: err = %v, want ErrNotFlightPlan", err)
+	}
+	if _, err := Parse(strings.NewReader(`<SimBase.Document>`)); err == nil {
+		t.Error("truncated document parsed")
+	}
+
+	bad := strings.Replace(samplePLN, `N50° 11' 17.00",E11° 49' 20.00"`, `N95° 11' 17.00",E11° 49' 20.00"`, 1)
+	_, err := Parse(strings.NewReader(bad))
+	if !errors.Is(err, ErrInvalidPosition) || !strings.Contains(err.Error(), "UNAVI") {
+		t.Errorf("bad waypoint position: err = %v, want ErrInvalidPosition naming UNAVI", err)
+	}
+	bad = strings.Replace(samplePLN, "<DepartureLLA>N50°", "<DepartureLLA>X50°", 1)
+	if _, err := Parse(strings.NewReader(bad)); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("bad departure position: err = %v, want ErrInvalidPosition", err)
+	}
+	bad = strings.Replace(samplePLN, "36000.000", "FL360", 1)
+	if _, err := Parse(strings.NewReader(bad)); !errors.Is(err, ErrInvalidAltitude) {
+		t.Errorf("bad cruising altitude: err = %v, want ErrInvalidAltitude", err)
+	}
+	empty := strings.Replace(samplePLN, "<CruisingAlt>36000.000</CruisingAlt>", "<CruisingAlt> </CruisingAlt>", 1)
+	if p, err := Parse(strings.NewReader(empty)); err != nil {
+		t.Errorf("empty cruising altitude: %v", err)
+	} else if p.CruisingAltitude != 0 {
+		t.Errorf("empty cruising altitude = %v, want 0", p.CruisingAltitude)
+	}
+}
+
+func TestRunwayConversion(t *testing.T) {
+	tests := []struct {
+		rwy, number, designator string
+	}{
+		{"27R", "27", "RIGHT"},
+		{"06L", "6", "LEFT"},
+		{"18", "18", ""},
+		{"36W", "36", "WATER"},
+	}
+	for _, tt := range tests {
+		number, designator := splitRunway(tt.rwy)
+		if number != tt.number || designator != tt.designator {
+			t.Errorf("splitRunway(%s) = %s, %s", tt.rwy, number, designator)
+		}
+		if got := joinRunway(number, designator); got != tt.rwy {
+			t.Errorf("joinRunway(%s, %s) = %s, want %s", number, designator, got, tt.rwy)
+		}
+	}
+	if got := joinRunway("9", "NONE"); got != "09" {
+		t.Errorf("joinRunway(9, NONE) = %s, want 09", got)
+	}
+}
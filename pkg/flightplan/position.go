@@ -0,0 +1,92 @@
+package flightplan
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParsePosition parses a position as .PLN files write it, e.g.
+// N50° 6' 3.00",E14° 15' 36.00",+001234.00. Minutes and seconds may be
+// left out, and so may the altitude, which is then zero.
+func ParsePosition(s string) (Position, error) {
+	parts := strings.Split(strings.TrimSpace(s), ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Position{}, fmt.Errorf("%w: %q", ErrInvalidPosition, s)
+	}
+	lat, err := parseDMS(parts[0], 'N', 'S', 90)
+	if err != nil {
+		return Position{}, fmt.Errorf("%w: %q", ErrInvalidPosition, s)
+	}
+	lon, err := parseDMS(parts[1], 'E', 'W', 180)
+	if err != nil {
+		return Position{}, fmt.Errorf("%w: %q", ErrInvalidPosition, s)
+	}
+	p := Position{Latitude: lat, Longitude: lon}
+	if len(parts) == 3 {
+		if p.Altitude, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64); err != nil {
+			return Position{}, fmt.Errorf("%w: %q", ErrInvalidPosition, s)
+		}
+	}
+	return p, nil
+}
+
+// parseDMS parses one coordinate: a hemisphere letter followed by degrees,
+// minutes and seconds separated by any non-numeric characters.
+func parseDMS(s string, pos, neg byte, limit float64) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidPosition
+	}
+	sign := 1.0
+	switch s[0] {
+	case pos:
+	case neg:
+		sign = -1
+	default:
+		return 0, ErrInvalidPosition
+	}
+	fields := strings.FieldsFunc(s[1:], func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if len(fields) == 0 || len(fields) > 3 {
+		return 0, ErrInvalidPosition
+	}
+	deg := 0.0
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || (i > 0 && v >= 60) {
+			return 0, ErrInvalidPosition
+		}
+		deg += v / math.Pow(60, float64(i))
+	}
+	if deg > limit {
+		return 0, ErrInvalidPosition
+	}
+	return sign * deg, nil
+}
+
+// FormatPosition writes p as .PLN files do, e.g.
+// N50° 6' 3.00",E14° 15' 36.00",+001234.00.
+func FormatPosition(p Position) string {
+	return fmt.Sprintf("%s,%s,%+010.2f",
+		formatDMS(p.Latitude, "N", "S"), formatDMS(p.Longitude, "E", "W"), p.Altitude)
+}
+
+func formatDMS(deg float64, pos, neg string) string {
+	hemi := pos
+	if deg < 0 {
+		hemi, deg = neg, -deg
+	}
+	d := math.Floor(deg)
+	m := math.Floor((deg - d) * 60)
+	s := ((deg-d)*60 - m) * 60
+	if math.Round(s*100) >= 6000 {
+		s, m = 0, m+1
+	}
+	if m >= 60 {
+		m, d = 0, d+1
+	}
+	return fmt.Sprintf("%s%.0f° %.0f' %.2f\"", hemi, d, m, s)
+}
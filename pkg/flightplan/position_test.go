@@ -0,0 +1,75 @@
+package flightplan
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParsePosition(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want Position
+	}{
+		{
+			name: "full",
+			in:   `N50° 6' 3.00",E14° 15' 36.00",+001234.00`,
+			want: Position{Latitude: 50.100833, Longitude: 14.26, Altitude: 1234},
+		},
+		{
+			name: "south west",
+			in:   `S33° 56' 49.00",W151° 10' 38.00",-000021.00`,
+			want: Position{Latitude: -33.946944, Longitude: -151.177222, Altitude: -21},
+		},
+		{
+			name: "degrees only without altitude",
+			in:   `N49.5°,E2.25°`,
+			want: Position{Latitude: 49.5, Longitude: 2.25},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePosition(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(got.Latitude-tt.want.Latitude) > 1e-6 ||
+				math.Abs(got.Longitude-tt.want.Longitude) > 1e-6 ||
+				got.Altitude != tt.want.Altitude {
+				t.Errorf("ParsePosition(%s) = %+v, want %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		`N50° 6' 3.00"`,
+		`X50° 6' 3.00",E14° 15' 36.00"`,
+		`N91° 0' 0.00",E14° 15' 36.00"`,
+		`N50° 60' 0.00",E14° 15' 36.00"`,
+		`N50° 6' 3.00",E14° 15' 36.00",high`,
+	} {
+		if _, err := ParsePosition(in); !errors.Is(err, ErrInvalidPosition) {
+			t.Errorf("ParsePosition(%q): err = %v, want ErrInvalidPosition", in, err)
+		}
+	}
+}
+
+func TestFormatPosition(t *testing.T) {
+	tests := []struct {
+		in   Position
+		want string
+	}{
+		{Position{Latitude: 50.100556, Longitude: -14.26, Altitude: 1247}, `N50° 6' 2.00",W14° 15' 36.00",+001247.00`},
+		// Seconds that round to 60 carry into the minutes.
+		{Position{Latitude: -0.9999999, Longitude: 179.9999999}, `S1° 0' 0.00",E180° 0' 0.00",+000000.00`},
+	}
+	for _, tt := range tests {
+		if got := FormatPosition(tt.in); got != tt.want {
+			t.Errorf("FormatPosition(%+v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package flightplan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/mrlm-net/simconnect/pkg/convert"
+)
+
+// Altitude limits, in feet, checked by Validate.
+const (
+	MaxAltitude = 60000
+	MinAltitude = -1500 // below sea level, e.g. airports by the Dead Sea
+)
+
+// Validate checks the plan: airport codes, waypoint identifiers and
+// regions, positions, runways, the cruise altitude and waypoint
+// altitudes. Returns nil if the plan is valid, otherwise the joined
+// errors, each wrapping ErrInvalidIdent, ErrInvalidPosition,
+// ErrInvalidRunway or ErrInvalidAltitude.
+func (p *Plan) Validate() error {
+	var errs []error
+	check := func(what string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", what, err))
+		}
+	}
+
+	for _, e := range []struct {
+		what string
+		ep   Endpoint
+	}{{"departure", p.Departure}, {"destination", p.Destination}} {
+		check(e.what, validateAirport(e.ep.ICAO))
+		check(e.what, validatePosition(e.ep.Position))
+	}
+	if c := p.CruisingAltitude; math.IsNaN(c) || c <= 0 || c > MaxAltitude {
+		errs = append(errs, fmt.Errorf("cruise %v ft: %w", c, ErrInvalidAltitude))
+	}
+
+	for i, w := range p.Waypoints {
+		what := fmt.Sprintf("waypoint %d (%s)", i, w.Ident)
+		if w.Type == WaypointAirport {
+			check(what, validateAirport(w.Ident))
+		} else {
+			check(what, validateIdent(w.Ident, w.Type))
+		}
+		if w.Region != "" && (len(w.Region) != 2 || !isIdent(w.Region)) {
+			errs = append(errs, fmt.Errorf("%s: region %q: %w", what, w.Region, ErrInvalidIdent))
+		}
+		check(what, validatePosition(w.Position))
+		if w.Runway != "" {
+			check(what, ValidateRunway(w.Runway))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateRunway checks a runway identifier: a number from 01 to 36 with
+// an optional L, R, C, W, A or B designator.
+func ValidateRunway(rwy string) error {
+	i := 0
+	for i < len(rwy) && rwy[i] >= '0' && rwy[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(rwy[:i])
+	if _, ok := designators[rwy[i:]]; err != nil || i > 2 || n < 1 || n > 36 || (rwy[i:] != "" && !ok) {
+		return fmt.Errorf("%w: %q", ErrInvalidRunway, rwy)
+	}
+	return nil
+}
+
+// validateAirport checks an ICAO airport code.
+func validateAirport(icao string) error {
+	if !convert.IsICAOCode(icao) || !isIdent(icao) {
+		return fmt.Errorf("%w: airport %q", ErrInvalidIdent, icao)
+	}
+	return nil
+}
+
+// validateIdent checks a waypoint identifier: up to five upper-case
+// letters and digits, or more for user waypoints.
+func validateIdent(ident string, typ WaypointType) error {
+	if !isIdent(ident) || len(ident) > 5 && typ != WaypointUser {
+		return fmt.Errorf("%w: %q", ErrInvalidIdent, ident)
+	}
+	return nil
+}
+
+// isIdent reports whether s is non-empty and only upper-case letters and
+// digits.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// validatePosition checks the ranges of a position.
+func validatePosition(p Position) error {
+	switch {
+	case math.IsNaN(p.Latitude) || p.Latitude < -90 || p.Latitude > 90,
+		math.IsNaN(p.Longitude) || p.Longitude < -180 || p.Longitude > 180:
+		return fmt.Errorf("%w: %v, %v", ErrInvalidPosition, p.Latitude, p.Longitude)
+	case math.IsNaN(p.Altitude) || p.Altitude < MinAltitude || p.Altitude > MaxAltitude:
+		return fmt.Errorf("%w: %v ft", ErrInvalidAltitude, p.Altitude)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package flightplan
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func validPlan() Plan {
+	return NewBuilder().
+		Cruise(36000).
+		Departure("LKPR", "Ruzyne", lkpr).
+		Direct(fix("BALTU", "LK", 50.089461, 13.326522, 19900)).
+		Destination("LFPG", "Charles-de-gaulle", lfpg).
+		Build()
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *Plan)
+		want   error
+	}{
+		{"lowercase airport", func(p *Plan) { p.Departure.ICAO = "lkpr" }, ErrInvalidIdent},
+		{"not an ICAO code", func(p *Plan) { p.Destination.ICAO = "XYZ1" }, ErrInvalidIdent},
+		{"long ident", func(p *Plan) { p.Waypoints[1].Ident = "BALTUX" }, ErrInvalidIdent},
+		{"region", func(p *Plan) { p.Waypoints[1].Region = "L" }, ErrInvalidIdent},
+		{"latitude", func(p *Plan) { p.Waypoints[1].Position.Latitude = 91 }, ErrInvalidPosition},
+		{"NaN longitude", func(p *Plan) { p.Destination.Position.Longitude = math.NaN() }, ErrInvalidPosition},
+		{"cruise", func(p *Plan) { p.CruisingAltitude = 0 }, ErrInvalidAltitude},
+		{"waypoint altitude", func(p *Plan) { p.Waypoints[1].Position.Altitude = 70000 }, ErrInvalidAltitude},
+		{"runway", func(p *Plan) { p.Waypoints[0].Runway = "37" }, ErrInvalidRunway},
+	}
+	if err := (&Plan{}).Validate(); err == nil {
+		t.Error("empty plan valid")
+	}
+	p := validPlan()
+	if err := p.Validate(); err != nil {
+		t.Fatalf("valid plan: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validPlan()
+			tt.modify(&p)
+			if err := p.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// User waypoints may have longer names.
+	p.Waypoints[1].Type, p.Waypoints[1].Ident, p.Waypoints[1].Region = WaypointUser, "TOPOFCLIMB", ""
+	if err := p.Validate(); err != nil {
+		t.Errorf("user waypoint: %v", err)
+	}
+}
+
+func TestValidateRunway(t *testing.T) {
+	for _, rwy := range []string{"01", "9", "27R", "06L", "18C", "36W", "04A", "04B"} {
+		if err := ValidateRunway(rwy); err != nil {
+			t.Errorf("ValidateRunway(%s): %v", rwy, err)
+		}
+	}
+	for _, rwy := range []string{"", "00", "37", "27X", "L", "027", "27RR"} {
+		if err := ValidateRunway(rwy); !errors.Is(err, ErrInvalidRunway) {
+			t.Errorf("ValidateRunway(%q): err = %v, want ErrInvalidRunway", rwy, err)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// Direct LKPR–LFPG is about 460 NM; via BALTU adds a little.
+	direct := Plan{Departure: Endpoint{Position: lkpr}, Destination: Endpoint{Position: lfpg}}
+	if d := direct.Distance(); math.Abs(d-460) > 2 {
+		t.Errorf("direct distance = %.1f NM, want about 460", d)
+	}
+	p := validPlan()
+	if d := p.Distance(); d < direct.Distance() || d > direct.Distance()+5 {
+		t.Errorf("route distance = %.1f NM, want a little over %.1f", d, direct.Distance())
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
 
@@ -20,6 +22,7 @@ const (
 	DEFAULT_SHUTDOWN_TIMEOUT   = 10 * time.Second // Timeout for graceful shutdown
 	DEFAULT_MAX_RETRIES        = 0                // 0 = unlimited retries
 	DEFAULT_AUTO_RECONNECT     = true
+	DEFAULT_BATCH_DISPATCH     = true
 )
 
 // Config holds the configuration for the Manager
@@ -43,12 +46,22 @@ type Config struct {
 
 	// Behavior settings
 	AutoReconnect bool // Whether to automatically reconnect on disconnect
+	BatchDispatch bool // Whether to consume engine.StreamBatches instead of Stream
 
 	// SimStatePeriod controls how often the manager requests SimState data from SimConnect.
 	// Default is SIMCONNECT_PERIOD_SIM_FRAME (every simulation frame).
 	// Use SIMCONNECT_PERIOD_SECOND for lower-frequency updates (1Hz) to reduce CPU usage.
 	SimStatePeriod types.SIMCONNECT_PERIOD
 
+	// FacilityCache, when set, serves Facilities() lookups from disk and
+	// stores what the simulator returns.
+	FacilityCache *facility.Cache
+
+	// FleetPendingTimeout is how long a Fleet() creation may wait for its
+	// ObjectID before it fails with traffic.ErrSpawnTimeout. Zero disables
+	// the timeout.
+	FleetPendingTimeout time.Duration
+
 	// Engine options to pass through
 	EngineOptions []engine.Option
 }
@@ -127,6 +140,18 @@ func WithAutoReconnect(enabled bool) Option {
 	}
 }
 
+// WithBatchDispatch selects how the manager consumes the engine stream.
+// When enabled (default), messages are read with engine.StreamBatches and
+// handlers and subscriptions are snapshotted once per batch. When disabled,
+// the manager reads engine.Stream and processes one message at a time.
+// Batch size and latency are tuned via engine.WithBatchSize and
+// engine.WithBatchLatency passed through WithEngineOptions.
+func WithBatchDispatch(enabled bool) Option {
+	return func(c *Config) {
+		c.BatchDispatch = enabled
+	}
+}
+
 // WithSimStatePeriod sets the update frequency for internal SimState data requests.
 // Controls how often the manager polls simulator state variables (camera, position, weather, etc.).
 //
@@ -142,6 +167,25 @@ func WithSimStatePeriod(period types.SIMCONNECT_PERIOD) Option {
 	}
 }
 
+// WithFacilityCache serves Facilities().Airport and Facilities().List from
+// cache when it holds a valid entry, and stores and saves every result
+// fetched from the simulator. Entries are keyed by the simulator build
+// reported when the connection opens.
+func WithFacilityCache(cache *facility.Cache) Option {
+	return func(c *Config) {
+		c.FacilityCache = cache
+	}
+}
+
+// WithFleetPendingTimeout sets how long a Fleet() creation may wait for its
+// ObjectID before it fails with traffic.ErrSpawnTimeout. Zero disables the
+// timeout. Default is traffic.DefaultPendingTimeout (30s).
+func WithFleetPendingTimeout(d time.Duration) Option {
+	return func(c *Config) {
+		c.FleetPendingTimeout = d
+	}
+}
+
 // WithEngineOptions passes options through to the underlying engine.
 // Note: Context and Logger options passed here will be ignored as the manager
 // controls these settings. Use WithContext and WithLogger on the manager instead.
@@ -193,16 +237,18 @@ func defaultConfig() *Config {
 		Context: context.Background(),
 		// Defer creating a concrete logger until constructor time so that
 		// WithLogLevel and WithLogger options can be applied in any order.
-		Logger:            nil,
-		LogLevel:          slog.LevelInfo,
-		RetryInterval:     DEFAULT_RETRY_INTERVAL,
-		ConnectionTimeout: DEFAULT_CONNECTION_TIMEOUT,
-		ReconnectDelay:    DEFAULT_RECONNECT_DELAY,
-		ShutdownTimeout:   DEFAULT_SHUTDOWN_TIMEOUT,
-		MaxRetries:        DEFAULT_MAX_RETRIES,
-		AutoReconnect:     DEFAULT_AUTO_RECONNECT,
-		SimStatePeriod:    types.SIMCONNECT_PERIOD_SIM_FRAME,
-		EngineOptions:     []engine.Option{},
+		Logger:              nil,
+		LogLevel:            slog.LevelInfo,
+		RetryInterval:       DEFAULT_RETRY_INTERVAL,
+		ConnectionTimeout:   DEFAULT_CONNECTION_TIMEOUT,
+		ReconnectDelay:      DEFAULT_RECONNECT_DELAY,
+		ShutdownTimeout:     DEFAULT_SHUTDOWN_TIMEOUT,
+		MaxRetries:          DEFAULT_MAX_RETRIES,
+		AutoReconnect:       DEFAULT_AUTO_RECONNECT,
+		BatchDispatch:       DEFAULT_BATCH_DISPATCH,
+		SimStatePeriod:      types.SIMCONNECT_PERIOD_SIM_FRAME,
+		FleetPendingTimeout: traffic.DefaultPendingTimeout,
+		EngineOptions:       []engine.Option{},
 	}
 }
 
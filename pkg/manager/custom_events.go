@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager/internal/instance"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
@@ -59,15 +58,9 @@ func (m *Instance) SubscribeToCustomSystemEvent(eventName string, bufferSize int
 		eventID := ce.ID
 		m.mu.Unlock()
 		// Create filtered subscription outside lock to avoid deadlock
-		// (SubscribeWithFilter also acquires mu.Lock)
-		filter := func(msg engine.Message) bool {
-			if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-				return false
-			}
-			ev := msg.AsEvent()
-			return ev != nil && ev.UEventID == types.DWORD(eventID)
-		}
-		return m.SubscribeWithFilter(eventName+"-custom", bufferSize, filter), nil
+		// (SubscribeWithType also acquires mu.Lock)
+		return m.SubscribeWithType(eventName+"-custom", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+			WithRequestIDs(uint32(eventID))), nil
 	}
 
 	// Allocate new event ID
@@ -99,15 +92,9 @@ func (m *Instance) SubscribeToCustomSystemEvent(eventName string, bufferSize int
 	m.mu.Unlock()
 
 	// Create filtered subscription outside lock to avoid deadlock
-	// (SubscribeWithFilter also acquires mu.Lock)
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(eventID)
-	}
-	return m.SubscribeWithFilter(eventName+"-custom", bufferSize, filter), nil
+	// (SubscribeWithType also acquires mu.Lock)
+	return m.SubscribeWithType(eventName+"-custom", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(eventID))), nil
 }
 
 // UnsubscribeFromCustomSystemEvent unsubscribes from a custom system event.
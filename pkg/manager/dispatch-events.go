@@ -52,6 +52,9 @@ func (m *Instance) processEventMessage(msg engine.Message) {
 	case m.simEventID:
 		// Handle sim running event
 		newSimRunningState := eventData == 1
+		m.updateFlightSession(func(cur FlightSession, flowSeen bool) FlightSession {
+			return flightSessionAfterSim(cur, flowSeen, newSimRunningState)
+		}, false)
 
 		m.mu.Lock()
 		if m.simState.SimRunning != newSimRunningState {
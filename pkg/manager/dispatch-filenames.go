@@ -17,6 +17,9 @@ func (m *Instance) processFilenameEvent(msg engine.Message) {
 
 	if eventID == m.flightLoadedEventID {
 		m.logger.Debug("[manager] FlightLoaded event", "filename", filename)
+		m.updateFlightSession(func(cur FlightSession, _ bool) FlightSession {
+			return flightSessionAfterFlightLoaded(cur, filename)
+		}, false)
 		// Invoke registered FlightLoaded handlers with panic recovery
 		m.mu.RLock()
 		if cap(m.flightLoadedHandlersBuf) < len(m.flightLoadedHandlers) {
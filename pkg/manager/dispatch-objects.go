@@ -41,6 +41,9 @@ func (m *Instance) processObjectEvent(msg engine.Message) {
 
 	if eventID == m.objectRemovedEventID {
 		m.logger.Debug("[manager] ObjectRemoved event", "id", objectID, "type", objType)
+		if m.fleet != nil {
+			m.fleet.Removed(objectID)
+		}
 		m.mu.RLock()
 		if cap(m.objectChangeHandlersBuf) < len(m.objectRemovedHandlers) {
 			m.objectChangeHandlersBuf = make([]ObjectChangeHandler, len(m.objectRemovedHandlers))
@@ -0,0 +1,48 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// processAssignedObjectID acknowledges fleet creations from
+// SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID messages. Request IDs the fleet did
+// not issue are ignored.
+func (m *Instance) processAssignedObjectID(msg engine.Message) {
+	assigned := msg.AsAssignedObjectID()
+	if assigned == nil || m.fleet == nil {
+		return
+	}
+	if a, ok := m.fleet.Acknowledge(uint32(assigned.DwRequestID), uint32(assigned.DwObjectID)); ok {
+		m.logger.Debug("[manager] Fleet aircraft spawned", "tail", a.Tail, "objectID", a.ObjectID)
+	}
+}
+
+// processFleetData applies SIMCONNECT_RECV_ID_SIMOBJECT_DATA messages
+// answering Fleet().Track requests. Other requests are ignored.
+func (m *Instance) processFleetData(msg engine.Message) {
+	data := msg.AsSimObjectData()
+	if data == nil || m.fleet == nil {
+		return
+	}
+	reqID := uint32(data.DwRequestID)
+	if reqID < FleetTrackRequestIDMin || reqID > FleetTrackRequestIDMax {
+		return
+	}
+	m.fleet.HandleData(data)
+}
+
+// processException fails the fleet creation a SIMCONNECT_RECV_ID_EXCEPTION
+// message refers to, if any.
+func (m *Instance) processException(msg engine.Message) {
+	ex := msg.AsException()
+	if ex == nil || m.fleet == nil {
+		return
+	}
+	if m.fleet.FailSend(uint32(ex.DwSendID), types.SIMCONNECT_EXCEPTION(ex.DwException)) {
+		m.logger.Debug("[manager] Fleet aircraft creation rejected", "sendID", ex.DwSendID, "exception", ex.DwException)
+	}
+}
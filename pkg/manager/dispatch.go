@@ -3,7 +3,10 @@
 package manager
 
 import (
+	"unsafe"
+
 	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
 
@@ -15,9 +18,42 @@ func (m *Instance) processMessage(msg engine.Message) {
 	// This is critical for memory efficiency under high message load
 	defer msg.Release()
 
+	if m.handleMessage(msg) {
+		m.forwardMessage(msg, m.loadMessageHandlers(), m.subsIndex.Load())
+	}
+}
+
+// processBatch handles a batch of messages from engine.StreamBatches.
+// Internal state is updated per message, but handlers and subscriptions are
+// loaded once for the whole batch.
+func (m *Instance) processBatch(batch []engine.Message) {
+	forward := m.forwardBuf[:0]
+	for i := range batch {
+		if m.handleMessage(batch[i]) {
+			forward = append(forward, batch[i])
+		} else {
+			batch[i].Release()
+		}
+	}
+
+	if len(forward) > 0 {
+		handlers, index := m.loadMessageHandlers(), m.subsIndex.Load()
+		for i := range forward {
+			m.forwardMessage(forward[i], handlers, index)
+			forward[i].Release()
+			forward[i] = engine.Message{}
+		}
+	}
+	m.forwardBuf = forward[:0]
+}
+
+// handleMessage applies a message to the manager's internal state and
+// reports whether it should be forwarded to OnMessage handlers and
+// subscriptions.
+func (m *Instance) handleMessage(msg engine.Message) bool {
 	if msg.Err != nil {
 		m.logger.Error("[manager] Stream error", "error", msg.Err)
-		return
+		return false
 	}
 
 	// Check for connection ready (OPEN) message
@@ -41,6 +77,9 @@ func (m *Instance) processMessage(msg engine.Message) {
 				SimConnectBuildMinor:    uint32(openMsg.DwSimConnectBuildMinor),
 			}
 			m.setOpen(openData)
+			if m.facilities != nil {
+				m.facilities.setBuild(openData)
+			}
 		}
 
 		// Initialize simulator state and request camera data
@@ -51,7 +90,7 @@ func (m *Instance) processMessage(msg engine.Message) {
 		if client != nil {
 			m.registerSimStateSubscriptions(client)
 		}
-		return
+		return false
 	}
 
 	// Check for quit message
@@ -60,58 +99,100 @@ func (m *Instance) processMessage(msg engine.Message) {
 		quitData := types.ConnectionQuitData{}
 		m.setQuit(quitData)
 		m.setSimState(defaultSimState())
+		m.resetFlightSession()
 		m.setState(StateDisconnected)
 		m.mu.Lock()
 		m.engine = nil
 		m.mu.Unlock()
-		return
+		return false
 	}
 
 	// Handle pause and sim events
 	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EVENT {
 		m.processEventMessage(msg)
-		return
+		m.keyEvents.intercept(msg)
+		return true
+	}
+
+	// Hand captured key events to their interceptor; masked events must
+	// not go through a lossy subscription
+	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EVENT_EX1 {
+		m.keyEvents.intercept(msg)
+		return true
 	}
 
 	// Handle filename events (FlightLoaded, AircraftLoaded, FlightPlanActivated)
 	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EVENT_FILENAME {
 		m.processFilenameEvent(msg)
-		return
+		return true
+	}
+
+	// Handle flow events (MSFS 2024) and the derived flight session
+	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_FLOW_EVENT {
+		m.processFlowEvent(msg)
+		return true
 	}
 
 	// Handle object add/remove events (ObjectAdded, ObjectRemoved)
 	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE {
 		m.processObjectEvent(msg)
-		return
+		return true
 	}
 
-	// Handle camera state data
-	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA {
-		m.processSimStateData(msg)
+	// Resolve fleet creations (assigned object IDs and creation exceptions)
+	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID {
+		m.processAssignedObjectID(msg)
+		return true
 	}
-
-	// Forward message to registered handlers
-	m.mu.RLock()
-	// Reuse pre-allocated slices, grow if necessary
-	if cap(m.handlersBuf) < len(m.messageHandlers) {
-		m.handlersBuf = make([]MessageHandler, len(m.messageHandlers))
-	} else {
-		m.handlersBuf = m.handlersBuf[:len(m.messageHandlers)]
+	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_EXCEPTION {
+		m.processException(msg)
+		return true
 	}
-	for i, e := range m.messageHandlers {
-		m.handlersBuf[i] = e.Fn.(MessageHandler)
+
+	// Feed facility responses to the requests and the nearby tracker waiting
+	// for them; a dropped packet would fail a request or leave a stale entry
+	switch types.SIMCONNECT_RECV_ID(msg.DwID) {
+	case types.SIMCONNECT_RECV_ID_FACILITY_DATA,
+		types.SIMCONNECT_RECV_ID_FACILITY_DATA_END:
+		if m.facilities != nil {
+			m.facilities.processMessage(msg)
+		}
+		return true
+	case types.SIMCONNECT_RECV_ID_AIRPORT_LIST,
+		types.SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+		types.SIMCONNECT_RECV_ID_NDB_LIST,
+		types.SIMCONNECT_RECV_ID_VOR_LIST:
+		if m.facilities != nil {
+			m.facilities.processMessage(msg)
+		}
+		if m.nearbyFacilities != nil {
+			m.nearbyFacilities.processMessage(msg)
+		}
+		return true
 	}
-	if cap(m.subsBuf) < len(m.subscriptions) {
-		m.subsBuf = make([]*subscription, 0, len(m.subscriptions))
-	} else {
-		m.subsBuf = m.subsBuf[:0]
+
+	// Handle camera state data and fleet telemetry
+	if types.SIMCONNECT_RECV_ID(msg.DwID) == types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA {
+		m.processSimStateData(msg)
+		m.processFleetData(msg)
 	}
-	for _, sub := range m.subscriptions {
-		m.subsBuf = append(m.subsBuf, sub)
+
+	return true
+}
+
+// loadMessageHandlers returns the current OnMessage handler snapshot.
+func (m *Instance) loadMessageHandlers() []MessageHandler {
+	if snap := m.messageHandlersSnap.Load(); snap != nil {
+		return *snap
 	}
-	m.mu.RUnlock()
+	return nil
+}
 
-	for _, handler := range m.handlersBuf {
+// forwardMessage delivers a message to the given handlers and to the
+// subscriptions the index routes it to. It takes no locks: the handler
+// slice and the index are immutable snapshots.
+func (m *Instance) forwardMessage(msg engine.Message, handlers []MessageHandler, index *subscriptions.Index[*subscription]) {
+	for _, handler := range handlers {
 		h := handler   // capture for closure
 		message := msg // capture for closure
 		safeCallHandler(m.logger, "MessageHandler", func() {
@@ -119,58 +200,89 @@ func (m *Instance) processMessage(msg engine.Message) {
 		})
 	}
 
+	if index.Len() == 0 || msg.SIMCONNECT_RECV == nil {
+		return
+	}
+	key, hasKey := routingKeyOf(msg)
+	m.subsBuf = index.Match(m.subsBuf[:0], uint32(msg.DwID), key, hasKey)
+
 	// Forward message to subscriptions (non-blocking)
-	for _, sub := range m.subsBuf {
-		// fast-path: skip closed subscriptions
-		sub.closeMu.Lock()
-		closed := sub.closed.Load()
-		sub.closeMu.Unlock()
-		if closed {
+	for i, sub := range m.subsBuf {
+		m.subsBuf[i] = nil // do not pin unsubscribed subscriptions
+		if sub.closed.Load() {
 			continue
 		}
-
-		// Determine whether this subscription should receive the message
-		allowed := true
-		if sub.filter != nil {
-			// Protect against panics in user-provided filters
+		if sub.filter != nil && !m.callFilter(sub.filter, msg) {
+			continue
+		}
+		if sub.trySend(msg) {
+			continue
+		}
+		// Channel full, message skipped to avoid blocking
+		if sub.onDrop != nil {
+			// Protect dispatch loop from user callback panics
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						m.logger.Error("[manager] Subscription filter panic", "panic", r)
-						allowed = false
+						m.logger.Error("[manager] OnDrop callback panicked", "panic", r)
 					}
 				}()
-				allowed = sub.filter(msg)
+				sub.onDrop(1)
 			}()
-		} else if len(sub.allowedTypes) > 0 {
-			_, ok := sub.allowedTypes[types.SIMCONNECT_RECV_ID(msg.DwID)]
-			allowed = ok
 		}
+		m.logger.Debug("[manager] Subscription channel full, dropping message")
+	}
+}
 
-		if !allowed {
-			continue
+// callFilter evaluates a subscription filter, treating a panic as a
+// rejection so a faulty filter cannot stop the dispatch loop.
+func (m *Instance) callFilter(filter func(engine.Message) bool, msg engine.Message) (allowed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.logger.Error("[manager] Subscription filter panic", "panic", r)
+			allowed = false
 		}
+	}()
+	return filter(msg)
+}
 
-		sub.closeMu.Lock()
-		if !sub.closed.Load() {
-			select {
-			case sub.ch <- msg:
-			default:
-				// Channel full, skip message to avoid blocking
-				if sub.onDrop != nil {
-					// Protect dispatch loop from user callback panics
-					func() {
-						defer func() {
-							if r := recover(); r != nil {
-								m.logger.Error("[manager] OnDrop callback panicked", "panic", r)
-							}
-						}()
-						sub.onDrop(1)
-					}()
-				}
-				m.logger.Debug("[manager] Subscription channel full, dropping message")
-			}
-		}
-		sub.closeMu.Unlock()
+// requestHeader overlays the DWORD that follows SIMCONNECT_RECV in
+// responses to ID-carrying requests (SimObject, client and facility data,
+// system state, assigned object IDs, lists and input events).
+type requestHeader struct {
+	types.SIMCONNECT_RECV
+	RequestID types.DWORD
+}
+
+// routingKeyOf returns the ID used by the subscription index to route msg:
+// the event ID for event messages and the request ID for responses to
+// requests. Other messages have no routing key.
+func routingKeyOf(msg engine.Message) (uint32, bool) {
+	switch types.SIMCONNECT_RECV_ID(msg.DwID) {
+	case types.SIMCONNECT_RECV_ID_EVENT,
+		types.SIMCONNECT_RECV_ID_EVENT_EX1,
+		types.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE,
+		types.SIMCONNECT_RECV_ID_EVENT_FILENAME,
+		types.SIMCONNECT_RECV_ID_EVENT_FRAME:
+		ev := (*types.SIMCONNECT_RECV_EVENT)(unsafe.Pointer(msg.SIMCONNECT_RECV))
+		return uint32(ev.UEventID), true
+	case types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA,
+		types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE,
+		types.SIMCONNECT_RECV_ID_CLIENT_DATA,
+		types.SIMCONNECT_RECV_ID_SYSTEM_STATE,
+		types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID,
+		types.SIMCONNECT_RECV_ID_AIRPORT_LIST,
+		types.SIMCONNECT_RECV_ID_VOR_LIST,
+		types.SIMCONNECT_RECV_ID_NDB_LIST,
+		types.SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+		types.SIMCONNECT_RECV_ID_FACILITY_DATA,
+		types.SIMCONNECT_RECV_ID_FACILITY_DATA_END,
+		types.SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST,
+		types.SIMCONNECT_RECV_ID_GET_INPUT_EVENT,
+		types.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS,
+		types.SIMCONNECT_RECV_ID_ENUMERATE_SIMOBJECT_AND_LIVERY_LIST:
+		hdr := (*requestHeader)(unsafe.Pointer(msg.SIMCONNECT_RECV))
+		return uint32(hdr.RequestID), true
 	}
+	return 0, false
 }
@@ -0,0 +1,224 @@
+//go:build windows
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// newDispatchTestManager returns a manager with just enough state to
+// register subscriptions and forward messages, without a connection.
+func newDispatchTestManager() *Instance {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Instance{
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        slog.New(slog.NewTextHandler(io.Discard, nil)),
+		subscriptions: make(map[string]*subscription),
+		pauseEventID:  PauseEventID,
+	}
+}
+
+// newDataMessage returns an unpooled SIMOBJECT_DATA message for requestID.
+func newDataMessage(requestID uint32) engine.Message {
+	data := &types.SIMCONNECT_RECV_SIMOBJECT_DATA{}
+	data.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA)
+	data.DwRequestID = types.DWORD(requestID)
+	return engine.Message{SIMCONNECT_RECV: &data.SIMCONNECT_RECV}
+}
+
+// newEventMessage returns an unpooled EVENT message for eventID.
+func newEventMessage(eventID uint32) engine.Message {
+	ev := &types.SIMCONNECT_RECV_EVENT{}
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EVENT)
+	ev.UEventID = types.DWORD(eventID)
+	return engine.Message{SIMCONNECT_RECV: &ev.SIMCONNECT_RECV}
+}
+
+func (m *Instance) forward(msg engine.Message) {
+	m.forwardMessage(msg, m.loadMessageHandlers(), m.subsIndex.Load())
+}
+
+func pending(sub Subscription) int {
+	return len(sub.(*subscription).ch)
+}
+
+func TestDispatchRoutesByRequestID(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+
+	var filterCalls atomic.Int32
+	dataTypes := []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA}
+	all := m.Subscribe("all", 8)
+	req7 := m.SubscribeWithType("req7", 8, dataTypes, WithRequestIDs(7))
+	filtered := m.SubscribeWithFilter("filtered", 8, func(engine.Message) bool {
+		filterCalls.Add(1)
+		return true
+	}, WithRequestIDs(7))
+	pause := m.SubscribeOnPause("pause", 8)
+	defer all.Unsubscribe()
+	defer req7.Unsubscribe()
+	defer filtered.Unsubscribe()
+	defer pause.Unsubscribe()
+
+	m.forward(newDataMessage(7))
+	m.forward(newDataMessage(8))
+	m.forward(newEventMessage(uint32(PauseEventID)))
+	m.forward(newEventMessage(7))
+
+	if got := pending(all); got != 4 {
+		t.Errorf("wildcard subscription got %d messages, want 4", got)
+	}
+	if got := pending(req7); got != 1 {
+		t.Errorf("request subscription got %d messages, want 1", got)
+	}
+	// Keys without types match any message kind carrying the ID.
+	if got := pending(filtered); got != 2 {
+		t.Errorf("keyed filter subscription got %d messages, want 2", got)
+	}
+	if got := filterCalls.Load(); got != 2 {
+		t.Errorf("filter called %d times, want 2 (only for routed messages)", got)
+	}
+	if got := pending(pause); got != 1 {
+		t.Errorf("pause subscription got %d messages, want 1", got)
+	}
+}
+
+func TestUnsubscribeReleasesBuffered(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+
+	sub := m.Subscribe("unread", 8)
+	msg := newDataMessage(7)
+	msg.Size = uint32(unsafe.Sizeof(types.SIMCONNECT_RECV_SIMOBJECT_DATA{}))
+	msg = msg.Clone() // reference-counted, unlike the literal
+	stale := msg
+	m.forward(msg)
+	msg.Release()
+
+	// The subscription holds the last reference until Unsubscribe drops it.
+	sub.Unsubscribe()
+	defer func() {
+		if recover() == nil {
+			t.Error("buffered message not released by Unsubscribe")
+		}
+	}()
+	stale.Retain()
+}
+
+func TestProcessMessageForwardsEvents(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+
+	var handled atomic.Int32
+	m.OnMessage(func(engine.Message) { handled.Add(1) })
+	pause := m.SubscribeOnPause("pause", 8)
+	defer pause.Unsubscribe()
+
+	// Internal pause tracking consumes the event first, then it is forwarded.
+	m.processMessage(newEventMessage(uint32(PauseEventID)))
+
+	if got := handled.Load(); got != 1 {
+		t.Errorf("OnMessage handler called %d times, want 1", got)
+	}
+	if got := pending(pause); got != 1 {
+		t.Errorf("pause subscription got %d messages, want 1", got)
+	}
+}
+
+func TestDispatchUnsubscribeDuringForward(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+
+	subs := make([]Subscription, 32)
+	for i := range subs {
+		subs[i] = m.Subscribe(fmt.Sprintf("sub-%d", i), 1)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2000; i++ {
+			m.forward(newDataMessage(1))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, sub := range subs {
+		wg.Add(1)
+		go func(s Subscription) {
+			defer wg.Done()
+			s.Unsubscribe()
+		}(sub)
+	}
+	wg.Wait()
+	<-done
+
+	if n := m.subsIndex.Load().Len(); n != 0 {
+		t.Fatalf("index still holds %d subscriptions", n)
+	}
+	for _, sub := range subs {
+		select {
+		case <-sub.Done():
+		default:
+			t.Fatalf("subscription %s not closed", sub.ID())
+		}
+	}
+}
+
+func BenchmarkDispatchSubscriptions(b *testing.B) {
+	dataTypes := []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA}
+	for _, n := range []int{1, 10, 100} {
+		for _, mode := range []string{"all", "routed", "filtered"} {
+			b.Run(fmt.Sprintf("subs=%d/%s", n, mode), func(b *testing.B) {
+				m := newDispatchTestManager()
+				defer m.cancel()
+
+				for i := 0; i < n; i++ {
+					id := fmt.Sprintf("bench-%d", i)
+					var sub Subscription
+					switch mode {
+					case "all":
+						sub = m.Subscribe(id, 1024)
+					case "routed":
+						sub = m.SubscribeWithType(id, 1024, dataTypes, WithRequestIDs(uint32(i)))
+					case "filtered":
+						requestID := types.DWORD(i)
+						sub = m.SubscribeWithFilter(id, 1024, func(msg engine.Message) bool {
+							data := msg.AsSimObjectData()
+							return data != nil && data.DwRequestID == requestID
+						})
+					}
+					defer sub.Unsubscribe()
+					go func() {
+						for msg := range sub.Messages() {
+							msg.Release()
+						}
+					}()
+				}
+
+				msgs := make([]engine.Message, n)
+				for i := range msgs {
+					msgs[i] = newDataMessage(uint32(i))
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					m.forward(msgs[i%n])
+				}
+				b.StopTimer()
+			})
+		}
+	}
+}
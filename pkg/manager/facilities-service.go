@@ -0,0 +1,321 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// Facilities requests facility data and returns it decoded.
+//
+// The facility definitions it needs are registered on first use and again
+// after every reconnect. With WithFacilityCache, lookups are answered from
+// the cache first and work without a connection.
+type Facilities struct {
+	m     *Instance
+	cache *facility.Cache // nil without WithFacilityCache
+
+	mu        sync.Mutex
+	client    engine.Client
+	defined   map[facility.Include]bool // airport definitions registered on the current connection
+	pending   map[uint32]*facilityRequest
+	nextReqID uint32
+}
+
+// facilityRequest collects the responses to one Airport or List request.
+// Large airports stream thousands of records back to back, so the
+// dispatcher feeds them in directly instead of through a subscription that
+// could drop one and fail the request with facility.ErrIncomplete.
+type facilityRequest struct {
+	asm   *facility.Assembler // Airport requests; nil for List
+	kind  facility.Kind       // List requests
+	list  []facility.Summary
+	pages map[uint32]bool
+	err   error
+	done  chan struct{} // closed once the request completed or failed
+}
+
+// newFacilities constructs an unconnected Facilities service backed by
+// cache, which may be nil.
+func newFacilities(m *Instance, cache *facility.Cache) *Facilities {
+	return &Facilities{
+		m:         m,
+		cache:     cache,
+		defined:   make(map[facility.Include]bool),
+		pending:   make(map[uint32]*facilityRequest),
+		nextReqID: FacilityRequestIDMin,
+	}
+}
+
+// Facilities returns the manager's facility service.
+func (m *Instance) Facilities() *Facilities {
+	return m.facilities
+}
+
+// Airport requests the airport icao with the child records selected by
+// opts.Include and waits for all of them, or for ctx to end. An unknown
+// ICAO code returns facility.ErrNotFound.
+// Returns ErrNotConnected if not connected to the simulator and the airport
+// is not cached.
+func (s *Facilities) Airport(ctx context.Context, icao string, opts facility.AirportOptions) (*facility.Airport, error) {
+	include := opts.Normalize()
+	icao = strings.ToUpper(strings.TrimSpace(icao))
+	if s.cache != nil {
+		if ap, ok := s.cache.Airport(icao, opts.Region, include); ok {
+			return ap, nil
+		}
+	}
+
+	s.mu.Lock()
+	client := s.client
+	if client == nil {
+		s.mu.Unlock()
+		return nil, ErrNotConnected
+	}
+	defID, err := s.airportDefinitionLocked(include)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	req := &facilityRequest{asm: facility.NewAssembler(), done: make(chan struct{})}
+	reqID := s.startLocked(req)
+	s.mu.Unlock()
+	defer s.cancel(reqID, req)
+
+	if err := client.RequestFacilityData(defID, reqID, icao, opts.Region); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-req.done:
+	}
+	if req.err != nil {
+		return nil, req.err
+	}
+	ap, err := req.asm.Airport()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, icao)
+	}
+	if s.cache != nil {
+		if err := s.cache.PutAirport(ap, include); err != nil {
+			s.m.logger.Warn("[manager] Facility cache store failed", "icao", icao, "error", err)
+		}
+		s.saveCache()
+	}
+	return ap, nil
+}
+
+// facilityListRecvIDs maps a facility kind to the message its list arrives in.
+var facilityListRecvIDs = map[facility.Kind]types.SIMCONNECT_RECV_ID{
+	facility.KindAirport:  types.SIMCONNECT_RECV_ID_AIRPORT_LIST,
+	facility.KindWaypoint: types.SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+	facility.KindNDB:      types.SIMCONNECT_RECV_ID_NDB_LIST,
+	facility.KindVOR:      types.SIMCONNECT_RECV_ID_VOR_LIST,
+}
+
+// List requests every facility of kind k known to the simulator and waits
+// for all pages of the list, or for ctx to end. Lists are large, so with a
+// cache the result is stored and later calls are answered from disk.
+// Returns ErrNotConnected if not connected to the simulator and the list is
+// not cached.
+func (s *Facilities) List(ctx context.Context, k facility.Kind) ([]facility.Summary, error) {
+	if _, ok := facilityListRecvIDs[k]; !ok {
+		return nil, fmt.Errorf("%w: list %s", facility.ErrUnsupportedType, k)
+	}
+	if s.cache != nil {
+		if list, ok := s.cache.List(k); ok {
+			return list, nil
+		}
+	}
+
+	s.mu.Lock()
+	client := s.client
+	if client == nil {
+		s.mu.Unlock()
+		return nil, ErrNotConnected
+	}
+	req := &facilityRequest{kind: k, pages: make(map[uint32]bool), done: make(chan struct{})}
+	reqID := s.startLocked(req)
+	s.mu.Unlock()
+	defer s.cancel(reqID, req)
+
+	if err := client.RequestAllFacilities(types.SIMCONNECT_FACILITY_LIST_TYPE(k), reqID); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-req.done:
+	}
+	if req.err != nil {
+		return nil, req.err
+	}
+	if s.cache != nil {
+		s.cache.PutList(k, req.list)
+		s.saveCache()
+	}
+	return req.list, nil
+}
+
+// startLocked registers req under a new request ID and returns the ID.
+// Caller must hold s.mu.
+func (s *Facilities) startLocked(req *facilityRequest) uint32 {
+	reqID := s.requestIDLocked()
+	s.pending[reqID] = req
+	return reqID
+}
+
+// cancel forgets req if it is still waiting for responses.
+func (s *Facilities) cancel(reqID uint32, req *facilityRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[reqID] == req {
+		delete(s.pending, reqID)
+	}
+}
+
+// completeLocked ends the request reqID with err, waking its caller.
+// Caller must hold s.mu.
+func (s *Facilities) completeLocked(reqID uint32, err error) {
+	req := s.pending[reqID]
+	delete(s.pending, reqID)
+	req.err = err
+	close(req.done)
+}
+
+// processMessage feeds a facility data or list message to the request
+// waiting for it. It runs on the dispatch goroutine, before msg is
+// forwarded, and copies everything it keeps out of the receive buffer.
+func (s *Facilities) processMessage(msg engine.Message) {
+	reqID, ok := routingKeyOf(msg)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	req := s.pending[reqID]
+	if req == nil {
+		return
+	}
+
+	if req.asm != nil {
+		if msg.AsFacilityDataEnd() != nil {
+			s.completeLocked(reqID, nil)
+			return
+		}
+		if p, ok := facilityPacket(&msg); ok {
+			if err := req.asm.Add(p); err != nil {
+				s.completeLocked(reqID, err)
+			}
+		}
+		return
+	}
+
+	page := msg.AsFacilityList()
+	if page == nil || types.SIMCONNECT_RECV_ID(msg.DwID) != facilityListRecvIDs[req.kind] {
+		return
+	}
+	entry, outOf := uint32(page.DwEntryNumber), uint32(page.DwOutOf)
+	data, count := engine.FacilityListBytes(&msg)
+	items, err := facility.DecodeList(req.kind, data, count)
+	if err != nil {
+		s.completeLocked(reqID, err)
+		return
+	}
+	if !req.pages[entry] {
+		req.pages[entry] = true
+		req.list = append(req.list, items...)
+	}
+	if uint32(len(req.pages)) >= outOf {
+		s.completeLocked(reqID, nil)
+	}
+}
+
+// saveCache writes the facility cache, logging rather than failing the
+// request that filled it.
+func (s *Facilities) saveCache() {
+	if err := s.cache.Save(); err != nil {
+		s.m.logger.Warn("[manager] Facility cache save failed", "path", s.cache.Path(), "error", err)
+	}
+}
+
+// setBuild keys the facility cache to the simulator that opened the
+// connection, so entries fetched from another version are not served.
+func (s *Facilities) setBuild(open types.ConnectionOpenData) {
+	if s.cache == nil {
+		return
+	}
+	s.cache.SetBuild(fmt.Sprintf("%d.%d.%d.%d", open.ApplicationVersionMajor, open.ApplicationVersionMinor,
+		open.ApplicationBuildMajor, open.ApplicationBuildMinor))
+}
+
+// airportDefinitionLocked returns the definition ID for an airport with the
+// given child records, registering it on the current connection if needed.
+// Caller must hold s.mu.
+func (s *Facilities) airportDefinitionLocked(include facility.Include) (uint32, error) {
+	defID := FacilityDefinitionIDMin + uint32(include)
+	if s.defined[include] {
+		return defID, nil
+	}
+	set := &datasets.FacilityDataSet{}
+	for _, f := range facility.AirportDefinition(include) {
+		set.Definitions = append(set.Definitions, datasets.FacilityDataDefinition(f))
+	}
+	if err := s.client.RegisterFacilityDataset(defID, set); err != nil {
+		return 0, err
+	}
+	s.defined[include] = true
+	return defID, nil
+}
+
+// setClient binds the service to a new connection, or detaches it when
+// client is nil. Definitions are registered again on the next request, and
+// requests sent on the previous connection fail with ErrNotConnected.
+func (s *Facilities) setClient(client engine.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = client
+	s.defined = make(map[facility.Include]bool)
+	for reqID := range s.pending {
+		s.completeLocked(reqID, ErrNotConnected)
+	}
+}
+
+// requestIDLocked returns the next request ID from the facility range.
+// Caller must hold s.mu.
+func (s *Facilities) requestIDLocked() uint32 {
+	id := s.nextReqID
+	s.nextReqID++
+	if s.nextReqID > FacilityRequestIDMax {
+		s.nextReqID = FacilityRequestIDMin
+	}
+	return id
+}
+
+// facilityPacket converts a FACILITY_DATA message into a facility.Packet.
+// Data aliases the receive buffer until msg is released.
+func facilityPacket(msg *engine.Message) (facility.Packet, bool) {
+	fd := msg.AsFacilityData()
+	if fd == nil {
+		return facility.Packet{}, false
+	}
+	return facility.Packet{
+		Type:       facility.DataType(fd.Type),
+		UniqueID:   uint32(fd.UniqueRequestId),
+		ParentID:   uint32(fd.ParentUniqueRequestId),
+		IsListItem: fd.IsListItem != 0,
+		ItemIndex:  uint32(fd.ItemIndex),
+		ListSize:   uint32(fd.ListSize),
+		Data:       engine.FacilityDataBytes(msg),
+	}, true
+}
@@ -0,0 +1,273 @@
+//go:build windows
+
+package manager
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeFacilityClient answers facility requests by forwarding the
+// simulator's responses straight into the manager. Methods not overridden
+// panic through the nil embedded interface.
+type fakeFacilityClient struct {
+	engine.Client
+	m        *Instance
+	airports map[string][]byte // ICAO → packed AIRPORT record
+	freqs    int               // FREQUENCY records streamed after each airport
+
+	registered   map[uint32][]datasets.FacilityDataDefinition
+	listRequests int
+}
+
+func (c *fakeFacilityClient) RegisterFacilityDataset(definitionID uint32, dataset *datasets.FacilityDataSet) error {
+	c.registered[definitionID] = dataset.Definitions
+	return nil
+}
+
+// RequestFacilityData replies with the airport record, if known, and the
+// closing DATA_END.
+func (c *fakeFacilityClient) RequestFacilityData(definitionID uint32, requestID uint32, icao string, region string) error {
+	if data, ok := c.airports[icao]; ok {
+		c.m.processMessage(newFacilityDataMessage(requestID, 1, 0, facility.DataAirport, data))
+		for i := 0; i < c.freqs; i++ {
+			msg := newFacilityDataMessage(requestID, uint32(2+i), 1, facility.DataFrequency, make([]byte, 512))
+			fd := msg.AsFacilityData()
+			fd.IsListItem, fd.ItemIndex, fd.ListSize = 1, types.DWORD(i), types.DWORD(c.freqs)
+			c.m.processMessage(msg)
+		}
+	}
+	end := &types.SIMCONNECT_RECV_FACILITY_DATA_END{RequestId: types.DWORD(requestID)}
+	end.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FACILITY_DATA_END)
+	c.m.processMessage(engine.Message{SIMCONNECT_RECV: &end.SIMCONNECT_RECV})
+	return nil
+}
+
+// newFacilityDataMessage returns an unpooled FACILITY_DATA message carrying data.
+func newFacilityDataMessage(requestID, uniqueID, parentID uint32, typ facility.DataType, data []byte) engine.Message {
+	header := int(unsafe.Offsetof(types.SIMCONNECT_RECV_FACILITY_DATA{}.Data))
+	buf := make([]byte, header+len(data))
+	fd := (*types.SIMCONNECT_RECV_FACILITY_DATA)(unsafe.Pointer(&buf[0]))
+	fd.DwSize = types.DWORD(len(buf))
+	fd.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FACILITY_DATA)
+	fd.UserRequestId = types.DWORD(requestID)
+	fd.UniqueRequestId = types.DWORD(uniqueID)
+	fd.ParentUniqueRequestId = types.DWORD(parentID)
+	fd.Type = types.SIMCONNECT_FACILITY_DATA_TYPE(typ)
+	copy(buf[header:], data)
+	return engine.Message{SIMCONNECT_RECV: &fd.SIMCONNECT_RECV, Size: uint32(len(buf))}
+}
+
+// packedAirport returns an AIRPORT record in the layout of facility.AirportDefinition:
+// LATITUDE, LONGITUDE, ALTITUDE (float64), MAGVAR (float32), NAME[32], NAME64[64], ICAO[8], ...
+func packedAirport(icao string, lat float64) []byte {
+	data := make([]byte, 172)
+	binary.LittleEndian.PutUint64(data[0:], math.Float64bits(lat))
+	copy(data[28:], icao+" Airport")
+	copy(data[124:], icao)
+	return data
+}
+
+func TestFacilitiesAirport(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.facilities = newFacilities(m, nil)
+	client := &fakeFacilityClient{
+		m:          m,
+		airports:   map[string][]byte{"LKPR": packedAirport("LKPR", 50.1)},
+		registered: make(map[uint32][]datasets.FacilityDataDefinition),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{}); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Airport before connect: err = %v, want ErrNotConnected", err)
+	}
+
+	m.facilities.setClient(client)
+	ap, err := m.Facilities().Airport(ctx, "lkpr", facility.AirportOptions{Include: facility.IncludeRunways})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ap.ICAO != "LKPR" || ap.Name != "LKPR Airport" || ap.Latitude != 50.1 {
+		t.Fatalf("airport = %+v", ap)
+	}
+
+	if _, err := m.Facilities().Airport(ctx, "ZZZZ", facility.AirportOptions{Include: facility.IncludeRunways}); !errors.Is(err, facility.ErrNotFound) {
+		t.Fatalf("unknown airport: err = %v, want facility.ErrNotFound", err)
+	}
+	if len(client.registered) != 1 {
+		t.Fatalf("registered %d definitions, want 1 reused definition", len(client.registered))
+	}
+
+	// A reconnect registers the definition again.
+	m.facilities.setClient(client)
+	client.registered = make(map[uint32][]datasets.FacilityDataDefinition)
+	if _, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{Include: facility.IncludeRunways}); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.registered) != 1 {
+		t.Fatalf("definition not registered after reconnect")
+	}
+}
+
+func TestFacilitiesAirportNeverDrops(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.facilities = newFacilities(m, nil)
+	m.facilities.setClient(&fakeFacilityClient{
+		m:          m,
+		airports:   map[string][]byte{"LKPR": packedAirport("LKPR", 50.1)},
+		freqs:      10000, // far more than any subscription buffer
+		registered: make(map[uint32][]datasets.FacilityDataDefinition),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ap, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{Include: facility.IncludeFrequencies})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ap.Frequencies) != 10000 {
+		t.Fatalf("got %d frequencies, want 10000", len(ap.Frequencies))
+	}
+}
+
+// blockedFacilityClient accepts facility requests and never answers them.
+type blockedFacilityClient struct {
+	fakeFacilityClient
+	sent chan struct{}
+}
+
+func (c *blockedFacilityClient) RequestFacilityData(definitionID uint32, requestID uint32, icao string, region string) error {
+	close(c.sent)
+	return nil
+}
+
+func TestFacilitiesAirportDisconnect(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.facilities = newFacilities(m, nil)
+	client := &blockedFacilityClient{
+		fakeFacilityClient: fakeFacilityClient{m: m, registered: make(map[uint32][]datasets.FacilityDataDefinition)},
+		sent:               make(chan struct{}),
+	}
+	m.facilities.setClient(client)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := m.Facilities().Airport(context.Background(), "LKPR", facility.AirportOptions{})
+		errc <- err
+	}()
+	<-client.sent
+	m.facilities.setClient(nil)
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrNotConnected) {
+			t.Fatalf("Airport across a disconnect: err = %v, want ErrNotConnected", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Airport still waiting after disconnect")
+	}
+	if len(m.facilities.pending) != 0 {
+		t.Fatalf("%d requests still pending", len(m.facilities.pending))
+	}
+}
+
+// RequestAllFacilities replies with the airport list split over two pages.
+func (c *fakeFacilityClient) RequestAllFacilities(listType types.SIMCONNECT_FACILITY_LIST_TYPE, requestID uint32) error {
+	c.listRequests++
+	c.m.processMessage(newAirportListMessage(requestID, 0, 2, packedListAirport("LKPR", 50)))
+	c.m.processMessage(newAirportListMessage(requestID, 1, 2, packedListAirport("LKVO", 51)))
+	return nil
+}
+
+// newAirportListMessage returns an unpooled AIRPORT_LIST message carrying
+// page of outOf with the given entries.
+func newAirportListMessage(requestID, page, outOf uint32, entries ...[]byte) engine.Message {
+	header := int(unsafe.Sizeof(types.SIMCONNECT_RECV_FACILITIES_LIST{}))
+	buf := make([]byte, header)
+	for _, e := range entries {
+		buf = append(buf, e...)
+	}
+	list := (*types.SIMCONNECT_RECV_FACILITIES_LIST)(unsafe.Pointer(&buf[0]))
+	list.DwSize = types.DWORD(len(buf))
+	list.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_AIRPORT_LIST)
+	list.DwRequestID = types.DWORD(requestID)
+	list.DwArraySize = types.DWORD(len(entries))
+	list.DwEntryNumber = types.DWORD(page)
+	list.DwOutOf = types.DWORD(outOf)
+	return engine.Message{SIMCONNECT_RECV: &list.SIMCONNECT_RECV, Size: uint32(len(buf))}
+}
+
+// packedListAirport returns an airport list entry in the MSFS 2024 layout:
+// ident[9], region[3], latitude, longitude, altitude.
+func packedListAirport(icao string, lat float64) []byte {
+	entry := make([]byte, 36)
+	copy(entry, icao)
+	copy(entry[9:], "LK")
+	binary.LittleEndian.PutUint64(entry[12:], math.Float64bits(lat))
+	return entry
+}
+
+func TestFacilitiesCache(t *testing.T) {
+	cache, err := facility.OpenCache(filepath.Join(t.TempDir(), "facilities.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.facilities = newFacilities(m, cache)
+	m.facilities.setBuild(types.ConnectionOpenData{ApplicationVersionMajor: 12, ApplicationVersionMinor: 1})
+	client := &fakeFacilityClient{
+		m:          m,
+		airports:   map[string][]byte{"LKPR": packedAirport("LKPR", 50.1)},
+		registered: make(map[uint32][]datasets.FacilityDataDefinition),
+	}
+	m.facilities.setClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	list, err := m.Facilities().List(ctx, facility.KindAirport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ICAO != "LKPR" || list[1].ICAO != "LKVO" || list[1].Latitude != 51 {
+		t.Fatalf("List = %+v", list)
+	}
+	if _, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{Include: facility.IncludeRunways}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Disconnected, both are served from the cache.
+	m.facilities.setClient(nil)
+	if _, err := m.Facilities().List(ctx, facility.KindAirport); err != nil || client.listRequests != 1 {
+		t.Fatalf("cached List: err = %v after %d requests", err, client.listRequests)
+	}
+	if ap, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{Include: facility.IncludeRunways}); err != nil || ap.Latitude != 50.1 {
+		t.Fatalf("cached Airport = %+v, %v", ap, err)
+	}
+	if _, err := m.Facilities().List(ctx, facility.KindVOR); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("uncached List: err = %v, want ErrNotConnected", err)
+	}
+
+	// A different simulator build does not reuse the entries.
+	m.facilities.setBuild(types.ConnectionOpenData{ApplicationVersionMajor: 12, ApplicationVersionMinor: 2})
+	if _, err := m.Facilities().Airport(ctx, "LKPR", facility.AirportOptions{Include: facility.IncludeRunways}); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Airport from another build: err = %v, want ErrNotConnected", err)
+	}
+}
@@ -57,6 +57,18 @@ func (s *filenameSubscription) Unsubscribe() {
 	}
 }
 
+// decodeFilenameEvent extracts the filename carried by msg when it matches
+// eventID. It consumes msg: the subscription reference is released before
+// returning, so only the copied string escapes.
+func decodeFilenameEvent(msg engine.Message, eventID uint32) (string, bool) {
+	defer msg.Release()
+	fname := msg.AsEventFilename()
+	if fname == nil || fname.UEventID != types.DWORD(eventID) {
+		return "", false
+	}
+	return engine.BytesToString(fname.SzFileName[:]), true
+}
+
 // SubscribeOnFlightLoaded returns a subscription delivering FlightLoaded filenames
 func (m *Instance) SubscribeOnFlightLoaded(id string, bufferSize int) FilenameSubscription {
 	id = subscriptions.GenerateID(id)
@@ -77,14 +89,10 @@ func (m *Instance) SubscribeOnFlightLoaded(id string, bufferSize int) FilenameSu
 				if !ok {
 					return
 				}
-				fname := msg.AsEventFilename()
-				if fname == nil {
-					continue
-				}
-				if fname.UEventID != types.DWORD(m.flightLoadedEventID) {
+				name, ok := decodeFilenameEvent(msg, m.flightLoadedEventID)
+				if !ok {
 					continue
 				}
-				name := engine.BytesToString(fname.SzFileName[:])
 				select {
 				case fs.ch <- FilenameEvent{Filename: name}:
 				default:
@@ -115,14 +123,10 @@ func (m *Instance) SubscribeOnAircraftLoaded(id string, bufferSize int) Filename
 				if !ok {
 					return
 				}
-				fname := msg.AsEventFilename()
-				if fname == nil {
-					continue
-				}
-				if fname.UEventID != types.DWORD(m.aircraftLoadedEventID) {
+				name, ok := decodeFilenameEvent(msg, m.aircraftLoadedEventID)
+				if !ok {
 					continue
 				}
-				name := engine.BytesToString(fname.SzFileName[:])
 				select {
 				case fs.ch <- FilenameEvent{Filename: name}:
 				default:
@@ -153,14 +157,10 @@ func (m *Instance) SubscribeOnFlightPlanActivated(id string, bufferSize int) Fil
 				if !ok {
 					return
 				}
-				fname := msg.AsEventFilename()
-				if fname == nil {
-					continue
-				}
-				if fname.UEventID != types.DWORD(m.flightPlanActivatedEventID) {
+				name, ok := decodeFilenameEvent(msg, m.flightPlanActivatedEventID)
+				if !ok {
 					continue
 				}
-				name := engine.BytesToString(fname.SzFileName[:])
 				select {
 				case fs.ch <- FilenameEvent{Filename: name}:
 				default:
@@ -0,0 +1,278 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/handlers"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// FlightSession is the derived phase of the user's simulator session.
+// It merges flow events (MSFS 2024) with the FlightLoaded and Sim system
+// events, so it also works, more coarsely, on MSFS 2020.
+type FlightSession int
+
+const (
+	// FlightSessionUnknown indicates no session information has arrived yet
+	// on the current connection.
+	FlightSessionUnknown FlightSession = iota
+	// FlightSessionLoading indicates a flight is being loaded.
+	FlightSessionLoading
+	// FlightSessionInMenu indicates the simulator is in the main menu.
+	FlightSessionInMenu
+	// FlightSessionBriefing indicates a flight is loaded but not started yet,
+	// e.g. the user is on the briefing or "Ready to Fly" screen.
+	FlightSessionBriefing
+	// FlightSessionInFlight indicates the user is flying. Aircraft-specific
+	// datasets can safely be registered in this state.
+	FlightSessionInFlight
+	// FlightSessionReturningToMenu indicates the flight has ended and the
+	// simulator is returning to the main menu.
+	FlightSessionReturningToMenu
+)
+
+// String returns the human-readable name of the flight session state.
+func (s FlightSession) String() string {
+	switch s {
+	case FlightSessionUnknown:
+		return "Unknown"
+	case FlightSessionLoading:
+		return "Loading"
+	case FlightSessionInMenu:
+		return "InMenu"
+	case FlightSessionBriefing:
+		return "Briefing"
+	case FlightSessionInFlight:
+		return "InFlight"
+	case FlightSessionReturningToMenu:
+		return "ReturningToMenu"
+	default:
+		return fmt.Sprintf("FlightSession(%d)", int(s))
+	}
+}
+
+// FlightSessionChange represents a flight session transition
+type FlightSessionChange struct {
+	OldState FlightSession
+	NewState FlightSession
+}
+
+// FlightSessionChangeHandler is invoked when the flight session changes
+type FlightSessionChangeHandler func(oldState, newState FlightSession)
+
+// FlightSessionSubscription represents a subscription to flight session changes
+type FlightSessionSubscription interface {
+	// ID returns the unique identifier of the subscription
+	ID() string
+
+	// FlightSessionChanges returns the channel for receiving flight session changes
+	FlightSessionChanges() <-chan FlightSessionChange
+
+	// Done returns a channel that is closed when the subscription ends.
+	Done() <-chan struct{}
+
+	// Unsubscribe cancels the subscription and closes the channel
+	Unsubscribe()
+}
+
+// FlightSession returns the current derived flight session state.
+func (m *Instance) FlightSession() FlightSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.flightSession
+}
+
+// OnFlightSessionChange registers a callback invoked when the flight session
+// changes. Registering a handler enables flow events (see OnFlowEvent) so
+// MSFS 2024 transitions are tracked precisely.
+func (m *Instance) OnFlightSessionChange(handler FlightSessionChangeHandler) string {
+	id := handlers.RegisterFlightSessionHandler(&m.mu, &m.flightSessionHandlers, handler, m.logger)
+	m.enableFlowEvents()
+	return id
+}
+
+// RemoveFlightSessionChange removes a previously registered flight session handler.
+func (m *Instance) RemoveFlightSessionChange(id string) error {
+	return handlers.RemoveFlightSessionHandler(&m.mu, &m.flightSessionHandlers, id, m.logger)
+}
+
+// flightSessionSubscription implements the FlightSessionSubscription interface
+type flightSessionSubscription struct {
+	id      string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	ch      chan FlightSessionChange
+	done    chan struct{}
+	closed  atomic.Bool
+	closeMu sync.Mutex // kept for channel close coordination only
+	manager *Instance
+}
+
+// SubscribeFlightSessionChange creates a subscription that delivers flight
+// session changes to a channel. Like OnFlightSessionChange, it enables flow
+// events.
+func (m *Instance) SubscribeFlightSessionChange(id string, bufferSize int) FlightSessionSubscription {
+	id = subscriptions.GenerateID(id)
+	bufferSize = subscriptions.ValidateBufferSize(bufferSize)
+
+	// Derive context from manager's context for automatic cancellation
+	subCtx, subCancel := context.WithCancel(m.ctx)
+
+	sub := &flightSessionSubscription{
+		id:      id,
+		ctx:     subCtx,
+		cancel:  subCancel,
+		ch:      make(chan FlightSessionChange, bufferSize),
+		done:    make(chan struct{}),
+		manager: m,
+	}
+
+	m.mu.Lock()
+	m.flightSessionSubscriptions[id] = sub
+	m.mu.Unlock()
+	m.enableFlowEvents()
+
+	// Start goroutine to watch for context cancellation
+	go sub.watchContext()
+
+	m.logger.Debug(fmt.Sprintf("[manager] Created flight session subscription: %s", id))
+	return sub
+}
+
+// watchContext monitors the subscription's context and auto-unsubscribes when cancelled
+func (s *flightSessionSubscription) watchContext() { <-s.ctx.Done(); s.Unsubscribe() }
+func (s *flightSessionSubscription) ID() string    { return s.id }
+func (s *flightSessionSubscription) FlightSessionChanges() <-chan FlightSessionChange {
+	return s.ch
+}
+func (s *flightSessionSubscription) Done() <-chan struct{} { return s.done }
+func (s *flightSessionSubscription) Unsubscribe() {
+	if s.closed.Swap(true) {
+		return // already closed
+	}
+	s.closeMu.Lock()
+	close(s.done)
+	close(s.ch)
+	s.closeMu.Unlock()
+	s.cancel()
+	s.manager.mu.Lock()
+	delete(s.manager.flightSessionSubscriptions, s.id)
+	s.manager.mu.Unlock()
+	s.manager.logger.Debug(fmt.Sprintf("[manager] Flight session subscription unsubscribed: %s", s.id))
+}
+
+// updateFlightSession applies next to the current flight session and
+// notifies handlers and subscriptions when it changes. fromFlow marks the
+// update as coming from a flow event; once one has been seen on the current
+// connection, Sim events no longer drive the session.
+func (m *Instance) updateFlightSession(next func(cur FlightSession, flowSeen bool) FlightSession, fromFlow bool) {
+	m.mu.Lock()
+	if fromFlow {
+		m.flightSessionFlowSeen = true
+	}
+	oldState := m.flightSession
+	newState := next(oldState, m.flightSessionFlowSeen)
+	if newState == oldState {
+		m.mu.Unlock()
+		return
+	}
+	m.flightSession = newState
+	hs := make([]FlightSessionChangeHandler, len(m.flightSessionHandlers))
+	for i, e := range m.flightSessionHandlers {
+		hs[i] = e.Fn.(FlightSessionChangeHandler)
+	}
+	subs := make([]*flightSessionSubscription, 0, len(m.flightSessionSubscriptions))
+	for _, sub := range m.flightSessionSubscriptions {
+		subs = append(subs, sub)
+	}
+	m.mu.Unlock()
+
+	m.logger.Debug("[manager] Flight session changed", "from", oldState.String(), "to", newState.String())
+	for _, h := range hs {
+		handler := h // capture for closure
+		safeCallHandler(m.logger, "FlightSessionChangeHandler", func() {
+			handler(oldState, newState)
+		})
+	}
+	change := FlightSessionChange{OldState: oldState, NewState: newState}
+	for _, sub := range subs {
+		sub.closeMu.Lock()
+		if !sub.closed.Load() {
+			select {
+			case sub.ch <- change:
+			default:
+				m.logger.Debug("[manager] Flight session subscription channel full, dropping change")
+			}
+		}
+		sub.closeMu.Unlock()
+	}
+}
+
+// resetFlightSession returns the session to FlightSessionUnknown after the
+// connection ends.
+func (m *Instance) resetFlightSession() {
+	m.updateFlightSession(func(FlightSession, bool) FlightSession {
+		return FlightSessionUnknown
+	}, false)
+	m.mu.Lock()
+	m.flightSessionFlowSeen = false
+	m.mu.Unlock()
+}
+
+// flightSessionAfterFlow returns the session state following a flow event.
+func flightSessionAfterFlow(cur FlightSession, event types.SIMCONNECT_FLOW_EVENT, fltPath string) FlightSession {
+	switch event {
+	case types.SIMCONNECT_FLOW_EVENT_FLT_LOAD:
+		return FlightSessionLoading
+	case types.SIMCONNECT_FLOW_EVENT_FLT_LOADED:
+		if isMainMenuFlight(fltPath) {
+			return FlightSessionInMenu
+		}
+		return FlightSessionBriefing
+	case types.SIMCONNECT_FLOW_EVENT_FLIGHT_START:
+		return FlightSessionInFlight
+	case types.SIMCONNECT_FLOW_EVENT_FLIGHT_END,
+		types.SIMCONNECT_FLOW_EVENT_BACK_TO_MAIN_MENU:
+		return FlightSessionReturningToMenu
+	}
+	return cur
+}
+
+// flightSessionAfterFlightLoaded returns the session state following a
+// FlightLoaded system event.
+func flightSessionAfterFlightLoaded(cur FlightSession, filename string) FlightSession {
+	if isMainMenuFlight(filename) {
+		return FlightSessionInMenu
+	}
+	if cur == FlightSessionInFlight {
+		return cur // a situation loaded mid-flight
+	}
+	return FlightSessionBriefing
+}
+
+// flightSessionAfterSim returns the session state following a Sim system
+// event. Without flow events, SimStart after a flight has loaded is the
+// only sign the user started flying.
+func flightSessionAfterSim(cur FlightSession, flowSeen, running bool) FlightSession {
+	if flowSeen || !running {
+		return cur
+	}
+	if cur == FlightSessionBriefing || cur == FlightSessionLoading {
+		return FlightSessionInFlight
+	}
+	return cur
+}
+
+// isMainMenuFlight reports whether path is the flight the simulator loads
+// for its main menu.
+func isMainMenuFlight(path string) bool {
+	return strings.Contains(strings.ToLower(path), "mainmenu")
+}
@@ -0,0 +1,89 @@
+//go:build windows
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// newFlowEventMessage returns an unpooled FLOW_EVENT message.
+func newFlowEventMessage(event types.SIMCONNECT_FLOW_EVENT, fltPath string) engine.Message {
+	ev := &types.SIMCONNECT_RECV_FLOW_EVENT{FlowEvent: event}
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_FLOW_EVENT)
+	copy(ev.FltPath[:], fltPath)
+	return engine.Message{SIMCONNECT_RECV: &ev.SIMCONNECT_RECV}
+}
+
+func TestFlightSessionTransitions(t *testing.T) {
+	cases := []struct {
+		name string
+		got  FlightSession
+		want FlightSession
+	}{
+		{"flt load", flightSessionAfterFlow(FlightSessionInMenu, types.SIMCONNECT_FLOW_EVENT_FLT_LOAD, ""), FlightSessionLoading},
+		{"main menu loaded", flightSessionAfterFlow(FlightSessionLoading, types.SIMCONNECT_FLOW_EVENT_FLT_LOADED, `flights\other\MainMenu.FLT`), FlightSessionInMenu},
+		{"flight loaded", flightSessionAfterFlow(FlightSessionLoading, types.SIMCONNECT_FLOW_EVENT_FLT_LOADED, "LFPG.FLT"), FlightSessionBriefing},
+		{"flight start", flightSessionAfterFlow(FlightSessionBriefing, types.SIMCONNECT_FLOW_EVENT_FLIGHT_START, ""), FlightSessionInFlight},
+		{"back to menu", flightSessionAfterFlow(FlightSessionInFlight, types.SIMCONNECT_FLOW_EVENT_BACK_TO_MAIN_MENU, ""), FlightSessionReturningToMenu},
+		{"teleport ignored", flightSessionAfterFlow(FlightSessionInFlight, types.SIMCONNECT_FLOW_EVENT_TELEPORT_DONE, ""), FlightSessionInFlight},
+		{"FlightLoaded menu", flightSessionAfterFlightLoaded(FlightSessionReturningToMenu, "MainMenu.FLT"), FlightSessionInMenu},
+		{"FlightLoaded flight", flightSessionAfterFlightLoaded(FlightSessionInMenu, "LFPG.FLT"), FlightSessionBriefing},
+		{"FlightLoaded in flight", flightSessionAfterFlightLoaded(FlightSessionInFlight, "LFPG.FLT"), FlightSessionInFlight},
+		{"SimStart without flow", flightSessionAfterSim(FlightSessionBriefing, false, true), FlightSessionInFlight},
+		{"SimStart with flow", flightSessionAfterSim(FlightSessionBriefing, true, true), FlightSessionBriefing},
+		{"SimStop", flightSessionAfterSim(FlightSessionInFlight, false, false), FlightSessionInFlight},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestFlightSessionFromFlowEvents(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.flightSessionSubscriptions = make(map[string]*flightSessionSubscription)
+
+	var flows []types.SIMCONNECT_FLOW_EVENT
+	m.OnFlowEvent(func(event types.SIMCONNECT_FLOW_EVENT, fltPath string) {
+		flows = append(flows, event)
+	})
+	var changes []FlightSession
+	m.OnFlightSessionChange(func(oldState, newState FlightSession) {
+		changes = append(changes, newState)
+	})
+	sub := m.SubscribeFlightSessionChange("", 8)
+	defer sub.Unsubscribe()
+	if !m.flowEventsWanted {
+		t.Fatalf("registering flow handlers must enable flow events")
+	}
+
+	m.processFlowEvent(newFlowEventMessage(types.SIMCONNECT_FLOW_EVENT_FLT_LOAD, "LFPG.FLT"))
+	m.processFlowEvent(newFlowEventMessage(types.SIMCONNECT_FLOW_EVENT_FLT_LOADED, "LFPG.FLT"))
+	m.processFlowEvent(newFlowEventMessage(types.SIMCONNECT_FLOW_EVENT_FLIGHT_START, ""))
+
+	want := []FlightSession{FlightSessionLoading, FlightSessionBriefing, FlightSessionInFlight}
+	if len(flows) != 3 || len(changes) != len(want) {
+		t.Fatalf("flows %v, changes %v", flows, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("changes = %v, want %v", changes, want)
+		}
+		if got := <-sub.FlightSessionChanges(); got.NewState != want[i] {
+			t.Fatalf("subscription change %d = %+v, want %s", i, got, want[i])
+		}
+	}
+	if m.FlightSession() != FlightSessionInFlight {
+		t.Fatalf("FlightSession() = %s", m.FlightSession())
+	}
+
+	m.resetFlightSession()
+	if m.FlightSession() != FlightSessionUnknown || m.flightSessionFlowSeen {
+		t.Fatalf("session not reset after disconnect")
+	}
+}
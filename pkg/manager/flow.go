@@ -3,35 +3,130 @@
 
 package manager
 
+import (
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/handlers"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
 // SubscribeToFlowEvent subscribes to all simulator flow events.
 // Once subscribed, SIMCONNECT_RECV_FLOW_EVENT messages are delivered to
 // all active message subscriptions and OnMessage handlers.
 //
-// Important: there is no automatic re-subscription on reconnect — it is the
-// caller's responsibility to call SubscribeToFlowEvent again after a
-// reconnection event if persistent flow event delivery is required.
+// A successful call also enables flow events for later connections: the
+// manager subscribes again after every reconnect until UnsubscribeFromFlowEvent
+// is called.
 //
 // Note: MSFS 2024 only — returns an error on MSFS 2020.
 // Returns ErrNotConnected if not connected to the simulator.
 func (m *Instance) SubscribeToFlowEvent() error {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
 	if m.engine == nil {
+		m.mu.RUnlock()
 		return ErrNotConnected
 	}
-	return m.engine.SubscribeToFlowEvent()
+	err := m.engine.SubscribeToFlowEvent()
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.flowEventsWanted = true
+	m.mu.Unlock()
+	return nil
 }
 
 // UnsubscribeFromFlowEvent cancels the active flow event subscription.
-// After this call, SIMCONNECT_RECV_FLOW_EVENT messages will no longer be delivered.
+// After this call, SIMCONNECT_RECV_FLOW_EVENT messages will no longer be delivered
+// and the manager stops re-subscribing after reconnects.
 //
 // Note: MSFS 2024 only — returns an error on MSFS 2020.
 // Returns ErrNotConnected if not connected to the simulator.
 func (m *Instance) UnsubscribeFromFlowEvent() error {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.flowEventsWanted = false
 	if m.engine == nil {
 		return ErrNotConnected
 	}
 	return m.engine.UnsubscribeFromFlowEvent()
 }
+
+// OnFlowEvent registers a callback invoked for every simulator flow event.
+// Registering a handler enables flow events: the manager subscribes now if
+// connected and again after every reconnect.
+//
+// Note: MSFS 2024 only — flow events are never delivered by MSFS 2020.
+func (m *Instance) OnFlowEvent(handler FlowEventHandler) string {
+	id := handlers.RegisterFlowEventHandler(&m.mu, &m.flowEventHandlers, handler, m.logger)
+	m.enableFlowEvents()
+	return id
+}
+
+// RemoveFlowEvent removes a previously registered FlowEvent handler.
+func (m *Instance) RemoveFlowEvent(id string) error {
+	return handlers.RemoveFlowEventHandler(&m.mu, &m.flowEventHandlers, id, m.logger)
+}
+
+// SubscribeOnFlowEvent returns a subscription that receives raw engine.Message
+// for flow events. Use msg.AsFlowEvent() to decode them. Like OnFlowEvent, it
+// enables flow events across reconnects.
+//
+// Note: MSFS 2024 only — flow events are never delivered by MSFS 2020.
+func (m *Instance) SubscribeOnFlowEvent(id string, bufferSize int) Subscription {
+	if id == "" {
+		id = handlers.GenerateUUID()
+	}
+	sub := m.SubscribeWithType(id+"-flow", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_FLOW_EVENT})
+	m.enableFlowEvents()
+	return sub
+}
+
+// enableFlowEvents marks flow events as wanted and subscribes to them if the
+// connection is already open. Wanted flow events are subscribed again on
+// every OPEN by registerSimStateSubscriptions.
+func (m *Instance) enableFlowEvents() {
+	m.mu.Lock()
+	if m.flowEventsWanted {
+		m.mu.Unlock()
+		return
+	}
+	m.flowEventsWanted = true
+	client := m.engine
+	open := m.state == StateAvailable
+	m.mu.Unlock()
+
+	if client != nil && open {
+		if err := client.SubscribeToFlowEvent(); err != nil {
+			m.logger.Error("[manager] Failed to subscribe to flow events", "error", err)
+		}
+	}
+}
+
+// processFlowEvent handles SIMCONNECT_RECV_ID_FLOW_EVENT messages.
+func (m *Instance) processFlowEvent(msg engine.Message) {
+	ev := msg.AsFlowEvent()
+	if ev == nil {
+		return
+	}
+	event := ev.FlowEvent
+	fltPath := engine.BytesToString(ev.FltPath[:])
+	m.logger.Debug("[manager] Flow event", "event", event.String(), "flt", fltPath)
+
+	m.mu.RLock()
+	hs := make([]FlowEventHandler, len(m.flowEventHandlers))
+	for i, e := range m.flowEventHandlers {
+		hs[i] = e.Fn.(FlowEventHandler)
+	}
+	m.mu.RUnlock()
+	for _, h := range hs {
+		handler := h // capture for closure
+		safeCallHandler(m.logger, "FlowEventHandler", func() {
+			handler(event, fltPath)
+		})
+	}
+
+	m.updateFlightSession(func(cur FlightSession, _ bool) FlightSession {
+		return flightSessionAfterFlow(cur, event, fltPath)
+	}, true)
+}
@@ -27,12 +27,28 @@ func (m *Instance) RemoveConnectionStateChange(id string) error {
 // OnMessage registers a callback to be invoked when a message is received.
 // Returns a unique id that can be used to remove the handler via RemoveMessage.
 func (m *Instance) OnMessage(handler MessageHandler) string {
-	return handlers.RegisterMessageHandler(&m.mu, &m.messageHandlers, handler, m.logger)
+	id := handlers.RegisterMessageHandler(&m.mu, &m.messageHandlers, handler, m.logger)
+	m.publishMessageHandlers()
+	return id
 }
 
 // RemoveMessage removes a previously registered message handler by id.
 func (m *Instance) RemoveMessage(id string) error {
-	return handlers.RemoveMessageHandler(&m.mu, &m.messageHandlers, id, m.logger)
+	err := handlers.RemoveMessageHandler(&m.mu, &m.messageHandlers, id, m.logger)
+	m.publishMessageHandlers()
+	return err
+}
+
+// publishMessageHandlers copies the registered message handlers into the
+// snapshot read by the dispatcher.
+func (m *Instance) publishMessageHandlers() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	snap := make([]MessageHandler, len(m.messageHandlers))
+	for i, e := range m.messageHandlers {
+		snap[i] = e.Fn.(MessageHandler)
+	}
+	m.messageHandlersSnap.Store(&snap)
 }
 
 // OnOpen registers a callback to be invoked when the simulator connection opens.
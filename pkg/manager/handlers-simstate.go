@@ -4,7 +4,6 @@
 package manager
 
 import (
-	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager/internal/handlers"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
@@ -40,14 +39,8 @@ func (m *Instance) SubscribeOnPause(id string, bufferSize int) Subscription {
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.pauseEventID)
-	}
-	return m.SubscribeWithFilter(id+"-pause", bufferSize, filter)
+	return m.SubscribeWithType(id+"-pause", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.pauseEventID)))
 }
 
 // OnSimRunning registers a callback invoked when the simulator running state changes.
@@ -65,12 +58,6 @@ func (m *Instance) SubscribeOnSimRunning(id string, bufferSize int) Subscription
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.simEventID)
-	}
-	return m.SubscribeWithFilter(id+"-simrunning", bufferSize, filter)
+	return m.SubscribeWithType(id+"-simrunning", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.simEventID)))
 }
@@ -4,7 +4,6 @@
 package manager
 
 import (
-	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager/internal/handlers"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
@@ -14,14 +13,8 @@ func (m *Instance) SubscribeOnCrashed(id string, bufferSize int) Subscription {
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.crashedEventID)
-	}
-	return m.SubscribeWithFilter(id+"-crashed", bufferSize, filter)
+	return m.SubscribeWithType(id+"-crashed", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.crashedEventID)))
 }
 
 // SubscribeOnCrashReset returns a subscription for CrashReset events
@@ -29,14 +22,8 @@ func (m *Instance) SubscribeOnCrashReset(id string, bufferSize int) Subscription
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.crashResetEventID)
-	}
-	return m.SubscribeWithFilter(id+"-crashreset", bufferSize, filter)
+	return m.SubscribeWithType(id+"-crashreset", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.crashResetEventID)))
 }
 
 // SubscribeOnSoundEvent returns a subscription for Sound events
@@ -44,14 +31,8 @@ func (m *Instance) SubscribeOnSoundEvent(id string, bufferSize int) Subscription
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.soundEventID)
-	}
-	return m.SubscribeWithFilter(id+"-sound", bufferSize, filter)
+	return m.SubscribeWithType(id+"-sound", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.soundEventID)))
 }
 
 // OnCrashed registers a callback invoked when a Crashed system event arrives.
@@ -99,14 +80,8 @@ func (m *Instance) SubscribeOnView(id string, bufferSize int) Subscription {
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.viewEventID)
-	}
-	return m.SubscribeWithFilter(id+"-view", bufferSize, filter)
+	return m.SubscribeWithType(id+"-view", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.viewEventID)))
 }
 
 // OnFlightPlanDeactivated registers a callback invoked when the active flight plan is deactivated.
@@ -124,12 +99,6 @@ func (m *Instance) SubscribeOnFlightPlanDeactivated(id string, bufferSize int) S
 	if id == "" {
 		id = handlers.GenerateUUID()
 	}
-	filter := func(msg engine.Message) bool {
-		if types.SIMCONNECT_RECV_ID(msg.DwID) != types.SIMCONNECT_RECV_ID_EVENT {
-			return false
-		}
-		ev := msg.AsEvent()
-		return ev != nil && ev.UEventID == types.DWORD(m.flightPlanDeactivatedEventID)
-	}
-	return m.SubscribeWithFilter(id+"-flightplandeactivated", bufferSize, filter)
+	return m.SubscribeWithType(id+"-flightplandeactivated", bufferSize, []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT},
+		WithRequestIDs(uint32(m.flightPlanDeactivatedEventID)))
 }
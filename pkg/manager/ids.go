@@ -4,19 +4,19 @@ const (
 	// Manager ID Allocation Strategy:
 	// ==============================
 	//
-	// The manager uses high-number IDs near the end of the uint32 scale (999999900-999999999) to provide
+	// The manager uses high-number IDs near the end of the uint32 scale (999996975-999999999) to provide
 	// maximum flexibility for user-defined requests. This strategy:
 	//
 	// RATIONALE:
 	// - Reserves a dedicated, easily-identifiable range for internal manager operations
-	// - Provides plenty of space (999,999,899 IDs) for user-defined request/definition IDs (1-999999899)
+	// - Provides plenty of space (999,996,974 IDs) for user-defined request/definition IDs (1-999996974)
 	// - Avoids collisions with typical application ID assignments (which often start from 1)
 	// - Follows the principle of defensive ID allocation by using very high numbers
 	// - Simplifies ID range validation and conflict detection
 	//
 	// USAGE GUIDELINES FOR END USERS:
-	// - Use IDs from 1 to 999999899 for your own data definitions and requests
-	// - NEVER use IDs in the 999999900-999999999 range (reserved for manager)
+	// - Use IDs from 1 to 999996974 for your own data definitions and requests
+	// - NEVER use IDs in the 999996975-999999999 range (reserved for manager)
 	// - Consider organizing your IDs in logical sub-ranges if managing multiple concurrent requests
 	// - Example: Use 1000-1999 for aircraft data, 2000-2999 for environment data, etc.
 	// - Use the IsValidUserID() function to validate your chosen IDs before use
@@ -52,10 +52,55 @@ const (
 	CustomEventIDMin uint32 = 999999850
 	CustomEventIDMax uint32 = 999999886
 
+	// Key Event Range — client event IDs allocated by Events() for named key events
+	KeyEventIDMin uint32 = 999998000
+	KeyEventIDMax uint32 = 999999799
+
+	// Key Event Listen Group — notification group used by Events().Listen
+	KeyEventListenGroupID uint32 = 999999800
+
+	// Interceptor Group Range — notification groups allocated by Events().Intercept
+	InterceptorGroupIDMin uint32 = 999999801
+	InterceptorGroupIDMax uint32 = 999999849
+
+	// Input Event Request Range — request IDs rotated by InputEvents() for
+	// enumeration and value requests
+	InputEventRequestIDMin uint32 = 999999902
+	InputEventRequestIDMax uint32 = 999999931
+
+	// Facility Request Range — request IDs rotated by Facilities() for
+	// facility data requests
+	FacilityRequestIDMin uint32 = 999999932
+	FacilityRequestIDMax uint32 = 999999963
+
+	// Nearby Facility Request Range — fixed request ID pairs used by
+	// NearbyFacilities() for SubscribeToFacilitiesEX1, entered and left per
+	// facility.Kind
+	NearbyFacilityRequestIDMin uint32 = 999999964
+	NearbyFacilityRequestIDMax uint32 = 999999971
+
+	// Facility Definition Range — facility definitions registered by
+	// Facilities(), one per combination of facility.Include flags
+	FacilityDefinitionIDMin uint32 = 999997488
+	FacilityDefinitionIDMax uint32 = 999997999
+
+	// Fleet Tracking IDs — the data definition and the per-aircraft request
+	// IDs used by Fleet().Track
+	FleetTrackDefinitionID uint32 = 999996975
+	FleetTrackRequestIDMin uint32 = 999996976
+	FleetTrackRequestIDMax uint32 = 999997487
+
 	// ID Range Documentation:
-	// User-Available Range: 1 - 999999899 (999,999,899 IDs available for user requests)
-	// Manager Reserved Range: 999999900 - 999999999 (100 IDs reserved for manager operations)
+	// User-Available Range: 1 - 999996974 (999,996,974 IDs available for user requests)
+	// Manager Reserved Range: 999996975 - 999999999 (3,025 IDs reserved for manager operations)
 	// Custom Event Range: 999999850 - 999999886 (37 IDs for custom system events)
+	// Key Event Range: 999998000 - 999999799 (1800 IDs for named key events)
+	// Input Event Request Range: 999999902 - 999999931 (30 IDs for input event requests)
+	// Facility Request Range: 999999932 - 999999963 (32 IDs for facility requests)
+	// Nearby Facility Request Range: 999999964 - 999999971 (8 IDs for nearby facility subscriptions)
+	// Facility Definition Range: 999997488 - 999997999 (512 IDs for facility definitions)
+	// Fleet Tracking Definition: 999996975 (1 ID for the fleet telemetry dataset)
+	// Fleet Tracking Request Range: 999996976 - 999997487 (512 IDs for fleet telemetry requests)
 )
 
 // IDRange defines the boundaries for ID allocation
@@ -66,8 +111,8 @@ var IDRange = struct {
 	ManagerMax uint32
 }{
 	UserMin:    1,
-	UserMax:    999999899,
-	ManagerMin: 999999900,
+	UserMax:    999996974,
+	ManagerMin: 999996975,
 	ManagerMax: 999999999,
 }
 
@@ -0,0 +1,35 @@
+package manager
+
+import "testing"
+
+func TestReservedRangesOutsideUserRange(t *testing.T) {
+	ranges := []struct {
+		name     string
+		min, max uint32
+	}{
+		{"fleet track definition", FleetTrackDefinitionID, FleetTrackDefinitionID},
+		{"fleet track requests", FleetTrackRequestIDMin, FleetTrackRequestIDMax},
+		{"facility definitions", FacilityDefinitionIDMin, FacilityDefinitionIDMax},
+		{"key events", KeyEventIDMin, KeyEventIDMax},
+		{"key event listen group", KeyEventListenGroupID, KeyEventListenGroupID},
+		{"interceptor groups", InterceptorGroupIDMin, InterceptorGroupIDMax},
+		{"custom events", CustomEventIDMin, CustomEventIDMax},
+		{"input event requests", InputEventRequestIDMin, InputEventRequestIDMax},
+		{"facility requests", FacilityRequestIDMin, FacilityRequestIDMax},
+		{"nearby facility requests", NearbyFacilityRequestIDMin, NearbyFacilityRequestIDMax},
+		{"pause event", PauseEventID, PauseEventID},
+	}
+	for _, r := range ranges {
+		for _, id := range []uint32{r.min, r.max} {
+			if IsValidUserID(id) {
+				t.Errorf("%s: ID %d accepted by IsValidUserID", r.name, id)
+			}
+			if !IsManagerID(id) {
+				t.Errorf("%s: ID %d not reported by IsManagerID", r.name, id)
+			}
+		}
+	}
+	if IDRange.ManagerMin != IDRange.UserMax+1 {
+		t.Errorf("ManagerMin %d does not follow UserMax %d", IDRange.ManagerMin, IDRange.UserMax)
+	}
+}
@@ -0,0 +1,558 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+var (
+	// ErrInputEventNotFound is returned when a name does not match any input
+	// event enumerated for the loaded aircraft.
+	ErrInputEventNotFound = errors.New("manager: input event not found")
+
+	// ErrInputEventType is returned when a value does not match the type of
+	// the input event it is written to.
+	ErrInputEventType = errors.New("manager: input event value type mismatch")
+)
+
+// inputEventRequestTimeout bounds the requests InputEvents issues on its own,
+// such as the enumeration behind Set and the re-subscription after a reconnect.
+const inputEventRequestTimeout = 10 * time.Second
+
+// InputEventValue is a typed input event value. Double is set for
+// SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE events, String for
+// SIMCONNECT_INPUT_EVENT_TYPE_STRING events.
+type InputEventValue struct {
+	Type   types.SIMCONNECT_INPUT_EVENT_TYPE
+	Double float64
+	String string
+}
+
+// InputEventUpdate is a value change delivered by InputEvents.Subscribe.
+type InputEventUpdate struct {
+	Name  string
+	Value InputEventValue
+}
+
+// InputEventSubscription is a typed subscription for input event changes
+type InputEventSubscription interface {
+	ID() string
+	Updates() <-chan InputEventUpdate
+	Done() <-chan struct{}
+	Unsubscribe()
+}
+
+// InputEvents reads, writes and subscribes to MSFS 2024 input events by name.
+//
+// Descriptors are enumerated on first use and cached until the connection
+// drops. Subscriptions survive reconnects: on every connect the descriptors
+// are enumerated again and each subscribed name is re-subscribed under its
+// current hash.
+type InputEvents struct {
+	m *Instance
+
+	mu         sync.Mutex
+	client     engine.Client
+	gen        uint64             // bumped on every setClient
+	cancel     context.CancelFunc // cancels the re-subscription of the current connection
+	byName     map[string]engine.InputEventDescriptor
+	loading    chan struct{}     // closed when the running enumeration finishes
+	subscribed map[string]int    // name → number of Subscribe references
+	active     map[uint64]string // hash → name subscribed on the current connection
+	nextReqID  uint32
+}
+
+// newInputEvents constructs an unconnected InputEvents service.
+func newInputEvents(m *Instance) *InputEvents {
+	return &InputEvents{
+		m:          m,
+		subscribed: make(map[string]int),
+		active:     make(map[uint64]string),
+		nextReqID:  InputEventRequestIDMin,
+	}
+}
+
+// InputEvents returns the manager's input event service.
+func (m *Instance) InputEvents() *InputEvents {
+	return m.inputEvents
+}
+
+// Descriptors returns every input event of the loaded aircraft, enumerating
+// them first if the cache is empty.
+// Returns ErrNotConnected if not connected to the simulator.
+func (s *InputEvents) Descriptors(ctx context.Context) ([]engine.InputEventDescriptor, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]engine.InputEventDescriptor, 0, len(s.byName))
+	for _, d := range s.byName {
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// Describe returns the descriptor of the named input event. Names are
+// matched case-insensitively.
+func (s *InputEvents) Describe(ctx context.Context, name string) (engine.InputEventDescriptor, error) {
+	if err := s.load(ctx); err != nil {
+		return engine.InputEventDescriptor{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d, ok := s.byName[normalizeEventName(name)]
+	if !ok {
+		return engine.InputEventDescriptor{}, fmt.Errorf("%w: %s", ErrInputEventNotFound, name)
+	}
+	return d, nil
+}
+
+// Hash resolves the name of an input event to its hash.
+func (s *InputEvents) Hash(ctx context.Context, name string) (uint64, error) {
+	d, err := s.Describe(ctx, name)
+	return d.Hash, err
+}
+
+// Refresh drops the cached descriptors and enumerates them again, e.g. after
+// the user switched aircraft.
+func (s *InputEvents) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.byName = nil
+	s.mu.Unlock()
+	return s.load(ctx)
+}
+
+// Get requests the current value of the named input event and waits for the
+// response or for ctx to end.
+// Returns ErrNotConnected if not connected to the simulator.
+func (s *InputEvents) Get(ctx context.Context, name string) (InputEventValue, error) {
+	d, err := s.Describe(ctx, name)
+	if err != nil {
+		return InputEventValue{}, err
+	}
+
+	s.mu.Lock()
+	client := s.client
+	reqID := s.requestIDLocked()
+	s.mu.Unlock()
+	if client == nil {
+		return InputEventValue{}, ErrNotConnected
+	}
+
+	sub := s.m.SubscribeWithType(subscriptions.GenerateID("")+"-inputevent-get", 1,
+		[]types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_GET_INPUT_EVENT}, WithRequestIDs(reqID))
+	defer sub.Unsubscribe()
+	if err := client.GetInputEvent(reqID, d.Hash); err != nil {
+		return InputEventValue{}, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return InputEventValue{}, ctx.Err()
+		case <-sub.Done():
+			return InputEventValue{}, ErrNotConnected
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return InputEventValue{}, ErrNotConnected
+			}
+			v, ok := decodeGetInputEvent(msg)
+			if ok {
+				return v, nil
+			}
+		}
+	}
+}
+
+// Set writes value to the named input event. float64, float32 and int
+// values are accepted for DOUBLE events, string values for STRING events.
+// Descriptors are enumerated first if the cache is empty.
+// Returns ErrNotConnected if not connected to the simulator.
+func (s *InputEvents) Set(name string, value any) error {
+	ctx, cancel := context.WithTimeout(s.m.ctx, inputEventRequestTimeout)
+	defer cancel()
+	d, err := s.Describe(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+	if client == nil {
+		return ErrNotConnected
+	}
+
+	switch d.Type {
+	case types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE:
+		var f float64
+		switch v := value.(type) {
+		case float64:
+			f = v
+		case float32:
+			f = float64(v)
+		case int:
+			f = float64(v)
+		default:
+			return fmt.Errorf("%w: %s expects a number, got %T", ErrInputEventType, d.Name, value)
+		}
+		return client.SetInputEventDouble(d.Hash, f)
+	case types.SIMCONNECT_INPUT_EVENT_TYPE_STRING:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: %s expects a string, got %T", ErrInputEventType, d.Name, value)
+		}
+		return client.SetInputEventString(d.Hash, str)
+	}
+	return fmt.Errorf("%w: %s has no settable type", ErrInputEventType, d.Name)
+}
+
+// Subscribe returns a subscription delivering value changes of the named
+// input events. When connected, the names are resolved immediately and an
+// unknown name returns ErrInputEventNotFound. Subscribing may also start
+// before the connection is established; the names are then resolved and
+// subscribed on every (re)connect.
+func (s *InputEvents) Subscribe(ctx context.Context, names ...string) (InputEventSubscription, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("manager: Subscribe requires at least one input event name")
+	}
+	keys := make([]string, 0, len(names))
+	for _, raw := range names {
+		keys = append(keys, normalizeEventName(raw))
+	}
+
+	s.mu.Lock()
+	connected := s.client != nil
+	s.mu.Unlock()
+	if connected {
+		if err := s.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	if s.client != nil && s.byName != nil {
+		for i, name := range keys {
+			if _, ok := s.byName[name]; !ok {
+				s.mu.Unlock()
+				return nil, fmt.Errorf("%w: %s", ErrInputEventNotFound, names[i])
+			}
+		}
+	}
+	for _, name := range keys {
+		s.subscribed[name]++
+		if s.client != nil && s.byName != nil {
+			if err := s.activateLocked(name); err != nil {
+				s.m.logger.Error("[manager] Failed to subscribe to input event", "event", name, "error", err)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	id := subscriptions.GenerateID("")
+	bufferSize := subscriptions.DefaultBufferSize
+	msgSub := s.m.SubscribeWithType(id+"-inputevents", bufferSize,
+		[]types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT})
+	is := &inputEventSubscription{id: id, sub: msgSub, ch: make(chan InputEventUpdate, bufferSize), done: make(chan struct{}), events: s, names: keys}
+
+	go func() {
+		defer is.Unsubscribe()
+		for {
+			select {
+			case <-s.m.ctx.Done():
+				return
+			case <-msgSub.Done():
+				return
+			case msg, ok := <-msgSub.Messages():
+				if !ok {
+					return
+				}
+				if up, ok := s.decode(msg); ok && is.wants(up.Name) {
+					is.deliver(up)
+				}
+			}
+		}
+	}()
+	return is, nil
+}
+
+// decode converts a SUBSCRIBE_INPUT_EVENT message into an update for an
+// input event subscribed on the current connection. It consumes msg.
+func (s *InputEvents) decode(msg engine.Message) (InputEventUpdate, bool) {
+	defer msg.Release()
+	recv := msg.AsSubscribeInputEvent()
+	if recv == nil {
+		return InputEventUpdate{}, false
+	}
+	s.mu.Lock()
+	name, ok := s.active[engine.SubscribeInputEventHash(recv)]
+	s.mu.Unlock()
+	if !ok {
+		return InputEventUpdate{}, false
+	}
+
+	up := InputEventUpdate{Name: name, Value: InputEventValue{Type: recv.EType}}
+	if f, ok := engine.SubscribeInputEventValueAsFloat64(recv); ok {
+		up.Value.Double = f
+	} else if str, ok := engine.SubscribeInputEventValueAsString(recv); ok {
+		up.Value.String = str
+	}
+	return up, true
+}
+
+// decodeGetInputEvent converts a GET_INPUT_EVENT message into a value. It
+// consumes msg.
+func decodeGetInputEvent(msg engine.Message) (InputEventValue, bool) {
+	defer msg.Release()
+	recv := msg.AsGetInputEvent()
+	if recv == nil {
+		return InputEventValue{}, false
+	}
+	v := InputEventValue{Type: recv.Type}
+	if f, ok := engine.InputEventValueAsFloat64(recv); ok {
+		v.Double = f
+	} else if str, ok := engine.InputEventValueAsString(recv); ok {
+		v.String = str
+	}
+	return v, true
+}
+
+// load enumerates the descriptors unless they are cached. Concurrent callers
+// share a single enumeration.
+func (s *InputEvents) load(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		if s.byName != nil {
+			s.mu.Unlock()
+			return nil
+		}
+		if s.client == nil {
+			s.mu.Unlock()
+			return ErrNotConnected
+		}
+		if s.loading != nil {
+			wait := s.loading
+			s.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wait:
+			}
+			continue
+		}
+
+		done := make(chan struct{})
+		s.loading = done
+		client, gen, reqID := s.client, s.gen, s.requestIDLocked()
+		s.mu.Unlock()
+
+		descs, err := s.enumerate(ctx, client, reqID)
+
+		s.mu.Lock()
+		if err == nil && gen == s.gen {
+			s.byName = make(map[string]engine.InputEventDescriptor, len(descs))
+			for _, d := range descs {
+				s.byName[normalizeEventName(d.Name)] = d
+			}
+		}
+		s.loading = nil
+		close(done)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// enumerate requests the descriptor list and assembles all of its pages.
+func (s *InputEvents) enumerate(ctx context.Context, client engine.Client, reqID uint32) ([]engine.InputEventDescriptor, error) {
+	sub := s.m.SubscribeWithType(subscriptions.GenerateID("")+"-inputevent-enum", subscriptions.DefaultBufferSize,
+		[]types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS}, WithRequestIDs(reqID))
+	defer sub.Unsubscribe()
+	if err := client.EnumerateInputEvents(reqID); err != nil {
+		return nil, err
+	}
+
+	var descs []engine.InputEventDescriptor
+	pages := make(map[uint32]bool)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-sub.Done():
+			return nil, ErrNotConnected
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return nil, ErrNotConnected
+			}
+			list := msg.AsEnumerateInputEvents()
+			if list == nil {
+				msg.Release()
+				continue
+			}
+			entry, outOf := uint32(list.DwEntryNumber), uint32(list.DwOutOf)
+			if !pages[entry] {
+				pages[entry] = true
+				descs = append(descs, engine.InputEventDescriptors(&msg)...)
+			}
+			msg.Release()
+			if uint32(len(pages)) >= outOf {
+				return descs, nil
+			}
+		}
+	}
+}
+
+// activateLocked subscribes name on the current connection. Caller must
+// hold s.mu, and the client and descriptors must be set.
+func (s *InputEvents) activateLocked(name string) error {
+	d, ok := s.byName[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInputEventNotFound, name)
+	}
+	if _, ok := s.active[d.Hash]; ok {
+		return nil
+	}
+	if err := s.client.SubscribeInputEvent(d.Hash); err != nil {
+		return err
+	}
+	s.active[d.Hash] = name
+	return nil
+}
+
+// unsubscribe drops one Subscribe reference for each name and unsubscribes
+// input events nobody listens to any more.
+func (s *InputEvents) unsubscribe(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		s.subscribed[name]--
+		if s.subscribed[name] > 0 {
+			continue
+		}
+		delete(s.subscribed, name)
+		for hash, active := range s.active {
+			if active != name {
+				continue
+			}
+			delete(s.active, hash)
+			if s.client != nil {
+				if err := s.client.UnsubscribeInputEvent(hash); err != nil {
+					s.m.logger.Error("[manager] Failed to unsubscribe from input event", "event", name, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// setClient binds the service to a new connection, or detaches it when
+// client is nil. The descriptor cache is dropped either way; on connect the
+// descriptors are enumerated again in the background and every subscribed
+// name is re-subscribed.
+func (s *InputEvents) setClient(client engine.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.gen++
+	s.client = client
+	s.byName = nil
+	s.active = make(map[uint64]string)
+	if client == nil || len(s.subscribed) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.m.ctx, inputEventRequestTimeout)
+	s.cancel = cancel
+	go s.resubscribe(ctx, s.gen)
+}
+
+// resubscribe restores the subscriptions of connection gen.
+func (s *InputEvents) resubscribe(ctx context.Context, gen uint64) {
+	if err := s.load(ctx); err != nil {
+		s.m.logger.Error("[manager] Failed to enumerate input events after connect", "error", err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.gen || s.client == nil || s.byName == nil {
+		return
+	}
+	for name := range s.subscribed {
+		if err := s.activateLocked(name); err != nil {
+			s.m.logger.Error("[manager] Failed to restore input event subscription", "event", name, "error", err)
+		}
+	}
+}
+
+// requestIDLocked returns the next request ID from the input event range.
+// Caller must hold s.mu.
+func (s *InputEvents) requestIDLocked() uint32 {
+	id := s.nextReqID
+	s.nextReqID++
+	if s.nextReqID > InputEventRequestIDMax {
+		s.nextReqID = InputEventRequestIDMin
+	}
+	return id
+}
+
+type inputEventSubscription struct {
+	id      string
+	sub     Subscription
+	ch      chan InputEventUpdate
+	done    chan struct{}
+	events  *InputEvents
+	names   []string
+	closeMu sync.Mutex
+}
+
+func (s *inputEventSubscription) ID() string                       { return s.id }
+func (s *inputEventSubscription) Updates() <-chan InputEventUpdate { return s.ch }
+func (s *inputEventSubscription) Done() <-chan struct{}            { return s.done }
+
+// wants reports whether name is one of the subscription's input events.
+func (s *inputEventSubscription) wants(name string) bool {
+	return slices.Contains(s.names, name)
+}
+
+// deliver forwards up without blocking. It holds closeMu so a concurrent
+// Unsubscribe cannot close the channel mid-send.
+func (s *inputEventSubscription) deliver(up InputEventUpdate) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.sub == nil {
+		return
+	}
+	select {
+	case s.ch <- up:
+	default:
+		s.events.m.logger.Debug("[manager] Input event subscription channel full, dropping update")
+	}
+}
+
+func (s *inputEventSubscription) Unsubscribe() {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.sub == nil {
+		return // already closed
+	}
+	s.sub.Unsubscribe()
+	s.sub = nil
+	s.events.unsubscribe(s.names)
+	close(s.done)
+	close(s.ch)
+}
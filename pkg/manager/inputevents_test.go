@@ -0,0 +1,219 @@
+//go:build windows
+
+package manager
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// testInputEvent describes an input event served by fakeInputEventClient.
+type testInputEvent struct {
+	name  string
+	hash  uint64
+	typ   types.SIMCONNECT_INPUT_EVENT_TYPE
+	value float64
+}
+
+// fakeInputEventClient answers input event requests by forwarding the
+// simulator's responses straight into the manager. Methods not overridden
+// panic through the nil embedded interface.
+type fakeInputEventClient struct {
+	engine.Client
+	m      *Instance
+	events []testInputEvent
+
+	mu         sync.Mutex
+	enumerated int
+	set        map[uint64]float64
+	subscribed []uint64
+}
+
+func newFakeInputEventClient(m *Instance, events ...testInputEvent) *fakeInputEventClient {
+	return &fakeInputEventClient{m: m, events: events, set: make(map[uint64]float64)}
+}
+
+// EnumerateInputEvents replies with one page per descriptor.
+func (c *fakeInputEventClient) EnumerateInputEvents(requestID uint32) error {
+	c.mu.Lock()
+	c.enumerated++
+	c.mu.Unlock()
+	for i, ev := range c.events {
+		c.m.forward(newInputEventPage(requestID, uint32(i), uint32(len(c.events)), ev))
+	}
+	return nil
+}
+
+func (c *fakeInputEventClient) GetInputEvent(requestID uint32, hash uint64) error {
+	for _, ev := range c.events {
+		if ev.hash != hash {
+			continue
+		}
+		recv := &types.SIMCONNECT_RECV_GET_INPUT_EVENT{RequestID: types.DWORD(requestID), Type: ev.typ}
+		recv.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_GET_INPUT_EVENT)
+		binary.LittleEndian.PutUint64(recv.Value[:], math.Float64bits(ev.value))
+		c.m.forward(engine.Message{SIMCONNECT_RECV: &recv.SIMCONNECT_RECV})
+	}
+	return nil
+}
+
+func (c *fakeInputEventClient) SetInputEventDouble(hash uint64, value float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set[hash] = value
+	return nil
+}
+
+func (c *fakeInputEventClient) SubscribeInputEvent(hash uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribed = append(c.subscribed, hash)
+	return nil
+}
+
+func (c *fakeInputEventClient) UnsubscribeInputEvent(hash uint64) error {
+	return nil
+}
+
+func (c *fakeInputEventClient) subscriptions() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uint64(nil), c.subscribed...)
+}
+
+// newInputEventPage returns an unpooled ENUMERATE_INPUT_EVENTS page holding ev.
+func newInputEventPage(requestID, entry, outOf uint32, ev testInputEvent) engine.Message {
+	const stride = 1100
+	header := int(unsafe.Offsetof(types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS{}.RgData))
+	data := make([]byte, header+stride)
+	list := (*types.SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS)(unsafe.Pointer(&data[0]))
+	list.DwSize = types.DWORD(len(data))
+	list.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS)
+	list.DwRequestID = types.DWORD(requestID)
+	list.DwArraySize = 1
+	list.DwEntryNumber = types.DWORD(entry)
+	list.DwOutOf = types.DWORD(outOf)
+	copy(data[header:header+63], ev.name)
+	binary.LittleEndian.PutUint64(data[header+64:], ev.hash)
+	binary.LittleEndian.PutUint32(data[header+72:], uint32(ev.typ))
+	return engine.Message{SIMCONNECT_RECV: (*types.SIMCONNECT_RECV)(unsafe.Pointer(&data[0])), Size: uint32(len(data))}
+}
+
+// newInputEventUpdate returns an unpooled SUBSCRIBE_INPUT_EVENT message.
+func newInputEventUpdate(hash uint64, value float64) engine.Message {
+	recv := &types.SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT{EType: types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE}
+	recv.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT)
+	binary.LittleEndian.PutUint64(recv.HashBytes[:], hash)
+	binary.LittleEndian.PutUint64(recv.Value[:], math.Float64bits(value))
+	return engine.Message{SIMCONNECT_RECV: (*types.SIMCONNECT_RECV)(unsafe.Pointer(recv))}
+}
+
+var testInputEvents = []testInputEvent{
+	{name: "LIGHTING_LANDING_1", hash: 0x1000000000000001, typ: types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE, value: 1},
+	{name: "AUTOPILOT_MASTER", hash: 0x1000000000000002, typ: types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE, value: 0},
+}
+
+func TestInputEventsGetSet(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	s := newInputEvents(m)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := s.Get(ctx, "LIGHTING_LANDING_1"); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Get without connection = %v, want ErrNotConnected", err)
+	}
+
+	client := newFakeInputEventClient(m, testInputEvents...)
+	s.setClient(client)
+
+	descs, err := s.Descriptors(ctx)
+	if err != nil || len(descs) != 2 {
+		t.Fatalf("Descriptors = %v, %v; want both pages assembled", descs, err)
+	}
+	if hash, err := s.Hash(ctx, "lighting_landing_1"); err != nil || hash != testInputEvents[0].hash {
+		t.Fatalf("Hash = %x, %v", hash, err)
+	}
+
+	v, err := s.Get(ctx, "LIGHTING_LANDING_1")
+	if err != nil || v.Type != types.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE || v.Double != 1 {
+		t.Fatalf("Get = %+v, %v", v, err)
+	}
+	if _, err := s.Get(ctx, "NO_SUCH_EVENT"); !errors.Is(err, ErrInputEventNotFound) {
+		t.Fatalf("Get unknown = %v, want ErrInputEventNotFound", err)
+	}
+
+	if err := s.Set("AUTOPILOT_MASTER", 1); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("AUTOPILOT_MASTER", "on"); !errors.Is(err, ErrInputEventType) {
+		t.Fatalf("Set string on DOUBLE event = %v, want ErrInputEventType", err)
+	}
+	if client.set[testInputEvents[1].hash] != 1 {
+		t.Fatalf("SetInputEventDouble not called: %v", client.set)
+	}
+	if client.enumerated != 1 {
+		t.Fatalf("enumerated %d times, want descriptors cached after the first", client.enumerated)
+	}
+}
+
+func TestInputEventsSubscribe(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	s := newInputEvents(m)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Subscribing before the connection is established defers resolution.
+	sub, err := s.Subscribe(ctx, "LIGHTING_LANDING_1")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	hash := testInputEvents[0].hash
+	waitSubscribed := func(c *fakeInputEventClient) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for len(c.subscriptions()) == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if got := c.subscriptions(); len(got) != 1 || got[0] != hash {
+			t.Fatalf("subscribed hashes = %x, want [%x]", got, hash)
+		}
+	}
+
+	client := newFakeInputEventClient(m, testInputEvents...)
+	s.setClient(client)
+	waitSubscribed(client)
+
+	m.forward(newInputEventUpdate(testInputEvents[1].hash, 5)) // not subscribed
+	m.forward(newInputEventUpdate(hash, 0.5))
+	select {
+	case up := <-sub.Updates():
+		if up.Name != "LIGHTING_LANDING_1" || up.Value.Double != 0.5 {
+			t.Fatalf("got %+v", up)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for input event update")
+	}
+
+	// A reconnect enumerates again and restores the subscription.
+	s.setClient(nil)
+	reconnected := newFakeInputEventClient(m, testInputEvents...)
+	s.setClient(reconnected)
+	waitSubscribed(reconnected)
+
+	if _, err := s.Subscribe(ctx, "NO_SUCH_EVENT"); !errors.Is(err, ErrInputEventNotFound) {
+		t.Fatalf("Subscribe unknown = %v, want ErrInputEventNotFound", err)
+	}
+}
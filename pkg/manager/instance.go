@@ -7,9 +7,11 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 
 	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager/internal/instance"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
 	"github.com/mrlm-net/simconnect/pkg/traffic"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
@@ -80,6 +82,14 @@ type Instance struct {
 	pauseHandlers      []instance.PauseHandlerEntry
 	simRunningHandlers []instance.SimRunningHandlerEntry
 
+	// Flow events and the derived flight session
+	flowEventHandlers          []instance.FlowEventHandlerEntry
+	flowEventsWanted           bool // subscribe to flow events on every OPEN
+	flightSession              FlightSession
+	flightSessionFlowSeen      bool // a flow event arrived on the current connection
+	flightSessionHandlers      []instance.FlightSessionHandlerEntry
+	flightSessionSubscriptions map[string]*flightSessionSubscription
+
 	// Custom system events
 	customSystemEvents map[string]*instance.CustomSystemEvent
 	customEventIDAlloc uint32
@@ -87,28 +97,33 @@ type Instance struct {
 	// Request tracking
 	requestRegistry *RequestRegistry // Tracks active SimConnect requests for correlation with responses
 
+	// Copy-on-write views read by the dispatcher without taking mu. They are
+	// rebuilt under mu whenever message handlers or subscriptions change.
+	messageHandlersSnap atomic.Pointer[[]MessageHandler]
+	subsIndex           atomic.Pointer[subscriptions.Index[*subscription]]
+
 	// Pre-allocated slices to reduce GC pressure in hot path (reused per message)
-	handlersBuf []MessageHandler
-	subsBuf     []*subscription
+	subsBuf    []*subscription
+	forwardBuf []engine.Message // Messages of the current batch awaiting fan-out
 
 	// Pre-allocated buffers to reduce GC pressure (reused per notification)
-	stateHandlersBuf                     []ConnectionStateChangeHandler
-	simStateHandlersBuf                  []SimStateChangeHandler
-	openHandlersBuf                      []ConnectionOpenHandler
-	quitHandlersBuf                      []ConnectionQuitHandler
-	crashedHandlersBuf                   []CrashedHandler
-	crashResetHandlersBuf                []CrashResetHandler
-	soundEventHandlersBuf                []SoundEventHandler
-	viewHandlersBuf                      []ViewHandler
-	flightPlanDeactivatedHandlersBuf     []FlightPlanDeactivatedHandler
-	pauseHandlersBuf                     []PauseHandler
-	simRunningHandlersBuf                []SimRunningHandler
-	flightLoadedHandlersBuf              []FlightLoadedHandler
-	objectChangeHandlersBuf              []ObjectChangeHandler
-	stateSubsBuf                         []*connectionStateSubscription
-	simStateSubsBuf                      []*simStateSubscription
-	openSubsBuf                          []*connectionOpenSubscription
-	quitSubsBuf                          []*connectionQuitSubscription
+	stateHandlersBuf                 []ConnectionStateChangeHandler
+	simStateHandlersBuf              []SimStateChangeHandler
+	openHandlersBuf                  []ConnectionOpenHandler
+	quitHandlersBuf                  []ConnectionQuitHandler
+	crashedHandlersBuf               []CrashedHandler
+	crashResetHandlersBuf            []CrashResetHandler
+	soundEventHandlersBuf            []SoundEventHandler
+	viewHandlersBuf                  []ViewHandler
+	flightPlanDeactivatedHandlersBuf []FlightPlanDeactivatedHandler
+	pauseHandlersBuf                 []PauseHandler
+	simRunningHandlersBuf            []SimRunningHandler
+	flightLoadedHandlersBuf          []FlightLoadedHandler
+	objectChangeHandlersBuf          []ObjectChangeHandler
+	stateSubsBuf                     []*connectionStateSubscription
+	simStateSubsBuf                  []*simStateSubscription
+	openSubsBuf                      []*connectionOpenSubscription
+	quitSubsBuf                      []*connectionQuitSubscription
 
 	// Current engine instance (recreated on each connection)
 	engine *engine.Engine
@@ -116,6 +131,13 @@ type Instance struct {
 	// AI traffic fleet — tracks pending and active AI aircraft.
 	// Reset on each reconnect (ObjectIDs are invalidated across disconnects).
 	fleet *traffic.Fleet
+
+	// Named key event service — caches event ID mappings across reconnects.
+	keyEvents   *KeyEvents
+	inputEvents *InputEvents
+	facilities  *Facilities
+
+	nearbyFacilities *NearbyFacilities
 }
 
 // Handler function types that are part of the public Manager API
@@ -141,6 +163,9 @@ type ViewHandler func(viewID uint32)
 // FlightPlanDeactivated handler type — called when the active flight plan is deactivated
 type FlightPlanDeactivatedHandler func()
 
+// FlowEventHandler is invoked for every simulator flow event (MSFS 2024 only)
+type FlowEventHandler func(event types.SIMCONNECT_FLOW_EVENT, fltPath string)
+
 // PauseHandler is invoked when the simulator pause state changes
 type PauseHandler func(paused bool)
 
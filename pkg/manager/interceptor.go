@@ -0,0 +1,294 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// ErrInterceptorGroupsExhausted is returned when every interceptor
+// notification group is in use.
+var ErrInterceptorGroupsExhausted = errors.New("manager: interceptor notification groups exhausted")
+
+// InterceptAction is the decision an InterceptHandler makes for a captured
+// key event.
+type InterceptAction int
+
+const (
+	// InterceptPass re-emits the event unchanged to lower-priority groups
+	// and the simulator.
+	InterceptPass InterceptAction = iota
+	// InterceptModify re-emits the event with the data written to the
+	// KeyEvent by the handler.
+	InterceptModify
+	// InterceptSwallow drops the event; nothing below the interceptor's
+	// priority sees it.
+	InterceptSwallow
+)
+
+// InterceptHandler decides what happens to a captured key event. It may
+// change ev.Data before returning InterceptModify. A panicking handler is
+// treated as InterceptPass so pilot input is never lost.
+type InterceptHandler func(ev *KeyEvent) InterceptAction
+
+// Interceptor captures key events in a masked notification group. Create one
+// with KeyEvents.Intercept and call Close to hand the events back to the
+// simulator.
+type Interceptor struct {
+	events   *KeyEvents
+	groupID  uint32
+	priority uint32
+	names    []string
+	handler  InterceptHandler
+	once     sync.Once
+
+	// Captured events are queued by the dispatcher without bounds, so a
+	// slow handler delays masked input but never loses it.
+	qmu   sync.Mutex
+	queue []KeyEvent
+	ready chan struct{} // signalled when queue gains events
+	done  chan struct{} // closed by Close
+}
+
+// Intercept captures the named key events at the given notification group
+// priority (types.SIMCONNECT_GROUP_PRIORITY_HIGHEST is the highest). The
+// events are masked, so lower-priority groups and the simulator only see
+// what the handler passes on. Passed and modified events are re-emitted with
+// TransmitClientEvent just below the interceptor's priority.
+//
+// The handler runs on a dedicated goroutine, in event order. Every captured
+// event reaches it: the dispatcher queues events for the handler instead of
+// dropping them when it falls behind. Intercepting may start before the
+// connection is established; the group is registered on every (re)connect.
+// When connected, a failure to register the group is returned and the
+// interceptor is not created.
+func (k *KeyEvents) Intercept(priority uint32, handler InterceptHandler, names ...string) (*Interceptor, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("manager: Intercept requires a handler")
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("manager: Intercept requires at least one event name")
+	}
+	if priority < types.SIMCONNECT_GROUP_PRIORITY_HIGHEST || priority >= types.SIMCONNECT_GROUP_PRIORITY_LOWEST {
+		return nil, fmt.Errorf("manager: invalid interceptor priority %d", priority)
+	}
+
+	k.mu.Lock()
+	groupID, err := k.allocateGroupLocked()
+	if err != nil {
+		k.mu.Unlock()
+		return nil, err
+	}
+	ic := &Interceptor{
+		events:   k,
+		groupID:  groupID,
+		priority: priority,
+		handler:  handler,
+		ready:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	for _, raw := range names {
+		name := normalizeEventName(raw)
+		if slices.Contains(ic.names, name) {
+			continue
+		}
+		if _, ok := k.owners[name]; ok {
+			k.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrKeyEventInUse, name)
+		}
+		if _, err := k.idLocked(name); err != nil {
+			k.mu.Unlock()
+			return nil, err
+		}
+		ic.names = append(ic.names, name)
+	}
+	for _, name := range ic.names {
+		k.owners[name] = groupID
+	}
+	k.interceptors[groupID] = ic
+	if k.client != nil {
+		if err := ic.registerLocked(); err != nil {
+			ic.removeLocked()
+			if cerr := k.client.ClearNotificationGroup(groupID); cerr != nil {
+				k.m.logger.Error("[manager] Failed to clear key event interceptor group", "group", groupID, "error", cerr)
+			}
+			k.mu.Unlock()
+			return nil, fmt.Errorf("manager: register key event interceptor: %w", err)
+		}
+	}
+	k.mu.Unlock()
+
+	go ic.run()
+	return ic, nil
+}
+
+// Priority returns the notification group priority of the interceptor.
+func (ic *Interceptor) Priority() uint32 {
+	return ic.priority
+}
+
+// Close stops intercepting and removes the interceptor's notification
+// group, so the simulator processes the events directly again.
+func (ic *Interceptor) Close() error {
+	var err error
+	ic.once.Do(func() {
+		close(ic.done)
+
+		k := ic.events
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		ic.removeLocked()
+		if k.client != nil {
+			err = k.client.ClearNotificationGroup(ic.groupID)
+		}
+	})
+	return err
+}
+
+// removeLocked releases the interceptor's group and event names.
+// Caller must hold events.mu.
+func (ic *Interceptor) removeLocked() {
+	k := ic.events
+	delete(k.interceptors, ic.groupID)
+	for _, name := range ic.names {
+		delete(k.owners, name)
+	}
+}
+
+// intercept queues a captured key event message for the interceptor owning
+// its event. It is called by the dispatcher and never blocks on a handler.
+func (k *KeyEvents) intercept(msg engine.Message) {
+	var id uint32
+	if ev := msg.AsEvent(); ev != nil {
+		id = uint32(ev.UEventID)
+	} else if ev := msg.AsEventEx1(); ev != nil {
+		id = uint32(ev.UEventID)
+	}
+	if id < KeyEventIDMin || id > KeyEventIDMax {
+		return
+	}
+	k.mu.Lock()
+	ic := k.interceptors[k.owners[k.names[id]]]
+	k.mu.Unlock()
+	if ic == nil {
+		return
+	}
+	if ev, ok := k.decode(msg.Retain()); ok {
+		ic.enqueue(ev)
+	}
+}
+
+// enqueue appends ev to the queue of events awaiting the handler.
+func (ic *Interceptor) enqueue(ev KeyEvent) {
+	ic.qmu.Lock()
+	ic.queue = append(ic.queue, ev)
+	ic.qmu.Unlock()
+	select {
+	case ic.ready <- struct{}{}:
+	default: // already signalled
+	}
+}
+
+// run applies the handler to queued events, in order, until the
+// interceptor is closed.
+func (ic *Interceptor) run() {
+	var batch []KeyEvent
+	for {
+		select {
+		case <-ic.events.m.ctx.Done():
+			return
+		case <-ic.done:
+			return
+		case <-ic.ready:
+		}
+		ic.qmu.Lock()
+		batch, ic.queue = ic.queue, batch[:0]
+		ic.qmu.Unlock()
+		for i, ev := range batch {
+			select {
+			case <-ic.done:
+				return
+			default:
+			}
+			ic.handle(ev)
+			batch[i] = KeyEvent{}
+		}
+	}
+}
+
+// handle runs the handler for ev and re-emits the event unless it was
+// swallowed.
+func (ic *Interceptor) handle(ev KeyEvent) {
+	original := ev
+	action := InterceptPass
+	safeCallHandler(ic.events.m.logger, "InterceptHandler", func() {
+		action = ic.handler(&ev)
+	})
+
+	switch action {
+	case InterceptSwallow:
+		return
+	case InterceptModify:
+	default:
+		ev = original
+	}
+	if err := ic.emit(ev); err != nil {
+		ic.events.m.logger.Error("[manager] Failed to re-emit intercepted key event", "event", ev.Name, "error", err)
+	}
+}
+
+// emit transmits ev to the groups below the interceptor's priority.
+func (ic *Interceptor) emit(ev KeyEvent) error {
+	k := ic.events
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.client == nil {
+		return ErrNotConnected
+	}
+	id, err := k.mapLocked(ev.Name)
+	if err != nil {
+		return err
+	}
+
+	below := ic.priority + 1
+	flags := types.SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY
+	if ev.Data[1] != 0 || ev.Data[2] != 0 || ev.Data[3] != 0 || ev.Data[4] != 0 {
+		return k.client.TransmitClientEventEx1(types.SIMCONNECT_OBJECT_ID_USER, id, below, flags, ev.Data)
+	}
+	return k.client.TransmitClientEvent(types.SIMCONNECT_OBJECT_ID_USER, id, ev.Data[0], below, flags)
+}
+
+// registerLocked adds the intercepted events to the masked group and sets
+// its priority on the current connection. Caller must hold events.mu and
+// the client must be set.
+func (ic *Interceptor) registerLocked() error {
+	k := ic.events
+	for _, name := range ic.names {
+		id, err := k.mapLocked(name)
+		if err != nil {
+			return err
+		}
+		if err := k.client.AddClientEventToNotificationGroup(ic.groupID, id, true); err != nil {
+			return err
+		}
+	}
+	return k.client.SetNotificationGroupPriority(ic.groupID, ic.priority)
+}
+
+// allocateGroupLocked returns the lowest free interceptor group ID.
+// Caller must hold k.mu.
+func (k *KeyEvents) allocateGroupLocked() (uint32, error) {
+	for id := InterceptorGroupIDMin; id <= InterceptorGroupIDMax; id++ {
+		if _, used := k.interceptors[id]; !used {
+			return id, nil
+		}
+	}
+	return 0, ErrInterceptorGroupsExhausted
+}
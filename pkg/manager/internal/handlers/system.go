@@ -150,3 +150,59 @@ func RemoveFlightPlanDeactivatedHandler(mu *sync.RWMutex, handlers *[]instance.F
 	}
 	return fmt.Errorf("FlightPlanDeactivated handler not found: %s", id)
 }
+
+// RegisterFlowEventHandler adds a FlowEvent handler and returns its unique ID
+func RegisterFlowEventHandler(mu *sync.RWMutex, handlers *[]instance.FlowEventHandlerEntry, handler interface{}, logger *slog.Logger) string {
+	id := GenerateUUID()
+	mu.Lock()
+	*handlers = append(*handlers, instance.FlowEventHandlerEntry{ID: id, Fn: handler})
+	mu.Unlock()
+	if logger != nil {
+		logger.Debug("[manager] Registered FlowEvent handler", "id", id)
+	}
+	return id
+}
+
+// RemoveFlowEventHandler removes a FlowEvent handler by ID
+func RemoveFlowEventHandler(mu *sync.RWMutex, handlers *[]instance.FlowEventHandlerEntry, id string, logger *slog.Logger) error {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, e := range *handlers {
+		if e.ID == id {
+			*handlers = append((*handlers)[:i], (*handlers)[i+1:]...)
+			if logger != nil {
+				logger.Debug("[manager] Removed FlowEvent handler", "id", id)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("FlowEvent handler not found: %s", id)
+}
+
+// RegisterFlightSessionHandler adds a FlightSession handler and returns its unique ID
+func RegisterFlightSessionHandler(mu *sync.RWMutex, handlers *[]instance.FlightSessionHandlerEntry, handler interface{}, logger *slog.Logger) string {
+	id := GenerateUUID()
+	mu.Lock()
+	*handlers = append(*handlers, instance.FlightSessionHandlerEntry{ID: id, Fn: handler})
+	mu.Unlock()
+	if logger != nil {
+		logger.Debug("[manager] Registered FlightSession handler", "id", id)
+	}
+	return id
+}
+
+// RemoveFlightSessionHandler removes a FlightSession handler by ID
+func RemoveFlightSessionHandler(mu *sync.RWMutex, handlers *[]instance.FlightSessionHandlerEntry, id string, logger *slog.Logger) error {
+	mu.Lock()
+	defer mu.Unlock()
+	for i, e := range *handlers {
+		if e.ID == id {
+			*handlers = append((*handlers)[:i], (*handlers)[i+1:]...)
+			if logger != nil {
+				logger.Debug("[manager] Removed FlightSession handler", "id", id)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("FlightSession handler not found: %s", id)
+}
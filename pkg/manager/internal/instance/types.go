@@ -44,6 +44,18 @@ type CrashedHandlerEntry struct {
 	Fn interface{} // CrashedHandler
 }
 
+// FlowEventHandlerEntry stores a FlowEvent handler with an identifier
+type FlowEventHandlerEntry struct {
+	ID string
+	Fn interface{} // FlowEventHandler
+}
+
+// FlightSessionHandlerEntry stores a FlightSession change handler with an identifier
+type FlightSessionHandlerEntry struct {
+	ID string
+	Fn interface{} // FlightSessionChangeHandler
+}
+
 // CrashResetHandlerEntry stores a CrashReset handler with an identifier
 type CrashResetHandlerEntry struct {
 	ID string
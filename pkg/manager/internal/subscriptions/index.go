@@ -0,0 +1,128 @@
+//go:build windows
+// +build windows
+
+package subscriptions
+
+// Route describes which messages a subscriber is interested in. An empty
+// Types list matches every RECV ID and an empty Keys list matches every
+// routing key (request ID or event ID), so the zero Route matches all
+// messages.
+type Route struct {
+	Types []uint32
+	Keys  []uint32
+}
+
+// typeKey is the bucket key for routes restricted by both type and key.
+type typeKey struct {
+	recvID uint32
+	key    uint32
+}
+
+// Index is an immutable view of a subscriber set, bucketed by Route so the
+// dispatcher can look up interested subscribers instead of testing each one.
+// Every subscriber lives in exactly one bucket family and appears at most
+// once per bucket, so Match never yields a subscriber twice for the same
+// message. A new Index is built whenever the set changes and published
+// through an atomic pointer; readers never lock.
+type Index[S any] struct {
+	all       []S
+	wildcard  []S
+	byType    map[uint32][]S
+	byKey     map[uint32][]S
+	byTypeKey map[typeKey][]S
+}
+
+// NewIndex builds an Index over subs using route to obtain each
+// subscriber's Route.
+func NewIndex[S any](subs []S, route func(S) Route) *Index[S] {
+	ix := &Index[S]{all: append([]S(nil), subs...)}
+	for _, s := range subs {
+		r := route(s)
+		recvIDs := unique(r.Types)
+		keys := unique(r.Keys)
+		switch {
+		case len(recvIDs) == 0 && len(keys) == 0:
+			ix.wildcard = append(ix.wildcard, s)
+		case len(keys) == 0:
+			if ix.byType == nil {
+				ix.byType = make(map[uint32][]S)
+			}
+			for _, t := range recvIDs {
+				ix.byType[t] = append(ix.byType[t], s)
+			}
+		case len(recvIDs) == 0:
+			if ix.byKey == nil {
+				ix.byKey = make(map[uint32][]S)
+			}
+			for _, k := range keys {
+				ix.byKey[k] = append(ix.byKey[k], s)
+			}
+		default:
+			if ix.byTypeKey == nil {
+				ix.byTypeKey = make(map[typeKey][]S)
+			}
+			for _, t := range recvIDs {
+				for _, k := range keys {
+					tk := typeKey{recvID: t, key: k}
+					ix.byTypeKey[tk] = append(ix.byTypeKey[tk], s)
+				}
+			}
+		}
+	}
+	return ix
+}
+
+// Len returns the number of indexed subscribers. A nil Index is empty.
+func (ix *Index[S]) Len() int {
+	if ix == nil {
+		return 0
+	}
+	return len(ix.all)
+}
+
+// All returns every indexed subscriber. The slice must not be modified.
+func (ix *Index[S]) All() []S {
+	if ix == nil {
+		return nil
+	}
+	return ix.all
+}
+
+// Match appends to dst the subscribers whose Route covers a message with
+// the given RECV ID and, when hasKey is true, routing key. Messages without
+// a routing key only match routes that do not restrict keys.
+func (ix *Index[S]) Match(dst []S, recvID uint32, key uint32, hasKey bool) []S {
+	if ix == nil {
+		return dst
+	}
+	dst = append(dst, ix.wildcard...)
+	if ix.byType != nil {
+		dst = append(dst, ix.byType[recvID]...)
+	}
+	if hasKey {
+		if ix.byKey != nil {
+			dst = append(dst, ix.byKey[key]...)
+		}
+		if ix.byTypeKey != nil {
+			dst = append(dst, ix.byTypeKey[typeKey{recvID: recvID, key: key}]...)
+		}
+	}
+	return dst
+}
+
+// unique returns values without duplicates, preserving order.
+func unique(values []uint32) []uint32 {
+	if len(values) < 2 {
+		return values
+	}
+	out := make([]uint32, 0, len(values))
+	seen := make(map[uint32]struct{}, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+//go:build windows
+// +build windows
+
+package subscriptions
+
+import (
+	"slices"
+	"testing"
+)
+
+type testSub struct {
+	name  string
+	route Route
+}
+
+func routeOf(s *testSub) Route { return s.route }
+
+func names(subs []*testSub) []string {
+	out := make([]string, 0, len(subs))
+	for _, s := range subs {
+		out = append(out, s.name)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestIndexMatch(t *testing.T) {
+	all := &testSub{name: "all"}
+	events := &testSub{name: "events", route: Route{Types: []uint32{4, 4}}}
+	req7 := &testSub{name: "req7", route: Route{Keys: []uint32{7}}}
+	data7 := &testSub{name: "data7", route: Route{Types: []uint32{8, 9}, Keys: []uint32{7, 7}}}
+	ix := NewIndex([]*testSub{all, events, req7, data7}, routeOf)
+
+	tests := []struct {
+		recvID uint32
+		key    uint32
+		hasKey bool
+		want   []string
+	}{
+		{recvID: 2, want: []string{"all"}},
+		{recvID: 4, key: 1, hasKey: true, want: []string{"all", "events"}},
+		{recvID: 4, key: 7, hasKey: true, want: []string{"all", "events", "req7"}},
+		{recvID: 8, key: 7, hasKey: true, want: []string{"all", "data7", "req7"}},
+		{recvID: 9, key: 8, hasKey: true, want: []string{"all"}},
+		{recvID: 8, key: 7, hasKey: false, want: []string{"all"}},
+	}
+	for _, tt := range tests {
+		got := names(ix.Match(nil, tt.recvID, tt.key, tt.hasKey))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Match(%d, %d, %v) = %v, want %v", tt.recvID, tt.key, tt.hasKey, got, tt.want)
+		}
+	}
+	if ix.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", ix.Len())
+	}
+}
+
+func TestIndexNil(t *testing.T) {
+	var ix *Index[*testSub]
+	if ix.Len() != 0 || ix.All() != nil {
+		t.Fatalf("nil index should be empty")
+	}
+	if got := ix.Match(nil, 1, 1, true); len(got) != 0 {
+		t.Fatalf("nil index matched %d subscribers", len(got))
+	}
+}
@@ -0,0 +1,384 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+var (
+	// ErrKeyEventIDExhausted is returned when no client event IDs are left
+	// in the key event range.
+	ErrKeyEventIDExhausted = errors.New("manager: key event ID pool exhausted")
+
+	// ErrTooManyEventParams is returned when more than five parameters are
+	// passed to KeyEvents.Send.
+	ErrTooManyEventParams = errors.New("manager: key events take at most 5 parameters")
+
+	// ErrKeyEventInUse is returned when a key event is already captured by
+	// another notification group. SimConnect allows a client event in only
+	// one group, so a name can be listened to or intercepted, not both.
+	ErrKeyEventInUse = errors.New("manager: key event already used by another listener or interceptor")
+)
+
+// KeyEvent is a key event received through KeyEvents.Listen.
+type KeyEvent struct {
+	Name string    // Upper-case SimConnect event name, e.g. "AP_MASTER"
+	Data [5]uint32 // Event parameters; events sent with one parameter only carry Data[0]
+}
+
+// KeyEventSubscription is a typed subscription for key events
+type KeyEventSubscription interface {
+	ID() string
+	Events() <-chan KeyEvent
+	Done() <-chan struct{}
+	Unsubscribe()
+}
+
+// KeyEvents sends and listens to simulator key events by name.
+//
+// Client event IDs are allocated from the KeyEventIDMin-KeyEventIDMax range
+// the first time a name is used and cached for the lifetime of the manager.
+// Mappings are re-established automatically after every reconnect, so
+// callers never deal with IDs, groups or flags.
+type KeyEvents struct {
+	m *Instance
+
+	mu        sync.Mutex
+	client    engine.Client
+	ids       map[string]uint32 // name → client event ID
+	names     map[uint32]string // client event ID → name
+	mapped    map[string]bool   // names mapped on the current connection
+	listeners map[string]int    // name → number of Listen subscriptions
+	grouped   map[string]bool   // names in the listen group on the current connection
+	nextID    uint32
+
+	owners       map[string]uint32       // name → notification group capturing it
+	interceptors map[uint32]*Interceptor // group ID → active interceptor
+}
+
+// newKeyEvents constructs an unconnected KeyEvents service.
+func newKeyEvents(m *Instance) *KeyEvents {
+	return &KeyEvents{
+		m:         m,
+		ids:       make(map[string]uint32),
+		names:     make(map[uint32]string),
+		mapped:    make(map[string]bool),
+		listeners: make(map[string]int),
+		grouped:   make(map[string]bool),
+		nextID:    KeyEventIDMin,
+
+		owners:       make(map[string]uint32),
+		interceptors: make(map[uint32]*Interceptor),
+	}
+}
+
+// Events returns the manager's key event service.
+func (m *Instance) Events() *KeyEvents {
+	return m.keyEvents
+}
+
+// Send transmits the named key event to the user aircraft.
+// Up to five parameters are accepted; with more than one,
+// TransmitClientEventEx1 is used.
+// Returns ErrNotConnected if not connected to the simulator.
+func (k *KeyEvents) Send(name string, params ...uint32) error {
+	return k.SendTo(types.SIMCONNECT_OBJECT_ID_USER, name, params...)
+}
+
+// SendTo transmits the named key event to the given object.
+// Returns ErrNotConnected if not connected to the simulator.
+func (k *KeyEvents) SendTo(objectID uint32, name string, params ...uint32) error {
+	if len(params) > 5 {
+		return ErrTooManyEventParams
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.client == nil {
+		return ErrNotConnected
+	}
+	id, err := k.mapLocked(name)
+	if err != nil {
+		return err
+	}
+
+	groupID := uint32(types.SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+	flags := types.SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY
+	if len(params) > 1 {
+		var data [5]uint32
+		copy(data[:], params)
+		return k.client.TransmitClientEventEx1(objectID, id, groupID, flags, data)
+	}
+	var data uint32
+	if len(params) == 1 {
+		data = params[0]
+	}
+	return k.client.TransmitClientEvent(objectID, id, data, groupID, flags)
+}
+
+// ID returns the client event ID allocated for name, allocating one if the
+// name has not been used yet. It does not require a connection.
+func (k *KeyEvents) ID(name string) (uint32, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.idLocked(normalizeEventName(name))
+}
+
+// Name returns the event name for a client event ID allocated by KeyEvents.
+func (k *KeyEvents) Name(id uint32) (string, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	name, ok := k.names[id]
+	return name, ok
+}
+
+// Listen returns a subscription delivering the named key events as the
+// simulator processes them. Listening may start before the connection is
+// established; the events are registered on every (re)connect.
+func (k *KeyEvents) Listen(names ...string) (KeyEventSubscription, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("manager: Listen requires at least one event name")
+	}
+
+	k.mu.Lock()
+	ids := make([]uint32, 0, len(names))
+	keys := make([]string, 0, len(names))
+	for _, raw := range names {
+		name := normalizeEventName(raw)
+		id, err := k.idLocked(name)
+		if err != nil {
+			k.mu.Unlock()
+			return nil, err
+		}
+		if owner, ok := k.owners[name]; ok && owner != KeyEventListenGroupID {
+			k.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrKeyEventInUse, name)
+		}
+		ids = append(ids, id)
+		keys = append(keys, name)
+	}
+	for _, name := range keys {
+		k.owners[name] = KeyEventListenGroupID
+		k.listeners[name]++
+		if k.client != nil {
+			if err := k.groupLocked(name); err != nil {
+				k.m.logger.Error("[manager] Failed to listen to key event", "event", name, "error", err)
+			}
+		}
+	}
+	k.mu.Unlock()
+
+	id := subscriptions.GenerateID("")
+	bufferSize := subscriptions.DefaultBufferSize
+	msgSub := k.m.SubscribeWithType(id+"-keyevents", bufferSize, keyEventRecvIDs, WithRequestIDs(ids...))
+	ks := &keyEventSubscription{id: id, sub: msgSub, ch: make(chan KeyEvent, bufferSize), done: make(chan struct{}), events: k, names: keys}
+
+	go func() {
+		defer ks.Unsubscribe()
+		for {
+			select {
+			case <-k.m.ctx.Done():
+				return
+			case <-msgSub.Done():
+				return
+			case msg, ok := <-msgSub.Messages():
+				if !ok {
+					return
+				}
+				if ev, ok := k.decode(msg); ok {
+					ks.deliver(ev)
+				}
+			}
+		}
+	}()
+	return ks, nil
+}
+
+// keyEventRecvIDs are the message types carrying key events: EVENT for
+// events sent with at most one parameter and EVENT_EX1 for the rest.
+var keyEventRecvIDs = []types.SIMCONNECT_RECV_ID{types.SIMCONNECT_RECV_ID_EVENT, types.SIMCONNECT_RECV_ID_EVENT_EX1}
+
+// decode converts an EVENT or EVENT_EX1 message into a KeyEvent. It
+// consumes msg.
+func (k *KeyEvents) decode(msg engine.Message) (KeyEvent, bool) {
+	defer msg.Release()
+	var id uint32
+	var data [5]uint32
+	if ev := msg.AsEvent(); ev != nil {
+		id = uint32(ev.UEventID)
+		data[0] = uint32(ev.DwData)
+	} else if ev := msg.AsEventEx1(); ev != nil {
+		id = uint32(ev.UEventID)
+		data = [5]uint32{uint32(ev.DwData0), uint32(ev.DwData1), uint32(ev.DwData2), uint32(ev.DwData3), uint32(ev.DwData4)}
+	} else {
+		return KeyEvent{}, false
+	}
+	name, ok := k.Name(id)
+	if !ok {
+		return KeyEvent{}, false
+	}
+	return KeyEvent{Name: name, Data: data}, true
+}
+
+// unlisten drops one Listen reference for each name and removes events
+// nobody listens to any more from the listen group.
+func (k *KeyEvents) unlisten(names []string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, name := range names {
+		k.listeners[name]--
+		if k.listeners[name] > 0 {
+			continue
+		}
+		delete(k.listeners, name)
+		delete(k.owners, name)
+		if k.client != nil && k.grouped[name] {
+			if err := k.client.RemoveClientEvent(KeyEventListenGroupID, k.ids[name]); err != nil {
+				k.m.logger.Error("[manager] Failed to stop listening to key event", "event", name, "error", err)
+			}
+		}
+		delete(k.grouped, name)
+	}
+}
+
+// setClient binds the service to a new connection, or detaches it when
+// client is nil. On connect every known name is mapped again and listened
+// events are added back to the listen group.
+func (k *KeyEvents) setClient(client engine.Client) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.client = client
+	k.mapped = make(map[string]bool)
+	k.grouped = make(map[string]bool)
+	if client == nil {
+		return
+	}
+
+	for name := range k.ids {
+		if _, err := k.mapLocked(name); err != nil {
+			k.m.logger.Error("[manager] Failed to map key event", "event", name, "error", err)
+		}
+	}
+	for name := range k.listeners {
+		if err := k.groupLocked(name); err != nil {
+			k.m.logger.Error("[manager] Failed to listen to key event", "event", name, "error", err)
+		}
+	}
+	for _, ic := range k.interceptors {
+		if err := ic.registerLocked(); err != nil {
+			k.m.logger.Error("[manager] Failed to restore key event interceptor", "group", ic.groupID, "error", err)
+		}
+	}
+}
+
+// idLocked returns the cached client event ID for name, allocating one if
+// needed. Caller must hold k.mu.
+func (k *KeyEvents) idLocked(name string) (uint32, error) {
+	if id, ok := k.ids[name]; ok {
+		return id, nil
+	}
+	if k.nextID > KeyEventIDMax {
+		return 0, ErrKeyEventIDExhausted
+	}
+	id := k.nextID
+	k.nextID++
+	k.ids[name] = id
+	k.names[id] = name
+	return id, nil
+}
+
+// mapLocked maps name on the current connection if it is not mapped yet.
+// Caller must hold k.mu and k.client must be set.
+func (k *KeyEvents) mapLocked(name string) (uint32, error) {
+	name = normalizeEventName(name)
+	id, err := k.idLocked(name)
+	if err != nil {
+		return 0, err
+	}
+	if k.mapped[name] {
+		return id, nil
+	}
+	if err := k.client.MapClientEventToSimEvent(id, name); err != nil {
+		return 0, fmt.Errorf("manager: failed to map key event '%s': %w", name, err)
+	}
+	k.mapped[name] = true
+	return id, nil
+}
+
+// groupLocked adds name to the listen group on the current connection.
+// Caller must hold k.mu and k.client must be set.
+func (k *KeyEvents) groupLocked(name string) error {
+	if k.grouped[name] {
+		return nil
+	}
+	id, err := k.mapLocked(name)
+	if err != nil {
+		return err
+	}
+	if err := k.client.AddClientEventToNotificationGroup(KeyEventListenGroupID, id, false); err != nil {
+		return err
+	}
+	if len(k.grouped) == 0 {
+		if err := k.client.SetNotificationGroupPriority(KeyEventListenGroupID, types.SIMCONNECT_GROUP_PRIORITY_HIGHEST); err != nil {
+			return err
+		}
+	}
+	k.grouped[name] = true
+	return nil
+}
+
+// normalizeEventName returns the canonical (upper-case, trimmed) event name.
+func normalizeEventName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+type keyEventSubscription struct {
+	id      string
+	sub     Subscription
+	ch      chan KeyEvent
+	done    chan struct{}
+	events  *KeyEvents
+	names   []string
+	closeMu sync.Mutex
+}
+
+func (s *keyEventSubscription) ID() string              { return s.id }
+func (s *keyEventSubscription) Events() <-chan KeyEvent { return s.ch }
+func (s *keyEventSubscription) Done() <-chan struct{}   { return s.done }
+
+// deliver forwards ev without blocking. It holds closeMu so a concurrent
+// Unsubscribe cannot close the channel mid-send.
+func (s *keyEventSubscription) deliver(ev KeyEvent) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.sub == nil {
+		return
+	}
+	select {
+	case s.ch <- ev:
+	default:
+		s.events.m.logger.Debug("[manager] Key event subscription channel full, dropping event")
+	}
+}
+
+func (s *keyEventSubscription) Unsubscribe() {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.sub == nil {
+		return // already closed
+	}
+	s.sub.Unsubscribe()
+	s.sub = nil
+	s.events.unlisten(s.names)
+	close(s.done)
+	close(s.ch)
+}
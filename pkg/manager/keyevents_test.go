@@ -0,0 +1,319 @@
+//go:build windows
+
+package manager
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeEventClient records the event calls made by KeyEvents. Methods not
+// overridden panic through the nil embedded interface.
+type fakeEventClient struct {
+	engine.Client
+	mu       sync.Mutex
+	cleared  []uint32
+	mapped   map[uint32]string
+	sent     []uint32
+	sentEx1  [][5]uint32
+	grouped  []uint32
+	priority uint32
+	groupErr error // returned by AddClientEventToNotificationGroup
+}
+
+func newFakeEventClient() *fakeEventClient {
+	return &fakeEventClient{mapped: make(map[uint32]string)}
+}
+
+func (c *fakeEventClient) MapClientEventToSimEvent(eventID uint32, eventName string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mapped[eventID] = eventName
+	return nil
+}
+
+func (c *fakeEventClient) TransmitClientEvent(objectID uint32, eventID uint32, data uint32, groupID uint32, flags types.SIMCONNECT_EVENT_FLAG) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, data)
+	return nil
+}
+
+func (c *fakeEventClient) TransmitClientEventEx1(objectID uint32, eventID uint32, groupID uint32, flags types.SIMCONNECT_EVENT_FLAG, data [5]uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sentEx1 = append(c.sentEx1, data)
+	return nil
+}
+
+func (c *fakeEventClient) AddClientEventToNotificationGroup(groupID uint32, eventID uint32, mask bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.groupErr != nil {
+		return c.groupErr
+	}
+	c.grouped = append(c.grouped, eventID)
+	return nil
+}
+
+func (c *fakeEventClient) SetNotificationGroupPriority(groupID uint32, priority uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.priority = priority
+	return nil
+}
+
+func (c *fakeEventClient) RemoveClientEvent(groupID uint32, eventID uint32) error {
+	return nil
+}
+
+func (c *fakeEventClient) ClearNotificationGroup(groupID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cleared = append(c.cleared, groupID)
+	return nil
+}
+
+// sentData returns a copy of the data passed to TransmitClientEvent.
+func (c *fakeEventClient) sentData() []uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uint32(nil), c.sent...)
+}
+
+// newKeyEventMessage returns an unpooled EVENT message for a key event.
+func newKeyEventMessage(k *KeyEvents, name string, data uint32) engine.Message {
+	id, _ := k.ID(name)
+	ev := &types.SIMCONNECT_RECV_EVENT{}
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EVENT)
+	ev.UEventID = types.DWORD(id)
+	ev.DwData = types.DWORD(data)
+	return engine.Message{SIMCONNECT_RECV: &ev.SIMCONNECT_RECV}
+}
+
+// newKeyEventEx1Message returns an unpooled EVENT_EX1 message for a key
+// event sent with several parameters.
+func newKeyEventEx1Message(k *KeyEvents, name string, data [5]uint32) engine.Message {
+	id, _ := k.ID(name)
+	ev := &types.SIMCONNECT_RECV_EVENT_EX1{}
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EVENT_EX1)
+	ev.UEventID = types.DWORD(id)
+	ev.DwData0 = types.DWORD(data[0])
+	ev.DwData1 = types.DWORD(data[1])
+	ev.DwData2 = types.DWORD(data[2])
+	ev.DwData3 = types.DWORD(data[3])
+	ev.DwData4 = types.DWORD(data[4])
+	return engine.Message{SIMCONNECT_RECV: &ev.SIMCONNECT_RECV}
+}
+
+func TestKeyEventsSend(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+
+	if err := k.Send("AP_MASTER"); !errors.Is(err, ErrNotConnected) {
+		t.Fatalf("Send without connection = %v, want ErrNotConnected", err)
+	}
+
+	client := newFakeEventClient()
+	k.setClient(client)
+	if err := k.Send("ap_master"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := k.Send("AP_MASTER", 1); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := k.Send("AXIS_THROTTLE_SET_EX1", 1, 2); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if err := k.Send("AP_MASTER", 1, 2, 3, 4, 5, 6); !errors.Is(err, ErrTooManyEventParams) {
+		t.Fatalf("Send with 6 params = %v, want ErrTooManyEventParams", err)
+	}
+
+	if len(client.mapped) != 2 {
+		t.Fatalf("mapped %d events, want 2", len(client.mapped))
+	}
+	if len(client.sent) != 2 || client.sent[1] != 1 {
+		t.Fatalf("TransmitClientEvent data = %v, want [0 1]", client.sent)
+	}
+	if len(client.sentEx1) != 1 || client.sentEx1[0] != [5]uint32{1, 2} {
+		t.Fatalf("TransmitClientEventEx1 data = %v", client.sentEx1)
+	}
+
+	// A reconnect maps the cached names again under the same IDs.
+	id, _ := k.ID("AP_MASTER")
+	reconnected := newFakeEventClient()
+	k.setClient(reconnected)
+	if reconnected.mapped[id] != "AP_MASTER" {
+		t.Fatalf("AP_MASTER not remapped after reconnect: %v", reconnected.mapped)
+	}
+}
+
+func TestKeyEventsListen(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+
+	sub, err := k.Listen("TOGGLE_FLIGHT_DIRECTOR")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	client := newFakeEventClient()
+	k.setClient(client)
+	if len(client.grouped) != 1 || client.priority != types.SIMCONNECT_GROUP_PRIORITY_HIGHEST {
+		t.Fatalf("listen group not restored on connect: %v priority %d", client.grouped, client.priority)
+	}
+
+	m.forward(newKeyEventMessage(k, "TOGGLE_FLIGHT_DIRECTOR", 7))
+
+	select {
+	case got := <-sub.Events():
+		if got.Name != "TOGGLE_FLIGHT_DIRECTOR" || got.Data[0] != 7 {
+			t.Fatalf("got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for key event")
+	}
+}
+
+func TestKeyEventsListenEx1(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+
+	sub, err := k.Listen("AXIS_THROTTLE_SET_EX1")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	defer sub.Unsubscribe()
+	k.setClient(newFakeEventClient())
+
+	want := [5]uint32{8192, 1, 2, 3, 4}
+	m.forward(newKeyEventEx1Message(k, "AXIS_THROTTLE_SET_EX1", want))
+
+	select {
+	case got := <-sub.Events():
+		if got.Name != "AXIS_THROTTLE_SET_EX1" || got.Data != want {
+			t.Fatalf("got %+v, want data %v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for key event")
+	}
+}
+
+func TestKeyEventsIntercept(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+	m.keyEvents = k
+	client := newFakeEventClient()
+	k.setClient(client)
+
+	ic, err := k.Intercept(1000, func(ev *KeyEvent) InterceptAction {
+		switch {
+		case ev.Name == "AP_MASTER":
+			return InterceptSwallow
+		case ev.Data[0] > 100:
+			ev.Data[0] = 100
+			return InterceptModify
+		}
+		return InterceptPass
+	}, "THROTTLE_SET", "AP_MASTER")
+	if err != nil {
+		t.Fatalf("Intercept: %v", err)
+	}
+	if _, err := k.Listen("AP_MASTER"); !errors.Is(err, ErrKeyEventInUse) {
+		t.Fatalf("Listen on intercepted event = %v, want ErrKeyEventInUse", err)
+	}
+
+	m.processMessage(newKeyEventMessage(k, "THROTTLE_SET", 50))
+	m.processMessage(newKeyEventMessage(k, "AP_MASTER", 1))
+	m.processMessage(newKeyEventMessage(k, "THROTTLE_SET", 500))
+
+	deadline := time.Now().Add(time.Second)
+	for len(client.sentData()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := client.sentData(); !slices.Equal(got, []uint32{50, 100}) {
+		t.Fatalf("re-emitted data = %v, want [50 100]", got)
+	}
+
+	if err := ic.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := k.Listen("AP_MASTER"); err != nil {
+		t.Fatalf("Listen after Close: %v", err)
+	}
+}
+
+func TestKeyEventsInterceptNeverDrops(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+	m.keyEvents = k
+	client := newFakeEventClient()
+	k.setClient(client)
+
+	// Hold the handler until the dispatcher has captured far more events
+	// than a subscription buffer holds.
+	release := make(chan struct{})
+	ic, err := k.Intercept(1000, func(ev *KeyEvent) InterceptAction {
+		<-release
+		return InterceptPass
+	}, "THROTTLE_SET")
+	if err != nil {
+		t.Fatalf("Intercept: %v", err)
+	}
+	defer ic.Close()
+
+	const n = 1000
+	for i := uint32(0); i < n; i++ {
+		m.processMessage(newKeyEventMessage(k, "THROTTLE_SET", i))
+	}
+	close(release)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(client.sentData()) < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	got := client.sentData()
+	if len(got) != n {
+		t.Fatalf("re-emitted %d events, want %d", len(got), n)
+	}
+	for i, data := range got {
+		if data != uint32(i) {
+			t.Fatalf("event %d re-emitted with data %d, out of order", i, data)
+		}
+	}
+}
+
+func TestKeyEventsInterceptRegisterFails(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	k := newKeyEvents(m)
+	client := newFakeEventClient()
+	client.groupErr = errors.New("group refused")
+	k.setClient(client)
+
+	if _, err := k.Intercept(1000, func(*KeyEvent) InterceptAction { return InterceptPass }, "AP_MASTER"); !errors.Is(err, client.groupErr) {
+		t.Fatalf("Intercept = %v, want the registration error", err)
+	}
+	if len(client.cleared) != 1 || client.cleared[0] != InterceptorGroupIDMin {
+		t.Errorf("cleared groups = %v, want the partially registered group", client.cleared)
+	}
+	if len(k.interceptors) != 0 {
+		t.Errorf("%d interceptors left after a failed Intercept", len(k.interceptors))
+	}
+	client.groupErr = nil
+	if _, err := k.Listen("AP_MASTER"); err != nil {
+		t.Fatalf("Listen after failed Intercept: %v", err)
+	}
+}
@@ -75,15 +75,30 @@ func (m *Instance) runConnection() error {
 		m.engine = nil
 		m.mu.Unlock()
 		m.fleet.SetClient(nil)
+		m.keyEvents.setClient(nil)
+		m.inputEvents.setClient(nil)
+		m.facilities.setClient(nil)
+		m.nearbyFacilities.setClient(nil)
 		return err
 	}
 
 	m.setState(StateConnected)
 	m.logger.Debug("[manager] Connected to simulator")
 	m.fleet.SetClient(m.engine)
+	m.keyEvents.setClient(m.engine)
+	m.inputEvents.setClient(m.engine)
+	m.facilities.setClient(m.engine)
+	m.nearbyFacilities.setClient(m.engine)
 
-	// Process messages until disconnection or cancellation
-	stream := m.engine.Stream()
+	if m.config.BatchDispatch {
+		return m.consumeBatches(m.engine.StreamBatches())
+	}
+	return m.consumeStream(m.engine.Stream())
+}
+
+// consumeStream processes messages one at a time until disconnection or
+// cancellation.
+func (m *Instance) consumeStream(stream <-chan engine.Message) error {
 	for {
 		select {
 		case <-m.ctx.Done():
@@ -93,14 +108,7 @@ func (m *Instance) runConnection() error {
 
 		case msg, ok := <-stream:
 			if !ok {
-				// Stream closed (simulator disconnected)
-				m.logger.Debug("[manager] Stream closed (simulator disconnected)")
-				m.setSimState(defaultSimState())
-				m.setState(StateDisconnected)
-				m.mu.Lock()
-				m.engine = nil
-				m.mu.Unlock()
-				m.fleet.SetClient(nil)
+				m.handleStreamClosed()
 				return nil // Return nil to allow reconnection
 			}
 
@@ -110,6 +118,43 @@ func (m *Instance) runConnection() error {
 	}
 }
 
+// consumeBatches processes message batches until disconnection or
+// cancellation.
+func (m *Instance) consumeBatches(batches <-chan []engine.Message) error {
+	for {
+		select {
+		case <-m.ctx.Done():
+			m.logger.Debug("[manager] Context cancelled, disconnecting...")
+			m.disconnect()
+			return m.ctx.Err()
+
+		case batch, ok := <-batches:
+			if !ok {
+				m.handleStreamClosed()
+				return nil // Return nil to allow reconnection
+			}
+			m.processBatch(batch)
+		}
+	}
+}
+
+// handleStreamClosed resets connection state after the engine stream closed
+// (simulator disconnected).
+func (m *Instance) handleStreamClosed() {
+	m.logger.Debug("[manager] Stream closed (simulator disconnected)")
+	m.setSimState(defaultSimState())
+	m.resetFlightSession()
+	m.setState(StateDisconnected)
+	m.mu.Lock()
+	m.engine = nil
+	m.mu.Unlock()
+	m.fleet.SetClient(nil)
+	m.keyEvents.setClient(nil)
+	m.inputEvents.setClient(nil)
+	m.facilities.setClient(nil)
+	m.nearbyFacilities.setClient(nil)
+}
+
 // connectWithRetry attempts to connect to the simulator with fixed retry interval
 func (m *Instance) connectWithRetry() error {
 	m.setState(StateConnecting)
@@ -175,6 +220,10 @@ func (m *Instance) disconnect() {
 	cameraRequestPending := m.cameraDataRequestPending
 	m.cameraDataRequestPending = false
 	m.mu.Unlock()
+	m.keyEvents.setClient(nil)
+	m.inputEvents.setClient(nil)
+	m.facilities.setClient(nil)
+	m.nearbyFacilities.setClient(nil)
 
 	if eng != nil {
 		// Clear camera data definition if it was requested
@@ -197,6 +246,7 @@ func (m *Instance) disconnect() {
 
 	// Clean up request registry on disconnect
 	m.requestRegistry.Clear()
+	m.resetFlightSession()
 
 	m.setState(StateDisconnected)
 }
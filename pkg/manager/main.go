@@ -106,7 +106,7 @@ func New(name string, opts ...Option) Manager {
 
 	ctx, cancel := context.WithCancel(config.Context)
 
-	return &Instance{
+	m := &Instance{
 		name:                         name,
 		config:                       config,
 		ctx:                          ctx,
@@ -124,6 +124,7 @@ func New(name string, opts ...Option) Manager {
 		simState:                     defaultSimState(),
 		simStateHandlers:             []instance.SimStateHandlerEntry{},
 		simStateSubscriptions:        make(map[string]*simStateSubscription),
+		flightSessionSubscriptions:   make(map[string]*flightSessionSubscription),
 		cameraDefinitionID:           CameraDefinitionID,
 		cameraRequestID:              CameraRequestID,
 		pauseEventID:                 PauseEventID,
@@ -150,4 +151,11 @@ func New(name string, opts ...Option) Manager {
 		requestRegistry:        NewRequestRegistry(),
 		fleet:                  traffic.NewFleet(nil),
 	}
+	m.fleet.SetPendingTimeout(config.FleetPendingTimeout)
+	m.fleet.SetTrackIDs(FleetTrackDefinitionID, FleetTrackRequestIDMin, FleetTrackRequestIDMax)
+	m.keyEvents = newKeyEvents(m)
+	m.inputEvents = newInputEvents(m)
+	m.facilities = newFacilities(m, config.FacilityCache)
+	m.nearbyFacilities = newNearbyFacilities(m)
+	return m
 }
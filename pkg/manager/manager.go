@@ -20,7 +20,8 @@ var ErrNotConnected = errors.New("manager: not connected to simulator")
 
 // Manager defines the interface for managing SimConnect connections with
 // automatic lifecycle handling and reconnection support
-// MessageHandler is a callback function invoked when a message is received from the simulator
+// MessageHandler is a callback function invoked when a message is received from the simulator.
+// The message is only valid for the duration of the call; use Retain or Clone to keep it.
 type MessageHandler func(msg engine.Message)
 
 // Subscription represents an active message subscription that can be cancelled
@@ -28,7 +29,9 @@ type Subscription interface {
 	// ID returns the unique identifier of the subscription
 	ID() string
 
-	// Messages returns the channel for receiving messages
+	// Messages returns the channel for receiving messages.
+	// Each delivered message is a reference owned by the receiver, who must
+	// call Release once done with it. Use Clone to keep the data longer.
 	Messages() <-chan engine.Message
 
 	// Done returns a channel that is closed when the subscription ends.
@@ -326,8 +329,8 @@ type Manager interface {
 	RequestSystemState(requestID uint32, state types.SIMCONNECT_SYSTEM_STATE) error
 
 	// SubscribeToSystemEvent subscribes to a SimConnect system event.
-	// WARNING: Do not use event IDs in the manager's reserved range (999,999,900 - 999,999,999).
-	// Use IDs from 1 to 999,999,899 for your own subscriptions.
+	// WARNING: Do not use event IDs in the manager's reserved range (999,996,975 - 999,999,999).
+	// Use IDs from 1 to 999,996,974 for your own subscriptions.
 	// Returns ErrNotConnected if not connected to the simulator.
 	SubscribeToSystemEvent(eventID uint32, eventName string) error
 
@@ -338,7 +341,8 @@ type Manager interface {
 	// Flow Event Methods (MSFS 2024 only)
 
 	// SubscribeToFlowEvent subscribes to all simulator flow events.
-	// No automatic re-subscription on reconnect — caller responsibility.
+	// The subscription is restored after every reconnect until
+	// UnsubscribeFromFlowEvent is called.
 	// Returns ErrNotConnected if not connected to the simulator.
 	SubscribeToFlowEvent() error
 
@@ -346,6 +350,28 @@ type Manager interface {
 	// Returns ErrNotConnected if not connected to the simulator.
 	UnsubscribeFromFlowEvent() error
 
+	// OnFlowEvent registers a callback invoked for every flow event and
+	// enables flow events across reconnects.
+	OnFlowEvent(handler FlowEventHandler) string
+	RemoveFlowEvent(id string) error
+
+	// SubscribeOnFlowEvent returns a subscription for raw flow event messages
+	// and enables flow events across reconnects.
+	SubscribeOnFlowEvent(id string, bufferSize int) Subscription
+
+	// FlightSession returns the derived flight session state (loading, in
+	// menu, briefing, in flight, returning to menu).
+	FlightSession() FlightSession
+
+	// OnFlightSessionChange registers a callback invoked when the flight
+	// session changes. Returns an id for RemoveFlightSessionChange.
+	OnFlightSessionChange(handler FlightSessionChangeHandler) string
+	RemoveFlightSessionChange(id string) error
+
+	// SubscribeFlightSessionChange creates a subscription that delivers
+	// flight session changes to a channel.
+	SubscribeFlightSessionChange(id string, bufferSize int) FlightSessionSubscription
+
 	// Input Event Methods (MSFS 2024 only)
 
 	// EnumerateInputEvents requests an enumeration of all registered input events.
@@ -490,6 +516,24 @@ type Manager interface {
 	// Returns ErrNotConnected if not connected to the simulator.
 	AICreateParkedATCAircraftEX1(szContainerTitle string, szLivery string, szTailNumber string, szAirportID string, RequestID uint32) error
 
+	// Events returns the named key event service. Event names are mapped
+	// lazily, cached for the lifetime of the manager and remapped after
+	// every reconnect.
+	Events() *KeyEvents
+
+	// InputEvents returns the MSFS 2024 input event service. Descriptors are
+	// enumerated on first use and subscriptions are restored after every
+	// reconnect.
+	InputEvents() *InputEvents
+
+	// Facilities returns the facility service, which requests facility data
+	// and returns it decoded into pkg/facility types.
+	Facilities() *Facilities
+
+	// NearbyFacilities returns the tracker of facilities inside the reality
+	// bubble. Watched kinds are subscribed again after every reconnect.
+	NearbyFacilities() *NearbyFacilities
+
 	// Traffic Package Methods
 	// High-level AI aircraft management via pkg/traffic.Fleet.
 	// These wrap the raw AI* methods above with fleet tracking and typed options.
@@ -0,0 +1,343 @@
+//go:build windows
+// +build windows
+
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// NearbyFacilityChange tells whether a facility entered or left the
+// reality bubble.
+type NearbyFacilityChange uint8
+
+const (
+	NearbyFacilityEntered NearbyFacilityChange = iota
+	NearbyFacilityLeft
+)
+
+// String returns "entered" or "left".
+func (c NearbyFacilityChange) String() string {
+	if c == NearbyFacilityLeft {
+		return "left"
+	}
+	return "entered"
+}
+
+// NearbyFacilityEvent is a change of the in-range set delivered by
+// NearbyFacilities.Subscribe.
+type NearbyFacilityEvent struct {
+	Change   NearbyFacilityChange
+	Facility facility.Summary
+}
+
+// NearbyFacilitySubscription is a typed subscription for in-range set changes
+type NearbyFacilitySubscription interface {
+	ID() string
+	Events() <-chan NearbyFacilityEvent
+	Done() <-chan struct{}
+	Unsubscribe()
+}
+
+// NearbyFacilities tracks the airports, VORs, NDBs and waypoints inside the
+// reality bubble through SubscribeToFacilitiesEX1.
+//
+// Watched kinds survive reconnects: on every connect they are subscribed
+// again and the simulator resends the facilities in range. On disconnect
+// the in-range set is emptied and a Left event is emitted for each entry,
+// so Entered and Left events always pair up.
+type NearbyFacilities struct {
+	m *Instance
+
+	mu      sync.Mutex
+	client  engine.Client
+	watched map[facility.Kind]bool
+	inRange map[facility.Kind]map[string]facility.Summary // keyed by ICAO/region
+	subs    map[string]*nearbyFacilitySubscription
+}
+
+// newNearbyFacilities constructs an unconnected NearbyFacilities service.
+func newNearbyFacilities(m *Instance) *NearbyFacilities {
+	return &NearbyFacilities{
+		m:       m,
+		watched: make(map[facility.Kind]bool),
+		inRange: make(map[facility.Kind]map[string]facility.Summary),
+		subs:    make(map[string]*nearbyFacilitySubscription),
+	}
+}
+
+// NearbyFacilities returns the manager's nearby facility tracker.
+func (m *Instance) NearbyFacilities() *NearbyFacilities {
+	return m.nearbyFacilities
+}
+
+// nearbyRequestIDs returns the request IDs under which facilities of kind k
+// enter and leave the reality bubble.
+func nearbyRequestIDs(k facility.Kind) (entered, left uint32) {
+	entered = NearbyFacilityRequestIDMin + 2*uint32(k)
+	return entered, entered + 1
+}
+
+// Watch starts tracking the given kinds. When connected, the simulator is
+// subscribed immediately; otherwise on the next connect.
+func (s *NearbyFacilities) Watch(kinds ...facility.Kind) error {
+	for _, k := range kinds {
+		if k > facility.KindVOR {
+			return fmt.Errorf("%w: list %s", facility.ErrUnsupportedType, k)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range kinds {
+		if s.watched[k] {
+			continue
+		}
+		s.watched[k] = true
+		if s.client != nil {
+			if err := s.subscribeLocked(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Unwatch stops tracking the given kinds and emits a Left event for every
+// facility of those kinds that was in range.
+func (s *NearbyFacilities) Unwatch(kinds ...facility.Kind) error {
+	s.mu.Lock()
+	var events []NearbyFacilityEvent
+	var err error
+	for _, k := range kinds {
+		if !s.watched[k] {
+			continue
+		}
+		delete(s.watched, k)
+		if s.client != nil {
+			if uerr := s.client.UnsubscribeToFacilitiesEX1(types.SIMCONNECT_FACILITY_LIST_TYPE(k), true, true); uerr != nil && err == nil {
+				err = uerr
+			}
+		}
+		events = append(events, s.clearLocked(k)...)
+	}
+	subs := s.subscribersLocked()
+	s.mu.Unlock()
+
+	publishNearby(subs, events)
+	return err
+}
+
+// InRange returns the facilities of kind k currently in the reality
+// bubble, sorted by ICAO.
+func (s *NearbyFacilities) InRange(k facility.Kind) []facility.Summary {
+	s.mu.Lock()
+	out := make([]facility.Summary, 0, len(s.inRange[k]))
+	for _, f := range s.inRange[k] {
+		out = append(out, f)
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ICAO != out[j].ICAO {
+			return out[i].ICAO < out[j].ICAO
+		}
+		return out[i].Region < out[j].Region
+	})
+	return out
+}
+
+// Nearest returns up to n facilities of kind k in range, nearest to the
+// user aircraft position from SimState first.
+func (s *NearbyFacilities) Nearest(k facility.Kind, n int) []facility.Summary {
+	state := s.m.SimState()
+	return facility.Nearest(s.InRange(k), state.Latitude, state.Longitude, n)
+}
+
+// Subscribe returns a subscription delivering Entered and Left events of
+// every watched kind. The channel is buffered with bufferSize (defaults to
+// 16 if <= 0); events are dropped when it is full.
+func (s *NearbyFacilities) Subscribe(id string, bufferSize int) NearbyFacilitySubscription {
+	if id == "" {
+		id = subscriptions.GenerateID("")
+	}
+	if bufferSize <= 0 {
+		bufferSize = subscriptions.DefaultBufferSize
+	}
+	sub := &nearbyFacilitySubscription{
+		id:     id,
+		ch:     make(chan NearbyFacilityEvent, bufferSize),
+		done:   make(chan struct{}),
+		nearby: s,
+	}
+	s.mu.Lock()
+	s.subs[id] = sub
+	s.mu.Unlock()
+	return sub
+}
+
+// processMessage applies one list message to the in-range set. It runs on
+// the dispatch goroutine, before msg is forwarded, so a burst of list pages
+// cannot overflow a subscription and leave stale entries in the set.
+func (s *NearbyFacilities) processMessage(msg engine.Message) {
+	list := msg.AsFacilityList()
+	if list == nil {
+		return
+	}
+	reqID := uint32(list.DwRequestID)
+	if reqID < NearbyFacilityRequestIDMin || reqID > NearbyFacilityRequestIDMax {
+		return
+	}
+	k := facility.Kind((reqID - NearbyFacilityRequestIDMin) / 2)
+	change := NearbyFacilityChange((reqID - NearbyFacilityRequestIDMin) % 2)
+	data, count := engine.FacilityListBytes(&msg)
+	items, err := facility.DecodeList(k, data, count)
+	if err != nil {
+		s.m.logger.Error("[manager] Failed to decode nearby facility list", "kind", k, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	if !s.watched[k] {
+		s.mu.Unlock()
+		return
+	}
+	set := s.inRange[k]
+	if set == nil {
+		set = make(map[string]facility.Summary)
+		s.inRange[k] = set
+	}
+	events := make([]NearbyFacilityEvent, 0, len(items))
+	for _, f := range items {
+		key := f.ICAO + "/" + f.Region
+		_, known := set[key]
+		switch {
+		case change == NearbyFacilityEntered:
+			set[key] = f
+			if known {
+				continue
+			}
+		case known:
+			f = set[key]
+			delete(set, key)
+		default:
+			continue
+		}
+		events = append(events, NearbyFacilityEvent{Change: change, Facility: f})
+	}
+	subs := s.subscribersLocked()
+	s.mu.Unlock()
+
+	publishNearby(subs, events)
+}
+
+// subscribeLocked subscribes the simulator to kind k on the current
+// connection. Caller must hold s.mu.
+func (s *NearbyFacilities) subscribeLocked(k facility.Kind) error {
+	entered, left := nearbyRequestIDs(k)
+	return s.client.SubscribeToFacilitiesEX1(types.SIMCONNECT_FACILITY_LIST_TYPE(k), entered, left)
+}
+
+// clearLocked empties the in-range set of kind k and returns a Left event
+// for each entry. Caller must hold s.mu.
+func (s *NearbyFacilities) clearLocked(k facility.Kind) []NearbyFacilityEvent {
+	set := s.inRange[k]
+	delete(s.inRange, k)
+	events := make([]NearbyFacilityEvent, 0, len(set))
+	for _, f := range set {
+		events = append(events, NearbyFacilityEvent{Change: NearbyFacilityLeft, Facility: f})
+	}
+	return events
+}
+
+// subscribersLocked returns a snapshot of the subscriptions.
+// Caller must hold s.mu.
+func (s *NearbyFacilities) subscribersLocked() []*nearbyFacilitySubscription {
+	subs := make([]*nearbyFacilitySubscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// publishNearby delivers events to every subscription, outside s.mu.
+func publishNearby(subs []*nearbyFacilitySubscription, events []NearbyFacilityEvent) {
+	for _, ev := range events {
+		for _, sub := range subs {
+			sub.deliver(ev)
+		}
+	}
+}
+
+// setClient binds the service to a new connection, or detaches it when
+// client is nil. Either way the in-range sets are emptied with Left events;
+// on connect every watched kind is subscribed again and the simulator
+// refills them.
+func (s *NearbyFacilities) setClient(client engine.Client) {
+	s.mu.Lock()
+	s.client = client
+	var events []NearbyFacilityEvent
+	for k := range s.inRange {
+		events = append(events, s.clearLocked(k)...)
+	}
+	if client != nil {
+		for k := range s.watched {
+			if err := s.subscribeLocked(k); err != nil {
+				s.m.logger.Error("[manager] Failed to subscribe to nearby facilities", "kind", k, "error", err)
+			}
+		}
+	}
+	subs := s.subscribersLocked()
+	s.mu.Unlock()
+
+	publishNearby(subs, events)
+}
+
+type nearbyFacilitySubscription struct {
+	id      string
+	ch      chan NearbyFacilityEvent
+	done    chan struct{}
+	nearby  *NearbyFacilities
+	closeMu sync.Mutex
+	closed  bool
+}
+
+func (s *nearbyFacilitySubscription) ID() string                         { return s.id }
+func (s *nearbyFacilitySubscription) Events() <-chan NearbyFacilityEvent { return s.ch }
+func (s *nearbyFacilitySubscription) Done() <-chan struct{}              { return s.done }
+
+// deliver forwards ev without blocking. It holds closeMu so a concurrent
+// Unsubscribe cannot close the channel mid-send.
+func (s *nearbyFacilitySubscription) deliver(ev NearbyFacilityEvent) {
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- ev:
+	default:
+		s.nearby.m.logger.Debug("[manager] Nearby facility subscription channel full, dropping event")
+	}
+}
+
+func (s *nearbyFacilitySubscription) Unsubscribe() {
+	s.nearby.mu.Lock()
+	delete(s.nearby.subs, s.id)
+	s.nearby.mu.Unlock()
+
+	s.closeMu.Lock()
+	defer s.closeMu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	close(s.ch)
+}
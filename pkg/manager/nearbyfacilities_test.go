@@ -0,0 +1,110 @@
+//go:build windows
+
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeNearbyClient records facility subscriptions and answers each with the
+// airports currently in range.
+type fakeNearbyClient struct {
+	engine.Client
+	m            *Instance
+	inRange      [][]byte
+	subscribed   int
+	unsubscribed int
+}
+
+func (c *fakeNearbyClient) SubscribeToFacilitiesEX1(listType types.SIMCONNECT_FACILITY_LIST_TYPE, newElemInRangeRequestID uint32, oldElemOutRangeRequestID uint32) error {
+	c.subscribed++
+	// The dispatcher delivers the reply on its own goroutine, once the
+	// subscribing caller has released the service.
+	go c.m.processMessage(newAirportListMessage(newElemInRangeRequestID, 0, 1, c.inRange...))
+	return nil
+}
+
+func (c *fakeNearbyClient) UnsubscribeToFacilitiesEX1(listType types.SIMCONNECT_FACILITY_LIST_TYPE, unsubscribeNewInRange bool, unsubscribeOldOutRange bool) error {
+	c.unsubscribed++
+	return nil
+}
+
+// nextNearbyEvent waits for the next event of sub.
+func nextNearbyEvent(t *testing.T, sub NearbyFacilitySubscription) NearbyFacilityEvent {
+	t.Helper()
+	select {
+	case ev := <-sub.Events():
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for nearby facility event")
+	}
+	return NearbyFacilityEvent{}
+}
+
+func TestNearbyFacilities(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.nearbyFacilities = newNearbyFacilities(m)
+	m.simState = SimState{Latitude: 50.2, Longitude: 14.4}
+	client := &fakeNearbyClient{
+		m:       m,
+		inRange: [][]byte{packedListAirport("LKPR", 50.1), packedListAirport("LKVO", 50.2)},
+	}
+	nearby := m.NearbyFacilities()
+	sub := nearby.Subscribe("test", 8)
+	defer sub.Unsubscribe()
+
+	// Watching before the connection subscribes on connect.
+	if err := nearby.Watch(facility.KindAirport); err != nil {
+		t.Fatal(err)
+	}
+	nearby.setClient(client)
+	for i := 0; i < 2; i++ {
+		if ev := nextNearbyEvent(t, sub); ev.Change != NearbyFacilityEntered {
+			t.Fatalf("event %d = %+v, want entered", i, ev)
+		}
+	}
+	if got := nearby.Nearest(facility.KindAirport, 1); len(got) != 1 || got[0].ICAO != "LKVO" {
+		t.Fatalf("Nearest = %+v, want LKVO", got)
+	}
+
+	entered, left := nearbyRequestIDs(facility.KindAirport)
+	m.processMessage(newAirportListMessage(entered, 0, 1, packedListAirport("LKKB", 50.12)))
+	if ev := nextNearbyEvent(t, sub); ev.Change != NearbyFacilityEntered || ev.Facility.ICAO != "LKKB" {
+		t.Fatalf("event = %+v, want LKKB entered", ev)
+	}
+	m.processMessage(newAirportListMessage(left, 0, 1, packedListAirport("LKPR", 0)))
+	if ev := nextNearbyEvent(t, sub); ev.Change != NearbyFacilityLeft || ev.Facility.ICAO != "LKPR" || ev.Facility.Latitude != 50.1 {
+		t.Fatalf("event = %+v, want LKPR left with its known position", ev)
+	}
+	if got := nearby.InRange(facility.KindAirport); len(got) != 2 || got[0].ICAO != "LKKB" || got[1].ICAO != "LKVO" {
+		t.Fatalf("InRange = %+v", got)
+	}
+
+	// A reconnect empties the set and the simulator refills it.
+	nearby.setClient(nil)
+	for i := 0; i < 2; i++ {
+		if ev := nextNearbyEvent(t, sub); ev.Change != NearbyFacilityLeft {
+			t.Fatalf("disconnect event %d = %+v, want left", i, ev)
+		}
+	}
+	nearby.setClient(client)
+	for i := 0; i < 2; i++ {
+		nextNearbyEvent(t, sub)
+	}
+	if client.subscribed != 2 || len(nearby.InRange(facility.KindAirport)) != 2 {
+		t.Fatalf("after reconnect: %d subscriptions, in range %+v", client.subscribed, nearby.InRange(facility.KindAirport))
+	}
+
+	if err := nearby.Unwatch(facility.KindAirport); err != nil || client.unsubscribed != 1 {
+		t.Fatalf("Unwatch: err = %v, %d unsubscriptions", err, client.unsubscribed)
+	}
+	if got := nearby.InRange(facility.KindAirport); len(got) != 0 {
+		t.Fatalf("InRange after Unwatch = %+v", got)
+	}
+}
@@ -6,6 +6,7 @@ package manager
 import (
 	"sync"
 
+	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/manager/internal/subscriptions"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
@@ -55,6 +56,17 @@ func (s *objectSubscription) Unsubscribe() {
 	}
 }
 
+// decodeObjectEvent extracts an ObjectEvent from msg when it matches eventID.
+// It consumes msg by releasing the subscription reference before returning.
+func decodeObjectEvent(msg engine.Message, eventID uint32) (ObjectEvent, bool) {
+	defer msg.Release()
+	o := msg.AsEventObjectAddRemove()
+	if o == nil || o.UEventID != types.DWORD(eventID) {
+		return ObjectEvent{}, false
+	}
+	return ObjectEvent{ObjectID: uint32(o.DwData), ObjType: o.EObjType}, true
+}
+
 // SubscribeOnObjectAdded returns a subscription delivering ObjectAdded events
 func (m *Instance) SubscribeOnObjectAdded(id string, bufferSize int) ObjectSubscription {
 	id = subscriptions.GenerateID(id)
@@ -74,15 +86,12 @@ func (m *Instance) SubscribeOnObjectAdded(id string, bufferSize int) ObjectSubsc
 				if !ok {
 					return
 				}
-				o := msg.AsEventObjectAddRemove()
-				if o == nil {
-					continue
-				}
-				if o.UEventID != types.DWORD(m.objectAddedEventID) {
+				ev, ok := decodeObjectEvent(msg, m.objectAddedEventID)
+				if !ok {
 					continue
 				}
 				select {
-				case os.ch <- ObjectEvent{ObjectID: uint32(o.DwData), ObjType: o.EObjType}:
+				case os.ch <- ev:
 				default:
 					m.logger.Debug("[manager] ObjectAdded subscription channel full, dropping event")
 				}
@@ -111,15 +120,12 @@ func (m *Instance) SubscribeOnObjectRemoved(id string, bufferSize int) ObjectSub
 				if !ok {
 					return
 				}
-				o := msg.AsEventObjectAddRemove()
-				if o == nil {
-					continue
-				}
-				if o.UEventID != types.DWORD(m.objectRemovedEventID) {
+				ev, ok := decodeObjectEvent(msg, m.objectRemovedEventID)
+				if !ok {
 					continue
 				}
 				select {
-				case os.ch <- ObjectEvent{ObjectID: uint32(o.DwData), ObjType: o.EObjType}:
+				case os.ch <- ev:
 				default:
 					m.logger.Debug("[manager] ObjectRemoved subscription channel full, dropping event")
 				}
@@ -78,6 +78,16 @@ func (m *Instance) registerSimStateSubscriptions(client engine.Client) {
 		m.logger.Error("[manager] Failed to subscribe to FlightPlanDeactivated event", "error", err)
 	}
 
+	// Re-subscribe to flow events if they were enabled (MSFS 2024 only)
+	m.mu.RLock()
+	flowEventsWanted := m.flowEventsWanted
+	m.mu.RUnlock()
+	if flowEventsWanted {
+		if err := client.SubscribeToFlowEvent(); err != nil {
+			m.logger.Error("[manager] Failed to subscribe to flow events", "error", err)
+		}
+	}
+
 	// Define camera data structure
 	m.requestRegistry.Register(m.cameraDefinitionID, RequestTypeDataDefinition, "Simulator State Definition")
 	if err := client.AddToDataDefinition(m.cameraDefinitionID, "CAMERA STATE", "", types.SIMCONNECT_DATATYPE_FLOAT64, 0, 0); err != nil {
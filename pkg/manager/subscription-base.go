@@ -20,15 +20,20 @@ type subscription struct {
 	ch      chan engine.Message
 	done    chan struct{}
 	closed  atomic.Bool
-	closeMu sync.Mutex // kept for channel close coordination only
 	manager *Instance
+	// Number of dispatcher sends currently in progress. Unsubscribe waits
+	// on drained for it to reach zero before closing ch, so the dispatch hot
+	// path only needs atomic operations instead of a per-subscription mutex.
+	inflight atomic.Int32
+	drainMu  sync.Mutex
+	drained  sync.Cond // L is &drainMu; signalled when inflight drops to zero after close
 	// Optional filter predicate. If non-nil, only messages for which
 	// filter(msg) == true are forwarded to this subscription.
 	filter func(engine.Message) bool
-	// Optional set of allowed SIMCONNECT_RECV_ID values. If non-nil
-	// and non-empty, only messages whose DwID matches one of the keys
-	// will be forwarded.
-	allowedTypes map[types.SIMCONNECT_RECV_ID]struct{}
+	// Routing restrictions (RECV IDs and request/event IDs) used to index
+	// the subscription, so messages outside the route never reach the
+	// filter.
+	route subscriptions.Route
 	// Optional callback invoked when messages are dropped due to full buffer.
 	// Called with the number of messages dropped (typically 1).
 	// Must not block - called from message dispatch loop.
@@ -69,7 +74,8 @@ func (s *subscription) ID() string {
 	return s.id
 }
 
-// Messages returns the channel for receiving messages
+// Messages returns the channel for receiving messages.
+// Receivers own every delivered message and must Release it when done.
 func (s *subscription) Messages() <-chan engine.Message {
 	return s.ch
 }
@@ -86,23 +92,70 @@ func (s *subscription) Unsubscribe() {
 	if s.closed.Swap(true) {
 		return // already closed
 	}
-	s.closeMu.Lock()
+
+	// Remove from manager's subscription map and dispatch index
+	s.manager.mu.Lock()
+	if s.manager.subscriptions[s.id] == s {
+		delete(s.manager.subscriptions, s.id)
+	}
+	s.manager.publishSubscriptionsLocked()
+	s.manager.mu.Unlock()
+
+	// A dispatcher that loaded the previous index may still be sending;
+	// it re-checks closed after announcing itself, so waiting for the
+	// in-flight count to drain makes closing the channel safe.
+	s.drainMu.Lock()
+	for s.inflight.Load() != 0 {
+		s.drained.Wait()
+	}
+	s.drainMu.Unlock()
 	close(s.done) // Signal consumers to stop
 	close(s.ch)   // Close message channel
-	s.closeMu.Unlock()
 
-	s.cancel() // Cancel the subscription's context
+	// Messages still buffered were never received; release them so their
+	// buffers return to the pool.
+	for msg := range s.ch {
+		msg.Release()
+	}
 
-	// Remove from manager's subscription map
-	s.manager.mu.Lock()
-	delete(s.manager.subscriptions, s.id)
-	s.manager.mu.Unlock()
+	s.cancel() // Cancel the subscription's context
 
 	// Signal WaitGroup that this subscription is done
 	s.manager.subsWg.Done()
 	s.manager.logger.Debug("[manager] Unsubscribed: " + s.id)
 }
 
+// trySend delivers a retained reference to msg without blocking. It reports
+// false when the buffer is full and the message was dropped; a closed
+// subscription silently skips the message.
+func (s *subscription) trySend(msg engine.Message) bool {
+	s.inflight.Add(1)
+	defer s.sendDone()
+	if s.closed.Load() {
+		return true
+	}
+	// Each subscription receives its own reference; the subscriber
+	// owns it and must call Release once done with the message.
+	ref := msg.Retain()
+	select {
+	case s.ch <- ref:
+		return true
+	default:
+		ref.Release()
+		return false
+	}
+}
+
+// sendDone ends a send started by trySend and wakes Unsubscribe when it was
+// the last one in flight on a closed subscription.
+func (s *subscription) sendDone() {
+	if s.inflight.Add(-1) == 0 && s.closed.Load() {
+		s.drainMu.Lock()
+		s.drained.Broadcast()
+		s.drainMu.Unlock()
+	}
+}
+
 // SubscriptionOption is a functional option for configuring subscriptions
 type SubscriptionOption func(*subscription)
 
@@ -116,6 +169,19 @@ func WithOnDrop(fn func(dropped int)) SubscriptionOption {
 	}
 }
 
+// WithRequestIDs restricts a subscription to messages carrying one of the
+// given request IDs (DwRequestID for data, system state, facility and input
+// event responses; UEventID for event messages). Messages without such an
+// ID are not delivered. The restriction is resolved through the dispatch
+// index, so it is cheaper than an equivalent filter. Combine it with
+// SubscribeWithType to avoid matching unrelated message kinds that reuse
+// the same numeric ID.
+func WithRequestIDs(ids ...uint32) SubscriptionOption {
+	return func(s *subscription) {
+		s.route.Keys = append(s.route.Keys, ids...)
+	}
+}
+
 // Subscribe creates a new message subscription that delivers messages to a channel.
 // The returned Subscription can be used to receive messages in an isolated goroutine.
 // The id parameter is a unique identifier for the subscription (use "" for auto-generated UUID).
@@ -124,36 +190,8 @@ func WithOnDrop(fn func(dropped int)) SubscriptionOption {
 // Call Unsubscribe() when done to release resources.
 // Optional SubscriptionOption parameters can be provided to configure drop notifications.
 func (m *Instance) Subscribe(id string, bufferSize int, opts ...SubscriptionOption) Subscription {
-	id = subscriptions.GenerateID(id)
-	bufferSize = subscriptions.ValidateBufferSize(bufferSize)
-
-	// Derive context from manager's context for automatic cancellation
-	subCtx, subCancel := context.WithCancel(m.ctx)
-
-	sub := &subscription{
-		id:      id,
-		ctx:     subCtx,
-		cancel:  subCancel,
-		ch:      make(chan engine.Message, bufferSize),
-		done:    make(chan struct{}),
-		manager: m,
-	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(sub)
-	}
-
-	m.mu.Lock()
-	m.subscriptions[id] = sub
-	m.subsWg.Add(1)
-	m.mu.Unlock()
-
-	// Start goroutine to watch for context cancellation with tracking
-	sub.watchWg.Add(1)
-	go sub.watchContext()
-
-	m.logger.Debug("[manager] Created subscription: " + id)
+	sub := m.addSubscription(id, bufferSize, nil, nil, opts)
+	m.logger.Debug("[manager] Created subscription: " + sub.id)
 	return sub
 }
 
@@ -161,38 +199,8 @@ func (m *Instance) Subscribe(id string, bufferSize int, opts ...SubscriptionOpti
 // to a channel only when the provided filter returns true for a message.
 // Optional SubscriptionOption parameters can be provided to configure drop notifications.
 func (m *Instance) SubscribeWithFilter(id string, bufferSize int, filter func(engine.Message) bool, opts ...SubscriptionOption) Subscription {
-	id = subscriptions.GenerateID(id)
-	bufferSize = subscriptions.ValidateBufferSize(bufferSize)
-
-	// Derive context from manager's context for automatic cancellation
-	subCtx, subCancel := context.WithCancel(m.ctx)
-
-	sub := &subscription{
-		id:           id,
-		ctx:          subCtx,
-		cancel:       subCancel,
-		ch:           make(chan engine.Message, bufferSize),
-		done:         make(chan struct{}),
-		manager:      m,
-		filter:       filter,
-		allowedTypes: nil,
-	}
-
-	// Apply options
-	for _, opt := range opts {
-		opt(sub)
-	}
-
-	m.mu.Lock()
-	m.subscriptions[id] = sub
-	m.subsWg.Add(1)
-	m.mu.Unlock()
-
-	// Start goroutine to watch for context cancellation with tracking
-	sub.watchWg.Add(1)
-	go sub.watchContext()
-
-	m.logger.Debug("[manager] Created filtered subscription: " + id)
+	sub := m.addSubscription(id, bufferSize, filter, nil, opts)
+	m.logger.Debug("[manager] Created filtered subscription: " + sub.id)
 	return sub
 }
 
@@ -200,26 +208,31 @@ func (m *Instance) SubscribeWithFilter(id string, bufferSize int, filter func(en
 // only when their DwID (SIMCONNECT_RECV_ID) is one of the provided types.
 // Optional SubscriptionOption parameters can be provided to configure drop notifications.
 func (m *Instance) SubscribeWithType(id string, bufferSize int, recvIDs []types.SIMCONNECT_RECV_ID, opts ...SubscriptionOption) Subscription {
+	sub := m.addSubscription(id, bufferSize, nil, recvIDs, opts)
+	m.logger.Debug("[manager] Created type subscription: " + sub.id)
+	return sub
+}
+
+// addSubscription creates, registers and publishes a message subscription.
+func (m *Instance) addSubscription(id string, bufferSize int, filter func(engine.Message) bool, recvIDs []types.SIMCONNECT_RECV_ID, opts []SubscriptionOption) *subscription {
 	id = subscriptions.GenerateID(id)
 	bufferSize = subscriptions.ValidateBufferSize(bufferSize)
 
 	// Derive context from manager's context for automatic cancellation
 	subCtx, subCancel := context.WithCancel(m.ctx)
 
-	allowed := make(map[types.SIMCONNECT_RECV_ID]struct{}, len(recvIDs))
-	for _, r := range recvIDs {
-		allowed[r] = struct{}{}
-	}
-
 	sub := &subscription{
-		id:           id,
-		ctx:          subCtx,
-		cancel:       subCancel,
-		ch:           make(chan engine.Message, bufferSize),
-		done:         make(chan struct{}),
-		manager:      m,
-		filter:       nil,
-		allowedTypes: allowed,
+		id:      id,
+		ctx:     subCtx,
+		cancel:  subCancel,
+		ch:      make(chan engine.Message, bufferSize),
+		done:    make(chan struct{}),
+		manager: m,
+		filter:  filter,
+	}
+	sub.drained.L = &sub.drainMu
+	for _, r := range recvIDs {
+		sub.route.Types = append(sub.route.Types, uint32(r))
 	}
 
 	// Apply options
@@ -230,12 +243,25 @@ func (m *Instance) SubscribeWithType(id string, bufferSize int, recvIDs []types.
 	m.mu.Lock()
 	m.subscriptions[id] = sub
 	m.subsWg.Add(1)
+	m.publishSubscriptionsLocked()
 	m.mu.Unlock()
 
 	// Start goroutine to watch for context cancellation with tracking
 	sub.watchWg.Add(1)
 	go sub.watchContext()
 
-	m.logger.Debug("[manager] Created type subscription: " + id)
 	return sub
 }
+
+// publishSubscriptionsLocked rebuilds the dispatch index from the
+// subscription map and swaps it in. The dispatcher reads the index without
+// locking. Caller must hold m.mu for writing.
+func (m *Instance) publishSubscriptionsLocked() {
+	subs := make([]*subscription, 0, len(m.subscriptions))
+	for _, sub := range m.subscriptions {
+		subs = append(subs, sub)
+	}
+	m.subsIndex.Store(subscriptions.NewIndex(subs, func(s *subscription) subscriptions.Route {
+		return s.route
+	}))
+}
@@ -13,13 +13,23 @@ import (
 // The fleet is valid for the lifetime of the manager but is reset (cleared) on
 // each reconnect — ObjectIDs are invalidated when the simulator disconnects.
 //
+// The manager keeps the fleet in sync with the message stream: assigned
+// ObjectIDs acknowledge pending creations, creation exceptions fail them,
+// creations unanswered after WithFleetPendingTimeout time out, and aircraft
+// reported by ObjectRemoved leave the fleet. Subscribe to follow these
+// changes. After a reconnect, Fleet().Restore(Fleet().Discarded(), opts)
+// brings back the aircraft of the previous connection.
+//
 // Usage pattern:
 //
+//	events := mgr.Fleet().Subscribe(0)
 //	mgr.TrafficNonATC(opts, reqID)
-//	// ... receive SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID ...
-//	aircraft, ok := mgr.Fleet().Acknowledge(reqID, objectID)
-//	mgr.Fleet().ReleaseControl(aircraft.ObjectID, releaseReqID)
-//	mgr.Fleet().SetWaypoints(aircraft.ObjectID, defID, wps)
+//	for ev := range events.Events() {
+//		if ev.Kind == traffic.FleetSpawned && ev.Pending.ReqID == reqID {
+//			mgr.Fleet().ReleaseControl(ev.Aircraft.ObjectID, releaseReqID)
+//			mgr.Fleet().SetWaypoints(ev.Aircraft.ObjectID, defID, wps)
+//		}
+//	}
 func (m *Instance) Fleet() *traffic.Fleet {
 	return m.fleet
 }
@@ -27,9 +37,9 @@ func (m *Instance) Fleet() *traffic.Fleet {
 // TrafficParked queues a parked ATC aircraft creation at an airport gate.
 // Returns ErrNotConnected if not connected to the simulator.
 //
-// An SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID message will arrive shortly after;
-// call m.Fleet().Acknowledge(reqID, objectID) from your message handler to
-// register the aircraft in the fleet.
+// The aircraft joins the fleet when its SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID
+// message arrives; a traffic.FleetSpawned event is published to
+// m.Fleet().Subscribe subscriptions.
 func (m *Instance) TrafficParked(opts traffic.ParkedOpts, reqID uint32) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -53,7 +63,7 @@ func (m *Instance) TrafficEnroute(opts traffic.EnrouteOpts, reqID uint32) error
 // TrafficNonATC queues a non-ATC aircraft creation at an explicit position.
 // Returns ErrNotConnected if not connected to the simulator.
 //
-// After acknowledging the ObjectID, call TrafficReleaseControl followed by
+// Once the aircraft is spawned, call TrafficReleaseControl followed by
 // TrafficSetWaypoints to begin waypoint-guided movement (pushback, taxi, takeoff).
 func (m *Instance) TrafficNonATC(opts traffic.NonATCOpts, reqID uint32) error {
 	m.mu.RLock()
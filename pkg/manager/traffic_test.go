@@ -0,0 +1,210 @@
+//go:build windows
+
+package manager
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeTrafficClient accepts parked creations and numbers their packets.
+type fakeTrafficClient struct {
+	engine.Client
+	sendID   uint32
+	requests map[uint32]uint32 // objectID → telemetry request ID
+}
+
+func (c *fakeTrafficClient) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	c.sendID++
+	return nil
+}
+
+func (c *fakeTrafficClient) GetLastSentPacketID() (uint32, error) {
+	return c.sendID, nil
+}
+
+func (c *fakeTrafficClient) RegisterDataset(definitionID uint32, dataset *datasets.DataSet) error {
+	return nil
+}
+
+func (c *fakeTrafficClient) RequestDataOnSimObject(requestID uint32, definitionID uint32, objectID uint32, period types.SIMCONNECT_PERIOD, flags types.SIMCONNECT_DATA_REQUEST_FLAG, origin uint32, interval uint32, limit uint32) error {
+	if c.requests == nil {
+		c.requests = make(map[uint32]uint32)
+	}
+	if period == types.SIMCONNECT_PERIOD_NEVER {
+		delete(c.requests, objectID)
+	} else {
+		c.requests[objectID] = requestID
+	}
+	return nil
+}
+
+// newAssignedObjectIDMessage returns an unpooled ASSIGNED_OBJECT_ID message.
+func newAssignedObjectIDMessage(requestID, objectID uint32) engine.Message {
+	a := &types.SIMCONNECT_RECV_ASSIGNED_OBJECT_ID{}
+	a.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID)
+	a.DwRequestID = types.DWORD(requestID)
+	a.DwObjectID = types.DWORD(objectID)
+	return engine.Message{SIMCONNECT_RECV: &a.SIMCONNECT_RECV}
+}
+
+// newExceptionMessage returns an unpooled EXCEPTION message for sendID.
+func newExceptionMessage(sendID uint32, exception types.SIMCONNECT_EXCEPTION) engine.Message {
+	ex := &types.SIMCONNECT_RECV_EXCEPTION{}
+	ex.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EXCEPTION)
+	ex.DwSendID = types.DWORD(sendID)
+	ex.DwException = types.DWORD(exception)
+	return engine.Message{SIMCONNECT_RECV: &ex.SIMCONNECT_RECV}
+}
+
+// newObjectRemovedMessage returns an unpooled ObjectRemoved event message.
+func newObjectRemovedMessage(objectID uint32) engine.Message {
+	ev := &types.SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE{}
+	ev.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE)
+	ev.UEventID = types.DWORD(ObjectRemovedEventID)
+	ev.DwData = types.DWORD(objectID)
+	ev.EObjType = types.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT
+	return engine.Message{SIMCONNECT_RECV: &ev.SIMCONNECT_RECV}
+}
+
+// newFleetDataMessage returns an unpooled SIMOBJECT_DATA message carrying
+// latitude, longitude and altitude as consecutive FLOAT64 datums.
+func newFleetDataMessage(requestID uint32, lat, lon, alt float64) engine.Message {
+	header := int(unsafe.Offsetof(types.SIMCONNECT_RECV_SIMOBJECT_DATA{}.DwData))
+	buf := make([]uint64, (header+24+7)/8)
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), len(buf)*8)
+	for i, v := range []float64{lat, lon, alt} {
+		binary.LittleEndian.PutUint64(raw[header+8*i:], math.Float64bits(v))
+	}
+	data := (*types.SIMCONNECT_RECV_SIMOBJECT_DATA)(unsafe.Pointer(&buf[0]))
+	data.DwSize = types.DWORD(header + 24)
+	data.DwID = types.DWORD(types.SIMCONNECT_RECV_ID_SIMOBJECT_DATA)
+	data.DwRequestID = types.DWORD(requestID)
+	return engine.Message{SIMCONNECT_RECV: &data.SIMCONNECT_RECV}
+}
+
+// nextFleetEvent waits for the next event of sub.
+func nextFleetEvent(t *testing.T, sub *traffic.FleetSubscription) traffic.FleetEvent {
+	t.Helper()
+	select {
+	case ev := <-sub.Events():
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for fleet event")
+	}
+	return traffic.FleetEvent{}
+}
+
+func TestFleetAutoWiring(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.objectRemovedEventID = ObjectRemovedEventID
+	m.fleet = traffic.NewFleet(&fakeTrafficClient{})
+	sub := m.Fleet().Subscribe(8)
+	defer sub.Unsubscribe()
+
+	parked := func(tail string, reqID uint32) {
+		t.Helper()
+		if err := m.fleet.RequestParked(traffic.ParkedOpts{Model: "A320", Tail: tail, Airport: "LKPR"}, reqID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An assigned object ID acknowledges the creation.
+	parked("OK-AAA", 100)
+	if !m.handleMessage(newAssignedObjectIDMessage(100, 7)) {
+		t.Fatal("ASSIGNED_OBJECT_ID should be forwarded")
+	}
+	if ev := nextFleetEvent(t, sub); ev.Kind != traffic.FleetSpawned || ev.Aircraft.ObjectID != 7 || ev.Pending.ReqID != 100 {
+		t.Fatalf("event = %+v, want spawned 7", ev)
+	}
+
+	// An exception for the creation's packet fails it.
+	parked("OK-BBB", 101)
+	m.handleMessage(newExceptionMessage(2, types.SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED))
+	ev := nextFleetEvent(t, sub)
+	var cerr *traffic.CreationError
+	if ev.Kind != traffic.FleetSpawnFailed || !errors.As(ev.Err, &cerr) || cerr.ReqID != 101 || !errors.Is(ev.Err, traffic.ErrCreationFailed) {
+		t.Fatalf("event = %+v, want creation error for 101", ev)
+	}
+
+	// A creation that is never answered times out.
+	m.fleet.SetPendingTimeout(10 * time.Millisecond)
+	parked("OK-CCC", 102)
+	if ev := nextFleetEvent(t, sub); ev.Kind != traffic.FleetSpawnFailed || !errors.Is(ev.Err, traffic.ErrSpawnTimeout) {
+		t.Fatalf("event = %+v, want timeout", ev)
+	}
+	if n := m.fleet.PendingCount(); n != 0 {
+		t.Fatalf("PendingCount = %d, want 0", n)
+	}
+
+	// The simulator removing the aircraft drops it from the fleet.
+	m.handleMessage(newObjectRemovedMessage(7))
+	if ev := nextFleetEvent(t, sub); ev.Kind != traffic.FleetRemoved || ev.Aircraft.Tail != "OK-AAA" {
+		t.Fatalf("event = %+v, want OK-AAA removed", ev)
+	}
+	if n := m.fleet.Len(); n != 0 {
+		t.Fatalf("Len = %d, want 0", n)
+	}
+}
+
+func TestFleetTracking(t *testing.T) {
+	m := newDispatchTestManager()
+	defer m.cancel()
+	m.objectRemovedEventID = ObjectRemovedEventID
+	client := &fakeTrafficClient{}
+	m.fleet = traffic.NewFleet(client)
+	m.fleet.SetTrackIDs(FleetTrackDefinitionID, FleetTrackRequestIDMin, FleetTrackRequestIDMax)
+	sub := m.Fleet().Subscribe(8)
+	defer sub.Unsubscribe()
+	updates := m.Fleet().SubscribeUpdates(8)
+	defer updates.Unsubscribe()
+
+	ds := datasets.NewBuilder().
+		AddField("PLANE LATITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE LONGITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE ALTITUDE", "feet", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		Build()
+	if err := m.fleet.Track(&ds, types.SIMCONNECT_PERIOD_SECOND); err != nil {
+		t.Fatal(err)
+	}
+
+	// An acknowledged aircraft gets a telemetry request.
+	if err := m.fleet.RequestParked(traffic.ParkedOpts{Model: "A320", Tail: "OK-AAA", Airport: "LKPR"}, 100); err != nil {
+		t.Fatal(err)
+	}
+	m.handleMessage(newAssignedObjectIDMessage(100, 7))
+	nextFleetEvent(t, sub)
+	reqID, ok := client.requests[7]
+	if !ok || reqID < FleetTrackRequestIDMin || reqID > FleetTrackRequestIDMax {
+		t.Fatalf("telemetry request = %d, %v; want one in the fleet range", reqID, ok)
+	}
+
+	// Its data updates the aircraft.
+	if !m.handleMessage(newFleetDataMessage(reqID, 50.1, 14.26, 1247)) {
+		t.Fatal("SIMOBJECT_DATA should be forwarded")
+	}
+	ev := nextFleetEvent(t, updates)
+	if ev.Kind != traffic.FleetUpdated || ev.Aircraft.State.Latitude != 50.1 || ev.Aircraft.State.Altitude != 1247 {
+		t.Fatalf("event = %+v, want update of OK-AAA", ev)
+	}
+	if a, _ := m.fleet.Get(7); a.State.Longitude != 14.26 {
+		t.Fatalf("State = %+v, want longitude 14.26", a.State)
+	}
+
+	// Removal cancels the request.
+	m.handleMessage(newObjectRemovedMessage(7))
+	nextFleetEvent(t, sub)
+	if _, ok := client.requests[7]; ok {
+		t.Fatal("telemetry request not cancelled on removal")
+	}
+}
@@ -6,9 +6,15 @@ package traffic
 // Aircraft is a handle for a spawned AI aircraft whose ObjectID has been
 // confirmed by SimConnect. Handles are created by Fleet.Acknowledge and stored
 // inside the Fleet. All aircraft operations are performed through the Fleet.
+//
+// A tracked aircraft's handle is replaced on every telemetry update rather
+// than modified, so a handle is safe to read while the fleet changes; call
+// Fleet.Get again for the latest State.
 type Aircraft struct {
 	// ObjectID is the SimConnect object identifier assigned by the simulator.
 	ObjectID uint32
+	// ReqID is the request ID its creation was made with.
+	ReqID uint32
 	// Kind indicates how the aircraft was created (parked / enroute / non-ATC).
 	Kind AircraftKind
 	// Model is the container title string used during creation.
@@ -17,4 +23,9 @@ type Aircraft struct {
 	Livery string
 	// Tail is the tail number or identifier string used during creation.
 	Tail string
+	// State is the latest telemetry while the fleet is tracking (Fleet.Track);
+	// zero until the first update.
+	State Telemetry
+
+	spec AircraftSnapshot // creation options and later assignments, for Snapshot
 }
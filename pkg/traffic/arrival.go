@@ -0,0 +1,204 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// DefaultGlidepathAngle is the descent angle, in degrees, used by
+// PlanArrival when ArrivalOpts.GlidepathAngle is zero.
+const DefaultGlidepathAngle = 3.0
+
+// Arrival geometry, in meters from the runway threshold.
+const (
+	touchdownDistance  = 300.0   // aiming point past the threshold
+	shortFinalDistance = 1852.0  // 1 nm out, where every arrival is lined up
+	straightInDistance = 18520.0 // 10 nm out, where a straight-in starts
+)
+
+// approachProfile holds the approach speeds and landing rollout of a
+// category.
+type approachProfile struct {
+	initial   float64 // kts until the final approach fix
+	final     float64 // kts from the final approach fix
+	touchdown float64 // kts
+	rollout   float64 // meters from the threshold to the runway exit
+}
+
+var approachProfiles = map[AircraftCategory]approachProfile{
+	CategoryLight:     {90, 75, 65, 400},
+	CategoryTurboprop: {160, 120, 110, 900},
+	CategoryJet:       {180, 150, 135, 1500},
+	CategoryHeavy:     {190, 160, 145, 2000},
+}
+
+// ArrivalOpts configures PlanArrival.
+type ArrivalOpts struct {
+	Category AircraftCategory // sizes speeds and rollout; zero is CategoryJet
+	Parking  int              // index into Airport.Parking to taxi to
+	// Transition names the approach transition to fly before the final
+	// approach legs. Empty flies the final approach legs only.
+	Transition string
+	// GlidepathAngle is the descent angle, in degrees, of a straight-in and
+	// of legs without an altitude constraint. Zero means DefaultGlidepathAngle.
+	GlidepathAngle float64
+	// Rollout is how far past the threshold, in meters, the aircraft slows
+	// enough to leave the runway. Zero takes the category's default.
+	Rollout float64
+	Speeds  TaxiSpeeds // taxi speed profile after landing
+	// Constraints shape the taxi route. AvoidRunways is always set.
+	Constraints airportgraph.Constraints
+}
+
+// PlanArrival builds the complete waypoint chain for a non-ATC aircraft
+// landing on runway end runway, e.g. "24", and taxiing to the parking spot
+// opts.Parking:
+//
+//	[DescentWaypoint...] [TouchdownWaypoint] [TaxiWaypoint...]
+//
+// With an approach, the descent follows the fixes of its legs, at their
+// altitude constraints, up to the missed approach point. Pass an element of
+// ap.Approaches, fetched with facility.IncludeApproaches. With a nil
+// approach, or one whose legs carry no fix positions, it is a straight-in
+// from 10 nm on the glidepath. Either way the aircraft is lined up 1 nm out
+// and touches down 300 m past the threshold. After the rollout it leaves the
+// runway at the first exit past opts.Rollout and taxis over the airport's
+// taxi network.
+//
+// Returns ErrApproachRunway, ErrUnknownTransition,
+// airportgraph.ErrUnknownNode for a parking spot the airport does not have,
+// airportgraph.ErrUnknownRunway or airportgraph.ErrNoRoute.
+func PlanArrival(ap *facility.Airport, runway string, approach *facility.Approach, opts ArrivalOpts) ([]types.SIMCONNECT_DATA_WAYPOINT, error) {
+	p := newGroundPlan(ap, opts.Speeds)
+	prof, ok := approachProfiles[opts.Category]
+	if !ok {
+		prof = approachProfiles[CategoryJet]
+	}
+	if opts.Rollout <= 0 {
+		opts.Rollout = prof.rollout
+	}
+	opts.Rollout = math.Max(opts.Rollout, touchdownDistance)
+	angle := opts.GlidepathAngle
+	if angle <= 0 {
+		angle = DefaultGlidepathAngle
+	}
+
+	n, ok := p.g.ParkingNode(opts.Parking)
+	if !ok {
+		return nil, fmt.Errorf("%w: parking %d", airportgraph.ErrUnknownNode, opts.Parking)
+	}
+	arr, err := p.g.ArrivalRoute(runway, opts.Rollout, n, opts.Constraints)
+	if err != nil {
+		return nil, err
+	}
+	t := arr.Threshold
+	thrLat, thrLon := p.latLon(t.X, t.Z)
+	// glidepath returns the altitude, in feet MSL, of the glidepath at
+	// meters from the touchdown point.
+	glidepath := func(meters float64) float64 {
+		return p.altFt + convert.MetersToFeet(meters*math.Tan(angle*math.Pi/180))
+	}
+
+	var legs []facility.Leg
+	if approach != nil {
+		if legs, err = approachLegs(approach, t.Runway, opts.Transition); err != nil {
+			return nil, err
+		}
+	}
+
+	var wps []types.SIMCONNECT_DATA_WAYPOINT
+	if len(legs) > 0 {
+		kts := prof.initial
+		for _, l := range legs {
+			if l.IsFAF {
+				kts = prof.final
+			}
+			dist := calc.HaversineMeters(thrLat, thrLon, l.FixLatitude, l.FixLongitude)
+			alt := glidepath(dist + touchdownDistance)
+			if l.Altitude1 > 0 {
+				alt = convert.MetersToFeet(l.Altitude1)
+			}
+			speed := kts
+			if l.SpeedLimit > 0 && l.SpeedLimit < speed {
+				speed = l.SpeedLimit
+			}
+			wps = append(wps, DescentWaypoint(l.FixLatitude, l.FixLongitude, alt, speed))
+		}
+		if last := legs[len(legs)-1]; calc.HaversineMeters(thrLat, thrLon, last.FixLatitude, last.FixLongitude) <= 1.5*shortFinalDistance {
+			wps = wps[:len(wps)-1] // too close in to turn onto short final
+		}
+	} else {
+		for _, d := range []float64{straightInDistance, 11112, 5556} { // 10, 6 and 3 nm
+			lat, lon := calc.DisplaceByHeading(thrLat, thrLon, t.Heading+180, d)
+			kts := prof.final
+			if d == straightInDistance {
+				kts = prof.initial
+			}
+			wps = append(wps, DescentWaypoint(lat, lon, glidepath(d+touchdownDistance), kts))
+		}
+	}
+
+	lat, lon := calc.DisplaceByHeading(thrLat, thrLon, t.Heading+180, shortFinalDistance)
+	wps = append(wps, DescentWaypoint(lat, lon, glidepath(shortFinalDistance+touchdownDistance), prof.final))
+	lat, lon = calc.DisplaceByHeading(thrLat, thrLon, t.Heading, touchdownDistance)
+	wps = append(wps, TouchdownWaypoint(lat, lon, p.altFt, prof.touchdown))
+
+	// Roll out to the exit on the centreline, then taxi off.
+	lat, lon, _ = p.g.LatLon(arr.Access.Entry)
+	wps = append(wps, TaxiWaypoint(lat, lon, p.altFt, p.speeds.Turn))
+	return append(wps, p.taxi(arr.Route.Nodes)...), nil
+}
+
+// approachLegs returns the legs of approach to fly to runway, from the
+// transition named transition, if any, to the missed approach point. Legs
+// without a fix position and runway fixes are left out, so the result is
+// empty for approach data without fix positions.
+func approachLegs(approach *facility.Approach, runway, transition string) ([]facility.Leg, error) {
+	if approach.Runway() != runway {
+		return nil, fmt.Errorf("%w: approach to %s, runway %s", ErrApproachRunway, approach.Runway(), runway)
+	}
+	var legs []facility.Leg
+	if transition != "" {
+		found := false
+		for _, tr := range approach.Transitions {
+			if strings.EqualFold(tr.Name, transition) || strings.EqualFold(tr.IAFICAO, transition) {
+				legs = append(legs, tr.Legs...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTransition, transition)
+		}
+	}
+	legs = append(legs, approach.FinalLegs...)
+
+	out := make([]facility.Leg, 0, len(legs))
+	for _, l := range legs {
+		if strings.HasPrefix(l.FixICAO, "RW") {
+			break
+		}
+		if l.FixLatitude == 0 && l.FixLongitude == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].FixICAO == l.FixICAO {
+			out[n-1] = l // transition ends where the final approach starts
+		} else {
+			out = append(out, l)
+		}
+		if l.IsMAP {
+			break
+		}
+	}
+	return out, nil
+}
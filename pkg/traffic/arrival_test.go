@@ -0,0 +1,229 @@
+//go:build windows
+
+package traffic
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+// glidepathFt returns the altitude, in feet MSL, of the 3° glidepath to the
+// fixture's runway at meters from the threshold.
+func glidepathFt(meters float64) float64 {
+	return convert.MetersToFeet(fixtureAltitude) + convert.MetersToFeet((meters+touchdownDistance)*math.Tan(3*math.Pi/180))
+}
+
+// fix returns an approach leg to a fix meters before the threshold of
+// runway 09 in g.
+func fix(g *airportgraph.Graph, ident string, meters float64) facility.Leg {
+	lat, lon, _ := g.LatLon(r0)
+	lat, lon = calc.DisplaceByHeading(lat, lon, 270, meters)
+	return facility.Leg{FixICAO: ident, FixLatitude: lat, FixLongitude: lon}
+}
+
+// fixtureApproach returns an approach to runway 09 of the fixture airport
+// with a transition from IAF ALPHA:
+//
+//	ALPHA (25 km, 1500 m) - INTER (15 km) - FINAL (8 km, FAF) - MAPT (2 km, MAP) - MISSD
+func fixtureApproach(g *airportgraph.Graph) *facility.Approach {
+	iaf := fix(g, "ALPHA", 25000)
+	iaf.IsIAF, iaf.Altitude1 = true, 1500
+	inter := fix(g, "INTER", 15000)
+	inter.IsIF, inter.SpeedLimit = true, 170
+	faf := fix(g, "FINAL", 8000)
+	faf.IsFAF = true
+	mapt := fix(g, "MAPT", 2000)
+	mapt.IsMAP = true
+	return &facility.Approach{
+		RunwayNumber: 9,
+		Transitions: []facility.ApproachTransition{
+			{Name: "ALPHA1", IAFICAO: "ALPHA", Legs: []facility.Leg{iaf, fix(g, "INTER", 15000)}},
+		},
+		FinalLegs: []facility.Leg{
+			inter, faf,
+			{FixICAO: "CD09"}, // no position
+			mapt,
+			fix(g, "MISSD", 0),
+		},
+	}
+}
+
+func TestPlanArrivalStraightIn(t *testing.T) {
+	ap := fixtureAirport()
+	g := airportgraph.Build(ap)
+	thrLat, thrLon, _ := g.LatLon(r0)
+
+	wps, err := PlanArrival(ap, "09", nil, ArrivalOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10, 6, 3 and 1 nm out on the glidepath, then the touchdown.
+	descent := []struct {
+		meters float64
+		kts    float64
+	}{{18520, 180}, {11112, 150}, {5556, 150}, {1852, 150}}
+	for i, d := range descent {
+		lat, lon := calc.DisplaceByHeading(thrLat, thrLon, 270, d.meters)
+		if !near(wps[i].Latitude, wps[i].Longitude, lat, lon) {
+			t.Errorf("descent %d not %v m before the threshold", i, d.meters)
+		}
+		if want := glidepathFt(d.meters); math.Abs(wps[i].Altitude-want) > 1e-6 {
+			t.Errorf("descent %d: %v ft, want %v on the glidepath", i, wps[i].Altitude, want)
+		}
+		if wps[i].KtsSpeed != d.kts {
+			t.Errorf("descent %d: %v kts, want %v", i, wps[i].KtsSpeed, d.kts)
+		}
+	}
+	td := wps[len(descent)]
+	lat, lon := calc.DisplaceByHeading(thrLat, thrLon, 90, touchdownDistance)
+	if !near(td.Latitude, td.Longitude, lat, lon) || td.KtsSpeed != 135 || td.Altitude != convert.MetersToFeet(fixtureAltitude) {
+		t.Errorf("touchdown = %+v, want 135 kts 300 m past the threshold", td)
+	}
+	if last := wps[len(wps)-1]; !atNode(g, last, p0) {
+		t.Errorf("last waypoint = %+v, want the gate", last)
+	}
+}
+
+func TestPlanArrivalExit(t *testing.T) {
+	ap := fixtureAirport()
+	g := airportgraph.Build(ap)
+	// Exits of runway 09 are 0, 800 and 1600 m past the threshold.
+	tests := []struct {
+		name string
+		opts ArrivalOpts
+		exit int
+	}{
+		{"jet", ArrivalOpts{}, r4},
+		{"light", ArrivalOpts{Category: CategoryLight}, r2},
+		{"short rollout", ArrivalOpts{Rollout: 500}, r2},
+		{"past the last exit", ArrivalOpts{Rollout: 3000}, r4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wps, err := PlanArrival(ap, "09", nil, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Straight-in, short final and touchdown come first.
+			exit := wps[5]
+			if !atNode(g, exit, tt.exit) || exit.KtsSpeed != DefaultTurnSpeed {
+				t.Errorf("exit = %+v, want node %d at turn speed", exit, tt.exit)
+			}
+			if last := wps[len(wps)-1]; !atNode(g, last, p0) {
+				t.Errorf("last waypoint = %+v, want the gate", last)
+			}
+		})
+	}
+}
+
+func TestPlanArrivalApproach(t *testing.T) {
+	ap := fixtureAirport()
+	g := airportgraph.Build(ap)
+	approach := fixtureApproach(g)
+	thrLat, thrLon, _ := g.LatLon(r0)
+
+	type descent struct {
+		fix string
+		alt float64 // feet MSL; zero is on the glidepath
+		kts float64
+	}
+	alpha := descent{"ALPHA", convert.MetersToFeet(1500), 180}
+	inter := descent{"INTER", 0, 170}
+	final := descent{"FINAL", 0, 150}
+	tests := []struct {
+		name       string
+		transition string
+		want       []descent // MAPT is dropped, being within 1.5 nm
+	}{
+		{"final legs", "", []descent{inter, final}},
+		{"transition by name", "alpha1", []descent{alpha, inter, final}},
+		{"transition by IAF", "ALPHA", []descent{alpha, inter, final}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wps, err := PlanArrival(ap, "09", approach, ArrivalOpts{Transition: tt.transition})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, d := range tt.want {
+				var l facility.Leg
+				for _, l = range append(approach.Transitions[0].Legs, approach.FinalLegs...) {
+					if l.FixICAO == d.fix {
+						break
+					}
+				}
+				if !near(wps[i].Latitude, wps[i].Longitude, l.FixLatitude, l.FixLongitude) {
+					t.Errorf("waypoint %d not at %s", i, d.fix)
+				}
+				alt := d.alt
+				if alt == 0 {
+					alt = glidepathFt(calc.HaversineMeters(thrLat, thrLon, l.FixLatitude, l.FixLongitude))
+				}
+				if math.Abs(wps[i].Altitude-alt) > 1e-6 || wps[i].KtsSpeed != d.kts {
+					t.Errorf("waypoint %d at %s: %v ft %v kts, want %v ft %v kts", i, d.fix, wps[i].Altitude, wps[i].KtsSpeed, alt, d.kts)
+				}
+			}
+			// Short final follows the last fix.
+			lat, lon := calc.DisplaceByHeading(thrLat, thrLon, 270, shortFinalDistance)
+			if sf := wps[len(tt.want)]; !near(sf.Latitude, sf.Longitude, lat, lon) {
+				t.Errorf("waypoint %d = %+v, want short final", len(tt.want), sf)
+			}
+		})
+	}
+}
+
+func TestApproachLegs(t *testing.T) {
+	g := airportgraph.Build(fixtureAirport())
+	approach := fixtureApproach(g)
+
+	legs, err := approachLegs(approach, "09", "ALPHA1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// INTER ends the transition and starts the final legs; the final leg
+	// replaces it. CD09 has no position and MISSD follows the MAP.
+	want := []string{"ALPHA", "INTER", "FINAL", "MAPT"}
+	if len(legs) != len(want) {
+		t.Fatalf("legs = %+v, want %v", legs, want)
+	}
+	for i, l := range legs {
+		if l.FixICAO != want[i] {
+			t.Fatalf("leg %d = %s, want %s", i, l.FixICAO, want[i])
+		}
+	}
+	if legs[1].SpeedLimit != 170 {
+		t.Error("duplicate INTER kept the transition leg")
+	}
+
+	rw := *approach
+	rw.FinalLegs = append([]facility.Leg{approach.FinalLegs[0], fix(g, "RW09", 0)}, approach.FinalLegs[1:]...)
+	if legs, err = approachLegs(&rw, "09", ""); err != nil || len(legs) != 1 {
+		t.Errorf("legs = %+v, %v, want INTER only up to the runway fix", legs, err)
+	}
+
+	if _, err := approachLegs(approach, "27", ""); !errors.Is(err, ErrApproachRunway) {
+		t.Errorf("other runway: err = %v, want ErrApproachRunway", err)
+	}
+	if _, err := PlanArrival(fixtureAirport(), "27", approach, ArrivalOpts{}); !errors.Is(err, ErrApproachRunway) {
+		t.Errorf("PlanArrival to other runway: err = %v, want ErrApproachRunway", err)
+	}
+	if _, err := approachLegs(approach, "09", "BRAVO"); !errors.Is(err, ErrUnknownTransition) {
+		t.Errorf("unknown transition: err = %v, want ErrUnknownTransition", err)
+	}
+}
+
+func TestPlanArrivalErrors(t *testing.T) {
+	ap := fixtureAirport()
+	if _, err := PlanArrival(ap, "09", nil, ArrivalOpts{Parking: 1}); !errors.Is(err, airportgraph.ErrUnknownNode) {
+		t.Errorf("unknown parking: err = %v, want ErrUnknownNode", err)
+	}
+	if _, err := PlanArrival(ap, "18", nil, ArrivalOpts{}); !errors.Is(err, airportgraph.ErrUnknownRunway) {
+		t.Errorf("unknown runway: err = %v, want ErrUnknownRunway", err)
+	}
+}
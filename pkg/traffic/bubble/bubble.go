@@ -0,0 +1,223 @@
+// Package bubble keeps a traffic "bubble" around the user aircraft: a
+// target number of AI aircraft within a radius of the user, set per flight
+// phase. As the user moves, aircraft left behind are removed and new ones
+// are spawned, slowly enough not to cause stutters, and only while the
+// scene does not already hold enough traffic of its own.
+//
+// Controller makes the decisions and has no simulator dependency.
+// FleetBubble carries them out on a traffic.Fleet.
+package bubble
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+const (
+	// DefaultTerminalCeiling is the height above ground, in feet, below
+	// which an airborne user is in the terminal area.
+	DefaultTerminalCeiling = 10000.0
+
+	// DefaultSpawnInterval is the minimum time between two spawns.
+	DefaultSpawnInterval = 5 * time.Second
+
+	// DefaultRemoveMargin scales the radius beyond which aircraft are
+	// removed, so aircraft near the edge are not removed and spawned again
+	// as the user moves.
+	DefaultRemoveMargin = 1.25
+)
+
+// Phase is the flight phase of the user, which selects the Target.
+type Phase uint8
+
+const (
+	PhaseGround   Phase = iota // on the ground
+	PhaseTerminal              // airborne below the terminal ceiling
+	PhaseEnroute               // airborne above the terminal ceiling
+)
+
+// String returns "ground", "terminal" or "enroute".
+func (p Phase) String() string {
+	switch p {
+	case PhaseGround:
+		return "ground"
+	case PhaseTerminal:
+		return "terminal"
+	case PhaseEnroute:
+		return "enroute"
+	}
+	return fmt.Sprintf("Phase(%d)", uint8(p))
+}
+
+// Target is the traffic wanted in a phase: Count aircraft within Radius
+// meters of the user. Other traffic in the radius counts towards Count.
+type Target struct {
+	Count  int
+	Radius float64
+}
+
+// Options configure a Controller. Zero values of the tuning fields use the
+// defaults; a zero Target means no traffic in that phase.
+type Options struct {
+	Ground   Target
+	Terminal Target
+	Enroute  Target
+
+	// TerminalCeiling is the height above ground, in feet, separating
+	// PhaseTerminal from PhaseEnroute.
+	TerminalCeiling float64
+	// SpawnInterval is the minimum time between two spawns.
+	SpawnInterval time.Duration
+	// RemoveMargin scales the radius beyond which aircraft are removed, at
+	// least 1.
+	RemoveMargin float64
+}
+
+// DefaultOptions returns options for a moderately busy bubble: 8 aircraft
+// within 5 km on the ground, 6 within 40 km in the terminal area and 4
+// within 150 km enroute.
+func DefaultOptions() Options {
+	return Options{
+		Ground:   Target{Count: 8, Radius: 5000},
+		Terminal: Target{Count: 6, Radius: 40000},
+		Enroute:  Target{Count: 4, Radius: 150000},
+	}
+}
+
+// Target returns the target of phase p.
+func (o Options) Target(p Phase) Target {
+	switch p {
+	case PhaseGround:
+		return o.Ground
+	case PhaseTerminal:
+		return o.Terminal
+	}
+	return o.Enroute
+}
+
+// User is the state of the user aircraft.
+type User struct {
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	AltitudeAGL float64 // feet
+	OnGround    bool
+}
+
+// Member is an aircraft the bubble spawned.
+type Member struct {
+	ObjectID  uint32
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	// Located is false until the position is known; such aircraft are
+	// counted as inside the bubble and never removed.
+	Located bool
+}
+
+// Situation is what a Controller decides on.
+type Situation struct {
+	User    User
+	Members []Member
+	// Pending is the number of spawns not yet acknowledged; they count as
+	// inside the bubble.
+	Pending int
+	// Others is the number of aircraft within the phase radius that the
+	// bubble did not spawn, the user excluded.
+	Others int
+}
+
+// Decision is what to do next.
+type Decision struct {
+	Phase  Phase
+	Target Target
+	// Spawn asks for one new aircraft within Target.Radius.
+	Spawn bool
+	// Remove lists members to remove, farthest first.
+	Remove []uint32
+}
+
+// Controller decides when to spawn and remove aircraft. It is not safe
+// for concurrent use.
+type Controller struct {
+	opts      Options
+	lastSpawn time.Time
+}
+
+// NewController returns a controller using opts.
+func NewController(opts Options) *Controller {
+	if opts.TerminalCeiling <= 0 {
+		opts.TerminalCeiling = DefaultTerminalCeiling
+	}
+	if opts.SpawnInterval <= 0 {
+		opts.SpawnInterval = DefaultSpawnInterval
+	}
+	if opts.RemoveMargin < 1 {
+		opts.RemoveMargin = DefaultRemoveMargin
+	}
+	return &Controller{opts: opts}
+}
+
+// Options returns the options in use, defaults applied.
+func (c *Controller) Options() Options { return c.opts }
+
+// Phase returns the phase of u.
+func (c *Controller) Phase(u User) Phase {
+	switch {
+	case u.OnGround:
+		return PhaseGround
+	case u.AltitudeAGL < c.opts.TerminalCeiling:
+		return PhaseTerminal
+	}
+	return PhaseEnroute
+}
+
+// Decide returns what to do at now. Members beyond the phase radius times
+// RemoveMargin are removed. When the bubble holds more than the target,
+// the farthest members are removed too; other traffic is never removed.
+// One spawn is asked for while the bubble holds less than the target and
+// SpawnInterval has passed since the last one.
+func (c *Controller) Decide(now time.Time, s Situation) Decision {
+	phase := c.Phase(s.User)
+	target := c.opts.Target(phase)
+	d := Decision{Phase: phase, Target: target}
+
+	type ranged struct {
+		id   uint32
+		dist float64
+	}
+	var located []ranged
+	unlocated := 0
+	for _, m := range s.Members {
+		if !m.Located {
+			unlocated++
+			continue
+		}
+		dist := calc.HaversineMeters(s.User.Latitude, s.User.Longitude, m.Latitude, m.Longitude)
+		located = append(located, ranged{m.ObjectID, dist})
+	}
+	sort.Slice(located, func(i, j int) bool { return located[i].dist > located[j].dist })
+
+	limit := target.Radius * c.opts.RemoveMargin
+	var inside []ranged
+	for _, r := range located {
+		if r.dist > limit {
+			d.Remove = append(d.Remove, r.id)
+		} else {
+			inside = append(inside, r)
+		}
+	}
+
+	total := len(inside) + unlocated + s.Pending + s.Others
+	for i := 0; total > target.Count && i < len(inside); i++ {
+		d.Remove = append(d.Remove, inside[i].id)
+		total--
+	}
+
+	if total < target.Count && now.Sub(c.lastSpawn) >= c.opts.SpawnInterval {
+		d.Spawn = true
+		c.lastSpawn = now
+	}
+	return d
+}
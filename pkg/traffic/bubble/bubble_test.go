@@ -0,0 +1,78 @@
+package bubble
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+// memberAt returns a located member dist meters north of the origin.
+func memberAt(id uint32, dist float64) Member {
+	lat, lon := calc.DisplaceByHeading(0, 0, 0, dist)
+	return Member{ObjectID: id, Latitude: lat, Longitude: lon, Located: true}
+}
+
+func TestPhase(t *testing.T) {
+	c := NewController(DefaultOptions())
+	tests := []struct {
+		user User
+		want Phase
+	}{
+		{User{OnGround: true}, PhaseGround},
+		{User{AltitudeAGL: 3000}, PhaseTerminal},
+		{User{AltitudeAGL: 35000}, PhaseEnroute},
+	}
+	for _, tt := range tests {
+		if got := c.Phase(tt.user); got != tt.want {
+			t.Errorf("Phase(%+v) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	opts := Options{Ground: Target{Count: 3, Radius: 5000}, SpawnInterval: time.Second}
+	ground := User{OnGround: true}
+	t0 := time.Unix(1000, 0)
+
+	tests := []struct {
+		name   string
+		s      Situation
+		spawn  bool
+		remove []uint32
+	}{
+		{"empty scene spawns", Situation{User: ground}, true, nil},
+		{"full with others", Situation{User: ground, Others: 3}, false, nil},
+		{"pending counts", Situation{User: ground, Members: []Member{memberAt(1, 100)}, Pending: 2}, false, nil},
+		{"beyond margin removed farthest first",
+			Situation{User: ground, Members: []Member{memberAt(1, 6500), memberAt(2, 100), memberAt(3, 9000)}},
+			true, []uint32{3, 1}},
+		{"inside margin kept", Situation{User: ground, Members: []Member{memberAt(1, 6000), memberAt(2, 100), memberAt(3, 200)}}, false, nil},
+		{"excess over busy scene",
+			Situation{User: ground, Members: []Member{memberAt(1, 1000), memberAt(2, 3000), {ObjectID: 3}}, Others: 2},
+			false, []uint32{2, 1}},
+	}
+	for _, tt := range tests {
+		c := NewController(opts)
+		d := c.Decide(t0, tt.s)
+		if d.Spawn != tt.spawn || !reflect.DeepEqual(d.Remove, tt.remove) {
+			t.Errorf("%s: spawn = %v, remove = %v; want %v, %v", tt.name, d.Spawn, d.Remove, tt.spawn, tt.remove)
+		}
+	}
+}
+
+func TestDecideSpawnInterval(t *testing.T) {
+	c := NewController(Options{Terminal: Target{Count: 5, Radius: 40000}, SpawnInterval: 10 * time.Second})
+	s := Situation{User: User{AltitudeAGL: 2000}}
+	t0 := time.Unix(1000, 0)
+
+	for i, tt := range []struct {
+		at    time.Duration
+		spawn bool
+	}{{0, true}, {5 * time.Second, false}, {10 * time.Second, true}, {11 * time.Second, false}} {
+		if d := c.Decide(t0.Add(tt.at), s); d.Spawn != tt.spawn || d.Phase != PhaseTerminal {
+			t.Errorf("step %d: spawn = %v, phase = %v; want %v", i, d.Spawn, d.Phase, tt.spawn)
+		}
+	}
+}
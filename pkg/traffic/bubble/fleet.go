@@ -0,0 +1,277 @@
+//go:build windows
+// +build windows
+
+package bubble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// MaxCountRadius is the largest radius, in meters, SimConnect accepts for
+// RequestDataOnSimObjectType; larger phase radii are counted within it.
+const MaxCountRadius = 200000
+
+// pendingExpiry is how long a spawn counts as pending when its outcome
+// was never seen, e.g. because HandleEvent was not given the event. The
+// bubble then removes the aircraft, or fails the creation if it is still
+// pending in the fleet.
+const pendingExpiry = 2 * traffic.DefaultPendingTimeout
+
+// Requester issues the data request FleetBubble counts the scene's traffic
+// with, usually the manager.
+type Requester interface {
+	RequestDataOnSimObjectType(requestID uint32, definitionID uint32, dwRadiusMeters uint32, objectType types.SIMCONNECT_SIMOBJECT_TYPE) error
+}
+
+// SpawnRequest asks a SpawnFunc for an aircraft.
+type SpawnRequest struct {
+	Phase  Phase
+	User   User
+	Radius float64 // meters around the user the aircraft should appear in
+}
+
+// Spawn is the aircraft a SpawnFunc picked. Set Parked or Enroute.
+type Spawn struct {
+	Parked  *traffic.ParkedOpts
+	Enroute *traffic.EnrouteOpts
+}
+
+// SpawnFunc picks an aircraft to spawn, e.g. parked at an airport within
+// the radius on the ground, or enroute between two such airports in the
+// air.
+type SpawnFunc func(req SpawnRequest) (Spawn, error)
+
+// Config configures a FleetBubble.
+type Config struct {
+	Options Options
+	Spawn   SpawnFunc
+
+	// ReqIDMin and ReqIDMax bound the request IDs of creations and
+	// removals, taken in turn.
+	ReqIDMin, ReqIDMax uint32
+
+	// CountRequestID is the request ID of the traffic count, and
+	// CountDefinitionID the data definition it requests. Any registered
+	// definition will do, e.g. manager.FleetTrackDefinitionID once
+	// Fleet().Track is active.
+	CountRequestID    uint32
+	CountDefinitionID uint32
+}
+
+// FleetBubble runs a Controller on a traffic.Fleet. It spawns the aircraft
+// its SpawnFunc picks and removes them again; aircraft it did not spawn are
+// left alone. Member positions come from Aircraft.State, so the fleet must
+// be tracking (Fleet.Track).
+//
+// Each Tick also requests the aircraft within the phase radius with
+// RequestDataOnSimObjectType. Route the SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE
+// answers to HandleData; aircraft the bubble did not spawn, the user
+// excluded, count towards the target.
+type FleetBubble struct {
+	ctrl      *Controller
+	fleet     *traffic.Fleet
+	requester Requester
+	cfg       Config
+
+	mu      sync.Mutex
+	user    User
+	hasUser bool
+	members map[uint32]struct{}  // object IDs spawned by the bubble
+	pending map[uint32]time.Time // request ID → time of spawn
+	reqIDs  *traffic.RequestIDs
+	others  int // other aircraft in the last complete count
+	counted int // other aircraft so far in the count being received
+}
+
+// NewFleetBubble returns a bubble spawning into fleet and counting traffic
+// through requester.
+func NewFleetBubble(fleet *traffic.Fleet, requester Requester, cfg Config) *FleetBubble {
+	return &FleetBubble{
+		ctrl:      NewController(cfg.Options),
+		fleet:     fleet,
+		requester: requester,
+		cfg:       cfg,
+		members:   make(map[uint32]struct{}),
+		pending:   make(map[uint32]time.Time),
+		reqIDs:    traffic.NewRequestIDs(cfg.ReqIDMin, cfg.ReqIDMax),
+	}
+}
+
+// SetUser records the state of the user aircraft. Nothing is spawned
+// before the first call.
+func (b *FleetBubble) SetUser(u User) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.user, b.hasUser = u, true
+}
+
+// HandleData counts an answer to the traffic count request. Reports
+// whether msg belonged to it.
+func (b *FleetBubble) HandleData(msg *types.SIMCONNECT_RECV_SIMOBJECT_DATA) bool {
+	if uint32(msg.DwRequestID) != b.cfg.CountRequestID {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if msg.DwOutOf == 0 {
+		b.others, b.counted = 0, 0
+		return true
+	}
+	if msg.DwEntryNumber <= 1 {
+		b.counted = 0
+	}
+	if !b.ownLocked(uint32(msg.DwObjectID)) {
+		b.counted++
+	}
+	if msg.DwEntryNumber >= msg.DwOutOf {
+		// The user aircraft is part of the answer.
+		b.others = max(b.counted-1, 0)
+	}
+	return true
+}
+
+// ownLocked reports whether objectID is one of the bubble's aircraft. A
+// spawn acknowledged before HandleEvent saw it is counted as pending, so it
+// must not count as other traffic as well. Caller must hold b.mu.
+func (b *FleetBubble) ownLocked(objectID uint32) bool {
+	if _, ok := b.members[objectID]; ok {
+		return true
+	}
+	a, ok := b.fleet.Get(objectID)
+	if !ok {
+		return false
+	}
+	_, pending := b.pending[a.ReqID]
+	return pending
+}
+
+// HandleEvent follows the fate of the bubble's spawns. Run calls it; call
+// it yourself when driving Tick from your own loop.
+func (b *FleetBubble) HandleEvent(ev traffic.FleetEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch ev.Kind {
+	case traffic.FleetSpawned:
+		if _, ok := b.pending[ev.Pending.ReqID]; ok {
+			delete(b.pending, ev.Pending.ReqID)
+			b.members[ev.Aircraft.ObjectID] = struct{}{}
+		}
+	case traffic.FleetSpawnFailed:
+		delete(b.pending, ev.Pending.ReqID)
+	case traffic.FleetRemoved:
+		delete(b.members, ev.Aircraft.ObjectID)
+	}
+}
+
+// Members returns the object IDs of the aircraft the bubble spawned.
+func (b *FleetBubble) Members() []uint32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ids := make([]uint32, 0, len(b.members))
+	for id := range b.members {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Tick decides and performs the removals and the spawn due at now, then
+// requests a new traffic count. Returns the joined errors of these calls.
+func (b *FleetBubble) Tick(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.hasUser {
+		return nil
+	}
+
+	s := Situation{User: b.user, Others: b.others}
+	for id := range b.members {
+		a, ok := b.fleet.Get(id)
+		if !ok {
+			delete(b.members, id)
+			continue
+		}
+		s.Members = append(s.Members, Member{
+			ObjectID:  id,
+			Latitude:  a.State.Latitude,
+			Longitude: a.State.Longitude,
+			Located:   !a.State.Updated.IsZero(),
+		})
+	}
+	var errs []error
+	for reqID, at := range b.pending {
+		if now.Sub(at) > pendingExpiry {
+			delete(b.pending, reqID)
+			if err := b.expireLocked(reqID); err != nil {
+				errs = append(errs, fmt.Errorf("expiring spawn %d: %w", reqID, err))
+			}
+		}
+	}
+	s.Pending = len(b.pending)
+
+	d := b.ctrl.Decide(now, s)
+	for _, id := range d.Remove {
+		if err := b.fleet.Remove(id, b.reqIDs.Next()); err != nil {
+			errs = append(errs, fmt.Errorf("removing %d: %w", id, err))
+			continue
+		}
+		delete(b.members, id)
+	}
+	if d.Spawn && b.cfg.Spawn != nil {
+		if err := b.spawnLocked(now, SpawnRequest{Phase: d.Phase, User: b.user, Radius: d.Target.Radius}); err != nil {
+			errs = append(errs, fmt.Errorf("spawning: %w", err))
+		}
+	}
+	radius := min(d.Target.Radius, MaxCountRadius)
+	if err := b.requester.RequestDataOnSimObjectType(b.cfg.CountRequestID, b.cfg.CountDefinitionID, uint32(radius), types.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT); err != nil {
+		errs = append(errs, fmt.Errorf("counting traffic: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// expireLocked gives up on the spawn reqID: the aircraft it created is
+// removed, or the creation is failed if it is still pending.
+// Caller must hold b.mu.
+func (b *FleetBubble) expireLocked(reqID uint32) error {
+	for _, a := range b.fleet.List() {
+		if _, member := b.members[a.ObjectID]; a.ReqID != reqID || member {
+			continue
+		}
+		return b.fleet.Remove(a.ObjectID, b.reqIDs.Next())
+	}
+	b.fleet.Fail(reqID, fmt.Errorf("%w: bubble spawn %d", traffic.ErrSpawnTimeout, reqID))
+	return nil
+}
+
+// spawnLocked creates the aircraft the SpawnFunc picks for req.
+// Caller must hold b.mu.
+func (b *FleetBubble) spawnLocked(now time.Time, req SpawnRequest) error {
+	sp, err := b.cfg.Spawn(req)
+	if err != nil {
+		return err
+	}
+	reqID := b.reqIDs.Next()
+	switch {
+	case sp.Parked != nil:
+		err = b.fleet.RequestParked(*sp.Parked, reqID)
+	case sp.Enroute != nil:
+		err = b.fleet.RequestEnroute(*sp.Enroute, reqID)
+	default:
+		return nil
+	}
+	if err == nil {
+		b.pending[reqID] = now
+	}
+	return err
+}
+
+// Run drives the bubble with traffic.Run until ctx is done.
+func (b *FleetBubble) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return traffic.Run(ctx, b.fleet, b, interval, onError)
+}
@@ -0,0 +1,119 @@
+//go:build windows
+
+package bubble
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeClient records the creations and removals made through the fleet and
+// accepts the traffic count requests.
+type fakeClient struct {
+	engine.Client
+	mu      sync.Mutex
+	sendID  uint32
+	created []uint32 // request IDs
+	removed []uint32 // object IDs
+}
+
+func (c *fakeClient) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.created = append(c.created, RequestID)
+	return nil
+}
+
+func (c *fakeClient) AIRemoveObject(objectID uint32, requestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.removed = append(c.removed, objectID)
+	return nil
+}
+
+func (c *fakeClient) GetLastSentPacketID() (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendID, nil
+}
+
+func (c *fakeClient) RequestDataOnSimObjectType(requestID uint32, definitionID uint32, dwRadiusMeters uint32, objectType types.SIMCONNECT_SIMOBJECT_TYPE) error {
+	return nil
+}
+
+// newTestBubble returns a ground bubble wanting three aircraft, with the
+// user set, and the client behind its fleet.
+func newTestBubble() (*FleetBubble, *traffic.Fleet, *fakeClient) {
+	c := &fakeClient{}
+	f := traffic.NewFleet(c)
+	f.SetPendingTimeout(0)
+	b := NewFleetBubble(f, c, Config{
+		Options: Options{Ground: Target{Count: 3, Radius: 5000}, SpawnInterval: time.Second},
+		Spawn: func(SpawnRequest) (Spawn, error) {
+			return Spawn{Parked: &traffic.ParkedOpts{Model: "A320", Airport: "LKPR"}}, nil
+		},
+		ReqIDMin:       100,
+		ReqIDMax:       199,
+		CountRequestID: 50,
+	})
+	b.SetUser(User{OnGround: true})
+	return b, f, c
+}
+
+// countAnswer returns entry n of outOf in an answer to the traffic count.
+func countAnswer(objectID, n, outOf uint32) *types.SIMCONNECT_RECV_SIMOBJECT_DATA {
+	return &types.SIMCONNECT_RECV_SIMOBJECT_DATA{
+		DwRequestID:   50,
+		DwObjectID:    types.DWORD(objectID),
+		DwEntryNumber: types.DWORD(n),
+		DwOutOf:       types.DWORD(outOf),
+	}
+}
+
+func TestFleetBubbleAcknowledgedSpawnIsNotOtherTraffic(t *testing.T) {
+	b, f, _ := newTestBubble()
+	t0 := time.Unix(1000, 0)
+	if err := b.Tick(t0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The spawn is acknowledged and counted before HandleEvent hears of it.
+	if _, ok := f.Acknowledge(100, 7); !ok {
+		t.Fatal("spawn not pending in the fleet")
+	}
+	b.HandleData(countAnswer(1, 1, 2)) // the user
+	b.HandleData(countAnswer(7, 2, 2))
+
+	if b.others != 0 || len(b.pending) != 1 {
+		t.Fatalf("others = %d, pending = %d; want the spawn counted once, as pending", b.others, len(b.pending))
+	}
+}
+
+func TestFleetBubbleExpiryRemovesAircraft(t *testing.T) {
+	b, f, c := newTestBubble()
+	t0 := time.Unix(1000, 0)
+	if err := b.Tick(t0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.Acknowledge(100, 7); !ok {
+		t.Fatal("spawn not pending in the fleet")
+	}
+
+	// The FleetSpawned event never reaches the bubble.
+	if err := b.Tick(t0.Add(pendingExpiry + time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.Get(7); ok {
+		t.Fatal("aircraft of the expired spawn still in the fleet")
+	}
+	if len(c.removed) != 1 || c.removed[0] != 7 {
+		t.Fatalf("removed %v, want [7]", c.removed)
+	}
+}
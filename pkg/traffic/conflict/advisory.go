@@ -0,0 +1,335 @@
+package conflict
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/convert"
+)
+
+const (
+	// DefaultResponseDelay is how long after an RA the own aircraft is
+	// assumed to start the manoeuvre.
+	DefaultResponseDelay = 5 * time.Second
+
+	// DefaultClimbRate is the vertical speed, in feet per minute, an RA
+	// asks for.
+	DefaultClimbRate = 1500.0
+
+	// DefaultProximateRange and DefaultProximateAltitude bound proximate
+	// traffic, in nautical miles and feet.
+	DefaultProximateRange    = 6.0
+	DefaultProximateAltitude = 1200.0
+)
+
+// Level is the severity of an advisory.
+type Level uint8
+
+const (
+	LevelNone      Level = iota // other traffic
+	LevelProximate              // within 6 NM and 1200 ft
+	LevelTA                     // traffic advisory
+	LevelRA                     // resolution advisory
+)
+
+// String returns "none", "proximate", "TA" or "RA".
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelProximate:
+		return "proximate"
+	case LevelTA:
+		return "TA"
+	case LevelRA:
+		return "RA"
+	}
+	return fmt.Sprintf("Level(%d)", uint8(l))
+}
+
+// Sense is the vertical direction of an RA.
+type Sense int8
+
+const (
+	SenseNone    Sense = 0
+	SenseClimb   Sense = 1
+	SenseDescend Sense = -1
+)
+
+// String returns "climb", "descend" or "none".
+func (s Sense) String() string {
+	switch s {
+	case SenseClimb:
+		return "climb"
+	case SenseDescend:
+		return "descend"
+	}
+	return "none"
+}
+
+// Opposite returns the complementary sense, for coordinating an RA with
+// the intruder.
+func (s Sense) Opposite() Sense { return -s }
+
+// Options tune the advisory logic. Zero values use the defaults.
+type Options struct {
+	// TAOnly inhibits RAs, as the TA ONLY mode of TCAS does.
+	TAOnly bool
+	// ResponseDelay is the assumed delay before an RA manoeuvre.
+	ResponseDelay time.Duration
+	// ClimbRate is the vertical speed, in feet per minute, of an RA.
+	ClimbRate float64
+	// ProximateRange and ProximateAltitude bound proximate traffic, in
+	// nautical miles and feet.
+	ProximateRange    float64
+	ProximateAltitude float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.ResponseDelay <= 0 {
+		o.ResponseDelay = DefaultResponseDelay
+	}
+	if o.ClimbRate <= 0 {
+		o.ClimbRate = DefaultClimbRate
+	}
+	if o.ProximateRange <= 0 {
+		o.ProximateRange = DefaultProximateRange
+	}
+	if o.ProximateAltitude <= 0 {
+		o.ProximateAltitude = DefaultProximateAltitude
+	}
+	return o
+}
+
+// Advisory is the assessment of one intruder.
+type Advisory struct {
+	Intruder uint32
+	Level    Level
+	Geometry Geometry
+	// Sense is the manoeuvre of an RA.
+	Sense Sense
+	// Corrective is set for an RA that requires a change of vertical
+	// speed; a preventive RA only asks to keep it.
+	Corrective bool
+	// Crossing is set for an RA whose manoeuvre crosses the intruder's
+	// altitude.
+	Crossing bool
+}
+
+// Advise assesses intruder against own with the thresholds of own's
+// sensitivity level. Intruders on the ground and encounters with own on
+// the ground are LevelNone.
+func Advise(own, intruder Aircraft, opts Options) Advisory {
+	opts = opts.withDefaults()
+	g := Assess(own, intruder)
+	a := Advisory{Intruder: intruder.ID, Geometry: g}
+	if own.OnGround || intruder.OnGround {
+		return a
+	}
+	th := Sensitivity(own)
+	if g.Range <= convert.NMToMeters(opts.ProximateRange) && math.Abs(g.RelativeAltitude) <= opts.ProximateAltitude {
+		a.Level = LevelProximate
+	}
+	if alerts(g, th.TauTA, th.DMODTA, th.ZTHRTA) {
+		a.Level = LevelTA
+	}
+	if !opts.TAOnly && th.TauRA > 0 && alerts(g, th.TauRA, th.DMODRA, th.ZTHRRA) {
+		a.Level = LevelRA
+		a.Sense, a.Corrective, a.Crossing = resolve(own, intruder, g, th, opts, SenseNone)
+	}
+	return a
+}
+
+// alerts applies the range and altitude tests of an alert level.
+func alerts(g Geometry, tau time.Duration, dmod, zthr float64) bool {
+	rangeTest := g.Tau(convert.NMToMeters(dmod)) < tau
+	altitudeTest := math.Abs(g.RelativeAltitude) < zthr || g.VerticalTau() < tau
+	return rangeTest && altitudeTest
+}
+
+// resolve selects the sense of an RA: the one that does not cross the
+// intruder's altitude if it still reaches ALIM at the closest point of
+// approach, else the one giving more separation. A forced sense skips the
+// selection.
+func resolve(own, intruder Aircraft, g Geometry, th Thresholds, opts Options, forced Sense) (sense Sense, corrective, crossing bool) {
+	t := g.TimeToCPA.Minutes()
+	delay := min(g.TimeToCPA, opts.ResponseDelay).Minutes()
+	intruderAlt := intruder.Altitude + intruder.VerticalSpeed*t
+	ownAlt := func(rate float64) float64 {
+		return own.Altitude + own.VerticalSpeed*delay + rate*(t-delay)
+	}
+	above := ownAlt(max(own.VerticalSpeed, opts.ClimbRate)) - intruderAlt
+	below := intruderAlt - ownAlt(min(own.VerticalSpeed, -opts.ClimbRate))
+
+	nonCrossing := SenseClimb
+	if own.Altitude < intruder.Altitude {
+		nonCrossing = SenseDescend
+	}
+	sense = forced
+	if sense == SenseNone {
+		sense = nonCrossing
+		sep := above
+		if sense == SenseDescend {
+			sep = below
+		}
+		if sep < th.ALIM {
+			if above >= below {
+				sense = SenseClimb
+			} else {
+				sense = SenseDescend
+			}
+		}
+	}
+
+	// Corrective unless holding the current vertical speed already keeps
+	// ALIM on the chosen side.
+	keep := (own.Altitude + own.VerticalSpeed*t - intruderAlt) * float64(sense)
+	return sense, keep < th.ALIM, sense != nonCrossing
+}
+
+// Conflict is an RA between two aircraft of a Pairwise check, with
+// coordinated, opposite senses.
+type Conflict struct {
+	A, B   uint32
+	SenseA Sense
+	SenseB Sense
+	// Geometry is the encounter seen from A.
+	Geometry Geometry
+}
+
+// Pairwise finds the RAs between all pairs of airborne aircraft, each pair
+// judged with the thresholds of its lower aircraft. The senses are
+// coordinated: the lower aircraft's choice is taken and the other aircraft
+// gets the opposite sense.
+func Pairwise(aircraft []Aircraft, opts Options) []Conflict {
+	opts = opts.withDefaults()
+	opts.TAOnly = false
+	var out []Conflict
+	for i := range aircraft {
+		for j := i + 1; j < len(aircraft); j++ {
+			a, b := aircraft[i], aircraft[j]
+			if b.Altitude < a.Altitude {
+				a, b = b, a
+			}
+			adv := Advise(a, b, opts)
+			if adv.Level != LevelRA {
+				continue
+			}
+			out = append(out, Conflict{A: a.ID, B: b.ID, SenseA: adv.Sense, SenseB: adv.Sense.Opposite(), Geometry: adv.Geometry})
+		}
+	}
+	return out
+}
+
+// EventKind is a change of an intruder's advisory.
+type EventKind uint8
+
+const (
+	// EventTA — a TA was raised, or an RA weakened to a TA.
+	EventTA EventKind = iota
+	// EventRA — an RA was raised.
+	EventRA
+	// EventClear — clear of conflict: a TA or RA ended.
+	EventClear
+)
+
+// String returns "TA", "RA" or "clear of conflict".
+func (k EventKind) String() string {
+	switch k {
+	case EventTA:
+		return "TA"
+	case EventRA:
+		return "RA"
+	}
+	return "clear of conflict"
+}
+
+// Event is an advisory change reported by Monitor.Update.
+type Event struct {
+	Kind     EventKind
+	Advisory Advisory
+}
+
+// Monitor tracks the advisories of the own aircraft across updates and
+// reports their changes. An RA keeps its sense while it lasts. It is safe
+// for concurrent use.
+type Monitor struct {
+	opts Options
+
+	mu     sync.Mutex
+	active map[uint32]Advisory
+}
+
+// NewMonitor returns a monitor without traffic.
+func NewMonitor(opts Options) *Monitor {
+	return &Monitor{opts: opts.withDefaults(), active: make(map[uint32]Advisory)}
+}
+
+// Update assesses traffic against own and returns the changes since the
+// last update, ordered by intruder. An intruder left out of traffic is
+// gone; its TA or RA clears. own itself is skipped if it is in traffic.
+func (m *Monitor) Update(own Aircraft, traffic []Aircraft) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[uint32]bool, len(traffic))
+	var events []Event
+	for _, intruder := range traffic {
+		if intruder.ID == own.ID {
+			continue
+		}
+		seen[intruder.ID] = true
+		a := Advise(own, intruder, m.opts)
+		prev, had := m.active[intruder.ID]
+		if a.Level == LevelRA && had && prev.Level == LevelRA {
+			// Keep the sense of a running RA; only its strength may change.
+			a.Sense, a.Corrective, a.Crossing = resolve(own, intruder, a.Geometry, Sensitivity(own), m.opts, prev.Sense)
+		}
+		switch {
+		case a.Level == LevelRA && (!had || prev.Level != LevelRA):
+			events = append(events, Event{Kind: EventRA, Advisory: a})
+		case a.Level == LevelTA && (!had || prev.Level < LevelTA || prev.Level == LevelRA):
+			events = append(events, Event{Kind: EventTA, Advisory: a})
+		case a.Level < LevelTA && had && prev.Level >= LevelTA:
+			events = append(events, Event{Kind: EventClear, Advisory: a})
+		}
+		if a.Level == LevelNone {
+			delete(m.active, intruder.ID)
+		} else {
+			m.active[intruder.ID] = a
+		}
+	}
+	for id, prev := range m.active {
+		if seen[id] {
+			continue
+		}
+		delete(m.active, id)
+		if prev.Level >= LevelTA {
+			prev.Level, prev.Sense = LevelNone, SenseNone
+			events = append(events, Event{Kind: EventClear, Advisory: prev})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Advisory.Intruder < events[j].Advisory.Intruder })
+	return events
+}
+
+// Advisories returns the current advisories above LevelNone, most severe
+// first, then by range.
+func (m *Monitor) Advisories() []Advisory {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Advisory, 0, len(m.active))
+	for _, a := range m.active {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Level != out[j].Level {
+			return out[i].Level > out[j].Level
+		}
+		return out[i].Geometry.Range < out[j].Geometry.Range
+	})
+	return out
+}
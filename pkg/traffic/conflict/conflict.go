@@ -0,0 +1,172 @@
+// Package conflict detects airborne traffic conflicts the way TCAS II does.
+// For each intruder it computes the closest point of approach, the range
+// and vertical tau and the separation at that point, and raises traffic
+// advisories (TA) and resolution advisories (RA) with a climb or descend
+// sense against the thresholds of the own aircraft's sensitivity level.
+//
+// Assess, Advise, Monitor and Pairwise are plain math with no simulator
+// dependency. Detector feeds a Monitor from the simulator's traffic, and
+// FleetResolver steers non-ATC fleet members away from each other.
+package conflict
+
+import (
+	"math"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+)
+
+// Never is the tau of an encounter that does not converge.
+const Never = time.Duration(math.MaxInt64)
+
+// Aircraft is the state of the own aircraft or an intruder.
+type Aircraft struct {
+	ID            uint32  // object ID; 0 for the user aircraft
+	Latitude      float64 // degrees
+	Longitude     float64 // degrees
+	Altitude      float64 // feet MSL
+	AltitudeAGL   float64 // feet; selects the sensitivity level near the ground, Altitude is used while 0 and airborne
+	Heading       float64 // degrees true, taken as the ground track
+	GroundSpeed   float64 // knots
+	VerticalSpeed float64 // feet per minute
+	OnGround      bool
+}
+
+// Geometry is the encounter of the own aircraft with an intruder, assuming
+// both hold their track, speed and vertical speed.
+type Geometry struct {
+	Range            float64 // meters, horizontal
+	Bearing          float64 // degrees true from the own aircraft
+	RelativeAltitude float64 // feet, intruder above own when positive
+	ClosureRate      float64 // knots, positive while the range decreases
+	VerticalRate     float64 // feet per minute, intruder relative to own
+
+	// TimeToCPA is the time to the closest point of approach, zero once
+	// it has passed.
+	TimeToCPA time.Duration
+	// CPARange and CPAAltitude are the horizontal and vertical separation
+	// at the closest point of approach, in meters and feet.
+	CPARange    float64
+	CPAAltitude float64
+}
+
+// Closing reports whether the range decreases.
+func (g Geometry) Closing() bool { return g.ClosureRate > 0 }
+
+// Tau returns the modified range tau for the distance modifier dmod,
+// in meters: the time until the range falls to dmod at the current
+// closure rate. It is zero within dmod and Never while the range does not
+// decrease.
+func (g Geometry) Tau(dmod float64) time.Duration {
+	if g.Range <= dmod {
+		return 0
+	}
+	if !g.Closing() {
+		return Never
+	}
+	rdot := convert.KnotsToMetersPerSecond(g.ClosureRate)
+	return seconds((g.Range*g.Range - dmod*dmod) / (g.Range * rdot))
+}
+
+// VerticalTau returns the time until the intruder reaches the own
+// altitude, or Never while the altitudes do not converge.
+func (g Geometry) VerticalTau() time.Duration {
+	if g.RelativeAltitude == 0 {
+		return 0
+	}
+	if g.RelativeAltitude*g.VerticalRate >= 0 {
+		return Never
+	}
+	return seconds(-g.RelativeAltitude / g.VerticalRate * 60)
+}
+
+// Assess computes the geometry of own and intruder, working in a flat
+// plane around own, which holds well within TCAS ranges.
+func Assess(own, intruder Aircraft) Geometry {
+	east, north := convert.LatLonToOffset(own.Latitude, own.Longitude, intruder.Latitude, intruder.Longitude)
+	ve, vn := velocity(intruder)
+	oe, on := velocity(own)
+	ve, vn = ve-oe, vn-on
+
+	g := Geometry{
+		Range:            math.Hypot(east, north),
+		Bearing:          calc.BearingFromOffsets(east, north),
+		RelativeAltitude: intruder.Altitude - own.Altitude,
+		VerticalRate:     intruder.VerticalSpeed - own.VerticalSpeed,
+	}
+	if g.Range > 0 {
+		g.ClosureRate = convert.MetersPerSecondToKnots(-(east*ve + north*vn) / g.Range)
+	}
+	t := 0.0
+	if v2 := ve*ve + vn*vn; v2 > 0 {
+		t = max(-(east*ve+north*vn)/v2, 0)
+	}
+	g.TimeToCPA = seconds(t)
+	g.CPARange = math.Hypot(east+ve*t, north+vn*t)
+	g.CPAAltitude = g.RelativeAltitude + g.VerticalRate*t/60
+	return g
+}
+
+// velocity returns the east and north ground speed of a, in meters per
+// second.
+func velocity(a Aircraft) (float64, float64) {
+	v := convert.KnotsToMetersPerSecond(a.GroundSpeed)
+	h := convert.DegreesToRadians(a.Heading)
+	return v * math.Sin(h), v * math.Cos(h)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Thresholds are the TCAS II alerting thresholds of a sensitivity level.
+// A zero TauRA means RAs are inhibited.
+type Thresholds struct {
+	Level  int
+	TauTA  time.Duration
+	TauRA  time.Duration
+	DMODTA float64 // nautical miles
+	DMODRA float64 // nautical miles
+	ZTHRTA float64 // feet
+	ZTHRRA float64 // feet
+	ALIM   float64 // feet, the separation an RA aims for
+}
+
+// sensitivity is the TCAS II version 7.1 threshold table, by the altitude
+// each level starts at.
+var sensitivity = []struct {
+	floor float64
+	agl   bool
+	t     Thresholds
+}{
+	{0, true, Thresholds{Level: 2, TauTA: 20 * time.Second, DMODTA: 0.30, ZTHRTA: 850}},
+	{1000, true, Thresholds{3, 25 * time.Second, 15 * time.Second, 0.33, 0.20, 850, 600, 300}},
+	{2350, true, Thresholds{4, 30 * time.Second, 20 * time.Second, 0.48, 0.35, 850, 600, 300}},
+	{5000, false, Thresholds{5, 40 * time.Second, 25 * time.Second, 0.75, 0.55, 850, 600, 350}},
+	{10000, false, Thresholds{6, 45 * time.Second, 30 * time.Second, 1.00, 0.80, 850, 600, 400}},
+	{20000, false, Thresholds{7, 48 * time.Second, 35 * time.Second, 1.30, 1.10, 850, 700, 600}},
+	{42000, false, Thresholds{7, 48 * time.Second, 35 * time.Second, 1.30, 1.10, 1200, 800, 700}},
+}
+
+// Sensitivity returns the thresholds for own: by height above ground below
+// 2350 ft AGL, by altitude above. Without a height above ground, an
+// airborne own aircraft is placed by its altitude throughout.
+func Sensitivity(own Aircraft) Thresholds {
+	agl := own.AltitudeAGL
+	if agl == 0 && !own.OnGround {
+		agl = own.Altitude
+	}
+	t := sensitivity[0].t
+	for _, s := range sensitivity {
+		alt := own.Altitude
+		if s.agl {
+			alt = agl
+		}
+		if alt < s.floor {
+			break
+		}
+		t = s.t
+	}
+	return t
+}
@@ -0,0 +1,170 @@
+package conflict
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+// at returns an aircraft dist meters from the origin along bearing, flying
+// heading.
+func at(id uint32, bearing, dist, alt, heading, gs, vs float64) Aircraft {
+	lat, lon := calc.DisplaceByHeading(0, 0, bearing, dist)
+	return Aircraft{ID: id, Latitude: lat, Longitude: lon, Altitude: alt, AltitudeAGL: alt,
+		Heading: heading, GroundSpeed: gs, VerticalSpeed: vs}
+}
+
+func TestAssessHeadOn(t *testing.T) {
+	own := at(0, 0, 0, 10000, 0, 300, 0)
+	// 10 km north, flying south at 300 kts, 500 ft above and descending.
+	intruder := at(1, 0, 10000, 10500, 180, 300, -500)
+	g := Assess(own, intruder)
+
+	if math.Abs(g.Range-10000) > 1 || math.Abs(g.ClosureRate-600) > 0.01 {
+		t.Errorf("range %.1f m closing %.2f kts, want 10000 m at 600 kts", g.Range, g.ClosureRate)
+	}
+	// 10000 m at 308.67 m/s.
+	if d := g.TimeToCPA - 32397*time.Millisecond; d.Abs() > 10*time.Millisecond {
+		t.Errorf("time to CPA = %v, want 32.4s", g.TimeToCPA)
+	}
+	if g.CPARange > 1 {
+		t.Errorf("CPA range = %.1f, want 0", g.CPARange)
+	}
+	if math.Abs(g.CPAAltitude-(500-500*g.TimeToCPA.Minutes())) > 1e-6 {
+		t.Errorf("CPA altitude = %.1f", g.CPAAltitude)
+	}
+	if tau := g.Tau(0); (tau - g.TimeToCPA).Abs() > 10*time.Millisecond {
+		t.Errorf("tau = %v, want time to CPA for a head-on encounter", tau)
+	}
+	if vt := g.VerticalTau(); (vt - time.Minute).Abs() > time.Millisecond {
+		t.Errorf("vertical tau = %v, want 1m", vt)
+	}
+
+	away := Assess(own, at(1, 0, 10000, 10000, 0, 400, 0))
+	if away.Closing() || away.Tau(0) != Never || away.TimeToCPA != 0 {
+		t.Errorf("diverging: %+v", away)
+	}
+}
+
+func TestSensitivity(t *testing.T) {
+	tests := []struct {
+		alt, agl float64
+		level    int
+		ra       bool
+	}{
+		{500, 500, 2, false},
+		{1500, 1500, 3, true},
+		{4000, 4000, 4, true},
+		{8000, 8000, 5, true},
+		{15000, 15000, 6, true},
+		{35000, 35000, 7, true},
+		{45000, 45000, 7, true},
+		{6000, 800, 2, false}, // near terrain
+		{8000, 0, 5, true},    // height above ground unknown
+	}
+	for _, tt := range tests {
+		th := Sensitivity(Aircraft{Altitude: tt.alt, AltitudeAGL: tt.agl})
+		if th.Level != tt.level || (th.TauRA > 0) != tt.ra {
+			t.Errorf("alt %v agl %v: level %d RA %v, want %d %v", tt.alt, tt.agl, th.Level, th.TauRA > 0, tt.level, tt.ra)
+		}
+	}
+	if th := Sensitivity(Aircraft{Altitude: 1200, OnGround: true}); th.Level != 2 {
+		t.Errorf("on the ground at 1200 ft: level %d, want 2", th.Level)
+	}
+	if th := Sensitivity(Aircraft{Altitude: 45000, AltitudeAGL: 45000}); th.ZTHRTA != 1200 {
+		t.Errorf("above FL420 ZTHR TA = %v, want 1200", th.ZTHRTA)
+	}
+}
+
+func TestAdvise(t *testing.T) {
+	own := at(0, 0, 0, 10000, 0, 300, 0)
+	tests := []struct {
+		name     string
+		intruder Aircraft
+		opts     Options
+		level    Level
+		sense    Sense
+		crossing bool
+	}{
+		{"far", at(1, 0, 50000, 10000, 180, 300, 0), Options{}, LevelNone, SenseNone, false},
+		{"proximate", at(1, 90, 9000, 10500, 90, 300, 0), Options{}, LevelProximate, SenseNone, false},
+		{"TA", at(1, 0, 13000, 10300, 180, 300, 0), Options{}, LevelTA, SenseNone, false},
+		{"RA above", at(1, 0, 8000, 10300, 180, 300, 0), Options{}, LevelRA, SenseDescend, false},
+		{"RA below", at(1, 0, 8000, 9700, 180, 300, 0), Options{}, LevelRA, SenseClimb, false},
+		{"TA only", at(1, 0, 8000, 10300, 180, 300, 0), Options{TAOnly: true}, LevelTA, SenseNone, false},
+		{"vertically clear", at(1, 0, 8000, 12000, 180, 300, 0), Options{}, LevelNone, SenseNone, false},
+		// Climbing hard through own altitude from below: staying above
+		// it cannot reach ALIM, descending under it can.
+		{"crossing", at(1, 0, 8000, 9900, 180, 300, 4000), Options{}, LevelRA, SenseDescend, true},
+	}
+	for _, tt := range tests {
+		a := Advise(own, tt.intruder, tt.opts)
+		if a.Level != tt.level || a.Sense != tt.sense {
+			t.Errorf("%s: %v %v, want %v %v (%+v)", tt.name, a.Level, a.Sense, tt.level, tt.sense, a.Geometry)
+		}
+		if a.Level == LevelRA && a.Crossing != tt.crossing {
+			t.Errorf("%s: crossing = %v, want %v", tt.name, a.Crossing, tt.crossing)
+		}
+	}
+
+	ground := at(1, 0, 2000, 10000, 180, 300, 0)
+	ground.OnGround = true
+	if a := Advise(own, ground, Options{}); a.Level != LevelNone {
+		t.Errorf("intruder on ground: %v", a.Level)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	m := NewMonitor(Options{})
+	own := at(0, 0, 0, 10000, 0, 300, 0)
+	kinds := func(events []Event) []EventKind {
+		var out []EventKind
+		for _, e := range events {
+			out = append(out, e.Kind)
+		}
+		return out
+	}
+	step := func(dist float64) []EventKind {
+		return kinds(m.Update(own, []Aircraft{own, at(1, 0, dist, 10300, 180, 300, 0)}))
+	}
+
+	if got := step(13000); len(got) != 1 || got[0] != EventTA {
+		t.Fatalf("at 13 km: %v, want TA", got)
+	}
+	if got := step(12500); len(got) != 0 {
+		t.Fatalf("TA again: %v, want no event", got)
+	}
+	if got := step(8000); len(got) != 1 || got[0] != EventRA {
+		t.Fatalf("at 8 km: %v, want RA", got)
+	}
+	if adv := m.Advisories(); len(adv) != 1 || adv[0].Sense != SenseDescend {
+		t.Fatalf("advisories = %+v", adv)
+	}
+	if got := step(6000); len(got) != 0 {
+		t.Fatalf("RA again: %v, want no event", got)
+	}
+	if got := kinds(m.Update(own, nil)); len(got) != 1 || got[0] != EventClear {
+		t.Fatalf("intruder gone: %v, want clear of conflict", got)
+	}
+	if adv := m.Advisories(); len(adv) != 0 {
+		t.Errorf("advisories after clear = %+v", adv)
+	}
+}
+
+func TestPairwise(t *testing.T) {
+	traffic := []Aircraft{
+		at(1, 0, 0, 10000, 0, 300, 0),
+		at(2, 0, 8000, 10300, 180, 300, 0),
+		at(3, 90, 80000, 10000, 0, 300, 0),
+	}
+	conflicts := Pairwise(traffic, Options{TAOnly: true})
+	if len(conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want 1", conflicts)
+	}
+	c := conflicts[0]
+	if c.A != 1 || c.B != 2 || c.SenseA != SenseDescend || c.SenseB != SenseClimb {
+		t.Errorf("conflict = %+v, want 1 descending and 2 climbing", c)
+	}
+}
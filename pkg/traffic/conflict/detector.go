@@ -0,0 +1,181 @@
+//go:build windows
+// +build windows
+
+package conflict
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	traffictypes "github.com/mrlm-net/simconnect/pkg/datasets/traffic"
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// DefaultRadius is the radius, in meters, Detector requests traffic in:
+// 20 NM, beyond the TA range of every sensitivity level.
+const DefaultRadius = 37040
+
+// MaxRadius is the largest radius, in meters, SimConnect accepts for
+// RequestDataOnSimObjectType.
+const MaxRadius = 200000
+
+// Requester registers the traffic definition and requests the aircraft
+// around the user, e.g. an engine.Client.
+type Requester interface {
+	RegisterDataset(definitionID uint32, dataset *datasets.DataSet) error
+	RequestDataOnSimObjectType(requestID uint32, definitionID uint32, dwRadiusMeters uint32, objectType types.SIMCONNECT_SIMOBJECT_TYPE) error
+}
+
+// Config configures a Detector.
+type Config struct {
+	Options Options
+
+	// RequestID is the request ID of the traffic request, and
+	// DefinitionID the definition Start registers the traffic aircraft
+	// dataset under. It must not be in use.
+	RequestID    uint32
+	DefinitionID uint32
+
+	// Radius is the request radius in meters, DefaultRadius if zero and
+	// at most MaxRadius.
+	Radius uint32
+
+	// OnEvent, if set, is called with every advisory change, outside of
+	// the detector's lock.
+	OnEvent func(Event)
+}
+
+// Detector runs a Monitor on the simulator's traffic. Each Request asks
+// for the aircraft within the radius with RequestDataOnSimObjectType and
+// the traffic aircraft dataset; route the
+// SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE answers to HandleData. Once the
+// last entry of an answer arrives, the batch is assessed against the state
+// given to SetOwn. The user aircraft is excluded from the traffic.
+type Detector struct {
+	monitor   *Monitor
+	requester Requester
+	cfg       Config
+
+	mu      sync.Mutex
+	own     Aircraft
+	hasOwn  bool
+	batch   []Aircraft // entries of the answer being received
+	traffic []Aircraft // last complete answer
+}
+
+// NewDetector returns a detector requesting traffic through requester.
+// Call Start once connected.
+func NewDetector(requester Requester, cfg Config) *Detector {
+	if cfg.Radius == 0 {
+		cfg.Radius = DefaultRadius
+	}
+	cfg.Radius = min(cfg.Radius, MaxRadius)
+	return &Detector{
+		monitor:   NewMonitor(cfg.Options),
+		requester: requester,
+		cfg:       cfg,
+	}
+}
+
+// Monitor returns the monitor, e.g. to read the current advisories.
+func (d *Detector) Monitor() *Monitor { return d.monitor }
+
+// Start registers the traffic aircraft dataset. Call it again after
+// reconnecting.
+func (d *Detector) Start() error {
+	if err := d.requester.RegisterDataset(d.cfg.DefinitionID, traffictypes.NewAircraftDataset()); err != nil {
+		return fmt.Errorf("registering traffic data: %w", err)
+	}
+	return nil
+}
+
+// SetOwn records the state of the own aircraft. Traffic is not assessed
+// before the first call.
+func (d *Detector) SetOwn(own Aircraft) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.own, d.hasOwn = own, true
+}
+
+// Request asks for the aircraft within the radius.
+func (d *Detector) Request() error {
+	return d.requester.RequestDataOnSimObjectType(d.cfg.RequestID, d.cfg.DefinitionID, d.cfg.Radius, types.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT)
+}
+
+// HandleData collects an answer to the traffic request and assesses it
+// once complete. Reports whether msg belonged to it.
+func (d *Detector) HandleData(msg *types.SIMCONNECT_RECV_SIMOBJECT_DATA) bool {
+	if uint32(msg.DwRequestID) != d.cfg.RequestID {
+		return false
+	}
+	d.mu.Lock()
+	if msg.DwEntryNumber <= 1 {
+		d.batch = d.batch[:0]
+	}
+	if msg.DwOutOf > 0 {
+		ac := engine.CastDataAs[traffictypes.AircraftDataset](&msg.DwData)
+		if ac.IsUserSim == 0 {
+			d.batch = append(d.batch, Aircraft{
+				ID:            uint32(msg.DwObjectID),
+				Latitude:      ac.Lat,
+				Longitude:     ac.Lon,
+				Altitude:      ac.Alt,
+				AltitudeAGL:   ac.AltAboveGround,
+				Heading:       ac.Head,
+				GroundSpeed:   ac.GroundSpeed,
+				VerticalSpeed: ac.Vs,
+				OnGround:      ac.SimOnGround != 0,
+			})
+		}
+	}
+	if msg.DwEntryNumber < msg.DwOutOf {
+		d.mu.Unlock()
+		return true
+	}
+	d.traffic = append(d.traffic[:0], d.batch...)
+	own, hasOwn := d.own, d.hasOwn
+	traffic := append([]Aircraft(nil), d.traffic...)
+	d.mu.Unlock()
+
+	if !hasOwn {
+		return true
+	}
+	for _, ev := range d.monitor.Update(own, traffic) {
+		if d.cfg.OnEvent != nil {
+			d.cfg.OnEvent(ev)
+		}
+	}
+	return true
+}
+
+// Traffic returns the aircraft of the last complete answer.
+func (d *Detector) Traffic() []Aircraft {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Aircraft(nil), d.traffic...)
+}
+
+// Advisories returns the current advisories, most severe first.
+func (d *Detector) Advisories() []Advisory { return d.monitor.Advisories() }
+
+// Run calls Request every interval until ctx is done, passing errors to
+// onError if it is not nil. Returns ctx.Err(). One second matches the
+// update rate of TCAS.
+func (d *Detector) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := d.Request(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
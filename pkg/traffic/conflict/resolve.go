@@ -0,0 +1,207 @@
+//go:build windows
+// +build windows
+
+package conflict
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// DefaultResolutionAltitude is how far, in feet, FleetResolver moves an
+// aircraft up or down out of a conflict.
+const DefaultResolutionAltitude = 1000.0
+
+// resolutionLeg is the time, at the aircraft's ground speed, between the
+// waypoints of a resolution chain.
+const resolutionLeg = time.Minute
+
+// ResolverConfig configures a FleetResolver.
+type ResolverConfig struct {
+	Options Options
+
+	// WaypointDefID is a definition registered for "AI Waypoint List".
+	WaypointDefID uint32
+
+	// Altitude is the climb or descent of a resolution in feet,
+	// DefaultResolutionAltitude if zero.
+	Altitude float64
+}
+
+// resolution is a member flying a resolution chain.
+type resolution struct {
+	chain []types.SIMCONNECT_DATA_WAYPOINT // chain before the resolution
+	defID uint32
+}
+
+// FleetResolver keeps non-ATC members of a traffic.Fleet clear of each
+// other. On every Tick it runs Pairwise over the airborne members; each
+// aircraft of a new RA gets a waypoint chain straight ahead, climbing or
+// descending by the resolution altitude in its coordinated sense. Once
+// clear of conflict it gets its earlier chain back from the first waypoint
+// still ahead of it, or flies on if none is.
+//
+// Member positions come from Aircraft.State, so the fleet must be tracking
+// (Fleet.Track), and members must already be released to the AI.
+type FleetResolver struct {
+	fleet *traffic.Fleet
+	cfg   ResolverConfig
+
+	mu        sync.Mutex
+	active    map[uint32]resolution
+	conflicts []Conflict
+}
+
+// NewFleetResolver returns a resolver for the non-ATC members of fleet.
+func NewFleetResolver(fleet *traffic.Fleet, cfg ResolverConfig) *FleetResolver {
+	if cfg.Altitude <= 0 {
+		cfg.Altitude = DefaultResolutionAltitude
+	}
+	return &FleetResolver{
+		fleet:  fleet,
+		cfg:    cfg,
+		active: make(map[uint32]resolution),
+	}
+}
+
+// Conflicts returns the conflicts found by the last Tick.
+func (r *FleetResolver) Conflicts() []Conflict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Conflict(nil), r.conflicts...)
+}
+
+// Resolving returns the object IDs of the members flying a resolution.
+func (r *FleetResolver) Resolving() []uint32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]uint32, 0, len(r.active))
+	for id := range r.active {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// HandleEvent forgets members that leave the fleet. Run calls it; call it
+// yourself when driving Tick from your own loop.
+func (r *FleetResolver) HandleEvent(ev traffic.FleetEvent) {
+	if ev.Kind != traffic.FleetRemoved {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.active, ev.Aircraft.ObjectID)
+}
+
+// Tick starts the resolutions of new conflicts and ends those of members
+// clear of conflict. Returns the joined errors of the chains sent.
+func (r *FleetResolver) Tick(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make(map[uint32]traffic.Telemetry)
+	var aircraft []Aircraft
+	for _, a := range r.fleet.List() {
+		s := a.State
+		if a.Kind != traffic.KindNonATC || s.Updated.IsZero() {
+			continue
+		}
+		states[a.ObjectID] = s
+		aircraft = append(aircraft, Aircraft{
+			ID:            a.ObjectID,
+			Latitude:      s.Latitude,
+			Longitude:     s.Longitude,
+			Altitude:      s.Altitude,
+			AltitudeAGL:   s.AltitudeAGL,
+			Heading:       s.Heading,
+			GroundSpeed:   s.GroundSpeed,
+			VerticalSpeed: s.VerticalSpeed,
+			OnGround:      s.OnGround,
+		})
+	}
+	r.conflicts = Pairwise(aircraft, r.cfg.Options)
+
+	var errs []error
+	inConflict := make(map[uint32]bool)
+	for _, c := range r.conflicts {
+		for _, side := range []struct {
+			id    uint32
+			sense Sense
+		}{{c.A, c.SenseA}, {c.B, c.SenseB}} {
+			inConflict[side.id] = true
+			if _, ok := r.active[side.id]; ok {
+				continue
+			}
+			if err := r.resolveLocked(side.id, side.sense, states[side.id]); err != nil {
+				errs = append(errs, fmt.Errorf("resolving %d: %w", side.id, err))
+			}
+		}
+	}
+	for id, res := range r.active {
+		if inConflict[id] {
+			continue
+		}
+		s, ok := states[id]
+		if !ok {
+			// Not tracked any more; wait for telemetry or removal.
+			continue
+		}
+		delete(r.active, id)
+		if chain := ahead(res.chain, s); len(chain) > 0 {
+			if err := r.fleet.SetWaypoints(id, res.defID, chain); err != nil {
+				errs = append(errs, fmt.Errorf("resuming %d: %w", id, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveLocked sends member id a chain straight ahead, climbing or
+// descending in sense. Caller must hold r.mu.
+func (r *FleetResolver) resolveLocked(id uint32, sense Sense, s traffic.Telemetry) error {
+	chain, defID, _ := r.fleet.Waypoints(id)
+	if defID == 0 {
+		defID = r.cfg.WaypointDefID
+	}
+	alt := s.Altitude + float64(sense)*r.cfg.Altitude
+	leg := convert.KnotsToMetersPerSecond(s.GroundSpeed) * resolutionLeg.Seconds()
+	lat1, lon1 := calc.DisplaceByHeading(s.Latitude, s.Longitude, s.Heading, leg)
+	lat2, lon2 := calc.DisplaceByHeading(lat1, lon1, s.Heading, leg)
+	if err := r.fleet.SetWaypoints(id, r.cfg.WaypointDefID, []types.SIMCONNECT_DATA_WAYPOINT{
+		traffic.DescentWaypoint(lat1, lon1, alt, s.GroundSpeed),
+		traffic.DescentWaypoint(lat2, lon2, alt, s.GroundSpeed),
+	}); err != nil {
+		return err
+	}
+	r.active[id] = resolution{chain: chain, defID: defID}
+	return nil
+}
+
+// ahead returns chain from its first waypoint within 90° of the heading
+// of s, or nil if every waypoint lies behind.
+func ahead(chain []types.SIMCONNECT_DATA_WAYPOINT, s traffic.Telemetry) []types.SIMCONNECT_DATA_WAYPOINT {
+	for i, wp := range chain {
+		brg := calc.BearingDegrees(s.Latitude, s.Longitude, wp.Latitude, wp.Longitude)
+		diff := math.Mod(brg-s.Heading+540, 360) - 180
+		if math.Abs(diff) < 90 {
+			return chain[i:]
+		}
+	}
+	return nil
+}
+
+// Run drives the resolver with traffic.Run until ctx is done.
+func (r *FleetResolver) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return traffic.Run(ctx, r.fleet, r, interval, onError)
+}
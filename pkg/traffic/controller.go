@@ -0,0 +1,68 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RequestIDs hands out request IDs from a range in turn, starting over at
+// the low end after the high one. It is safe for concurrent use.
+type RequestIDs struct {
+	mu       sync.Mutex
+	min, max uint32
+	next     uint32
+}
+
+// NewRequestIDs returns an allocator for the IDs min to max, inclusive.
+// Keep the range clear of other requests.
+func NewRequestIDs(min, max uint32) *RequestIDs {
+	return &RequestIDs{min: min, max: max, next: min}
+}
+
+// Next returns the next request ID of the range.
+func (r *RequestIDs) Next() uint32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.next
+	if r.next >= r.max {
+		r.next = r.min
+	} else {
+		r.next++
+	}
+	return id
+}
+
+// Controller steers part of a fleet: it reacts to the fleet's lifecycle
+// events and does its periodic work on each tick.
+type Controller interface {
+	HandleEvent(ev FleetEvent)
+	Tick(now time.Time) error
+}
+
+// Run calls c.Tick every interval and c.HandleEvent for every lifecycle
+// event of fleet until ctx is done, passing Tick errors to onError if it
+// is not nil. Returns ctx.Err().
+func Run(ctx context.Context, fleet *Fleet, c Controller, interval time.Duration, onError func(error)) error {
+	sub := fleet.Subscribe(256)
+	defer sub.Unsubscribe()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-sub.Events():
+			if ok {
+				c.HandleEvent(ev)
+			}
+		case now := <-ticker.C:
+			if err := c.Tick(now); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build windows
+
+package traffic
+
+import "testing"
+
+func TestRequestIDsWrap(t *testing.T) {
+	ids := NewRequestIDs(10, 12)
+	want := []uint32{10, 11, 12, 10, 11}
+	for i, w := range want {
+		if got := ids.Next(); got != w {
+			t.Fatalf("Next #%d = %d, want %d", i, got, w)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// PushbackDirection selects how a departing aircraft leaves its parking spot.
+type PushbackDirection uint8
+
+const (
+	// PushbackStraight pushes straight back onto the taxiway centreline and
+	// on along it in the push direction.
+	PushbackStraight PushbackDirection = iota
+	// PushbackTailLeft pushes back onto the taxiway with the tail swung to
+	// the left, as seen from the cockpit of the parked aircraft, so it taxis
+	// away to the right.
+	PushbackTailLeft
+	// PushbackTailRight is the mirror image of PushbackTailLeft.
+	PushbackTailRight
+	// PushbackNone taxis forward out of the spot without a pushback, as on a
+	// GA ramp.
+	PushbackNone
+)
+
+// DepartureOpts configures PlanDeparture.
+type DepartureOpts struct {
+	Category AircraftCategory  // sizes the climb-out; zero is CategoryJet
+	Pushback PushbackDirection // how the aircraft leaves the gate
+	Speeds   TaxiSpeeds        // pushback and taxi speed profile
+	// InitialAltitude caps the climb-out, in feet AGL, and is the altitude of
+	// its last waypoint. Zero keeps the category's profile.
+	InitialAltitude float64
+	// Constraints shape the taxi route, e.g. to avoid taxiing on runways.
+	Constraints airportgraph.Constraints
+}
+
+// PlanDeparture builds the complete waypoint chain for a non-ATC aircraft
+// leaving parking spot gate (an index into ap.Parking) from runway end
+// runway, e.g. "24":
+//
+//	[PushbackWaypoint...] [TaxiWaypoint...] [LineupWaypoint] [ClimbWaypoint...]
+//
+// The taxi follows the airport's taxi network to the hold-short point of
+// the runway entry closest to the threshold, then lines up at the
+// threshold. ap must include runways, parking and taxiways. Spawn the
+// aircraft with DepartureSpawn so the pushback starts in line.
+//
+// Returns airportgraph.ErrUnknownNode for a gate the airport does not have,
+// airportgraph.ErrUnknownRunway or airportgraph.ErrNoRoute.
+func PlanDeparture(ap *facility.Airport, gate int, runway string, opts DepartureOpts) ([]types.SIMCONNECT_DATA_WAYPOINT, error) {
+	r, err := PlanDepartureRoute(ap, gate, runway, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(r.Waypoints, r.Lineup...), nil
+}
+
+// GroundRoute is a departure planned by PlanDepartureRoute, split at the
+// runway hold-short point.
+type GroundRoute struct {
+	// Nodes are the nodes of airportgraph.Build(ap) the aircraft moves
+	// through, from its parking spot to the hold-short point, and
+	// Waypoints[i] is the pushback or taxi waypoint reaching Nodes[i+1].
+	Nodes     []int
+	Waypoints []types.SIMCONNECT_DATA_WAYPOINT
+	// Runway is the runway end and Lineup the lineup and climb-out flown
+	// from the hold-short point.
+	Runway string
+	Lineup []types.SIMCONNECT_DATA_WAYPOINT
+}
+
+// PlanDepartureRoute plans the same departure as PlanDeparture and keeps
+// the route on the taxi network with it, for ground controllers that
+// clear the aircraft along the route a part at a time.
+func PlanDepartureRoute(ap *facility.Airport, gate int, runway string, opts DepartureOpts) (GroundRoute, error) {
+	p := newGroundPlan(ap, opts.Speeds)
+	exit, err := p.gateExit(gate, opts.Pushback)
+	if err != nil {
+		return GroundRoute{}, err
+	}
+	t, err := p.g.Threshold(runway)
+	if err != nil {
+		return GroundRoute{}, err
+	}
+	route, _, err := p.g.RouteToRunway(exit.from, runway, opts.Constraints)
+	if err != nil {
+		return GroundRoute{}, err
+	}
+
+	r := GroundRoute{Nodes: []int{exit.parking}, Runway: runway}
+	for _, n := range exit.push {
+		lat, lon, _ := p.g.LatLon(n)
+		r.Waypoints = append(r.Waypoints, PushbackWaypoint(lat, lon, p.altFt, p.speeds.Pushback))
+		r.Nodes = append(r.Nodes, n)
+	}
+	nodes := route.Nodes
+	if len(exit.push) == 0 {
+		nodes = append([]int{exit.parking}, nodes...)
+	}
+	r.Waypoints = append(r.Waypoints, p.taxi(nodes)...)
+	r.Nodes = append(r.Nodes, nodes[1:]...)
+
+	lat, lon := p.latLon(t.X, t.Z)
+	r.Lineup = append([]types.SIMCONNECT_DATA_WAYPOINT{LineupWaypoint(lat, lon, p.altFt)},
+		climb(opts.Category, lat, lon, t.Heading, opts.InitialAltitude)...)
+	return r, nil
+}
+
+// DepartureSpawn returns the initial position for an aircraft at parking
+// spot gate that PlanDeparture will move with the same opts. Its heading
+// lines the aircraft up with the first pushback leg, or with the exit from
+// the spot for PushbackNone.
+func DepartureSpawn(ap *facility.Airport, gate int, opts DepartureOpts) (types.SIMCONNECT_DATA_INITPOSITION, error) {
+	p := newGroundPlan(ap, opts.Speeds)
+	exit, err := p.gateExit(gate, opts.Pushback)
+	if err != nil {
+		return types.SIMCONNECT_DATA_INITPOSITION{}, err
+	}
+	lat, lon, _ := p.g.LatLon(exit.parking)
+	return types.SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  lat,
+		Longitude: lon,
+		Altitude:  p.altFt,
+		Heading:   exit.heading,
+		OnGround:  1,
+	}, nil
+}
+
+// exitPlan describes how an aircraft leaves a parking spot.
+type exitPlan struct {
+	parking int     // parking node
+	push    []int   // nodes reversed through, empty without pushback
+	from    int     // node where the taxi starts
+	heading float64 // spawn heading at the parking spot
+}
+
+// gateExit resolves the exit from parking spot gate in direction dir.
+func (p *groundPlan) gateExit(gate int, dir PushbackDirection) (exitPlan, error) {
+	n, ok := p.g.ParkingNode(gate)
+	if !ok {
+		return exitPlan{}, fmt.Errorf("%w: parking %d", airportgraph.ErrUnknownNode, gate)
+	}
+	pb, err := p.g.GateExit(n)
+	if err != nil {
+		return exitPlan{}, err
+	}
+	parked := p.g.Nodes[n].Heading
+
+	exit := exitPlan{parking: n}
+	switch dir {
+	case PushbackTailLeft, PushbackTailRight:
+		tail := parked - 90
+		if dir == PushbackTailRight {
+			tail = parked + 90
+		}
+		exit.from = p.towards(pb.Entry, tail)
+		exit.push = []int{pb.Entry}
+		if exit.from != pb.Entry {
+			exit.push = append(exit.push, exit.from)
+		}
+		exit.heading = parked
+	case PushbackNone:
+		exit.from = pb.Entry
+		exit.heading = p.g.Heading(n, pb.Entry)
+	default:
+		exit.from = pb.Start
+		exit.push = []int{pb.Start}
+		exit.heading = pb.SpawnHeading
+	}
+	return exit, nil
+}
+
+// towards returns the taxi point next to node from that lies most nearly
+// along hdgDeg, or from itself if no neighbour lies ahead.
+func (p *groundPlan) towards(from int, hdgDeg float64) int {
+	rad := hdgDeg * math.Pi / 180
+	dirX, dirZ := math.Sin(rad), math.Cos(rad)
+	best, bestDot := from, 0.0
+	a := p.g.Nodes[from]
+	for _, e := range p.g.Incident(from) {
+		next := e.Other(from)
+		b := p.g.Nodes[next]
+		if b.Kind != airportgraph.NodeTaxiPoint {
+			continue
+		}
+		dx, dz := b.X-a.X, b.Z-a.Z
+		d := math.Hypot(dx, dz)
+		if d == 0 {
+			continue
+		}
+		if dot := (dx*dirX + dz*dirZ) / d; dot > bestDot {
+			best, bestDot = next, dot
+		}
+	}
+	return best
+}
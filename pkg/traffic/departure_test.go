@@ -0,0 +1,272 @@
+//go:build windows
+
+package traffic
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/airportgraph/airporttest"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// Fixture taxi point indices. The airport has one runway, 09/27, 1600 m
+// long along the x axis through the reference point, and a gate facing
+// north above C0:
+//
+//	                  P0
+//	        C1 ------ C0 ------ C2
+//	       /          |          \
+//	A0 --- A1 ------- A2 ------- A3 --- A4
+//	|                 |                 |
+//	H0                H2                H4
+//	|                 |                 |
+//	R0 ---- R1 ------ R2 ------ R3 ---- R4     runway 09/27
+const (
+	r0 = iota
+	r1
+	r2
+	r3
+	r4
+	h0
+	h2
+	h4
+	a0
+	a1
+	a2
+	a3
+	a4
+	c0
+	c1
+	c2
+	fixturePoints
+)
+
+// p0 is the node of the gate.
+const p0 = fixturePoints
+
+// fixtureAltitude is the airport elevation in meters.
+const fixtureAltitude = 100.0
+
+func fixtureAirport() *facility.Airport {
+	pt, hold, taxi, runway := airporttest.Point, airporttest.Hold, airporttest.Taxi, airporttest.Runway
+	points := []facility.TaxiPoint{
+		r0: pt(-800, 0), r1: pt(-400, 0), r2: pt(0, 0), r3: pt(400, 0), r4: pt(800, 0),
+		h0: hold(-800, 100), h2: hold(0, 100), h4: hold(800, 100),
+		a0: pt(-800, 200), a1: pt(-400, 200), a2: pt(0, 200), a3: pt(400, 200), a4: pt(800, 200),
+		c0: pt(0, 400), c1: pt(-300, 400), c2: pt(300, 400),
+	}
+	paths := []facility.TaxiPath{
+		runway(r0, r1), runway(r1, r2), runway(r2, r3), runway(r3, r4),
+		taxi("A", a0, a1), taxi("A", a1, a2), taxi("A", a2, a3), taxi("A", a3, a4),
+		taxi("B1", a0, h0), taxi("B1", h0, r0),
+		taxi("B3", a2, h2), taxi("B3", h2, r2),
+		taxi("B5", a4, h4), taxi("B5", h4, r4),
+		taxi("C", c1, c0), taxi("C", c0, c2), taxi("C", c0, a2),
+		taxi("D", c1, a1), taxi("E", c2, a3),
+		{Type: facility.TaxiPathParking, Start: c0, End: 0},
+	}
+	ap := airporttest.Airport(1600, points, paths)
+	ap.Altitude = fixtureAltitude
+	ap.Parking = []facility.Parking{
+		{Type: facility.ParkingGateMedium, Heading: 0, BiasX: 0, BiasZ: 450},
+	}
+	return ap
+}
+
+// near reports whether two positions are within a few centimetres.
+func near(lat1, lon1, lat2, lon2 float64) bool {
+	return math.Abs(lat1-lat2) < 1e-6 && math.Abs(lon1-lon2) < 1e-6
+}
+
+// atNode reports whether wp lies on node n of g.
+func atNode(g *airportgraph.Graph, wp types.SIMCONNECT_DATA_WAYPOINT, n int) bool {
+	lat, lon, _ := g.LatLon(n)
+	return near(wp.Latitude, wp.Longitude, lat, lon)
+}
+
+func isReverse(wp types.SIMCONNECT_DATA_WAYPOINT) bool {
+	return wp.Flags&uint32(types.SIMCONNECT_WAYPOINT_REVERSE) != 0
+}
+
+func TestPlanDepartureStraight(t *testing.T) {
+	ap := fixtureAirport()
+	g := airportgraph.Build(ap)
+	speeds := TaxiSpeeds{Pushback: 2, Taxi: 20, Turn: 6}
+
+	r, err := PlanDepartureRoute(ap, 0, "09", DepartureOpts{Speeds: speeds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pushed straight back through C0 to A2, then west along A to the
+	// hold-short point of the entry at the threshold.
+	wantNodes := []int{p0, a2, a1, a0, h0}
+	if len(r.Nodes) != len(wantNodes) {
+		t.Fatalf("nodes = %v, want %v", r.Nodes, wantNodes)
+	}
+	for i, n := range wantNodes {
+		if r.Nodes[i] != n {
+			t.Fatalf("nodes = %v, want %v", r.Nodes, wantNodes)
+		}
+	}
+	if len(r.Waypoints) != len(r.Nodes)-1 {
+		t.Fatalf("%d waypoints for %d nodes", len(r.Waypoints), len(r.Nodes))
+	}
+
+	// The pushback reverses; A1 is straight ahead, A0 is a 90° turn and H0
+	// is the hold-short point.
+	wantKts := []float64{2, 20, 6, 6}
+	for i, wp := range r.Waypoints {
+		if !atNode(g, wp, r.Nodes[i+1]) {
+			t.Errorf("waypoint %d not at node %d", i, r.Nodes[i+1])
+		}
+		if wp.KtsSpeed != wantKts[i] {
+			t.Errorf("waypoint %d: %v kts, want %v", i, wp.KtsSpeed, wantKts[i])
+		}
+		if isReverse(wp) != (i == 0) {
+			t.Errorf("waypoint %d: reverse = %v", i, isReverse(wp))
+		}
+		if want := convert.MetersToFeet(fixtureAltitude); math.Abs(wp.Altitude-want) > 1e-9 {
+			t.Errorf("waypoint %d: altitude %v ft, want field elevation %v", i, wp.Altitude, want)
+		}
+	}
+
+	// Lineup at the runway 09 threshold, then the jet climb-out.
+	if len(r.Lineup) != 4 {
+		t.Fatalf("lineup and climb = %d waypoints, want 4", len(r.Lineup))
+	}
+	if !atNode(g, r.Lineup[0], r0) || r.Lineup[0].KtsSpeed != 5 {
+		t.Errorf("lineup = %+v, want 5 kts at the threshold", r.Lineup[0])
+	}
+	for i, want := range []float64{1500, 4000, 9000} {
+		if got := r.Lineup[i+1].Altitude; got != want {
+			t.Errorf("climb %d: %v ft AGL, want %v", i, got, want)
+		}
+	}
+
+	wps, err := PlanDeparture(ap, 0, "09", DepartureOpts{Speeds: speeds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wps) != len(r.Waypoints)+len(r.Lineup) {
+		t.Errorf("PlanDeparture = %d waypoints, want %d", len(wps), len(r.Waypoints)+len(r.Lineup))
+	}
+}
+
+func TestPlanDeparturePushbackModes(t *testing.T) {
+	ap := fixtureAirport()
+	g := airportgraph.Build(ap)
+	gateLat, gateLon, _ := g.LatLon(p0)
+
+	tests := []struct {
+		name    string
+		dir     PushbackDirection
+		push    []int // nodes of the reversing waypoints
+		first   int   // node of the first forward waypoint
+		heading float64
+	}{
+		{"straight", PushbackStraight, []int{a2}, a1, 0},
+		{"tail left", PushbackTailLeft, []int{c0, c1}, a1, 0},
+		{"tail right", PushbackTailRight, []int{c0, c2}, c0, 0},
+		{"none", PushbackNone, nil, c0, 180},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DepartureOpts{Pushback: tt.dir}
+			wps, err := PlanDeparture(ap, 0, "09", opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, n := range tt.push {
+				if !isReverse(wps[i]) || !atNode(g, wps[i], n) || wps[i].KtsSpeed != DefaultPushbackSpeed {
+					t.Errorf("waypoint %d = %+v, want pushback to node %d", i, wps[i], n)
+				}
+			}
+			next := wps[len(tt.push)]
+			if isReverse(next) || !atNode(g, next, tt.first) {
+				t.Errorf("first taxi waypoint = %+v, want node %d", next, tt.first)
+			}
+
+			spawn, err := DepartureSpawn(ap, 0, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(spawn.Latitude, spawn.Longitude, gateLat, gateLon) || spawn.OnGround != 1 {
+				t.Errorf("spawn = %+v, want on the ground at the gate", spawn)
+			}
+			if math.Abs(convert.AngleDifference(spawn.Heading, tt.heading)) > 1e-6 {
+				t.Errorf("spawn heading = %v, want %v", spawn.Heading, tt.heading)
+			}
+		})
+	}
+}
+
+func TestPlanDepartureClimb(t *testing.T) {
+	ap := fixtureAirport()
+	tests := []struct {
+		name     string
+		opts     DepartureOpts
+		altitude []float64
+		kts      float64 // of the first climb waypoint
+	}{
+		{"jet", DepartureOpts{}, []float64{1500, 4000, 9000}, 200},
+		{"capped", DepartureOpts{InitialAltitude: 3000}, []float64{1500, 3000, 3000}, 200},
+		{"raised", DepartureOpts{InitialAltitude: 12000}, []float64{1500, 4000, 12000}, 200},
+		{"light", DepartureOpts{Category: CategoryLight}, []float64{1000, 2500, 4500}, 80},
+		{"heavy capped", DepartureOpts{Category: CategoryHeavy, InitialAltitude: 5000}, []float64{1500, 4000, 5000}, 180},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wps, err := PlanDeparture(ap, 0, "09", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			climb := wps[len(wps)-3:]
+			for i, want := range tt.altitude {
+				if climb[i].Altitude != want {
+					t.Errorf("climb %d: %v ft AGL, want %v", i, climb[i].Altitude, want)
+				}
+				if climb[i].Flags&uint32(types.SIMCONNECT_WAYPOINT_ALTITUDE_IS_AGL) == 0 {
+					t.Errorf("climb %d: altitude not AGL", i)
+				}
+			}
+			if climb[0].KtsSpeed != tt.kts {
+				t.Errorf("first climb waypoint: %v kts, want %v", climb[0].KtsSpeed, tt.kts)
+			}
+		})
+	}
+}
+
+func TestPlanDepartureDefaultSpeeds(t *testing.T) {
+	r, err := PlanDepartureRoute(fixtureAirport(), 0, "09", DepartureOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{DefaultPushbackSpeed, DefaultTaxiSpeed, DefaultTurnSpeed, DefaultTurnSpeed}
+	for i, wp := range r.Waypoints {
+		if wp.KtsSpeed != want[i] {
+			t.Errorf("waypoint %d: %v kts, want %v", i, wp.KtsSpeed, want[i])
+		}
+	}
+}
+
+func TestPlanDepartureErrors(t *testing.T) {
+	ap := fixtureAirport()
+	if _, err := PlanDeparture(ap, 1, "09", DepartureOpts{}); !errors.Is(err, airportgraph.ErrUnknownNode) {
+		t.Errorf("unknown gate: err = %v, want ErrUnknownNode", err)
+	}
+	if _, err := DepartureSpawn(ap, 1, DepartureOpts{}); !errors.Is(err, airportgraph.ErrUnknownNode) {
+		t.Errorf("spawn at unknown gate: err = %v, want ErrUnknownNode", err)
+	}
+	if _, err := PlanDeparture(ap, 0, "18", DepartureOpts{}); !errors.Is(err, airportgraph.ErrUnknownRunway) {
+		t.Errorf("unknown runway: err = %v, want ErrUnknownRunway", err)
+	}
+	cut := airportgraph.Constraints{AvoidTaxiways: []string{"B1", "B3", "B5"}}
+	if _, err := PlanDeparture(ap, 0, "09", DepartureOpts{Constraints: cut}); !errors.Is(err, airportgraph.ErrNoRoute) {
+		t.Errorf("no route: err = %v, want ErrNoRoute", err)
+	}
+}
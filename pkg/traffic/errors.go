@@ -3,7 +3,12 @@
 
 package traffic
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
 
 var (
 	// ErrNotConnected is returned when the fleet's engine client is nil.
@@ -16,7 +21,49 @@ var (
 	// ErrCreationFailed is returned when an aircraft creation call fails.
 	ErrCreationFailed = errors.New("traffic: aircraft creation failed")
 
+	// ErrSpawnTimeout is the error of a FleetSpawnFailed event for a creation
+	// that got no assigned ObjectID within the fleet's pending timeout.
+	ErrSpawnTimeout = errors.New("traffic: aircraft creation timed out")
+
+	// ErrSpawnCancelled is the error of a FleetSpawnFailed event for a
+	// creation withdrawn with Fleet.Cancel.
+	ErrSpawnCancelled = errors.New("traffic: aircraft creation cancelled")
+
+	// ErrSnapshotVersion is returned by LoadSnapshot for a snapshot written
+	// by a newer version of this package.
+	ErrSnapshotVersion = errors.New("traffic: unsupported snapshot version")
+
+	// ErrTrackIDs is returned by Track when SetTrackIDs has not been called.
+	ErrTrackIDs = errors.New("traffic: no data definition or request IDs set for tracking")
+
+	// ErrTrackDataset is returned by Track for a dataset it cannot decode.
+	ErrTrackDataset = errors.New("traffic: dataset not suitable for tracking")
+
 	// ErrEmptyWaypoints is returned when SetWaypoints is called with a nil or
 	// zero-length waypoint slice.
 	ErrEmptyWaypoints = errors.New("traffic: waypoints slice must not be empty")
+
+	// ErrApproachRunway is returned by PlanArrival when the approach serves a
+	// different runway than the one requested.
+	ErrApproachRunway = errors.New("traffic: approach does not serve the runway")
+
+	// ErrUnknownTransition is returned by PlanArrival when the approach has no
+	// transition of the requested name.
+	ErrUnknownTransition = errors.New("traffic: approach transition not found")
 )
+
+// CreationError is the error of a FleetSpawnFailed event for a creation the
+// simulator rejected with a SIMCONNECT_RECV_EXCEPTION. It matches
+// ErrCreationFailed with errors.Is.
+type CreationError struct {
+	ReqID     uint32                     // request ID of the creation
+	SendID    uint32                     // packet ID the exception referred to
+	Exception types.SIMCONNECT_EXCEPTION // exception code
+}
+
+func (e *CreationError) Error() string {
+	return fmt.Sprintf("%v: request %d rejected with exception %d", ErrCreationFailed, e.ReqID, e.Exception)
+}
+
+// Unwrap returns ErrCreationFailed.
+func (e *CreationError) Unwrap() error { return ErrCreationFailed }
@@ -0,0 +1,203 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import "sync"
+
+// DefaultEventBufferSize is the channel buffer of a FleetSubscription when
+// Subscribe is given a size <= 0.
+const DefaultEventBufferSize = 16
+
+// FleetEventKind identifies a change of the fleet.
+type FleetEventKind uint8
+
+const (
+	// FleetSpawned — a pending creation was acknowledged and the aircraft
+	// joined the fleet.
+	FleetSpawned FleetEventKind = iota
+	// FleetSpawnFailed — a pending creation was dropped: the simulator
+	// rejected it, it timed out or the connection was lost.
+	FleetSpawnFailed
+	// FleetRemoved — an aircraft left the fleet, either through Remove or
+	// because the simulator deleted it.
+	FleetRemoved
+	// FleetUpdated — new Telemetry arrived for a member tracked with
+	// Fleet.Track. Delivered by Fleet.SubscribeUpdates only.
+	FleetUpdated
+)
+
+// String returns "spawned", "spawn failed", "removed" or "updated".
+func (k FleetEventKind) String() string {
+	switch k {
+	case FleetUpdated:
+		return "updated"
+	case FleetSpawnFailed:
+		return "spawn failed"
+	case FleetRemoved:
+		return "removed"
+	default:
+		return "spawned"
+	}
+}
+
+// FleetEvent is a change of the fleet delivered by Fleet.Subscribe, or a
+// telemetry update delivered by Fleet.SubscribeUpdates.
+type FleetEvent struct {
+	Kind     FleetEventKind
+	Aircraft *Aircraft // the aircraft, as updated for FleetUpdated; nil for FleetSpawnFailed
+	Pending  Pending   // the creation request; zero for FleetRemoved
+	// Err is why the creation failed for FleetSpawnFailed. For FleetSpawned,
+	// it is the error of setting the waypoint chain or flight plan of a
+	// restored aircraft again, or of requesting its telemetry, if any.
+	Err error
+}
+
+// FleetSubscription delivers FleetEvents until Unsubscribe is called.
+type FleetSubscription struct {
+	fleet   *Fleet
+	updates bool // created by SubscribeUpdates: FleetUpdated events only
+	ch      chan FleetEvent
+	done    chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	// Lifecycle events wait here until the reader takes them, so a slow
+	// reader delays them but never loses one.
+	queue []FleetEvent
+	ready chan struct{} // signalled when queue gains events
+}
+
+// Events returns the event channel. It is closed after Unsubscribe.
+func (s *FleetSubscription) Events() <-chan FleetEvent { return s.ch }
+
+// Done returns a channel closed by Unsubscribe.
+func (s *FleetSubscription) Done() <-chan struct{} { return s.done }
+
+// Unsubscribe stops delivery and closes the channels. Queued events that
+// were not read are discarded. It is safe to call more than once.
+func (s *FleetSubscription) Unsubscribe() {
+	s.fleet.mu.Lock()
+	delete(s.fleet.subs, s)
+	s.fleet.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.queue = nil
+	close(s.done)
+	if s.updates {
+		close(s.ch) // a lifecycle subscription's channel is closed by pump
+	}
+}
+
+// deliver hands ev to the subscription if it carries events of its kind.
+// Lifecycle events are queued for pump; telemetry updates are sent without
+// blocking and dropped when the channel is full, as the next sample
+// supersedes them. It holds mu so a concurrent Unsubscribe cannot close the
+// channel mid-send.
+func (s *FleetSubscription) deliver(ev FleetEvent) {
+	if s.updates != (ev.Kind == FleetUpdated) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if s.updates {
+		select {
+		case s.ch <- ev:
+		default:
+		}
+		return
+	}
+	s.queue = append(s.queue, ev)
+	select {
+	case s.ready <- struct{}{}:
+	default: // already signalled
+	}
+}
+
+// pump moves queued lifecycle events to the channel, in order, until
+// Unsubscribe. It closes the channel on return.
+func (s *FleetSubscription) pump() {
+	defer close(s.ch)
+	var batch []FleetEvent
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.ready:
+		}
+		s.mu.Lock()
+		batch, s.queue = s.queue, batch[:0]
+		s.mu.Unlock()
+		for i, ev := range batch {
+			select {
+			case s.ch <- ev:
+			case <-s.done:
+				return
+			}
+			batch[i] = FleetEvent{}
+		}
+	}
+}
+
+// Subscribe returns a subscription delivering every Spawned, SpawnFailed
+// and Removed event of the fleet. The channel is buffered with bufferSize
+// (DefaultEventBufferSize if <= 0). No event is dropped: when the channel
+// is full, further events wait in the subscription until they are read.
+// Telemetry updates are delivered by SubscribeUpdates instead.
+func (f *Fleet) Subscribe(bufferSize int) *FleetSubscription {
+	s := f.subscribe(bufferSize, false)
+	s.ready = make(chan struct{}, 1)
+	go s.pump()
+	return s
+}
+
+// SubscribeUpdates returns a subscription delivering the FleetUpdated
+// events of aircraft tracked with Track. The channel is buffered with
+// bufferSize (DefaultEventBufferSize if <= 0); updates are dropped when it
+// is full, and Aircraft.State always holds the latest sample.
+func (f *Fleet) SubscribeUpdates(bufferSize int) *FleetSubscription {
+	return f.subscribe(bufferSize, true)
+}
+
+func (f *Fleet) subscribe(bufferSize int, updates bool) *FleetSubscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	s := &FleetSubscription{
+		fleet:   f,
+		updates: updates,
+		ch:      make(chan FleetEvent, bufferSize),
+		done:    make(chan struct{}),
+	}
+	f.mu.Lock()
+	f.subs[s] = struct{}{}
+	f.mu.Unlock()
+	return s
+}
+
+// subscribersLocked returns a snapshot of the subscriptions.
+// Caller must hold f.mu.
+func (f *Fleet) subscribersLocked() []*FleetSubscription {
+	subs := make([]*FleetSubscription, 0, len(f.subs))
+	for s := range f.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// publish delivers events to subs, outside f.mu.
+func publish(subs []*FleetSubscription, events ...FleetEvent) {
+	for _, ev := range events {
+		for _, s := range subs {
+			s.deliver(ev)
+		}
+	}
+}
@@ -0,0 +1,443 @@
+//go:build windows
+// +build windows
+
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+const (
+	// DefaultLookahead is how far ahead a waypoint chain reaches.
+	DefaultLookahead = 30 * time.Second
+
+	// DefaultRefresh is how often a waypoint chain is sent again.
+	DefaultRefresh = 5 * time.Second
+
+	// DefaultRetry is how long a target whose creation failed waits before
+	// it is requested again.
+	DefaultRetry = 30 * time.Second
+)
+
+// chainLength is the number of waypoints of a rolling chain, spread evenly
+// over the lookahead.
+const chainLength = 3
+
+// minTaxiSpeed is the waypoint speed, in knots, of a target reported
+// stationary on the ground, so the AI keeps creeping to its position.
+const minTaxiSpeed = 5
+
+// Mode is how an Injector moves its aircraft.
+type Mode uint8
+
+const (
+	// ModeWaypoints releases each aircraft to the AI and keeps it flying
+	// a short chain of predicted positions, sent again every Refresh.
+	ModeWaypoints Mode = iota
+	// ModeSlew keeps each aircraft under SimConnect control and writes the
+	// predicted position on every Tick.
+	ModeSlew
+)
+
+// String returns "waypoints" or "slew".
+func (m Mode) String() string {
+	if m == ModeSlew {
+		return "slew"
+	}
+	return "waypoints"
+}
+
+// Writer registers and writes the data ModeSlew moves aircraft with.
+type Writer interface {
+	RegisterDataset(definitionID uint32, dataset *datasets.DataSet) error
+	SetDataOnSimObject(definitionID uint32, objectID uint32, flags types.SIMCONNECT_DATA_SET_FLAG, arrayCount uint32, cbUnitSize uint32, data unsafe.Pointer) error
+}
+
+// InjectorConfig configures an Injector.
+type InjectorConfig struct {
+	Mode    Mode
+	Models  Models
+	Tracker TrackerOptions
+
+	// ReqIDMin and ReqIDMax bound the request IDs of creations, releases
+	// and removals, taken in turn.
+	ReqIDMin, ReqIDMax uint32
+
+	// WaypointDefID is a definition registered for "AI Waypoint List"
+	// (ModeWaypoints).
+	WaypointDefID uint32
+
+	// SlewDefID is the definition the Injector registers for position
+	// writes (ModeSlew). It must not be in use.
+	SlewDefID uint32
+
+	// Lookahead and Refresh tune ModeWaypoints; zero values use
+	// DefaultLookahead and DefaultRefresh.
+	Lookahead time.Duration
+	Refresh   time.Duration
+
+	// Retry is how long a failed creation waits; zero uses DefaultRetry.
+	Retry time.Duration
+
+	// Filter, if set, selects the targets to inject. Targets it rejects are
+	// not created, or removed if they were.
+	Filter func(Target) bool
+}
+
+// slewData is the layout of the ModeSlew position writes.
+type slewData struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Heading   float64
+}
+
+// slewDataset returns the definition of slewData.
+func slewDataset() datasets.DataSet {
+	return datasets.NewBuilder().
+		AddField("PLANE LATITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE LONGITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE ALTITUDE", "feet", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE HEADING DEGREES TRUE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		Build()
+}
+
+// injected is the simulator side of a target.
+type injected struct {
+	reqID    uint32    // creation request while pending, else 0
+	objectID uint32    // 0 while pending
+	retryAt  time.Time // when a failed creation may be requested again
+	released bool
+	chainAt  time.Time // when the last waypoint chain was sent
+	drop     bool      // remove as soon as the creation is acknowledged
+}
+
+// Injector mirrors the targets of a Tracker as non-ATC aircraft of a
+// traffic.Fleet. Feed it reports with Update, e.g. as the callback of Dial,
+// and call Tick periodically, or use Run.
+//
+// Each positioned target is created with RequestNonATC at its predicted
+// position, with the model Models picks for its type and its callsign, or
+// hex, as tail number; targets without a model are skipped. Targets that go
+// stale are removed. After the fleet is bound to a new connection, call
+// Reset.
+type Injector struct {
+	fleet   *traffic.Fleet
+	writer  Writer
+	cfg     InjectorConfig
+	tracker *Tracker
+
+	mu         sync.Mutex
+	aircraft   map[string]*injected // hex → aircraft
+	requests   map[uint32]string    // pending request ID → hex
+	registered bool
+	reqIDs     *traffic.RequestIDs
+}
+
+// NewInjector returns an injector creating aircraft in fleet. writer is
+// only used in ModeSlew and may be nil otherwise.
+func NewInjector(fleet *traffic.Fleet, writer Writer, cfg InjectorConfig) *Injector {
+	if cfg.Lookahead <= 0 {
+		cfg.Lookahead = DefaultLookahead
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = DefaultRefresh
+	}
+	if cfg.Retry <= 0 {
+		cfg.Retry = DefaultRetry
+	}
+	return &Injector{
+		fleet:    fleet,
+		writer:   writer,
+		cfg:      cfg,
+		tracker:  NewTracker(cfg.Tracker),
+		aircraft: make(map[string]*injected),
+		requests: make(map[uint32]string),
+		reqIDs:   traffic.NewRequestIDs(cfg.ReqIDMin, cfg.ReqIDMax),
+	}
+}
+
+// Tracker returns the tracker the injector mirrors.
+func (in *Injector) Tracker() *Tracker { return in.tracker }
+
+// Update merges a feed report into the tracker. It is safe to call from
+// the goroutine reading the feed.
+func (in *Injector) Update(r Report) { in.tracker.Update(r) }
+
+// Object returns the object ID of the aircraft mirroring target hex.
+func (in *Injector) Object(hex string) (uint32, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	ac, ok := in.aircraft[hex]
+	if !ok || ac.objectID == 0 {
+		return 0, false
+	}
+	return ac.objectID, true
+}
+
+// Reset forgets every aircraft and registration, so targets are created
+// again on the fleet's new connection. Call it after Fleet.SetClient.
+func (in *Injector) Reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.aircraft = make(map[string]*injected)
+	in.requests = make(map[uint32]string)
+	in.registered = false
+}
+
+// HandleEvent follows the fate of the injector's creations. Run calls it;
+// call it yourself when driving Tick from your own loop. Creations whose
+// event it misses are settled against the fleet by the next Tick.
+func (in *Injector) HandleEvent(ev traffic.FleetEvent) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	switch ev.Kind {
+	case traffic.FleetSpawned:
+		in.spawnedLocked(ev.Pending.ReqID, ev.Aircraft.ObjectID)
+	case traffic.FleetSpawnFailed:
+		in.failedLocked(ev.Pending.ReqID, time.Now())
+	case traffic.FleetRemoved:
+		for hex, ac := range in.aircraft {
+			if ac.objectID == ev.Aircraft.ObjectID {
+				// Deleted by the simulator; created again on the next Tick.
+				delete(in.aircraft, hex)
+				return
+			}
+		}
+	}
+}
+
+// spawnedLocked binds the aircraft created by request reqID to its target,
+// or removes it if the target was dropped meanwhile.
+// Caller must hold in.mu.
+func (in *Injector) spawnedLocked(reqID, objectID uint32) {
+	hex, ok := in.requests[reqID]
+	if !ok {
+		return
+	}
+	delete(in.requests, reqID)
+	ac := in.aircraft[hex]
+	if ac == nil || ac.drop {
+		delete(in.aircraft, hex)
+		_ = in.fleet.Remove(objectID, in.reqIDs.Next())
+		return
+	}
+	ac.reqID, ac.objectID = 0, objectID
+}
+
+// failedLocked lets the target of the failed request reqID be created
+// again after the retry delay. Caller must hold in.mu.
+func (in *Injector) failedLocked(reqID uint32, now time.Time) {
+	hex, ok := in.requests[reqID]
+	if !ok {
+		return
+	}
+	delete(in.requests, reqID)
+	if ac := in.aircraft[hex]; ac != nil {
+		if ac.drop {
+			delete(in.aircraft, hex)
+			return
+		}
+		ac.reqID, ac.retryAt = 0, now.Add(in.cfg.Retry)
+	}
+}
+
+// reconcileLocked settles the requests the fleet no longer has pending but
+// HandleEvent has not heard of: an aircraft created by the request is
+// bound to its target, otherwise the creation failed. Without this, a
+// missed event would hold the target's slot forever.
+// Caller must hold in.mu.
+func (in *Injector) reconcileLocked(now time.Time) {
+	var created map[uint32]uint32 // request ID → object ID of unbound members
+	for reqID := range in.requests {
+		if in.fleet.IsPending(reqID) {
+			continue
+		}
+		if created == nil {
+			created = in.unboundLocked()
+		}
+		if objectID, ok := created[reqID]; ok {
+			in.spawnedLocked(reqID, objectID)
+		} else {
+			in.failedLocked(reqID, now)
+		}
+	}
+}
+
+// unboundLocked maps the request IDs of the fleet's members that are not
+// yet bound to a target to their object IDs. Caller must hold in.mu.
+func (in *Injector) unboundLocked() map[uint32]uint32 {
+	bound := make(map[uint32]bool, len(in.aircraft))
+	for _, ac := range in.aircraft {
+		if ac.objectID != 0 {
+			bound[ac.objectID] = true
+		}
+	}
+	created := make(map[uint32]uint32)
+	for _, a := range in.fleet.List() {
+		if !bound[a.ObjectID] {
+			created[a.ReqID] = a.ObjectID
+		}
+	}
+	return created
+}
+
+// Tick removes the aircraft of stale and filtered targets, creates those of
+// new targets and moves the others to their predicted position at now.
+// Returns the joined errors of these calls.
+func (in *Injector) Tick(now time.Time) error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.reconcileLocked(now)
+
+	var errs []error
+	for _, tg := range in.tracker.Expire(now) {
+		if err := in.dropLocked(tg.Hex); err != nil {
+			errs = append(errs, fmt.Errorf("removing %s: %w", tg.Hex, err))
+		}
+	}
+	if in.cfg.Mode == ModeSlew && !in.registered {
+		ds := slewDataset()
+		if err := in.writer.RegisterDataset(in.cfg.SlewDefID, &ds); err != nil {
+			return errors.Join(append(errs, fmt.Errorf("registering slew data: %w", err))...)
+		}
+		in.registered = true
+	}
+
+	for _, tg := range in.tracker.Targets() {
+		if in.cfg.Filter != nil && !in.cfg.Filter(tg) {
+			if err := in.dropLocked(tg.Hex); err != nil {
+				errs = append(errs, fmt.Errorf("removing %s: %w", tg.Hex, err))
+			}
+			continue
+		}
+		pos, ok := in.tracker.Position(tg.Hex, now)
+		if !ok {
+			continue
+		}
+		ac, ok := in.aircraft[tg.Hex]
+		switch {
+		case !ok || (ac.reqID == 0 && ac.objectID == 0 && !now.Before(ac.retryAt)):
+			if err := in.createLocked(tg, pos); err != nil {
+				errs = append(errs, fmt.Errorf("creating %s: %w", tg.Hex, err))
+			}
+		case ac.objectID != 0:
+			if err := in.moveLocked(tg.Hex, ac, now, pos); err != nil {
+				errs = append(errs, fmt.Errorf("moving %s: %w", tg.Hex, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// createLocked requests the aircraft of tg at pos. Caller must hold in.mu.
+func (in *Injector) createLocked(tg Target, pos Position) error {
+	model := in.cfg.Models.Model(tg.Type)
+	if model == "" {
+		return nil
+	}
+	tail := tg.Callsign
+	if tail == "" {
+		tail = tg.Hex
+	}
+	var onGround types.DWORD
+	if pos.OnGround {
+		onGround = 1
+	}
+	reqID := in.reqIDs.Next()
+	err := in.fleet.RequestNonATC(traffic.NonATCOpts{
+		Model: model,
+		Tail:  tail,
+		Position: types.SIMCONNECT_DATA_INITPOSITION{
+			Latitude:  pos.Latitude,
+			Longitude: pos.Longitude,
+			Altitude:  pos.Altitude,
+			Heading:   pos.Heading,
+			OnGround:  onGround,
+			Airspeed:  types.SIMCONNECT_DATA_INITPOSITION_AIRSPEED(pos.Speed),
+		},
+	}, reqID)
+	if err != nil {
+		return err
+	}
+	in.aircraft[tg.Hex] = &injected{reqID: reqID}
+	in.requests[reqID] = tg.Hex
+	return nil
+}
+
+// moveLocked moves ac towards target hex, predicted at pos at now.
+// Caller must hold in.mu.
+func (in *Injector) moveLocked(hex string, ac *injected, now time.Time, pos Position) error {
+	if in.cfg.Mode == ModeSlew {
+		data := slewData{
+			Latitude:  pos.Latitude,
+			Longitude: pos.Longitude,
+			Altitude:  pos.Altitude,
+			Heading:   pos.Heading,
+		}
+		return in.writer.SetDataOnSimObject(
+			in.cfg.SlewDefID,
+			ac.objectID,
+			types.SIMCONNECT_DATA_SET_FLAG_DEFAULT,
+			1,
+			uint32(unsafe.Sizeof(data)),
+			unsafe.Pointer(&data),
+		)
+	}
+
+	if !ac.released {
+		if err := in.fleet.ReleaseControl(ac.objectID, in.reqIDs.Next()); err != nil {
+			return err
+		}
+		ac.released = true
+	}
+	if !ac.chainAt.IsZero() && now.Sub(ac.chainAt) < in.cfg.Refresh {
+		return nil
+	}
+	wps := make([]types.SIMCONNECT_DATA_WAYPOINT, 0, chainLength)
+	for i := 1; i <= chainLength; i++ {
+		p, _ := in.tracker.Position(hex, now.Add(in.cfg.Lookahead*time.Duration(i)/chainLength))
+		if p.OnGround {
+			wps = append(wps, traffic.TaxiWaypoint(p.Latitude, p.Longitude, p.Altitude, max(p.Speed, minTaxiSpeed)))
+		} else {
+			wps = append(wps, traffic.DescentWaypoint(p.Latitude, p.Longitude, p.Altitude, p.Speed))
+		}
+	}
+	if err := in.fleet.SetWaypoints(ac.objectID, in.cfg.WaypointDefID, wps); err != nil {
+		return err
+	}
+	ac.chainAt = now
+	return nil
+}
+
+// dropLocked removes the aircraft of target hex, now or, while its
+// creation is pending, once it is acknowledged. Caller must hold in.mu.
+func (in *Injector) dropLocked(hex string) error {
+	ac, ok := in.aircraft[hex]
+	if !ok {
+		return nil
+	}
+	if ac.reqID != 0 {
+		ac.drop = true
+		return nil
+	}
+	delete(in.aircraft, hex)
+	if ac.objectID == 0 {
+		return nil
+	}
+	return in.fleet.Remove(ac.objectID, in.reqIDs.Next())
+}
+
+// Run drives the injector with traffic.Run until ctx is done.
+func (in *Injector) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return traffic.Run(ctx, in.fleet, in, interval, onError)
+}
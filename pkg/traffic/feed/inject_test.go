@@ -0,0 +1,81 @@
+//go:build windows
+
+package feed
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeClient accepts the calls an Injector makes through its fleet and
+// records the creations.
+type fakeClient struct {
+	engine.Client
+	mu      sync.Mutex
+	sendID  uint32
+	created map[string]uint32 // tail → request ID
+}
+
+func (c *fakeClient) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, initPos types.SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.created[szTailNumber] = RequestID
+	return nil
+}
+
+func (c *fakeClient) AIReleaseControl(objectID uint32, requestID uint32) error { return nil }
+
+func (c *fakeClient) SetDataOnSimObject(definitionID uint32, objectID uint32, flags types.SIMCONNECT_DATA_SET_FLAG, arrayCount uint32, cbUnitSize uint32, data unsafe.Pointer) error {
+	return nil
+}
+
+func (c *fakeClient) GetLastSentPacketID() (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendID, nil
+}
+
+func TestInjectorSettlesMissedEvents(t *testing.T) {
+	c := &fakeClient{created: make(map[string]uint32)}
+	f := traffic.NewFleet(c)
+	f.SetPendingTimeout(0)
+	in := NewInjector(f, nil, InjectorConfig{
+		Models:   Models{Default: "A320"},
+		ReqIDMin: 100,
+		ReqIDMax: 199,
+	})
+	t0 := time.Now()
+	for _, hex := range []string{"A1", "A2"} {
+		in.Update(Report{Hex: hex, Fields: HasPosition | HasAltitude, Latitude: 50, Longitude: 14, Altitude: 3000, Time: t0})
+	}
+	if err := in.Tick(t0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both outcomes reach the fleet, but HandleEvent never hears of them.
+	if _, ok := f.Acknowledge(c.created["A1"], 7); !ok {
+		t.Fatal("creation of A1 not pending")
+	}
+	f.Fail(c.created["A2"], errors.New("rejected"))
+
+	if err := in.Tick(t0.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := in.Object("A1"); !ok || id != 7 {
+		t.Errorf("Object(A1) = %d, %v; want 7", id, ok)
+	}
+	if ac := in.aircraft["A2"]; ac == nil || ac.reqID != 0 || ac.retryAt.IsZero() {
+		t.Errorf("A2 = %+v, want its failed creation waiting for a retry", ac)
+	}
+	if len(in.requests) != 0 {
+		t.Errorf("%d requests left unsettled", len(in.requests))
+	}
+}
@@ -0,0 +1,155 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// jsonReport is a report of a JSON feed. It accepts the package's own
+// field names and those of dump1090/readsb aircraft.json entries; fields
+// that are absent stay nil.
+type jsonReport struct {
+	Hex      string          `json:"hex"`
+	Callsign *string         `json:"callsign"`
+	Flight   *string         `json:"flight"` // dump1090
+	Type     *string         `json:"type"`
+	T        *string         `json:"t"` // readsb
+	Lat      *float64        `json:"lat"`
+	Lon      *float64        `json:"lon"`
+	Alt      *float64        `json:"alt"`
+	AltBaro  json.RawMessage `json:"alt_baro"` // feet, or "ground"
+	GS       *float64        `json:"gs"`
+	Track    *float64        `json:"track"`
+	VS       *float64        `json:"vs"`
+	BaroRate *float64        `json:"baro_rate"` // dump1090
+	OnGround *bool           `json:"onGround"`
+	Squawk   *string         `json:"squawk"`
+}
+
+// jsonValue is a top-level value of a JSON feed: a single report, or an
+// aircraft.json document listing reports under "aircraft".
+type jsonValue struct {
+	jsonReport
+	Aircraft []jsonReport `json:"aircraft"`
+}
+
+func (j jsonReport) report(t time.Time) (Report, error) {
+	r := Report{Hex: strings.ToUpper(strings.TrimSpace(j.Hex)), Time: t}
+	str := func(dst *string, flag Fields, srcs ...*string) {
+		for _, s := range srcs {
+			if s != nil && strings.TrimSpace(*s) != "" {
+				*dst, r.Fields = strings.TrimSpace(*s), r.Fields|flag
+				return
+			}
+		}
+	}
+	num := func(dst *float64, flag Fields, srcs ...*float64) {
+		for _, s := range srcs {
+			if s != nil {
+				*dst, r.Fields = *s, r.Fields|flag
+				return
+			}
+		}
+	}
+	str(&r.Callsign, HasCallsign, j.Callsign, j.Flight)
+	str(&r.Type, HasType, j.Type, j.T)
+	str(&r.Squawk, HasSquawk, j.Squawk)
+	if j.Lat != nil && j.Lon != nil {
+		r.Latitude, r.Longitude, r.Fields = *j.Lat, *j.Lon, r.Fields|HasPosition
+	}
+	num(&r.Altitude, HasAltitude, j.Alt)
+	num(&r.GroundSpeed, HasGroundSpeed, j.GS)
+	num(&r.Track, HasTrack, j.Track)
+	num(&r.VerticalRate, HasVerticalRate, j.VS, j.BaroRate)
+	if j.OnGround != nil {
+		r.OnGround, r.Fields = *j.OnGround, r.Fields|HasOnGround
+	}
+	if len(j.AltBaro) > 0 {
+		var alt float64
+		var word string
+		switch {
+		case json.Unmarshal(j.AltBaro, &alt) == nil:
+			r.Altitude, r.Fields = alt, r.Fields|HasAltitude
+		case json.Unmarshal(j.AltBaro, &word) == nil && word == "ground":
+			r.OnGround, r.Fields = true, r.Fields|HasOnGround
+		default:
+			return Report{}, fmt.Errorf("%w: alt_baro %s", ErrMalformed, j.AltBaro)
+		}
+	}
+	if r.Hex == "" {
+		return Report{}, fmt.Errorf("%w: report without hex", ErrMalformed)
+	}
+	return r, nil
+}
+
+// ParseJSON parses one JSON feed value received at t. The value is a
+// report object, an array of them, or a dump1090/readsb aircraft.json
+// document. A report object has the fields
+//
+//	{"hex": "4CA2D6", "callsign": "RYR8AB", "type": "B738",
+//	 "lat": 50.1, "lon": 14.2, "alt": 35000, "gs": 450, "track": 270,
+//	 "vs": -500, "onGround": false, "squawk": "2000"}
+//
+// of which only hex is required.
+func ParseJSON(data []byte, t time.Time) ([]Report, error) {
+	var values []jsonValue
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+	} else {
+		var v jsonValue
+		if err := json.Unmarshal(trimmed, &v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		values = []jsonValue{v}
+	}
+
+	var reports []Report
+	for _, v := range values {
+		entries := v.Aircraft
+		if entries == nil {
+			entries = []jsonReport{v.jsonReport}
+		}
+		for _, e := range entries {
+			r, err := e.report(t)
+			if err != nil {
+				// aircraft.json lists aircraft without hex (TIS-B); skip them.
+				if v.Aircraft != nil {
+					continue
+				}
+				return nil, err
+			}
+			reports = append(reports, r)
+		}
+	}
+	return reports, nil
+}
+
+// ReadJSON reads JSON feed values from r until it ends, as with ParseJSON,
+// calling fn for every report. Values that are not valid reports are
+// skipped. Returns nil at the end of r, or the read or syntax error.
+func ReadJSON(r io.Reader, fn func(Report)) error {
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		reports, err := ParseJSON(raw, time.Now())
+		if err != nil {
+			continue
+		}
+		for _, rep := range reports {
+			fn(rep)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseJSON(t *testing.T) {
+	at := time.Unix(1000, 0)
+	reports, err := ParseJSON([]byte(`{"hex":"4ca2d6","callsign":"RYR8AB ","type":"B738","lat":50.1,"lon":14.2,"alt":35000,"gs":450,"track":270,"vs":-500}`), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := reports[0]
+	if r.Hex != "4CA2D6" || r.Callsign != "RYR8AB" || r.Type != "B738" || r.VerticalRate != -500 {
+		t.Errorf("report = %+v", r)
+	}
+	if !r.Has(HasPosition|HasAltitude|HasGroundSpeed|HasTrack) || r.Has(HasOnGround) || r.Has(HasSquawk) {
+		t.Errorf("fields = %b", r.Fields)
+	}
+}
+
+func TestParseJSONAircraftDocument(t *testing.T) {
+	const doc = `{"now":1705320000,"aircraft":[
+		{"hex":"4ca2d6","flight":"RYR8AB","t":"B738","alt_baro":35000,"baro_rate":-512,"lat":50.1,"lon":14.2},
+		{"hex":"3c6444","alt_baro":"ground","gs":12},
+		{"type":"tisb_other","lat":50.0,"lon":14.0}
+	]}`
+	reports, err := ParseJSON([]byte(doc), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+	if r := reports[0]; r.Callsign != "RYR8AB" || r.Type != "B738" || r.Altitude != 35000 || r.VerticalRate != -512 {
+		t.Errorf("report 0 = %+v", r)
+	}
+	if r := reports[1]; !r.OnGround || r.Has(HasAltitude) || r.GroundSpeed != 12 {
+		t.Errorf("report 1 = %+v", r)
+	}
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	for _, data := range []string{`{"callsign":"X"}`, `{"hex":"1","alt_baro":true}`, `{`} {
+		if _, err := ParseJSON([]byte(data), time.Now()); !errors.Is(err, ErrMalformed) {
+			t.Errorf("ParseJSON(%s) err = %v, want ErrMalformed", data, err)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	const stream = `{"hex":"A1","lat":1,"lon":2}
+[{"hex":"A2"},{"hex":"A3"}]
+{"nohex":true}
+{"hex":"A4"}`
+	var hexes []string
+	if err := ReadJSON(strings.NewReader(stream), func(r Report) { hexes = append(hexes, r.Hex) }); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(hexes, ","); got != "A1,A2,A3,A4" {
+		t.Errorf("hexes = %s", got)
+	}
+}
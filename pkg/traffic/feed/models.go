@@ -0,0 +1,23 @@
+package feed
+
+import "strings"
+
+// Models picks the container title a target is created with from its ICAO
+// type designator.
+type Models struct {
+	// ByType maps upper-case ICAO type designators, e.g. "A20N", to
+	// container titles.
+	ByType map[string]string
+
+	// Default is the title for targets of an unknown or unmapped type.
+	Default string
+}
+
+// Model returns the title for icaoType: its ByType entry, or Default.
+// Designators are matched case-insensitively.
+func (m Models) Model(icaoType string) string {
+	if title, ok := m.ByType[strings.ToUpper(strings.TrimSpace(icaoType))]; ok {
+		return title
+	}
+	return m.Default
+}
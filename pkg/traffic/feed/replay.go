@@ -0,0 +1,176 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReplayInterval is the pause between recorded lines without a
+// timestamp.
+const DefaultReplayInterval = time.Second
+
+// sbsTimeLayout is the layout of the generated date and time fields of an
+// SBS-1 line.
+const sbsTimeLayout = "2006/01/02 15:04:05.999999999"
+
+// SBS-1 field indexes of the time a message was generated.
+const (
+	sbsDateGenerated = 6
+	sbsTimeGenerated = 7
+)
+
+// Replayer serves a recorded feed over TCP to every client that connects,
+// for tests and offline sessions. SBS-1 lines are paced by their generated
+// timestamps; other lines, such as JSON values one per line, are sent
+// Interval apart.
+type Replayer struct {
+	Lines    []string
+	Interval time.Duration // pause between untimed lines; 0 uses DefaultReplayInterval
+	Speed    float64       // playback speed factor; 0 plays at recorded speed
+	Loop     bool          // start over at the end instead of closing the connection
+}
+
+// LoadReplay reads a recording, one feed line per line, skipping blank
+// lines.
+func LoadReplay(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rp := &Replayer{}
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		if line := strings.TrimSpace(sc.Text()); line != "" {
+			rp.Lines = append(rp.Lines, line)
+		}
+	}
+	return rp, sc.Err()
+}
+
+// ListenAndServe listens on addr, e.g. "127.0.0.1:30003", and serves the
+// recording until ctx is done. Returns ctx.Err().
+func (rp *Replayer) ListenAndServe(ctx context.Context, addr string) error {
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return rp.Serve(ctx, l)
+}
+
+// Serve accepts connections on l and plays the recording to each of them
+// until ctx is done, then closes l. Returns ctx.Err(), or the accept error
+// that stopped it.
+func (rp *Replayer) Serve(ctx context.Context, l net.Listener) error {
+	stop := context.AfterFunc(ctx, func() { l.Close() })
+	defer stop()
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			closeConn := context.AfterFunc(ctx, func() { conn.Close() })
+			defer closeConn()
+			_ = rp.Play(ctx, conn)
+		}()
+	}
+}
+
+// Play writes the recording to w, paced as it was recorded, until it ends
+// (or ctx is done when Loop is set). Returns nil at the end of the
+// recording, ctx.Err(), or the write error.
+func (rp *Replayer) Play(ctx context.Context, w io.Writer) error {
+	if len(rp.Lines) == 0 {
+		return nil
+	}
+	for {
+		var last time.Time
+		for i, line := range rp.Lines {
+			stamp, timed := sbsTime(line)
+			if i > 0 {
+				wait := rp.interval()
+				if timed && !last.IsZero() {
+					wait = max(stamp.Sub(last), 0)
+				}
+				if rp.Speed > 0 {
+					wait = time.Duration(float64(wait) / rp.Speed)
+				}
+				if err := sleep(ctx, wait); err != nil {
+					return err
+				}
+			}
+			if timed {
+				last = stamp
+			}
+			if _, err := io.WriteString(w, line+"\r\n"); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return err
+			}
+		}
+		if !rp.Loop {
+			return nil
+		}
+		if err := sleep(ctx, rp.interval()); err != nil {
+			return err
+		}
+	}
+}
+
+func (rp *Replayer) interval() time.Duration {
+	if rp.Interval > 0 {
+		return rp.Interval
+	}
+	return DefaultReplayInterval
+}
+
+// sleep waits d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sbsTime returns the generated timestamp of an SBS-1 line.
+func sbsTime(line string) (time.Time, bool) {
+	if !strings.HasPrefix(line, "MSG,") {
+		return time.Time{}, false
+	}
+	f := strings.Split(line, ",")
+	if len(f) <= sbsTimeGenerated {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(sbsTimeLayout, f[sbsDateGenerated]+" "+f[sbsTimeGenerated])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,64 @@
+package feed
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayerDial(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rp := &Replayer{Lines: strings.Split(strings.TrimSpace(sbsRecording), "\n"), Speed: 10}
+	served := make(chan error, 1)
+	go func() { served <- rp.Serve(ctx, l) }()
+
+	tr := NewTracker(TrackerOptions{})
+	start := time.Now()
+	if err := Dial(ctx, l.Addr().String(), FormatSBS, tr.Update); err != nil {
+		t.Fatal(err)
+	}
+	// 200 ms recorded at 10× speed.
+	if d := time.Since(start); d < 15*time.Millisecond {
+		t.Errorf("replay took %v, want it paced", d)
+	}
+
+	tg, ok := tr.Get("4CA2D6")
+	if !ok {
+		t.Fatal("target 4CA2D6 not tracked")
+	}
+	if tg.Callsign != "RYR8AB" || tg.Altitude != 34990 || tg.GroundSpeed != 450 || tg.Longitude != 14.198 {
+		t.Errorf("target = %+v", tg)
+	}
+
+	cancel()
+	if err := <-served; err != context.Canceled {
+		t.Errorf("Serve = %v, want context.Canceled", err)
+	}
+}
+
+func TestReplayerJSONInterval(t *testing.T) {
+	rp := &Replayer{Lines: []string{`{"hex":"A1"}`, `{"hex":"A2"}`}, Interval: 20 * time.Millisecond}
+	var sb strings.Builder
+	start := time.Now()
+	if err := rp.Play(context.Background(), &sb); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("replay took %v, want at least the interval", d)
+	}
+	var hexes []string
+	if err := ReadJSON(strings.NewReader(sb.String()), func(r Report) { hexes = append(hexes, r.Hex) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(hexes) != 2 {
+		t.Errorf("hexes = %v", hexes)
+	}
+}
@@ -0,0 +1,65 @@
+// Package feed mirrors external traffic feeds in the simulator.
+//
+// Reports come from BaseStation (SBS-1) lines, as served on TCP port 30003
+// by dump1090 and most ADS-B receivers, or from JSON position feeds. A
+// Tracker merges the partial reports of each aircraft and predicts where it
+// is between reports, blending out the jump a new report causes. Injector
+// creates a non-ATC aircraft for each tracked target and moves it, either
+// with short rolling waypoint chains or by writing its position, and removes
+// targets that go stale.
+//
+// Apart from Injector, the package has no simulator dependency. Replayer
+// serves a recorded feed over TCP, for tests and offline sessions.
+package feed
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrMalformed is returned for a feed line or value that cannot be
+	// parsed.
+	ErrMalformed = errors.New("feed: malformed report")
+
+	// ErrNoReport is returned by ParseSBS for a valid line that carries no
+	// aircraft data, such as a status or ID message without a hex ident.
+	ErrNoReport = errors.New("feed: no aircraft report")
+)
+
+// Fields flags the fields of a Report that are set. Feeds send partial
+// updates; a Tracker keeps the last value of fields a report leaves out.
+type Fields uint16
+
+const (
+	HasCallsign Fields = 1 << iota
+	HasType
+	HasPosition // Latitude and Longitude
+	HasAltitude
+	HasGroundSpeed
+	HasTrack
+	HasVerticalRate
+	HasOnGround
+	HasSquawk
+)
+
+// Report is one update of an aircraft from a feed.
+type Report struct {
+	Hex          string // ICAO 24-bit address, upper case hexadecimal
+	Fields       Fields
+	Callsign     string
+	Type         string  // ICAO aircraft type designator, e.g. "A320"
+	Latitude     float64 // degrees
+	Longitude    float64 // degrees
+	Altitude     float64 // feet, barometric
+	GroundSpeed  float64 // knots
+	Track        float64 // degrees true
+	VerticalRate float64 // feet per minute
+	OnGround     bool
+	Squawk       string
+	// Time is when the report was read from the feed.
+	Time time.Time
+}
+
+// Has reports whether all of f are set.
+func (r Report) Has(f Fields) bool { return r.Fields&f == f }
@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SBS-1 field indexes of a MSG line.
+const (
+	sbsType         = 0
+	sbsHex          = 4
+	sbsCallsign     = 10
+	sbsAltitude     = 11
+	sbsGroundSpeed  = 12
+	sbsTrack        = 13
+	sbsLatitude     = 14
+	sbsLongitude    = 15
+	sbsVerticalRate = 16
+	sbsSquawk       = 17
+	sbsOnGround     = 21
+)
+
+// ParseSBS parses a BaseStation (SBS-1) line received at t. Only MSG lines
+// carry aircraft data; other message types return ErrNoReport. Empty fields
+// are left out of the report.
+func ParseSBS(line string, t time.Time) (Report, error) {
+	f := strings.Split(strings.TrimRight(line, "\r\n"), ",")
+	if len(f) < 5 {
+		return Report{}, fmt.Errorf("%w: %q", ErrMalformed, line)
+	}
+	switch f[sbsType] {
+	case "MSG":
+	case "SEL", "ID", "AIR", "STA", "CLK":
+		return Report{}, ErrNoReport
+	default:
+		return Report{}, fmt.Errorf("%w: %q", ErrMalformed, line)
+	}
+	if strings.TrimSpace(f[sbsHex]) == "" {
+		return Report{}, ErrNoReport
+	}
+	r := Report{Hex: strings.ToUpper(strings.TrimSpace(f[sbsHex])), Time: t}
+	field := func(i int) string {
+		if i < len(f) {
+			return strings.TrimSpace(f[i])
+		}
+		return ""
+	}
+	number := func(i int, flag Fields, dst *float64) error {
+		v := field(i)
+		if v == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%w: field %d %q", ErrMalformed, i, v)
+		}
+		*dst = n
+		r.Fields |= flag
+		return nil
+	}
+
+	if v := field(sbsCallsign); v != "" {
+		r.Callsign, r.Fields = v, r.Fields|HasCallsign
+	}
+	if err := number(sbsAltitude, HasAltitude, &r.Altitude); err != nil {
+		return Report{}, err
+	}
+	if err := number(sbsGroundSpeed, HasGroundSpeed, &r.GroundSpeed); err != nil {
+		return Report{}, err
+	}
+	if err := number(sbsTrack, HasTrack, &r.Track); err != nil {
+		return Report{}, err
+	}
+	if field(sbsLatitude) != "" && field(sbsLongitude) != "" {
+		if err := number(sbsLatitude, 0, &r.Latitude); err != nil {
+			return Report{}, err
+		}
+		if err := number(sbsLongitude, HasPosition, &r.Longitude); err != nil {
+			return Report{}, err
+		}
+	}
+	if err := number(sbsVerticalRate, HasVerticalRate, &r.VerticalRate); err != nil {
+		return Report{}, err
+	}
+	if v := field(sbsSquawk); v != "" {
+		r.Squawk, r.Fields = v, r.Fields|HasSquawk
+	}
+	if v := field(sbsOnGround); v != "" {
+		// dump1090 writes -1 for true, other sources 1.
+		r.OnGround, r.Fields = v != "0", r.Fields|HasOnGround
+	}
+	return r, nil
+}
+
+// ReadSBS reads BaseStation lines from r until it ends, calling fn for
+// every aircraft report. Lines that are malformed or carry no report are
+// skipped. Returns nil at the end of r, or the read error.
+func ReadSBS(r io.Reader, fn func(Report)) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		rep, err := ParseSBS(sc.Text(), time.Now())
+		if err != nil {
+			continue
+		}
+		fn(rep)
+	}
+	return sc.Err()
+}
@@ -0,0 +1,73 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sbsRecording = `MSG,1,1,1,4CA2D6,1,2024/01/15,12:00:00.000,2024/01/15,12:00:00.000,RYR8AB,,,,,,,,,,,0
+MSG,3,1,1,4CA2D6,1,2024/01/15,12:00:00.050,2024/01/15,12:00:00.050,,35000,,,50.10000,14.20000,,,0,0,0,0
+MSG,4,1,1,4CA2D6,1,2024/01/15,12:00:00.100,2024/01/15,12:00:00.100,,,450,270,,,-512,,0,0,0,0
+STA,,5,179,4CA2D6,10103,2024/01/15,12:00:00.150,2024/01/15,12:00:00.150,RM
+MSG,3,1,1,4CA2D6,1,2024/01/15,12:00:00.200,2024/01/15,12:00:00.200,,34990,,,50.10010,14.19800,,,0,0,0,0
+`
+
+func TestParseSBS(t *testing.T) {
+	at := time.Unix(1000, 0)
+	r, err := ParseSBS("MSG,3,1,1,4ca2d6,1,2024/01/15,12:00:00.000,2024/01/15,12:00:00.000,,35000,,,50.1,14.2,,,0,0,0,-1", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Hex != "4CA2D6" || !r.Time.Equal(at) {
+		t.Errorf("report = %+v", r)
+	}
+	if !r.Has(HasPosition|HasAltitude|HasOnGround) || r.Has(HasCallsign) || r.Has(HasGroundSpeed) {
+		t.Errorf("fields = %b", r.Fields)
+	}
+	if r.Latitude != 50.1 || r.Longitude != 14.2 || r.Altitude != 35000 || !r.OnGround {
+		t.Errorf("report = %+v", r)
+	}
+
+	r, err = ParseSBS("MSG,4,1,1,4CA2D6,1,,,,,,,450,270,,,-512,,,,,0", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.GroundSpeed != 450 || r.Track != 270 || r.VerticalRate != -512 || r.OnGround {
+		t.Errorf("velocity report = %+v", r)
+	}
+}
+
+func TestParseSBSErrors(t *testing.T) {
+	tests := []struct {
+		line string
+		want error
+	}{
+		{"STA,,5,179,4CA2D6,10103,2024/01/15,12:00:00.150,2024/01/15,12:00:00.150,RM", ErrNoReport},
+		{"MSG,3,1,1,,1,,,,,,35000", ErrNoReport},
+		{"MSG,3,1,1,4CA2D6,1,,,,,,high", ErrMalformed},
+		{"XYZ,1,2,3,4", ErrMalformed},
+		{"garbage", ErrMalformed},
+	}
+	for _, tt := range tests {
+		if _, err := ParseSBS(tt.line, time.Now()); !errors.Is(err, tt.want) {
+			t.Errorf("ParseSBS(%q) err = %v, want %v", tt.line, err, tt.want)
+		}
+	}
+}
+
+func TestReadSBS(t *testing.T) {
+	var reports []Report
+	if err := ReadSBS(strings.NewReader(sbsRecording+"garbage\n"), func(r Report) {
+		reports = append(reports, r)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("got %d reports, want 4", len(reports))
+	}
+	if reports[0].Callsign != "RYR8AB" {
+		t.Errorf("callsign = %q", reports[0].Callsign)
+	}
+}
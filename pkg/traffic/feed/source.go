@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Format is the encoding of a feed.
+type Format uint8
+
+const (
+	FormatSBS  Format = iota // BaseStation lines (port 30003)
+	FormatJSON               // JSON values, see ParseJSON
+)
+
+// String returns "sbs" or "json".
+func (f Format) String() string {
+	if f == FormatJSON {
+		return "json"
+	}
+	return "sbs"
+}
+
+// Read reads a feed in format from r until it ends, calling fn for every
+// report.
+func Read(r io.Reader, format Format, fn func(Report)) error {
+	switch format {
+	case FormatSBS:
+		return ReadSBS(r, fn)
+	case FormatJSON:
+		return ReadJSON(r, fn)
+	}
+	return fmt.Errorf("feed: unknown format %d", format)
+}
+
+// ReadFile reads a feed file, calling fn for every report.
+func ReadFile(path string, format Format, fn func(Report)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return Read(file, format, fn)
+}
+
+// Dial connects to a feed served over TCP at addr, e.g. "localhost:30003"
+// for dump1090's BaseStation output, and reads it until the connection
+// ends or ctx is done, calling fn for every report. Returns nil when the
+// server closes the connection and ctx.Err() when ctx ends it.
+func Dial(ctx context.Context, addr string, format Format, fn func(Report)) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	err = Read(conn, format, fn)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
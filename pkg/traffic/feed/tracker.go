@@ -0,0 +1,229 @@
+package feed
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+const (
+	// DefaultStaleAfter is how long a target is kept without reports.
+	DefaultStaleAfter = 60 * time.Second
+
+	// DefaultBlend is how long the jump to a new position report is spread
+	// over.
+	DefaultBlend = 2 * time.Second
+
+	// DefaultMaxExtrapolation is how far past its last position report a
+	// target is dead-reckoned.
+	DefaultMaxExtrapolation = 20 * time.Second
+)
+
+// knotsToMetersPerSecond converts ground speed for dead reckoning.
+const knotsToMetersPerSecond = 1852.0 / 3600.0
+
+// Target is the merged state of an aircraft of a feed.
+type Target struct {
+	Hex          string
+	Callsign     string
+	Type         string
+	Latitude     float64 // degrees, at Fixed
+	Longitude    float64 // degrees, at Fixed
+	Altitude     float64 // feet, at AltitudeAt
+	GroundSpeed  float64 // knots
+	Track        float64 // degrees true
+	VerticalRate float64 // feet per minute
+	OnGround     bool
+	Squawk       string
+
+	Positioned bool      // a position was reported
+	Fixed      time.Time // time of the last position report
+	AltitudeAt time.Time // time of the last altitude report
+	Seen       time.Time // time of the last report of any kind
+
+	// corr* is the offset of the previous prediction from the new one at
+	// corrAt, faded out over the blend time.
+	corrLat, corrLon, corrAlt float64
+	corrAt                    time.Time
+}
+
+// Position is where a Target is predicted to be.
+type Position struct {
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Altitude  float64 // feet
+	Heading   float64 // degrees true, the reported track
+	Speed     float64 // knots
+	OnGround  bool
+}
+
+// TrackerOptions tune a Tracker. Zero values use the defaults.
+type TrackerOptions struct {
+	StaleAfter       time.Duration
+	Blend            time.Duration
+	MaxExtrapolation time.Duration
+}
+
+// Tracker merges feed reports into targets and predicts their positions.
+// It is safe for concurrent use.
+type Tracker struct {
+	mu      sync.Mutex
+	opts    TrackerOptions
+	targets map[string]*Target
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker(opts TrackerOptions) *Tracker {
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = DefaultStaleAfter
+	}
+	if opts.Blend <= 0 {
+		opts.Blend = DefaultBlend
+	}
+	if opts.MaxExtrapolation <= 0 {
+		opts.MaxExtrapolation = DefaultMaxExtrapolation
+	}
+	return &Tracker{opts: opts, targets: make(map[string]*Target)}
+}
+
+// Update merges r into its target, creating the target on its first
+// report. The fields r does not carry keep their last value.
+func (t *Tracker) Update(r Report) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.targets[r.Hex]
+	if !ok {
+		tg = &Target{Hex: r.Hex}
+		t.targets[r.Hex] = tg
+	}
+	var prev Position
+	blend := tg.Positioned
+	if blend {
+		prev = t.predictLocked(tg, r.Time)
+	}
+	if r.Has(HasPosition) {
+		tg.Latitude, tg.Longitude = r.Latitude, r.Longitude
+		tg.Fixed, tg.Positioned = r.Time, true
+	}
+	if r.Has(HasAltitude) {
+		tg.Altitude, tg.AltitudeAt = r.Altitude, r.Time
+	}
+	if r.Has(HasCallsign) {
+		tg.Callsign = r.Callsign
+	}
+	if r.Has(HasType) {
+		tg.Type = r.Type
+	}
+	if r.Has(HasGroundSpeed) {
+		tg.GroundSpeed = r.GroundSpeed
+	}
+	if r.Has(HasTrack) {
+		tg.Track = r.Track
+	}
+	if r.Has(HasVerticalRate) {
+		tg.VerticalRate = r.VerticalRate
+	}
+	if r.Has(HasOnGround) {
+		tg.OnGround = r.OnGround
+	}
+	if r.Has(HasSquawk) {
+		tg.Squawk = r.Squawk
+	}
+	tg.Seen = r.Time
+
+	// Spread the jump from the old prediction to the new one over the
+	// blend time.
+	if blend {
+		tg.corrLat, tg.corrLon, tg.corrAlt = 0, 0, 0
+		now := t.predictLocked(tg, r.Time)
+		tg.corrLat = prev.Latitude - now.Latitude
+		tg.corrLon = prev.Longitude - now.Longitude
+		tg.corrAlt = prev.Altitude - now.Altitude
+		tg.corrAt = r.Time
+	}
+}
+
+// Get returns a copy of the target hex.
+func (t *Tracker) Get(hex string) (Target, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.targets[hex]
+	if !ok {
+		return Target{}, false
+	}
+	return *tg, true
+}
+
+// Targets returns copies of all targets, ordered by hex.
+func (t *Tracker) Targets() []Target {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]Target, 0, len(t.targets))
+	for _, tg := range t.targets {
+		out = append(out, *tg)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Hex < out[j].Hex })
+	return out
+}
+
+// Position predicts where target hex is at: dead-reckoned from its last
+// position along its track and vertical rate, for at most
+// MaxExtrapolation, with the jump of the last report blended out. Reports
+// false for an unknown target or one without a position.
+func (t *Tracker) Position(hex string, at time.Time) (Position, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.targets[hex]
+	if !ok || !tg.Positioned {
+		return Position{}, false
+	}
+	return t.predictLocked(tg, at), true
+}
+
+// predictLocked predicts tg at at. Caller must hold t.mu.
+func (t *Tracker) predictLocked(tg *Target, at time.Time) Position {
+	p := Position{
+		Latitude:  tg.Latitude,
+		Longitude: tg.Longitude,
+		Altitude:  tg.Altitude,
+		Heading:   tg.Track,
+		Speed:     tg.GroundSpeed,
+		OnGround:  tg.OnGround,
+	}
+	if dt := t.extrapolation(at.Sub(tg.Fixed)); dt > 0 && tg.GroundSpeed > 0 {
+		p.Latitude, p.Longitude = calc.DisplaceByHeading(p.Latitude, p.Longitude, tg.Track, tg.GroundSpeed*knotsToMetersPerSecond*dt.Seconds())
+	}
+	if dt := t.extrapolation(at.Sub(tg.AltitudeAt)); dt > 0 && !tg.OnGround {
+		p.Altitude += tg.VerticalRate * dt.Minutes()
+	}
+	if since := at.Sub(tg.corrAt); since < t.opts.Blend {
+		w := 1 - float64(max(since, 0))/float64(t.opts.Blend)
+		p.Latitude += tg.corrLat * w
+		p.Longitude += tg.corrLon * w
+		p.Altitude += tg.corrAlt * w
+	}
+	return p
+}
+
+// extrapolation clamps a dead-reckoning interval to [0, MaxExtrapolation].
+func (t *Tracker) extrapolation(d time.Duration) time.Duration {
+	return min(max(d, 0), t.opts.MaxExtrapolation)
+}
+
+// Expire removes and returns the targets without reports for StaleAfter
+// at now.
+func (t *Tracker) Expire(now time.Time) []Target {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var stale []Target
+	for hex, tg := range t.targets {
+		if now.Sub(tg.Seen) > t.opts.StaleAfter {
+			stale = append(stale, *tg)
+			delete(t.targets, hex)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Hex < stale[j].Hex })
+	return stale
+}
@@ -0,0 +1,92 @@
+package feed
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+)
+
+func TestTrackerMerges(t *testing.T) {
+	tr := NewTracker(TrackerOptions{})
+	t0 := time.Unix(1000, 0)
+	tr.Update(Report{Hex: "A1", Fields: HasCallsign, Callsign: "CSA1", Time: t0})
+	tr.Update(Report{Hex: "A1", Fields: HasPosition | HasAltitude, Latitude: 50, Longitude: 14, Altitude: 3000, Time: t0})
+
+	tg, ok := tr.Get("A1")
+	if !ok || tg.Callsign != "CSA1" || !tg.Positioned || tg.Altitude != 3000 {
+		t.Fatalf("target = %+v", tg)
+	}
+	if _, ok := tr.Position("B2", t0); ok {
+		t.Error("Position of unknown target reported ok")
+	}
+}
+
+func TestTrackerDeadReckoning(t *testing.T) {
+	tr := NewTracker(TrackerOptions{MaxExtrapolation: 10 * time.Second})
+	t0 := time.Unix(1000, 0)
+	tr.Update(Report{
+		Hex:          "A1",
+		Fields:       HasPosition | HasAltitude | HasGroundSpeed | HasTrack | HasVerticalRate,
+		Latitude:     0,
+		Longitude:    0,
+		Altitude:     10000,
+		GroundSpeed:  360, // 185.2 m/s
+		Track:        90,
+		VerticalRate: 600,
+		Time:         t0,
+	})
+
+	p, _ := tr.Position("A1", t0.Add(5*time.Second))
+	if d := calc.HaversineMeters(0, 0, p.Latitude, p.Longitude); math.Abs(d-926) > 2 {
+		t.Errorf("distance after 5s = %.1f m, want 926", d)
+	}
+	if math.Abs(p.Altitude-10050) > 0.01 {
+		t.Errorf("altitude after 5s = %.2f, want 10050", p.Altitude)
+	}
+
+	// Prediction stops at MaxExtrapolation.
+	late, _ := tr.Position("A1", t0.Add(time.Minute))
+	limit, _ := tr.Position("A1", t0.Add(10*time.Second))
+	if late != limit {
+		t.Errorf("position after 1m = %+v, want %+v", late, limit)
+	}
+}
+
+func TestTrackerBlendsCorrection(t *testing.T) {
+	tr := NewTracker(TrackerOptions{Blend: 2 * time.Second})
+	t0 := time.Unix(1000, 0)
+	tr.Update(Report{Hex: "A1", Fields: HasPosition | HasAltitude, Altitude: 1000, Time: t0})
+	t1 := t0.Add(time.Second)
+	tr.Update(Report{Hex: "A1", Fields: HasPosition | HasAltitude, Latitude: 0.01, Altitude: 2000, Time: t1})
+
+	at := func(d time.Duration) Position {
+		p, _ := tr.Position("A1", t1.Add(d))
+		return p
+	}
+	if p := at(0); p.Latitude != 0 || p.Altitude != 1000 {
+		t.Errorf("at report = %+v, want previous prediction", p)
+	}
+	if p := at(time.Second); math.Abs(p.Latitude-0.005) > 1e-9 || math.Abs(p.Altitude-1500) > 1e-9 {
+		t.Errorf("halfway = %+v, want halfway between", p)
+	}
+	if p := at(2 * time.Second); p.Latitude != 0.01 || p.Altitude != 2000 {
+		t.Errorf("after blend = %+v, want report", p)
+	}
+}
+
+func TestTrackerExpire(t *testing.T) {
+	tr := NewTracker(TrackerOptions{StaleAfter: 10 * time.Second})
+	t0 := time.Unix(1000, 0)
+	tr.Update(Report{Hex: "A1", Time: t0})
+	tr.Update(Report{Hex: "B2", Time: t0.Add(5 * time.Second)})
+
+	stale := tr.Expire(t0.Add(12 * time.Second))
+	if len(stale) != 1 || stale[0].Hex != "A1" {
+		t.Fatalf("stale = %+v, want A1", stale)
+	}
+	if got := tr.Targets(); len(got) != 1 || got[0].Hex != "B2" {
+		t.Errorf("targets = %+v, want B2", got)
+	}
+}
@@ -4,13 +4,20 @@
 package traffic
 
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/mrlm-net/simconnect/pkg/engine"
 	"github.com/mrlm-net/simconnect/pkg/types"
 )
 
+// DefaultPendingTimeout is how long a Fleet waits for the ObjectID of a
+// creation before dropping it with ErrSpawnTimeout.
+const DefaultPendingTimeout = 30 * time.Second
+
 // Fleet manages a thread-safe collection of AI aircraft bound to a single
 // engine client. It tracks both pending creations (awaiting ObjectID assignment
 // from SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID) and active aircraft.
@@ -21,36 +28,93 @@ import (
 //   - Call Request* to spawn aircraft — each issues the SimConnect creation call
 //     and records a Pending entry keyed by reqID.
 //   - On receiving SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID, call Acknowledge to
-//     promote the Pending entry to a full Aircraft tracked by ObjectID.
+//     promote the Pending entry to a full Aircraft tracked by ObjectID. On a
+//     SIMCONNECT_RECV_EXCEPTION, call FailSend to drop the creation it
+//     rejected. Entries still pending after the pending timeout are dropped
+//     with ErrSpawnTimeout.
+//   - On the ObjectRemoved system event, call Removed so aircraft deleted by
+//     the simulator leave the fleet.
 //   - On disconnect / reconnect, call SetClient(newClient) which also clears all
 //     stale pending and member state (ObjectIDs are invalid after a disconnect).
+//
+// The manager does all of this itself for its Fleet(). Every change is
+// published to subscriptions created with Subscribe.
 type Fleet struct {
 	mu      sync.RWMutex
+	sendMu  sync.Mutex           // serializes creation calls with the reads of their packet IDs
 	pending map[uint32]*Pending  // reqID → pending creation
 	members map[uint32]*Aircraft // objectID → active aircraft
 	client  engine.Client
+	timeout time.Duration
+	subs    map[*FleetSubscription]struct{}
+	// discarded is the fleet as it was before the last reset.
+	discarded Snapshot
+	track     tracking
 }
 
-// NewFleet constructs a Fleet bound to the given engine client.
-// Pass nil to create an unconnected fleet and call SetClient later.
+// NewFleet constructs a Fleet bound to the given engine client, with the
+// DefaultPendingTimeout. Pass nil to create an unconnected fleet and call
+// SetClient later.
 func NewFleet(client engine.Client) *Fleet {
 	return &Fleet{
 		pending: make(map[uint32]*Pending),
 		members: make(map[uint32]*Aircraft),
 		client:  client,
+		timeout: DefaultPendingTimeout,
+		subs:    make(map[*FleetSubscription]struct{}),
 	}
 }
 
+// SetPendingTimeout sets how long creations made from now on may wait for
+// their ObjectID. Zero or less disables the timeout.
+func (f *Fleet) SetPendingTimeout(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.timeout = d
+}
+
 // SetClient updates the engine client reference and clears all pending and
 // member state. Call this on connect (passing the new client) and on disconnect
 // (passing nil). ObjectIDs are invalidated across reconnects so the fleet must
-// be reset each time.
+// be reset each time. Pending creations are failed with ErrNotConnected and
+// members are reported as removed. While Track is active, its dataset is
+// registered again on the new client.
 func (f *Fleet) SetClient(client engine.Client) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
 	f.client = client
+	events := f.resetLocked()
+	if client != nil && f.track.dataset != nil {
+		_ = f.startTrackingLocked()
+	}
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+
+	publish(subs, events...)
+}
+
+// resetLocked empties the fleet, keeping a snapshot of it for Discarded,
+// and returns the events describing it.
+// Caller must hold f.mu.
+func (f *Fleet) resetLocked() []FleetEvent {
+	if len(f.pending)+len(f.members) > 0 {
+		f.discarded = f.snapshotLocked()
+	}
+	events := make([]FleetEvent, 0, len(f.pending)+len(f.members))
+	for _, p := range f.pending {
+		if p.timer != nil {
+			p.timer.Stop()
+		}
+		events = append(events, FleetEvent{Kind: FleetSpawnFailed, Pending: *p, Err: ErrNotConnected})
+	}
+	for _, a := range f.members {
+		events = append(events, FleetEvent{Kind: FleetRemoved, Aircraft: a})
+	}
 	f.pending = make(map[uint32]*Pending)
 	f.members = make(map[uint32]*Aircraft)
+	// Telemetry requests end with the connection; there is nothing to cancel.
+	f.track.requests = nil
+	f.track.objects = nil
+	return events
 }
 
 // ── Creation requests (async) ─────────────────────────────────────────────
@@ -60,49 +124,28 @@ func (f *Fleet) SetClient(client engine.Client) {
 // SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID handler to complete the handle.
 // Returns ErrNotConnected if the fleet has no active client.
 func (f *Fleet) RequestParked(opts ParkedOpts, reqID uint32) error {
-	f.mu.RLock()
-	c := f.client
-	f.mu.RUnlock()
-	if c == nil {
-		return ErrNotConnected
-	}
-	var err error
-	if opts.Livery != "" {
-		err = c.AICreateParkedATCAircraftEX1(opts.Model, opts.Livery, opts.Tail, opts.Airport, reqID)
-	} else {
-		err = c.AICreateParkedATCAircraft(opts.Model, opts.Tail, opts.Airport, reqID)
-	}
-	if err != nil {
-		return err
-	}
-	f.mu.Lock()
-	f.pending[reqID] = &Pending{ReqID: reqID, Kind: KindParked, Model: opts.Model, Livery: opts.Livery, Tail: opts.Tail}
-	f.mu.Unlock()
-	return nil
+	return f.request(AircraftSnapshot{
+		Kind:    KindParked,
+		Model:   opts.Model,
+		Livery:  opts.Livery,
+		Tail:    opts.Tail,
+		Airport: opts.Airport,
+	}, reqID, false)
 }
 
 // RequestEnroute queues an enroute ATC aircraft creation along a flight plan.
 // Returns ErrNotConnected if the fleet has no active client.
 func (f *Fleet) RequestEnroute(opts EnrouteOpts, reqID uint32) error {
-	f.mu.RLock()
-	c := f.client
-	f.mu.RUnlock()
-	if c == nil {
-		return ErrNotConnected
-	}
-	var err error
-	if opts.Livery != "" {
-		err = c.AICreateEnrouteATCAircraftEX1(opts.Model, opts.Livery, opts.Tail, opts.FlightNumber, opts.FlightPlan, opts.Phase, opts.TouchAndGo, reqID)
-	} else {
-		err = c.AICreateEnrouteATCAircraft(opts.Model, opts.Tail, opts.FlightNumber, opts.FlightPlan, opts.Phase, opts.TouchAndGo, reqID)
-	}
-	if err != nil {
-		return err
-	}
-	f.mu.Lock()
-	f.pending[reqID] = &Pending{ReqID: reqID, Kind: KindEnroute, Model: opts.Model, Livery: opts.Livery, Tail: opts.Tail}
-	f.mu.Unlock()
-	return nil
+	return f.request(AircraftSnapshot{
+		Kind:         KindEnroute,
+		Model:        opts.Model,
+		Livery:       opts.Livery,
+		Tail:         opts.Tail,
+		FlightNumber: opts.FlightNumber,
+		FlightPlan:   opts.FlightPlan,
+		Phase:        opts.Phase,
+		TouchAndGo:   opts.TouchAndGo,
+	}, reqID, false)
 }
 
 // RequestNonATC queues a non-ATC aircraft creation at an explicit position.
@@ -110,56 +153,290 @@ func (f *Fleet) RequestEnroute(opts EnrouteOpts, reqID uint32) error {
 // After Acknowledge, call ReleaseControl then SetWaypoints to begin movement.
 // Returns ErrNotConnected if the fleet has no active client.
 func (f *Fleet) RequestNonATC(opts NonATCOpts, reqID uint32) error {
-	f.mu.RLock()
+	pos := opts.Position
+	return f.request(AircraftSnapshot{
+		Kind:     KindNonATC,
+		Model:    opts.Model,
+		Livery:   opts.Livery,
+		Tail:     opts.Tail,
+		Position: &pos,
+	}, reqID, false)
+}
+
+// request records a pending creation of spec and issues its creation call.
+// With restore, the waypoint chain or flight plan of spec is applied again
+// on Acknowledge.
+//
+// The entry is recorded before the call so an ObjectID assigned before the
+// call returns still finds it, and is discarded again if the call fails.
+// Its SendID is only set when send is sure of it, so FailSend never fails
+// the creation for an exception meant for another request; without it,
+// only the timeout ends an unanswered creation.
+func (f *Fleet) request(spec AircraftSnapshot, reqID uint32, restore bool) error {
+	p := &Pending{
+		ReqID:   reqID,
+		Kind:    spec.Kind,
+		Model:   spec.Model,
+		Livery:  spec.Livery,
+		Tail:    spec.Tail,
+		spec:    spec,
+		restore: restore,
+	}
+	f.mu.Lock()
 	c := f.client
-	f.mu.RUnlock()
 	if c == nil {
+		f.mu.Unlock()
 		return ErrNotConnected
 	}
-	var err error
-	if opts.Livery != "" {
-		err = c.AICreateNonATCAircraftEX1(opts.Model, opts.Livery, opts.Tail, opts.Position, reqID)
-	} else {
-		err = c.AICreateNonATCAircraft(opts.Model, opts.Tail, opts.Position, reqID)
+	if old, ok := f.pending[p.ReqID]; ok && old.timer != nil {
+		old.timer.Stop()
 	}
+	p.Requested = time.Now()
+	f.pending[p.ReqID] = p
+	f.mu.Unlock()
+
+	sendID, err := f.send(c, spec, reqID)
 	if err != nil {
+		f.mu.Lock()
+		if f.pending[p.ReqID] == p {
+			delete(f.pending, p.ReqID)
+		}
+		f.mu.Unlock()
 		return err
 	}
+
 	f.mu.Lock()
-	f.pending[reqID] = &Pending{ReqID: reqID, Kind: KindNonATC, Model: opts.Model, Livery: opts.Livery, Tail: opts.Tail}
-	f.mu.Unlock()
+	defer f.mu.Unlock()
+	if f.pending[p.ReqID] != p {
+		return nil // already resolved
+	}
+	p.SendID = sendID
+	if f.timeout > 0 {
+		p.timer = time.AfterFunc(f.timeout, func() { f.expire(p) })
+	}
 	return nil
 }
 
+// send issues the creation call for spec and returns its packet ID, or 0
+// if the ID is uncertain. Creations from f are serialized by sendMu. Other
+// users of the client do not take it, so the packet ID is read before and
+// after the call; it belongs to the creation only when exactly one packet
+// was sent in between.
+func (f *Fleet) send(c engine.Client, spec AircraftSnapshot, reqID uint32) (uint32, error) {
+	f.sendMu.Lock()
+	defer f.sendMu.Unlock()
+	before, beforeErr := c.GetLastSentPacketID()
+	if err := spawn(c, spec, reqID); err != nil {
+		return 0, err
+	}
+	after, err := c.GetLastSentPacketID()
+	if beforeErr != nil || err != nil || after != before+1 {
+		return 0, nil
+	}
+	return after, nil
+}
+
+// spawn issues the SimConnect creation call for spec, using the EX1 variant
+// when a livery is set.
+func spawn(c engine.Client, spec AircraftSnapshot, reqID uint32) error {
+	switch spec.Kind {
+	case KindParked:
+		if spec.Livery != "" {
+			return c.AICreateParkedATCAircraftEX1(spec.Model, spec.Livery, spec.Tail, spec.Airport, reqID)
+		}
+		return c.AICreateParkedATCAircraft(spec.Model, spec.Tail, spec.Airport, reqID)
+	case KindEnroute:
+		if spec.Livery != "" {
+			return c.AICreateEnrouteATCAircraftEX1(spec.Model, spec.Livery, spec.Tail, spec.FlightNumber, spec.FlightPlan, spec.Phase, spec.TouchAndGo, reqID)
+		}
+		return c.AICreateEnrouteATCAircraft(spec.Model, spec.Tail, spec.FlightNumber, spec.FlightPlan, spec.Phase, spec.TouchAndGo, reqID)
+	case KindNonATC:
+		if spec.Position == nil {
+			return fmt.Errorf("%w: non-ATC aircraft %q has no position", ErrCreationFailed, spec.Tail)
+		}
+		if spec.Livery != "" {
+			return c.AICreateNonATCAircraftEX1(spec.Model, spec.Livery, spec.Tail, *spec.Position, reqID)
+		}
+		return c.AICreateNonATCAircraft(spec.Model, spec.Tail, *spec.Position, reqID)
+	}
+	return fmt.Errorf("%w: unknown aircraft kind %d", ErrCreationFailed, spec.Kind)
+}
+
 // ── Acknowledge ────────────────────────────────────────────────────────────
 
-// Acknowledge resolves a pending creation with the ObjectID returned by SimConnect.
-// Call this from your SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID handler.
+// Acknowledge resolves a pending creation with the ObjectID returned by SimConnect
+// and publishes a FleetSpawned event. Call this from your
+// SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID handler; the manager calls it for its
+// own fleet.
 //
 // Returns the created Aircraft and true if reqID was a known pending request.
-// Returns nil and false when the reqID is unrecognised (belongs to another subsystem).
+// Returns nil and false when the reqID is unrecognised (belongs to another
+// subsystem, or was already acknowledged) or was withdrawn with Cancel.
 func (f *Fleet) Acknowledge(reqID uint32, objectID uint32) (*Aircraft, bool) {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	p, ok := f.pending[reqID]
+	p, ok := f.takePendingLocked(reqID)
 	if !ok {
+		f.mu.Unlock()
+		return nil, false
+	}
+	if p.cancel {
+		c := f.client
+		subs := f.subscribersLocked()
+		f.mu.Unlock()
+		err := ErrSpawnCancelled
+		if c != nil {
+			if rmErr := c.AIRemoveObject(objectID, p.removal); rmErr != nil {
+				err = errors.Join(err, fmt.Errorf("removing %d: %w", objectID, rmErr))
+			}
+		}
+		publish(subs, FleetEvent{Kind: FleetSpawnFailed, Pending: *p, Err: err})
 		return nil, false
 	}
-	delete(f.pending, reqID)
 	a := &Aircraft{
 		ObjectID: objectID,
+		ReqID:    reqID,
 		Kind:     p.Kind,
 		Model:    p.Model,
 		Livery:   p.Livery,
 		Tail:     p.Tail,
+		spec:     p.spec,
 	}
 	f.members[objectID] = a
+	var trackErr error
+	if f.track.dataset != nil && f.client != nil {
+		trackErr = f.trackLocked(objectID)
+	}
+	f.mu.Unlock()
+
+	var err error
+	if p.restore {
+		err = f.reapply(a, p.spec, reqID)
+	}
+	err = errors.Join(err, trackErr)
+	f.mu.RLock()
+	subs := f.subscribersLocked()
+	f.mu.RUnlock()
+	publish(subs, FleetEvent{Kind: FleetSpawned, Aircraft: a, Pending: *p, Err: err})
+	return a, true
+}
+
+// Cancel withdraws the pending creation reqID. The aircraft never joins the
+// fleet: once its ObjectID is assigned it is removed with the request ID
+// removeReqID, and a FleetSpawnFailed event carrying ErrSpawnCancelled is
+// published. Reports whether reqID was pending.
+func (f *Fleet) Cancel(reqID, removeReqID uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.pending[reqID]
+	if ok {
+		p.cancel, p.removal = true, removeReqID
+	}
+	return ok
+}
+
+// Fail drops the pending creation reqID and publishes a FleetSpawnFailed
+// event carrying err. Reports whether reqID was pending.
+func (f *Fleet) Fail(reqID uint32, err error) bool {
+	f.mu.Lock()
+	p, ok := f.takePendingLocked(reqID)
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+	if !ok {
+		return false
+	}
+	publish(subs, FleetEvent{Kind: FleetSpawnFailed, Pending: *p, Err: err})
+	return true
+}
+
+// FailSend drops the pending creation whose call was sent as packet sendID,
+// failing it with a *CreationError. Call this from your
+// SIMCONNECT_RECV_EXCEPTION handler with DwSendID and DwException; the
+// manager calls it for its own fleet. Reports whether a creation matched.
+func (f *Fleet) FailSend(sendID uint32, exception types.SIMCONNECT_EXCEPTION) bool {
+	if sendID == 0 {
+		return false
+	}
+	f.mu.RLock()
+	reqID, found := uint32(0), false
+	for id, p := range f.pending {
+		if p.SendID == sendID {
+			reqID, found = id, true
+			break
+		}
+	}
+	f.mu.RUnlock()
+	if !found {
+		return false
+	}
+	return f.Fail(reqID, &CreationError{ReqID: reqID, SendID: sendID, Exception: exception})
+}
+
+// expire fails p with ErrSpawnTimeout if it is still pending.
+func (f *Fleet) expire(p *Pending) {
+	f.mu.Lock()
+	if f.pending[p.ReqID] != p {
+		f.mu.Unlock()
+		return
+	}
+	delete(f.pending, p.ReqID)
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+
+	err := fmt.Errorf("%w: request %d after %s", ErrSpawnTimeout, p.ReqID, time.Since(p.Requested).Round(time.Millisecond))
+	publish(subs, FleetEvent{Kind: FleetSpawnFailed, Pending: *p, Err: err})
+}
+
+// takePendingLocked removes and returns the pending creation reqID,
+// stopping its timeout. Caller must hold f.mu.
+func (f *Fleet) takePendingLocked(reqID uint32) (*Pending, bool) {
+	p, ok := f.pending[reqID]
+	if !ok {
+		return nil, false
+	}
+	delete(f.pending, reqID)
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	return p, true
+}
+
+// Removed drops objectID from the fleet without a removal request and
+// publishes a FleetRemoved event. Call this from your ObjectRemoved handler
+// so aircraft the simulator deleted leave the fleet; the manager calls it
+// for its own fleet. Returns the aircraft and true if it was a member.
+func (f *Fleet) Removed(objectID uint32) (*Aircraft, bool) {
+	f.mu.Lock()
+	a, ok := f.members[objectID]
+	delete(f.members, objectID)
+	f.untrackLocked(objectID)
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	publish(subs, FleetEvent{Kind: FleetRemoved, Aircraft: a})
 	return a, true
 }
 
+// IsPending reports whether the creation reqID still awaits an ObjectID.
+func (f *Fleet) IsPending(reqID uint32) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.pending[reqID]
+	return ok
+}
+
+// PendingCount returns the number of creations awaiting an ObjectID.
+func (f *Fleet) PendingCount() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.pending)
+}
+
 // ── Aircraft operations ────────────────────────────────────────────────────
 
-// Remove removes an AI aircraft from the simulation and from the fleet.
+// Remove removes an AI aircraft from the simulation and from the fleet,
+// publishing a FleetRemoved event.
 // Returns ErrNotConnected if the fleet has no active client.
 func (f *Fleet) Remove(objectID uint32, reqID uint32) error {
 	f.mu.RLock()
@@ -171,9 +448,7 @@ func (f *Fleet) Remove(objectID uint32, reqID uint32) error {
 	if err := c.AIRemoveObject(objectID, reqID); err != nil {
 		return err
 	}
-	f.mu.Lock()
-	delete(f.members, objectID)
-	f.mu.Unlock()
+	f.Removed(objectID)
 	return nil
 }
 
@@ -199,7 +474,7 @@ func (f *Fleet) ReleaseControl(objectID uint32, reqID uint32) error {
 // and TakeoffClimb.
 //
 // Call ReleaseControl before SetWaypoints — the simulator will otherwise ignore
-// the waypoints.
+// the waypoints. The chain is kept for Snapshot.
 // Returns ErrNotConnected if the fleet has no active client.
 // Returns ErrEmptyWaypoints if wps is nil or empty.
 func (f *Fleet) SetWaypoints(objectID uint32, defID uint32, wps []types.SIMCONNECT_DATA_WAYPOINT) error {
@@ -213,17 +488,40 @@ func (f *Fleet) SetWaypoints(objectID uint32, defID uint32, wps []types.SIMCONNE
 		return ErrNotConnected
 	}
 	packed := engine.PackWaypoints(wps)
-	return c.SetDataOnSimObject(
+	if err := c.SetDataOnSimObject(
 		defID,
 		objectID,
 		types.SIMCONNECT_DATA_SET_FLAG_DEFAULT,
 		uint32(len(wps)),
 		engine.WaypointWireSize,
 		unsafe.Pointer(&packed[0]),
-	)
+	); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	if a, ok := f.members[objectID]; ok {
+		a.spec.Waypoints = append([]types.SIMCONNECT_DATA_WAYPOINT(nil), wps...)
+		a.spec.WaypointDefID = defID
+	}
+	f.mu.Unlock()
+	return nil
+}
+
+// Waypoints returns the chain last set with SetWaypoints on objectID and
+// its data definition. Returns ok false if the aircraft is not in the
+// fleet or has no chain.
+func (f *Fleet) Waypoints(objectID uint32) (wps []types.SIMCONNECT_DATA_WAYPOINT, defID uint32, ok bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	a, found := f.members[objectID]
+	if !found || len(a.spec.Waypoints) == 0 {
+		return nil, 0, false
+	}
+	return append([]types.SIMCONNECT_DATA_WAYPOINT(nil), a.spec.Waypoints...), a.spec.WaypointDefID, true
 }
 
 // SetFlightPlan assigns a flight plan to an ATC aircraft (parked or enroute).
+// The plan is kept for Snapshot.
 // Returns ErrNotConnected if the fleet has no active client.
 func (f *Fleet) SetFlightPlan(objectID uint32, planPath string, reqID uint32) error {
 	f.mu.RLock()
@@ -232,7 +530,15 @@ func (f *Fleet) SetFlightPlan(objectID uint32, planPath string, reqID uint32) er
 	if c == nil {
 		return ErrNotConnected
 	}
-	return c.AISetAircraftFlightPlan(objectID, planPath, reqID)
+	if err := c.AISetAircraftFlightPlan(objectID, planPath, reqID); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	if a, ok := f.members[objectID]; ok {
+		a.spec.FlightPlan = planPath
+	}
+	f.mu.Unlock()
+	return nil
 }
 
 // ── Collection ─────────────────────────────────────────────────────────────
@@ -264,7 +570,8 @@ func (f *Fleet) Len() int {
 	return len(f.members)
 }
 
-// RemoveAll removes all tracked aircraft from the simulation.
+// RemoveAll removes all tracked aircraft from the simulation, publishing a
+// FleetRemoved event for each.
 // reqIDBase is used as the base request ID; each aircraft is assigned
 // reqIDBase + i for i in [0, len). Returns the last non-nil error encountered.
 func (f *Fleet) RemoveAll(reqIDBase uint32) error {
@@ -285,16 +592,27 @@ func (f *Fleet) RemoveAll(reqIDBase uint32) error {
 		}
 	}
 	f.mu.Lock()
+	events := make([]FleetEvent, 0, len(f.members))
+	for _, a := range f.members {
+		events = append(events, FleetEvent{Kind: FleetRemoved, Aircraft: a})
+	}
+	f.untrackAllLocked()
 	f.members = make(map[uint32]*Aircraft)
+	subs := f.subscribersLocked()
 	f.mu.Unlock()
+
+	publish(subs, events...)
 	return last
 }
 
 // Clear resets the fleet without issuing removal requests to the simulator.
-// Use this after a disconnect when all ObjectIDs are already stale.
+// Use this after a disconnect when all ObjectIDs are already stale. Events
+// are published as for SetClient.
 func (f *Fleet) Clear() {
 	f.mu.Lock()
-	defer f.mu.Unlock()
-	f.pending = make(map[uint32]*Pending)
-	f.members = make(map[uint32]*Aircraft)
+	events := f.resetLocked()
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+
+	publish(subs, events...)
 }
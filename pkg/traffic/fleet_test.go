@@ -0,0 +1,272 @@
+//go:build windows
+
+package traffic
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// fakeClient numbers every packet sent on it, like SimConnect does for a
+// connection, and records the AI calls made on it.
+type fakeClient struct {
+	engine.Client
+	mu        sync.Mutex
+	sendID    uint32
+	created   map[uint32]uint32                             // reqID → packet ID of its creation call
+	tails     map[uint32]string                             // reqID → tail number created
+	positions map[uint32]types.SIMCONNECT_DATA_INITPOSITION // reqID → non-ATC creation position
+	plans     map[uint32]string                             // objectID → flight plan path
+	released  map[uint32]bool                               // objectID → control released
+	waypoints map[uint32][2]uint32                          // objectID → definition ID and waypoint count
+	removed   map[uint32]uint32                             // objectID → removal request ID
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		created:   make(map[uint32]uint32),
+		tails:     make(map[uint32]string),
+		positions: make(map[uint32]types.SIMCONNECT_DATA_INITPOSITION),
+		plans:     make(map[uint32]string),
+		released:  make(map[uint32]bool),
+		waypoints: make(map[uint32][2]uint32),
+		removed:   make(map[uint32]uint32),
+	}
+}
+
+// send records a packet sent by another user of the client.
+func (c *fakeClient) send() uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	return c.sendID
+}
+
+// create records the creation call of reqID. Caller must hold c.mu.
+func (c *fakeClient) create(tail string, reqID uint32) {
+	c.sendID++
+	c.created[reqID] = c.sendID
+	c.tails[reqID] = tail
+}
+
+func (c *fakeClient) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.create(szTailNumber, RequestID)
+	return nil
+}
+
+func (c *fakeClient) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, initPos types.SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.create(szTailNumber, RequestID)
+	c.positions[RequestID] = initPos
+	return nil
+}
+
+func (c *fakeClient) AISetAircraftFlightPlan(objectID uint32, szFlightPlanPath string, requestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.plans[objectID] = szFlightPlanPath
+	return nil
+}
+
+func (c *fakeClient) AIReleaseControl(objectID uint32, requestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.released[objectID] = true
+	return nil
+}
+
+func (c *fakeClient) AIRemoveObject(objectID uint32, requestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.removed[objectID] = requestID
+	return nil
+}
+
+func (c *fakeClient) SetDataOnSimObject(definitionID uint32, objectID uint32, flags types.SIMCONNECT_DATA_SET_FLAG, arrayCount uint32, cbUnitSize uint32, data unsafe.Pointer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.waypoints[objectID] = [2]uint32{definitionID, arrayCount}
+	return nil
+}
+
+func (c *fakeClient) GetLastSentPacketID() (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendID, nil
+}
+
+// requestParked issues n parked creations with request IDs from base on
+// each of workers goroutines.
+func requestParked(t *testing.T, f *Fleet, workers, n int, base uint32) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				reqID := base + uint32(w*n+i)
+				if err := f.RequestParked(ParkedOpts{Model: "A320", Airport: "LKPR"}, reqID); err != nil {
+					t.Error(err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestFleetConcurrentSendIDs(t *testing.T) {
+	c := newFakeClient()
+	f := NewFleet(c)
+	f.SetPendingTimeout(0)
+
+	// Creations from the fleet alone always learn their packet ID.
+	requestParked(t, f, 8, 50, 1000)
+	for reqID, p := range f.pending {
+		if p.SendID == 0 || p.SendID != c.created[reqID] {
+			t.Fatalf("request %d: SendID = %d, want %d", reqID, p.SendID, c.created[reqID])
+		}
+	}
+
+	// Packets sent by someone else at the same time must never be taken
+	// for a creation's.
+	var foreign []uint32
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 2000; i++ {
+			foreign = append(foreign, c.send())
+		}
+	}()
+	requestParked(t, f, 8, 50, 2000)
+	<-done
+
+	for reqID, p := range f.pending {
+		if p.SendID != 0 && p.SendID != c.created[reqID] {
+			t.Fatalf("request %d: SendID = %d, created as packet %d", reqID, p.SendID, c.created[reqID])
+		}
+	}
+	for _, sendID := range foreign {
+		if f.FailSend(sendID, 0) {
+			t.Fatalf("exception for foreign packet %d failed a creation", sendID)
+		}
+	}
+	if got := f.PendingCount(); got != 800 {
+		t.Errorf("PendingCount = %d, want 800", got)
+	}
+}
+
+func TestFleetSubscribeNeverDrops(t *testing.T) {
+	f := NewFleet(newFakeClient())
+	f.SetPendingTimeout(0)
+	sub := f.Subscribe(1)
+	defer sub.Unsubscribe()
+
+	// Nobody reads while far more aircraft join than the channel holds.
+	const n = 500
+	requestParked(t, f, 1, n, 1000)
+	for i := uint32(0); i < n; i++ {
+		if _, ok := f.Acknowledge(1000+i, 1+i); !ok {
+			t.Fatalf("request %d not acknowledged", 1000+i)
+		}
+	}
+
+	for i := uint32(0); i < n; i++ {
+		select {
+		case ev := <-sub.Events():
+			if ev.Kind != FleetSpawned || ev.Aircraft.ObjectID != 1+i {
+				t.Fatalf("event %d = %v of object %d, want spawned of %d", i, ev.Kind, ev.Aircraft.ObjectID, 1+i)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("received %d events, want %d", i, n)
+		}
+	}
+}
+
+func TestFleetSubscribeUpdatesOnly(t *testing.T) {
+	f := NewFleet(newFakeClient())
+	f.SetPendingTimeout(0)
+	sub := f.Subscribe(4)
+	defer sub.Unsubscribe()
+	updates := f.SubscribeUpdates(4)
+	defer updates.Unsubscribe()
+
+	f.mu.Lock()
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+	publish(subs,
+		FleetEvent{Kind: FleetSpawned, Aircraft: &Aircraft{ObjectID: 1}},
+		FleetEvent{Kind: FleetUpdated, Aircraft: &Aircraft{ObjectID: 1}},
+		FleetEvent{Kind: FleetRemoved, Aircraft: &Aircraft{ObjectID: 1}},
+	)
+
+	for _, want := range []FleetEventKind{FleetSpawned, FleetRemoved} {
+		select {
+		case ev := <-sub.Events():
+			if ev.Kind != want {
+				t.Fatalf("Subscribe delivered %v, want %v", ev.Kind, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Subscribe: timed out waiting for %v", want)
+		}
+	}
+	if ev := <-updates.Events(); ev.Kind != FleetUpdated {
+		t.Fatalf("SubscribeUpdates delivered %v, want updated", ev.Kind)
+	}
+	select {
+	case ev := <-updates.Events():
+		t.Fatalf("SubscribeUpdates delivered %v after the update", ev.Kind)
+	default:
+	}
+}
+
+func TestFleetCancel(t *testing.T) {
+	c := newFakeClient()
+	f := NewFleet(c)
+	f.SetPendingTimeout(0)
+	sub := f.Subscribe(4)
+	defer sub.Unsubscribe()
+
+	requestParked(t, f, 1, 1, 100)
+	if !f.Cancel(100, 900) {
+		t.Fatal("Cancel of a pending creation = false")
+	}
+	if f.Cancel(101, 901) {
+		t.Fatal("Cancel of an unknown request = true")
+	}
+	if len(f.Snapshot().Aircraft) != 0 {
+		t.Error("cancelled creation still in the snapshot")
+	}
+
+	// The aircraft is removed as soon as the simulator creates it.
+	if a, ok := f.Acknowledge(100, 7); ok || a != nil {
+		t.Fatalf("Acknowledge of a cancelled creation = %v, %v", a, ok)
+	}
+	if f.Len() != 0 || f.PendingCount() != 0 {
+		t.Fatalf("Len = %d, PendingCount = %d; want an empty fleet", f.Len(), f.PendingCount())
+	}
+	if reqID, ok := c.removed[7]; !ok || reqID != 900 {
+		t.Fatalf("removal of 7 = %d, %v; want request 900", reqID, ok)
+	}
+	select {
+	case ev := <-sub.Events():
+		if ev.Kind != FleetSpawnFailed || !errors.Is(ev.Err, ErrSpawnCancelled) {
+			t.Fatalf("event = %v %v, want spawn failed with ErrSpawnCancelled", ev.Kind, ev.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the spawn failed event")
+	}
+}
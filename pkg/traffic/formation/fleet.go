@@ -0,0 +1,331 @@
+//go:build windows
+// +build windows
+
+package formation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// extension is how far, in time at the commanded speed, the second
+// waypoint of a chain lies beyond the target, so the AI does not turn
+// when it reaches the target before the next chain.
+const extension = time.Minute
+
+// maxStep is the longest time a position write advances a wingman, so a
+// stalled loop does not make it jump.
+const maxStep = time.Second
+
+// Mode is how a FleetFormation moves its wingmen.
+type Mode uint8
+
+const (
+	// ModeWaypoints releases each wingman to the AI and sends it a
+	// waypoint chain towards its target on every Tick.
+	ModeWaypoints Mode = iota
+	// ModeSlew keeps each wingman under SimConnect control and writes its
+	// position, moved with Follow, on every Tick.
+	ModeSlew
+)
+
+// String returns "waypoints" or "slew".
+func (m Mode) String() string {
+	if m == ModeSlew {
+		return "slew"
+	}
+	return "waypoints"
+}
+
+// Client reads the leader and writes wingman positions; the manager has
+// all of its methods.
+type Client interface {
+	RegisterDataset(definitionID uint32, dataset *datasets.DataSet) error
+	RequestDataOnSimObject(requestID uint32, definitionID uint32, objectID uint32, period types.SIMCONNECT_PERIOD, flags types.SIMCONNECT_DATA_REQUEST_FLAG, origin uint32, interval uint32, limit uint32) error
+	SetDataOnSimObject(definitionID uint32, objectID uint32, flags types.SIMCONNECT_DATA_SET_FLAG, arrayCount uint32, cbUnitSize uint32, data unsafe.Pointer) error
+}
+
+// Config configures a FleetFormation.
+type Config struct {
+	Options Options
+	Mode    Mode
+
+	// Leader is the object ID of the leader: types.SIMCONNECT_OBJECT_ID_USER
+	// for the user aircraft, or any other aircraft such as a fleet member.
+	Leader uint32
+
+	// LeaderRequestID and LeaderDefinitionID read the leader's state every
+	// visual frame. The definition is registered by Start and must not be
+	// in use.
+	LeaderRequestID    uint32
+	LeaderDefinitionID uint32
+
+	// WaypointDefID is a definition registered for "AI Waypoint List"
+	// (ModeWaypoints).
+	WaypointDefID uint32
+
+	// SlewDefID is the definition Start registers for position writes
+	// (ModeSlew). It must not be in use.
+	SlewDefID uint32
+
+	// ReqIDMin and ReqIDMax bound the request IDs of releases, taken in
+	// turn.
+	ReqIDMin, ReqIDMax uint32
+}
+
+// leaderData is the layout of the leader data.
+type leaderData struct {
+	Latitude    float64
+	Longitude   float64
+	Altitude    float64
+	Heading     float64
+	GroundSpeed float64
+}
+
+// positionData is the layout of ModeSlew position writes.
+type positionData struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Heading   float64
+}
+
+// positionDataset returns the definition of positionData, followed by
+// ground speed for leaderData.
+func positionDataset(speed bool) datasets.DataSet {
+	b := datasets.NewBuilder().
+		AddField("PLANE LATITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE LONGITUDE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE ALTITUDE", "feet", types.SIMCONNECT_DATATYPE_FLOAT64, 0).
+		AddField("PLANE HEADING DEGREES TRUE", "degrees", types.SIMCONNECT_DATATYPE_FLOAT64, 0)
+	if speed {
+		b.AddField("GROUND VELOCITY", "knots", types.SIMCONNECT_DATATYPE_FLOAT64, 0)
+	}
+	return b.Build()
+}
+
+// FleetFormation flies a Formation with members of a traffic.Fleet as
+// wingmen. Wingman positions come from Aircraft.State, so the fleet must be
+// tracking (Fleet.Track). The leader is read every visual frame; route the
+// SIMCONNECT_RECV_SIMOBJECT_DATA messages to HandleData.
+type FleetFormation struct {
+	form   *Formation
+	fleet  *traffic.Fleet
+	client Client
+	cfg    Config
+
+	mu       sync.Mutex
+	leader   uint32
+	released map[uint32]bool     // wingmen released to the AI
+	slewed   map[uint32]Aircraft // last written wingman positions
+	lastTick time.Time
+	reqIDs   *traffic.RequestIDs
+}
+
+// NewFleetFormation returns a formation flying wingmen of fleet behind
+// cfg.Leader. Call Start once connected.
+func NewFleetFormation(fleet *traffic.Fleet, client Client, cfg Config) *FleetFormation {
+	return &FleetFormation{
+		form:     New(cfg.Options),
+		fleet:    fleet,
+		client:   client,
+		cfg:      cfg,
+		leader:   cfg.Leader,
+		released: make(map[uint32]bool),
+		slewed:   make(map[uint32]Aircraft),
+		reqIDs:   traffic.NewRequestIDs(cfg.ReqIDMin, cfg.ReqIDMax),
+	}
+}
+
+// Formation returns the formation, e.g. to read phases and slots.
+func (ff *FleetFormation) Formation() *Formation { return ff.form }
+
+// Start registers the definitions and requests the leader's state every
+// visual frame. Call it again after reconnecting.
+func (ff *FleetFormation) Start() error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	ds := positionDataset(true)
+	if err := ff.client.RegisterDataset(ff.cfg.LeaderDefinitionID, &ds); err != nil {
+		return fmt.Errorf("registering leader data: %w", err)
+	}
+	if ff.cfg.Mode == ModeSlew {
+		ds := positionDataset(false)
+		if err := ff.client.RegisterDataset(ff.cfg.SlewDefID, &ds); err != nil {
+			return fmt.Errorf("registering slew data: %w", err)
+		}
+	}
+	clear(ff.released)
+	clear(ff.slewed)
+	return ff.requestLeaderLocked(types.SIMCONNECT_PERIOD_VISUAL_FRAME)
+}
+
+// Stop cancels the leader request. Wingmen keep their last instructions.
+func (ff *FleetFormation) Stop() error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	return ff.requestLeaderLocked(types.SIMCONNECT_PERIOD_NEVER)
+}
+
+// SetLeader makes objectID the leader, once Start has been called.
+func (ff *FleetFormation) SetLeader(objectID uint32) error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	if err := ff.requestLeaderLocked(types.SIMCONNECT_PERIOD_NEVER); err != nil {
+		return err
+	}
+	ff.leader = objectID
+	return ff.requestLeaderLocked(types.SIMCONNECT_PERIOD_VISUAL_FRAME)
+}
+
+// requestLeaderLocked requests the leader's state at period.
+// Caller must hold ff.mu.
+func (ff *FleetFormation) requestLeaderLocked(period types.SIMCONNECT_PERIOD) error {
+	return ff.client.RequestDataOnSimObject(ff.cfg.LeaderRequestID, ff.cfg.LeaderDefinitionID, ff.leader, period, 0, 0, 0, 0)
+}
+
+// Join adds fleet member objectID as a wingman at off.
+func (ff *FleetFormation) Join(objectID uint32, off Offset) {
+	ff.form.Add(objectID, off)
+}
+
+// Leave removes wingman objectID from the formation. In ModeWaypoints it
+// flies on along its last chain.
+func (ff *FleetFormation) Leave(objectID uint32) {
+	ff.form.Remove(objectID)
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	delete(ff.released, objectID)
+	delete(ff.slewed, objectID)
+}
+
+// HandleData applies an answer to the leader request. Reports whether msg
+// belonged to it.
+func (ff *FleetFormation) HandleData(msg *types.SIMCONNECT_RECV_SIMOBJECT_DATA) bool {
+	if uint32(msg.DwRequestID) != ff.cfg.LeaderRequestID {
+		return false
+	}
+	header := int(unsafe.Offsetof(msg.DwData))
+	if int(msg.DwSize)-header < int(unsafe.Sizeof(leaderData{})) {
+		return true
+	}
+	d := *(*leaderData)(unsafe.Pointer(&msg.DwData))
+	ff.form.UpdateLeader(Aircraft(d), time.Now())
+	return true
+}
+
+// HandleEvent removes wingmen that leave the fleet. Run calls it; call it
+// yourself when driving Tick from your own loop.
+func (ff *FleetFormation) HandleEvent(ev traffic.FleetEvent) {
+	if ev.Kind == traffic.FleetRemoved {
+		ff.Leave(ev.Aircraft.ObjectID)
+	}
+}
+
+// Tick steers every wingman towards its target at now. Wingmen without
+// telemetry yet are skipped. Returns the joined errors of the calls.
+func (ff *FleetFormation) Tick(now time.Time) error {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+	dt := maxStep
+	if !ff.lastTick.IsZero() {
+		dt = min(max(now.Sub(ff.lastTick), 0), maxStep)
+	}
+	ff.lastTick = now
+
+	var errs []error
+	for _, id := range ff.form.Wingmen() {
+		ac, ok := ff.fleet.Get(id)
+		if !ok {
+			ff.form.Remove(id)
+			delete(ff.released, id)
+			delete(ff.slewed, id)
+			continue
+		}
+		pos, ok := ff.slewed[id]
+		if !ok || ff.cfg.Mode != ModeSlew {
+			if ac.State.Updated.IsZero() {
+				continue
+			}
+			pos = Aircraft{
+				Latitude:    ac.State.Latitude,
+				Longitude:   ac.State.Longitude,
+				Altitude:    ac.State.Altitude,
+				Heading:     ac.State.Heading,
+				GroundSpeed: ac.State.GroundSpeed,
+			}
+		}
+		cmd, err := ff.form.Steer(id, pos)
+		if errors.Is(err, ErrNoLeader) {
+			return err
+		}
+		if err != nil {
+			continue
+		}
+		if ff.cfg.Mode == ModeSlew {
+			err = ff.slewLocked(id, Follow(pos, cmd, dt))
+		} else {
+			err = ff.steerLocked(id, cmd)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("wingman %d: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// slewLocked writes the position of wingman id. Caller must hold ff.mu.
+func (ff *FleetFormation) slewLocked(id uint32, a Aircraft) error {
+	data := positionData{
+		Latitude:  a.Latitude,
+		Longitude: a.Longitude,
+		Altitude:  a.Altitude,
+		Heading:   a.Heading,
+	}
+	if err := ff.client.SetDataOnSimObject(
+		ff.cfg.SlewDefID,
+		id,
+		types.SIMCONNECT_DATA_SET_FLAG_DEFAULT,
+		1,
+		uint32(unsafe.Sizeof(data)),
+		unsafe.Pointer(&data),
+	); err != nil {
+		return err
+	}
+	ff.slewed[id] = a
+	return nil
+}
+
+// steerLocked sends wingman id a chain through cmd.Target. Caller must
+// hold ff.mu.
+func (ff *FleetFormation) steerLocked(id uint32, cmd Command) error {
+	if !ff.released[id] {
+		if err := ff.fleet.ReleaseControl(id, ff.reqIDs.Next()); err != nil {
+			return err
+		}
+		ff.released[id] = true
+	}
+	t := cmd.Target
+	lat, lon := calc.DisplaceByHeading(t.Latitude, t.Longitude, t.Heading,
+		convert.KnotsToMetersPerSecond(t.GroundSpeed)*extension.Seconds())
+	return ff.fleet.SetWaypoints(id, ff.cfg.WaypointDefID, []types.SIMCONNECT_DATA_WAYPOINT{
+		traffic.DescentWaypoint(t.Latitude, t.Longitude, t.Altitude, t.GroundSpeed),
+		traffic.DescentWaypoint(lat, lon, t.Altitude, t.GroundSpeed),
+	})
+}
+
+// Run drives the formation with traffic.Run until ctx is done.
+// In ModeSlew, use a short interval, such as 20 ms, for smooth movement.
+func (ff *FleetFormation) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return traffic.Run(ctx, ff.fleet, ff, interval, onError)
+}
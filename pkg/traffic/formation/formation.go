@@ -0,0 +1,409 @@
+// Package formation flies AI wingmen in formation with a leader, the user
+// aircraft or another AI aircraft. Each wingman holds a slot given as an
+// offset right of, behind and above the leader. A wingman far from its slot
+// joins up with extra speed on a lead pursuit course, holds the slot once
+// there, and can be sent away (Breakaway) and called back (Rejoin).
+//
+// Formation steers the wingmen and has no simulator dependency.
+// FleetFormation flies them on a traffic.Fleet, with waypoints or position
+// writes.
+package formation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+)
+
+const (
+	// DefaultInPosition is the distance from the slot, in meters, at which
+	// a joining wingman is in position.
+	DefaultInPosition = 30.0
+
+	// DefaultJoinDistance is the distance from the slot, in meters, beyond
+	// which a wingman in position joins up again.
+	DefaultJoinDistance = 300.0
+
+	// DefaultLookahead is how far ahead of the slot, in time at the
+	// leader's speed, a wingman in position aims.
+	DefaultLookahead = 3 * time.Second
+
+	// DefaultMaxLead is the longest lead of a joining wingman's pursuit.
+	DefaultMaxLead = 60 * time.Second
+
+	// DefaultJoinSpeedExcess is the speed, in knots, a joining wingman
+	// flies above the leader's.
+	DefaultJoinSpeedExcess = 40.0
+
+	// DefaultMaxCorrection is the largest speed correction, in knots, of a
+	// wingman in position.
+	DefaultMaxCorrection = 15.0
+
+	// DefaultGain is the speed correction, in knots per meter, for a
+	// wingman behind or ahead of its slot.
+	DefaultGain = 0.1
+
+	// DefaultSmoothing is the time constant the leader's heading, speed
+	// and altitude are smoothed with.
+	DefaultSmoothing = time.Second
+
+	// DefaultBreakawayTurn is how far, in degrees, a wingman turns away
+	// from the leader's heading on Breakaway.
+	DefaultBreakawayTurn = 45.0
+)
+
+var (
+	// ErrNoLeader is returned when the leader's state is not known.
+	ErrNoLeader = errors.New("formation: no leader state")
+
+	// ErrUnknownWingman is returned for an ID that is not in the formation.
+	ErrUnknownWingman = errors.New("formation: unknown wingman")
+)
+
+// Phase is what a wingman is doing.
+type Phase uint8
+
+const (
+	PhaseJoining    Phase = iota // flying to its slot
+	PhaseInPosition              // holding its slot
+	PhaseBreakaway               // turned away until Rejoin
+)
+
+// String returns "joining", "in position" or "breakaway".
+func (p Phase) String() string {
+	switch p {
+	case PhaseJoining:
+		return "joining"
+	case PhaseInPosition:
+		return "in position"
+	case PhaseBreakaway:
+		return "breakaway"
+	}
+	return fmt.Sprintf("Phase(%d)", uint8(p))
+}
+
+// Offset is a slot relative to the leader, in meters along the leader's
+// heading. Negative values place the slot left, ahead or below.
+type Offset struct {
+	Right float64
+	Aft   float64
+	Up    float64
+}
+
+// Aircraft is the state of the leader or a wingman.
+type Aircraft struct {
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	Altitude    float64 // feet MSL
+	Heading     float64 // degrees true
+	GroundSpeed float64 // knots
+}
+
+// Command is where a wingman should fly.
+type Command struct {
+	Phase Phase
+	// Target is the point to fly towards, with the heading to hold there
+	// and the speed to fly at as GroundSpeed.
+	Target Aircraft
+	// Slot is the slot itself, with the leader's heading and speed.
+	Slot Aircraft
+	// Distance is how far the wingman is from the slot, in meters.
+	Distance float64
+}
+
+// Options tune a Formation. Zero values use the defaults.
+type Options struct {
+	InPosition      float64       // meters, see DefaultInPosition
+	JoinDistance    float64       // meters, see DefaultJoinDistance
+	Lookahead       time.Duration // see DefaultLookahead
+	MaxLead         time.Duration // see DefaultMaxLead
+	JoinSpeedExcess float64       // knots, see DefaultJoinSpeedExcess
+	MaxCorrection   float64       // knots, see DefaultMaxCorrection
+	Gain            float64       // knots per meter, see DefaultGain
+	Smoothing       time.Duration // see DefaultSmoothing
+	BreakawayTurn   float64       // degrees, see DefaultBreakawayTurn
+}
+
+func (o Options) withDefaults() Options {
+	def := func(v *float64, d float64) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	defDur := func(v *time.Duration, d time.Duration) {
+		if *v <= 0 {
+			*v = d
+		}
+	}
+	def(&o.InPosition, DefaultInPosition)
+	def(&o.JoinDistance, DefaultJoinDistance)
+	defDur(&o.Lookahead, DefaultLookahead)
+	defDur(&o.MaxLead, DefaultMaxLead)
+	def(&o.JoinSpeedExcess, DefaultJoinSpeedExcess)
+	def(&o.MaxCorrection, DefaultMaxCorrection)
+	def(&o.Gain, DefaultGain)
+	defDur(&o.Smoothing, DefaultSmoothing)
+	def(&o.BreakawayTurn, DefaultBreakawayTurn)
+	o.JoinDistance = max(o.JoinDistance, o.InPosition)
+	return o
+}
+
+// wingman is a member of a Formation.
+type wingman struct {
+	offset       Offset
+	phase        Phase
+	breakHeading float64 // heading flown after Breakaway
+}
+
+// Formation steers wingmen relative to a leader. It is safe for concurrent
+// use.
+type Formation struct {
+	opts Options
+
+	mu        sync.Mutex
+	leader    Aircraft // smoothed
+	leaderAt  time.Time
+	hasLeader bool
+	wingmen   map[uint32]*wingman
+}
+
+// New returns a formation without leader state or wingmen.
+func New(opts Options) *Formation {
+	return &Formation{opts: opts.withDefaults(), wingmen: make(map[uint32]*wingman)}
+}
+
+// Add puts wingman id in the formation at off, joining up. Adding a
+// wingman again moves it to off.
+func (f *Formation) Add(id uint32, off Offset) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if w, ok := f.wingmen[id]; ok {
+		w.offset = off
+		return
+	}
+	f.wingmen[id] = &wingman{offset: off}
+}
+
+// Remove takes wingman id out of the formation.
+func (f *Formation) Remove(id uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.wingmen, id)
+}
+
+// Wingmen returns the IDs of the wingmen.
+func (f *Formation) Wingmen() []uint32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]uint32, 0, len(f.wingmen))
+	for id := range f.wingmen {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Phase returns the phase of wingman id.
+func (f *Formation) Phase(id uint32) (Phase, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, ok := f.wingmen[id]
+	if !ok {
+		return 0, false
+	}
+	return w.phase, true
+}
+
+// Breakaway turns wingman id away from the leader, to the side of its slot
+// (right for a slot in line), until Rejoin.
+func (f *Formation) Breakaway(id uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, ok := f.wingmen[id]
+	if !ok {
+		return ErrUnknownWingman
+	}
+	if w.phase == PhaseBreakaway {
+		return nil
+	}
+	turn := f.opts.BreakawayTurn
+	if w.offset.Right < 0 {
+		turn = -turn
+	}
+	w.phase, w.breakHeading = PhaseBreakaway, convert.NormalizeHeading(f.leader.Heading+turn)
+	return nil
+}
+
+// Rejoin sends wingman id back to its slot after Breakaway.
+func (f *Formation) Rejoin(id uint32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w, ok := f.wingmen[id]
+	if !ok {
+		return ErrUnknownWingman
+	}
+	if w.phase == PhaseBreakaway {
+		w.phase = PhaseJoining
+	}
+	return nil
+}
+
+// UpdateLeader records the leader's state at at. Heading, speed and
+// altitude are smoothed over Smoothing; the position is taken as is.
+// Call it whenever new leader state arrives, ideally every frame.
+func (f *Formation) UpdateLeader(a Aircraft, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case !f.hasLeader:
+		f.leader, f.leaderAt, f.hasLeader = a, at, true
+		return
+	case at.Before(f.leaderAt):
+		return
+	}
+	alpha := 1 - math.Exp(-at.Sub(f.leaderAt).Seconds()/f.opts.Smoothing.Seconds())
+	l := &f.leader
+	l.Latitude, l.Longitude = a.Latitude, a.Longitude
+	l.Heading = convert.NormalizeHeading(l.Heading + convert.AngleDifference(l.Heading, a.Heading)*alpha)
+	l.GroundSpeed += (a.GroundSpeed - l.GroundSpeed) * alpha
+	l.Altitude += (a.Altitude - l.Altitude) * alpha
+	f.leaderAt = at
+}
+
+// Leader returns the smoothed leader state.
+func (f *Formation) Leader() (Aircraft, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.leader, f.hasLeader
+}
+
+// Slot returns where the slot of wingman id is now.
+func (f *Formation) Slot(id uint32) (Aircraft, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.hasLeader {
+		return Aircraft{}, ErrNoLeader
+	}
+	w, ok := f.wingmen[id]
+	if !ok {
+		return Aircraft{}, ErrUnknownWingman
+	}
+	return SlotOf(f.leader, w.offset), nil
+}
+
+// SlotOf returns the slot at off from leader, with the leader's heading
+// and speed.
+func SlotOf(leader Aircraft, off Offset) Aircraft {
+	h := convert.DegreesToRadians(leader.Heading)
+	sin, cos := math.Sin(h), math.Cos(h)
+	// Right is the heading turned 90° clockwise, aft the heading reversed.
+	east := off.Right*cos - off.Aft*sin
+	north := -off.Right*sin - off.Aft*cos
+	lat, lon := convert.OffsetToLatLon(leader.Latitude, leader.Longitude, east, north)
+	return Aircraft{
+		Latitude:    lat,
+		Longitude:   lon,
+		Altitude:    leader.Altitude + convert.MetersToFeet(off.Up),
+		Heading:     leader.Heading,
+		GroundSpeed: leader.GroundSpeed,
+	}
+}
+
+// Steer returns where wingman id, now at a, should fly, and moves it
+// between PhaseJoining and PhaseInPosition by its distance from the slot.
+func (f *Formation) Steer(id uint32, a Aircraft) (Command, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.hasLeader {
+		return Command{}, ErrNoLeader
+	}
+	w, ok := f.wingmen[id]
+	if !ok {
+		return Command{}, ErrUnknownWingman
+	}
+
+	slot := SlotOf(f.leader, w.offset)
+	east, north := convert.LatLonToOffset(slot.Latitude, slot.Longitude, a.Latitude, a.Longitude)
+	up := convert.FeetToMeters(a.Altitude - slot.Altitude)
+	dist := math.Sqrt(east*east + north*north + up*up)
+
+	switch {
+	case w.phase == PhaseJoining && dist <= f.opts.InPosition:
+		w.phase = PhaseInPosition
+	case w.phase == PhaseInPosition && dist > f.opts.JoinDistance:
+		w.phase = PhaseJoining
+	}
+	cmd := Command{Phase: w.phase, Slot: slot, Distance: dist}
+
+	if w.phase == PhaseBreakaway {
+		lat, lon := calc.DisplaceByHeading(a.Latitude, a.Longitude, w.breakHeading,
+			convert.KnotsToMetersPerSecond(f.leader.GroundSpeed)*f.opts.MaxLead.Seconds())
+		cmd.Target = Aircraft{
+			Latitude:    lat,
+			Longitude:   lon,
+			Altitude:    a.Altitude,
+			Heading:     w.breakHeading,
+			GroundSpeed: f.leader.GroundSpeed,
+		}
+		return cmd, nil
+	}
+
+	// Meters the wingman is behind the slot along the leader's heading.
+	h := convert.DegreesToRadians(f.leader.Heading)
+	behind := -(east*math.Sin(h) + north*math.Cos(h))
+	lead := f.opts.Lookahead
+	speed := f.leader.GroundSpeed + clamp(behind*f.opts.Gain, -f.opts.MaxCorrection, f.opts.MaxCorrection)
+	if w.phase == PhaseJoining {
+		// Lead pursuit: aim where the slot will be when the wingman
+		// has closed the distance.
+		closure := convert.KnotsToMetersPerSecond(f.opts.JoinSpeedExcess)
+		lead = clamp(time.Duration(dist/closure*float64(time.Second)), f.opts.Lookahead, f.opts.MaxLead)
+		speed = f.leader.GroundSpeed + clamp(behind*f.opts.Gain, -f.opts.MaxCorrection, f.opts.JoinSpeedExcess)
+	}
+	lat, lon := calc.DisplaceByHeading(slot.Latitude, slot.Longitude, f.leader.Heading,
+		convert.KnotsToMetersPerSecond(f.leader.GroundSpeed)*lead.Seconds())
+	cmd.Target = Aircraft{
+		Latitude:    lat,
+		Longitude:   lon,
+		Altitude:    slot.Altitude,
+		Heading:     f.leader.Heading,
+		GroundSpeed: max(speed, 0),
+	}
+	return cmd, nil
+}
+
+// Follow moves a wingman at a along cmd for dt, for wingmen moved by
+// position writes rather than flown by the AI. It steps at the commanded
+// speed towards the target, or the slot itself when in position, and snaps
+// to the slot once the step reaches it.
+func Follow(a Aircraft, cmd Command, dt time.Duration) Aircraft {
+	step := convert.KnotsToMetersPerSecond(cmd.Target.GroundSpeed) * dt.Seconds()
+	if cmd.Phase != PhaseBreakaway && cmd.Distance <= step {
+		return cmd.Slot
+	}
+	to := cmd.Target
+	if cmd.Phase == PhaseInPosition {
+		to = cmd.Slot
+	}
+	dist := calc.HaversineMeters(a.Latitude, a.Longitude, to.Latitude, to.Longitude)
+	if dist <= step {
+		return to
+	}
+	brg := calc.BearingDegrees(a.Latitude, a.Longitude, to.Latitude, to.Longitude)
+	lat, lon := calc.DisplaceByHeading(a.Latitude, a.Longitude, brg, step)
+	return Aircraft{
+		Latitude:    lat,
+		Longitude:   lon,
+		Altitude:    a.Altitude + (to.Altitude-a.Altitude)*step/dist,
+		Heading:     brg,
+		GroundSpeed: to.GroundSpeed,
+	}
+}
+
+func clamp[T float64 | time.Duration](v, lo, hi T) T {
+	return min(max(v, lo), hi)
+}
@@ -0,0 +1,132 @@
+package formation
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+)
+
+func TestSlotOf(t *testing.T) {
+	tests := []struct {
+		heading     float64
+		off         Offset
+		east, north float64
+	}{
+		{0, Offset{Right: 100}, 100, 0},
+		{0, Offset{Aft: 50}, 0, -50},
+		{90, Offset{Right: 100}, 0, -100},
+		{90, Offset{Right: 30, Aft: 40}, -40, -30},
+		{270, Offset{Right: -20}, 0, -20},
+	}
+	for _, tt := range tests {
+		slot := SlotOf(Aircraft{Latitude: 50, Longitude: 14, Altitude: 1000, Heading: tt.heading}, tt.off)
+		east, north := convert.LatLonToOffset(50, 14, slot.Latitude, slot.Longitude)
+		if math.Abs(east-tt.east) > 0.01 || math.Abs(north-tt.north) > 0.01 {
+			t.Errorf("heading %v %+v: offset (%.2f, %.2f), want (%v, %v)", tt.heading, tt.off, east, north, tt.east, tt.north)
+		}
+	}
+	if slot := SlotOf(Aircraft{Altitude: 1000}, Offset{Up: 30.48}); math.Abs(slot.Altitude-1100) > 1e-9 {
+		t.Errorf("altitude = %v, want 1100", slot.Altitude)
+	}
+}
+
+func TestUpdateLeaderSmoothsAcrossNorth(t *testing.T) {
+	f := New(Options{Smoothing: time.Second})
+	t0 := time.Unix(1000, 0)
+	f.UpdateLeader(Aircraft{Heading: 350, GroundSpeed: 200}, t0)
+	f.UpdateLeader(Aircraft{Heading: 10, GroundSpeed: 300}, t0.Add(time.Second))
+
+	l, _ := f.Leader()
+	want := convert.NormalizeHeading(350 + 20*(1-math.Exp(-1)))
+	if math.Abs(l.Heading-want) > 1e-9 {
+		t.Errorf("heading = %v, want %v", l.Heading, want)
+	}
+	if l.GroundSpeed <= 200 || l.GroundSpeed >= 300 {
+		t.Errorf("speed = %v, want between", l.GroundSpeed)
+	}
+
+	// Older state is ignored.
+	f.UpdateLeader(Aircraft{Heading: 180}, t0)
+	if l2, _ := f.Leader(); l2 != l {
+		t.Errorf("leader = %+v after stale update, want %+v", l2, l)
+	}
+}
+
+func TestSteerErrors(t *testing.T) {
+	f := New(Options{})
+	if _, err := f.Steer(1, Aircraft{}); !errors.Is(err, ErrNoLeader) {
+		t.Errorf("err = %v, want ErrNoLeader", err)
+	}
+	f.UpdateLeader(Aircraft{}, time.Now())
+	if _, err := f.Steer(1, Aircraft{}); !errors.Is(err, ErrUnknownWingman) {
+		t.Errorf("err = %v, want ErrUnknownWingman", err)
+	}
+	if err := f.Breakaway(1); !errors.Is(err, ErrUnknownWingman) {
+		t.Errorf("Breakaway err = %v, want ErrUnknownWingman", err)
+	}
+}
+
+// fly moves the leader north at 250 kts and the wingman with Follow.
+func fly(f *Formation, leader, wing *Aircraft, t *time.Time, d time.Duration) Command {
+	const dt = 100 * time.Millisecond
+	var cmd Command
+	for end := t.Add(d); t.Before(end); *t = t.Add(dt) {
+		leader.Latitude, leader.Longitude = calc.DisplaceByHeading(leader.Latitude, leader.Longitude, leader.Heading,
+			convert.KnotsToMetersPerSecond(leader.GroundSpeed)*dt.Seconds())
+		f.UpdateLeader(*leader, *t)
+		var err error
+		if cmd, err = f.Steer(1, *wing); err != nil {
+			panic(err)
+		}
+		*wing = Follow(*wing, cmd, dt)
+	}
+	return cmd
+}
+
+func TestJoinHoldBreakawayRejoin(t *testing.T) {
+	f := New(Options{})
+	now := time.Unix(1000, 0)
+	leader := Aircraft{Latitude: 50, Longitude: 14, Altitude: 5000, GroundSpeed: 250}
+	f.UpdateLeader(leader, now)
+	f.Add(1, Offset{Right: 20, Aft: 20, Up: 3})
+
+	// Two kilometers behind and 500 ft below.
+	lat, lon := calc.DisplaceByHeading(50, 14, 180, 2000)
+	wing := Aircraft{Latitude: lat, Longitude: lon, Altitude: 4500, GroundSpeed: 250}
+
+	cmd := fly(f, &leader, &wing, &now, 5*time.Second)
+	if cmd.Phase != PhaseJoining || cmd.Target.GroundSpeed <= leader.GroundSpeed {
+		t.Fatalf("far behind: %v at %.0f kts, want joining faster than the leader", cmd.Phase, cmd.Target.GroundSpeed)
+	}
+
+	cmd = fly(f, &leader, &wing, &now, 3*time.Minute)
+	if cmd.Phase != PhaseInPosition || wing != cmd.Slot {
+		t.Fatalf("after join: %v, %.1f m from slot", cmd.Phase, cmd.Distance)
+	}
+
+	if err := f.Breakaway(1); err != nil {
+		t.Fatal(err)
+	}
+	cmd = fly(f, &leader, &wing, &now, 30*time.Second)
+	if cmd.Phase != PhaseBreakaway || cmd.Distance < 1000 {
+		t.Fatalf("after breakaway: %v, %.0f m from slot", cmd.Phase, cmd.Distance)
+	}
+	if wing.Heading < 40 || wing.Heading > 50 {
+		t.Errorf("breakaway heading = %.1f, want 45 (right of the leader)", wing.Heading)
+	}
+
+	if err := f.Rejoin(1); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := f.Phase(1); p != PhaseJoining {
+		t.Fatalf("after rejoin: %v, want joining", p)
+	}
+	cmd = fly(f, &leader, &wing, &now, 5*time.Minute)
+	if cmd.Phase != PhaseInPosition || wing != cmd.Slot {
+		t.Errorf("after rejoin: %v, %.1f m from slot", cmd.Phase, cmd.Distance)
+	}
+}
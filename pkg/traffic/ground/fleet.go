@@ -0,0 +1,199 @@
+//go:build windows
+// +build windows
+
+package ground
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// Config configures a FleetGround.
+type Config struct {
+	Options Options
+
+	// WaypointDefID is a definition registered for "AI Waypoint List".
+	WaypointDefID uint32
+
+	// ReqIDMin and ReqIDMax bound the request IDs of releases, taken in
+	// turn.
+	ReqIDMin, ReqIDMax uint32
+
+	// OnChange, if set, is called with every new clearance, e.g. to log
+	// aircraft holding short.
+	OnChange func(Change)
+}
+
+// sent is the part of a clearance last sent to an aircraft.
+type sent struct {
+	limit  int
+	lineup bool
+}
+
+// FleetGround runs a Controller on members of a traffic.Fleet. Each
+// aircraft given a route with Taxi is released to the AI and sent the
+// waypoints of its route up to its clearance limit, and the route's
+// lineup and climb-out once cleared onto the runway. The chain is sent
+// again whenever the limit moves.
+//
+// Member positions come from Aircraft.State, so the fleet must be tracking
+// (Fleet.Track). An aircraft leaves the controller when it reaches the end
+// of a route without a runway, becomes airborne or leaves the fleet.
+type FleetGround struct {
+	ctrl  *Controller
+	fleet *traffic.Fleet
+	g     *airportgraph.Graph
+	cfg   Config
+
+	mu       sync.Mutex
+	routes   map[uint32]traffic.GroundRoute
+	sent     map[uint32]sent
+	released map[uint32]bool
+	reqIDs   *traffic.RequestIDs
+}
+
+// NewFleetGround returns a ground controller for members of fleet on the
+// ground network g. For routes from traffic.PlanDepartureRoute, g must be
+// airportgraph.Build of the same airport.
+func NewFleetGround(fleet *traffic.Fleet, g *airportgraph.Graph, cfg Config) *FleetGround {
+	return &FleetGround{
+		ctrl:     New(g, cfg.Options),
+		fleet:    fleet,
+		g:        g,
+		cfg:      cfg,
+		routes:   make(map[uint32]traffic.GroundRoute),
+		sent:     make(map[uint32]sent),
+		released: make(map[uint32]bool),
+		reqIDs:   traffic.NewRequestIDs(cfg.ReqIDMin, cfg.ReqIDMax),
+	}
+}
+
+// Controller returns the controller, e.g. to read clearances.
+func (fg *FleetGround) Controller() *Controller { return fg.ctrl }
+
+// Taxi puts fleet member objectID under control along r. Giving a
+// controlled aircraft a new route replaces the old one. The aircraft
+// holds where it is until the next Tick clears it.
+func (fg *FleetGround) Taxi(objectID uint32, r traffic.GroundRoute) error {
+	if len(r.Waypoints) != len(r.Nodes)-1 {
+		return fmt.Errorf("ground: route of %d nodes has %d waypoints", len(r.Nodes), len(r.Waypoints))
+	}
+	if err := fg.ctrl.Add(objectID, Movement{Route: r.Nodes, Runway: r.Runway}); err != nil {
+		return err
+	}
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	fg.routes[objectID] = r
+	delete(fg.sent, objectID)
+	return nil
+}
+
+// Release takes objectID out of control. It keeps its last chain.
+func (fg *FleetGround) Release(objectID uint32) {
+	fg.ctrl.Remove(objectID)
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+	fg.forgetLocked(objectID)
+}
+
+// forgetLocked drops what is kept about objectID. Caller must hold fg.mu.
+func (fg *FleetGround) forgetLocked(objectID uint32) {
+	delete(fg.routes, objectID)
+	delete(fg.sent, objectID)
+	delete(fg.released, objectID)
+}
+
+// HandleEvent releases aircraft that leave the fleet. Run calls it; call
+// it yourself when driving Tick from your own loop.
+func (fg *FleetGround) HandleEvent(ev traffic.FleetEvent) {
+	if ev.Kind == traffic.FleetRemoved {
+		fg.Release(ev.Aircraft.ObjectID)
+	}
+}
+
+// Tick updates the controller with the members' positions at now and
+// sends the chains of the clearances that moved. Returns the joined errors
+// of the calls.
+func (fg *FleetGround) Tick(now time.Time) error {
+	fg.mu.Lock()
+	defer fg.mu.Unlock()
+
+	for id := range fg.routes {
+		a, ok := fg.fleet.Get(id)
+		if !ok {
+			fg.ctrl.Remove(id)
+			fg.forgetLocked(id)
+			continue
+		}
+		s := a.State
+		if s.Updated.IsZero() {
+			continue
+		}
+		if !s.OnGround {
+			// Departed: the runway is free for the next aircraft.
+			fg.ctrl.Remove(id)
+			fg.forgetLocked(id)
+			continue
+		}
+		x, z := fg.g.Offset(s.Latitude, s.Longitude)
+		fg.ctrl.Position(id, x, z)
+	}
+
+	var errs []error
+	for _, ch := range fg.ctrl.Update(now) {
+		if fg.cfg.OnChange != nil {
+			fg.cfg.OnChange(ch)
+		}
+		if ch.Clearance.Done {
+			fg.forgetLocked(ch.ID)
+			continue
+		}
+		if err := fg.sendLocked(ch.ID, ch.Clearance); err != nil {
+			errs = append(errs, fmt.Errorf("aircraft %d: %w", ch.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendLocked sends aircraft id the chain of clr if it differs from the
+// last one sent. Caller must hold fg.mu.
+func (fg *FleetGround) sendLocked(id uint32, clr Clearance) error {
+	r, ok := fg.routes[id]
+	if !ok {
+		return nil
+	}
+	s := sent{limit: clr.Limit, lineup: clr.Lineup}
+	if last, ok := fg.sent[id]; ok && last == s {
+		return nil
+	}
+	wps := append([]types.SIMCONNECT_DATA_WAYPOINT(nil), r.Waypoints[clr.Progress:clr.Limit]...)
+	if clr.Lineup {
+		wps = append(wps, r.Lineup...)
+	}
+	if len(wps) == 0 {
+		return nil
+	}
+	if !fg.released[id] {
+		if err := fg.fleet.ReleaseControl(id, fg.reqIDs.Next()); err != nil {
+			return err
+		}
+		fg.released[id] = true
+	}
+	if err := fg.fleet.SetWaypoints(id, fg.cfg.WaypointDefID, wps); err != nil {
+		return err
+	}
+	fg.sent[id] = s
+	return nil
+}
+
+// Run drives the ground traffic with traffic.Run until ctx is done.
+func (fg *FleetGround) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	return traffic.Run(ctx, fg.fleet, fg, interval, onError)
+}
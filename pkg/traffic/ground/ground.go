@@ -0,0 +1,528 @@
+// Package ground keeps AI aircraft taxiing on an airport's ground network
+// clear of each other. The Controller knows each aircraft's route on an
+// airportgraph.Graph and its live position, and clears it along the route
+// a little at a time by reserving what lies ahead:
+//
+//   - every node is held by one aircraft at a time, so aircraft follow each
+//     other in trail and cross at intersections one by one;
+//   - a taxiway between two intersections is held in one direction at a
+//     time, so aircraft never meet head-on. An aircraft is only cleared
+//     onto an intersection together with the taxiway it leaves it by, so
+//     it does not stop there in the way of traffic;
+//   - a runway is held by one aircraft at a time, whether crossing it or
+//     lined up on it, and a crossing is cleared in one piece, from the
+//     hold-short point to the first node off the runway.
+//
+// An aircraft that cannot be cleared further holds short at its clearance
+// limit. Holding aircraft are served first-come, first-served.
+//
+// The Controller has no simulator dependency. FleetGround runs one on the
+// members of a traffic.Fleet, re-issuing their waypoint chains up to the
+// clearance limit.
+package ground
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+var (
+	// ErrUnknownAircraft is returned for an aircraft the controller does
+	// not know.
+	ErrUnknownAircraft = errors.New("ground: unknown aircraft")
+
+	// ErrShortRoute is returned for a route of fewer than two nodes.
+	ErrShortRoute = errors.New("ground: route needs at least two nodes")
+)
+
+// Defaults for Options, in meters.
+const (
+	DefaultLookahead = 300.0
+	DefaultArrive    = 20.0
+	DefaultVacate    = 40.0
+)
+
+// Options tune the controller. Zero values use the defaults.
+type Options struct {
+	// Lookahead is how far ahead along its route an aircraft is cleared
+	// when nothing is in the way.
+	Lookahead float64
+	// Arrive is how close an aircraft must come to a node to have
+	// reached it.
+	Arrive float64
+	// Vacate is how far an aircraft must have moved on from a node before
+	// the node is free for others.
+	Vacate float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Lookahead <= 0 {
+		o.Lookahead = DefaultLookahead
+	}
+	if o.Arrive <= 0 {
+		o.Arrive = DefaultArrive
+	}
+	if o.Vacate <= 0 {
+		o.Vacate = DefaultVacate
+	}
+	return o
+}
+
+// Movement is the ground movement of one aircraft.
+type Movement struct {
+	// Route lists the graph nodes the aircraft moves through, starting
+	// where it is. Consecutive nodes not joined by an edge, such as a
+	// straight pushback past its entry point, are reserved as nodes only.
+	Route []int
+	// Runway is the runway end, e.g. "24", the aircraft lines up on after
+	// the last node, or empty for a movement that ends on the ground
+	// network, such as at a parking spot.
+	Runway string
+}
+
+// Clearance is how far an aircraft may move along its route.
+type Clearance struct {
+	// Progress is the index in Route of the last node the aircraft
+	// reached, and Limit that of the last node it is cleared to.
+	Progress int
+	Limit    int
+	// Lineup is set once the aircraft is cleared onto Movement.Runway.
+	Lineup bool
+	// Holding is set while other traffic keeps the aircraft short of the
+	// end of its route or of the lineup, and Blocker is that traffic.
+	Holding bool
+	Blocker uint32
+	// Done is set when the aircraft reached the end of a route without a
+	// runway. It has then left the controller.
+	Done bool
+}
+
+// Change is a new clearance reported by Controller.Update.
+type Change struct {
+	ID        uint32
+	Clearance Clearance
+}
+
+// aircraft is one aircraft under control.
+type aircraft struct {
+	id     uint32
+	seq    int
+	m      Movement
+	edges  []int  // edges[i] joins Route[i] and Route[i+1]; -1 if none does
+	lineup string // runway the aircraft lines up on, named as by RunwayOf
+	x, z   float64
+	clr    Clearance
+	since  time.Time // when it started holding
+}
+
+// use is the direction a stretch of taxiway is held in.
+type use struct {
+	dir    int8
+	holder uint32
+}
+
+// reservations are the nodes, stretches and runways held by all aircraft.
+type reservations struct {
+	nodes     map[int]uint32
+	stretches map[int]use
+	runways   map[string]uint32
+}
+
+// Controller clears aircraft along their routes on one airport's ground
+// network. It is safe for concurrent use.
+type Controller struct {
+	g    *airportgraph.Graph
+	opts Options
+
+	// stretch maps each edge to the stretch of taxiway it belongs to, -1
+	// for runway paths; orient gives the edge's direction in the stretch.
+	stretch []int
+	orient  []int8
+
+	mu       sync.Mutex
+	aircraft map[uint32]*aircraft
+	seq      int
+}
+
+// New returns a controller for the ground network g.
+func New(g *airportgraph.Graph, opts Options) *Controller {
+	c := &Controller{
+		g:        g,
+		opts:     opts.withDefaults(),
+		aircraft: make(map[uint32]*aircraft),
+	}
+	c.stretch, c.orient = stretches(g)
+	return c
+}
+
+// Options returns the options in use, defaults applied.
+func (c *Controller) Options() Options { return c.opts }
+
+// stretches splits the taxi paths of g into stretches of taxiway: runs of
+// edges joined by nodes with exactly two edges. Each edge is oriented so a
+// stretch can be walked in one direction from end to end.
+func stretches(g *airportgraph.Graph) ([]int, []int8) {
+	stretch := make([]int, len(g.Edges))
+	orient := make([]int8, len(g.Edges))
+	for i := range stretch {
+		stretch[i] = -1
+	}
+	inner := func(n int) bool { return len(g.Incident(n)) == 2 }
+	next := 0
+	for _, start := range g.Edges {
+		if start.Type == facility.TaxiPathRunway || stretch[start.ID] >= 0 {
+			continue
+		}
+		stretch[start.ID], orient[start.ID] = next, 1
+		queue := []int{start.ID}
+		for len(queue) > 0 {
+			e := g.Edges[queue[0]]
+			queue = queue[1:]
+			head, tail := e.B, e.A
+			if orient[e.ID] < 0 {
+				head, tail = e.A, e.B
+			}
+			for _, n := range []int{head, tail} {
+				if !inner(n) {
+					continue
+				}
+				for _, f := range g.Incident(n) {
+					if f.ID == e.ID || f.Type == facility.TaxiPathRunway || stretch[f.ID] >= 0 {
+						continue
+					}
+					// Leave the head forwards, arrive at the tail forwards.
+					o := int8(1)
+					if (n == head) != (f.A == n) {
+						o = -1
+					}
+					stretch[f.ID], orient[f.ID] = next, o
+					queue = append(queue, f.ID)
+				}
+			}
+		}
+		next++
+	}
+	return stretch, orient
+}
+
+// Add puts aircraft id under control, at the first node of m.Route and
+// cleared no further. Adding a known aircraft replaces its movement.
+//
+// Returns ErrShortRoute, or airportgraph.ErrUnknownNode or
+// airportgraph.ErrUnknownRunway for nodes or a runway g does not have.
+func (c *Controller) Add(id uint32, m Movement) error {
+	if len(m.Route) < 2 {
+		return ErrShortRoute
+	}
+	a := &aircraft{id: id, m: m, edges: make([]int, len(m.Route)-1)}
+	for i, n := range m.Route {
+		if n < 0 || n >= len(c.g.Nodes) {
+			return fmt.Errorf("%w: %d", airportgraph.ErrUnknownNode, n)
+		}
+		if i == 0 {
+			continue
+		}
+		a.edges[i-1] = -1
+		for _, e := range c.g.Incident(m.Route[i-1]) {
+			if e.Other(m.Route[i-1]) == n {
+				a.edges[i-1] = e.ID
+				break
+			}
+		}
+	}
+	if m.Runway != "" {
+		lineup, err := c.g.RunwayName(m.Runway)
+		if err != nil {
+			return err
+		}
+		a.lineup = lineup
+	}
+	first := c.g.Nodes[m.Route[0]]
+	a.x, a.z = first.X, first.Z
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	a.seq = c.seq
+	c.aircraft[id] = a
+	return nil
+}
+
+// Remove releases everything aircraft id holds, e.g. once it is airborne.
+// Reports whether it was under control.
+func (c *Controller) Remove(id uint32) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.aircraft[id]
+	delete(c.aircraft, id)
+	return ok
+}
+
+// Position records the position of aircraft id, in meters east and north
+// of the airport reference point (Graph.Offset), and advances its progress
+// along the route up to its clearance limit.
+func (c *Controller) Position(id uint32, x, z float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.aircraft[id]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownAircraft, id)
+	}
+	a.x, a.z = x, z
+	for k := a.clr.Progress + 1; k <= a.clr.Limit; k++ {
+		if c.dist(a, k) < c.opts.Arrive || (k < a.clr.Limit && c.onLeg(a, k)) {
+			a.clr.Progress = k
+		}
+	}
+	return nil
+}
+
+// dist returns the distance of a from node Route[k].
+func (c *Controller) dist(a *aircraft, k int) float64 {
+	n := c.g.Nodes[a.m.Route[k]]
+	return math.Hypot(a.x-n.X, a.z-n.Z)
+}
+
+// onLeg reports whether a is on the leg from Route[k] to Route[k+1].
+func (c *Controller) onLeg(a *aircraft, k int) bool {
+	p, q := c.g.Nodes[a.m.Route[k]], c.g.Nodes[a.m.Route[k+1]]
+	dx, dz := q.X-p.X, q.Z-p.Z
+	l2 := dx*dx + dz*dz
+	if l2 == 0 {
+		return false
+	}
+	t := ((a.x-p.X)*dx + (a.z-p.Z)*dz) / l2
+	if t <= 0 || t >= 1 {
+		return false
+	}
+	return math.Hypot(a.x-(p.X+t*dx), a.z-(p.Z+t*dz)) < c.opts.Arrive
+}
+
+// Clearance returns the clearance of aircraft id.
+func (c *Controller) Clearance(id uint32) (Clearance, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a, ok := c.aircraft[id]
+	if !ok {
+		return Clearance{}, false
+	}
+	return a.clr, true
+}
+
+// Update releases what aircraft have left behind and clears them further
+// where nothing is in the way, holding aircraft first in the order they
+// started holding. Returns the clearances that changed, ordered by
+// aircraft.
+func (c *Controller) Update(now time.Time) []Change {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var changes []Change
+	order := make([]*aircraft, 0, len(c.aircraft))
+	for id, a := range c.aircraft {
+		if a.m.Runway == "" && a.clr.Progress == len(a.m.Route)-1 {
+			delete(c.aircraft, id)
+			a.clr.Done, a.clr.Holding, a.clr.Blocker = true, false, 0
+			changes = append(changes, Change{ID: id, Clearance: a.clr})
+			continue
+		}
+		order = append(order, a)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.clr.Holding != b.clr.Holding {
+			return a.clr.Holding
+		}
+		if a.clr.Holding && !a.since.Equal(b.since) {
+			return a.since.Before(b.since)
+		}
+		return a.seq < b.seq
+	})
+
+	r := reservations{
+		nodes:     make(map[int]uint32),
+		stretches: make(map[int]use),
+		runways:   make(map[string]uint32),
+	}
+	for _, a := range order {
+		c.reserve(&r, a)
+	}
+	for _, a := range order {
+		prev := a.clr
+		c.extend(&r, a)
+		if a.clr.Holding && !prev.Holding {
+			a.since = now
+		}
+		if a.clr.Limit != prev.Limit || a.clr.Lineup != prev.Lineup ||
+			a.clr.Holding != prev.Holding || a.clr.Blocker != prev.Blocker {
+			changes = append(changes, Change{ID: a.id, Clearance: a.clr})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// reserve records what a holds: the nodes from the one it is at or has
+// not yet vacated to its limit, the stretches of the edges in between and
+// of the edge it takes next, the stretch it leaves an intersection at its
+// limit by, and the runways of those nodes and of its lineup.
+func (c *Controller) reserve(r *reservations, a *aircraft) {
+	p, limit := a.clr.Progress, a.clr.Limit
+	from := p
+	if c.dist(a, p) >= c.opts.Vacate && p < limit {
+		from = p + 1
+	}
+	for k := from; k <= limit; k++ {
+		c.take(r, a, k)
+	}
+	for k := p; k < max(limit, p+1) && k < len(a.edges); k++ {
+		c.takeEdge(r, a, k)
+	}
+	if limit < len(a.edges) && c.intersection(a.m.Route[limit]) {
+		c.takeEdge(r, a, limit)
+	}
+	if a.clr.Lineup {
+		r.runways[a.lineup] = a.id
+	}
+}
+
+// take reserves node Route[k] and its runway for a.
+func (c *Controller) take(r *reservations, a *aircraft, k int) {
+	n := a.m.Route[k]
+	r.nodes[n] = a.id
+	if rwy, ok := c.g.RunwayOf(n); ok {
+		r.runways[rwy] = a.id
+	}
+}
+
+// takeEdge reserves the stretch of edges[k] in a's direction of travel.
+func (c *Controller) takeEdge(r *reservations, a *aircraft, k int) {
+	if s, dir, ok := c.direction(a, k); ok {
+		if _, held := r.stretches[s]; !held {
+			r.stretches[s] = use{dir: dir, holder: a.id}
+		}
+	}
+}
+
+// direction returns the stretch of edges[k] and the direction a travels
+// it in, or false for runway paths and nodes not joined by an edge.
+func (c *Controller) direction(a *aircraft, k int) (int, int8, bool) {
+	id := a.edges[k]
+	if id < 0 || c.stretch[id] < 0 {
+		return 0, 0, false
+	}
+	dir := c.orient[id]
+	if c.g.Edges[id].A != a.m.Route[k] {
+		dir = -dir
+	}
+	return c.stretch[id], dir, true
+}
+
+// intersection reports whether more than two edges meet at node n.
+func (c *Controller) intersection(n int) bool { return len(c.g.Incident(n)) > 2 }
+
+// onRunway reports whether reaching Route[k] from Route[k-1] takes a onto
+// a runway.
+func (c *Controller) onRunway(a *aircraft, k int) bool {
+	if c.g.Nodes[a.m.Route[k]].OnRunway {
+		return true
+	}
+	id := a.edges[k-1]
+	return id >= 0 && c.g.Edges[id].Type == facility.TaxiPathRunway
+}
+
+// extend clears a further along its route while nothing is in the way, up
+// to the lookahead, then onto its runway once at the end of the route.
+func (c *Controller) extend(r *reservations, a *aircraft) {
+	last := len(a.m.Route) - 1
+	a.clr.Holding, a.clr.Blocker = false, 0
+	for a.clr.Limit < last && c.ahead(a) < c.opts.Lookahead {
+		k := a.clr.Limit
+		j := k + 1
+		if c.onRunway(a, j) {
+			// Clear a crossing in one piece, to the first node off the
+			// runway.
+			for j < last && c.onRunway(a, j) {
+				j++
+			}
+		}
+		if blocker, ok := c.free(r, a, k, j); !ok {
+			a.clr.Holding, a.clr.Blocker = true, blocker
+			return
+		}
+		for i := k + 1; i <= j; i++ {
+			c.take(r, a, i)
+			c.takeEdge(r, a, i-1)
+		}
+		if j < last && c.intersection(a.m.Route[j]) {
+			c.takeEdge(r, a, j)
+		}
+		a.clr.Limit = j
+	}
+	if a.clr.Limit < last || a.m.Runway == "" || a.clr.Lineup {
+		return
+	}
+	if holder, held := r.runways[a.lineup]; held && holder != a.id {
+		a.clr.Holding, a.clr.Blocker = true, holder
+		return
+	}
+	r.runways[a.lineup] = a.id
+	a.clr.Lineup = true
+}
+
+// ahead returns how far a is cleared ahead of its position.
+func (c *Controller) ahead(a *aircraft) float64 {
+	p, limit := a.clr.Progress, a.clr.Limit
+	if limit <= p {
+		return 0
+	}
+	d := c.dist(a, p+1)
+	for k := p + 1; k < limit; k++ {
+		n, m := c.g.Nodes[a.m.Route[k]], c.g.Nodes[a.m.Route[k+1]]
+		d += math.Hypot(m.X-n.X, m.Z-n.Z)
+	}
+	return d
+}
+
+// free reports whether a can be cleared from Route[k] to Route[j], and
+// otherwise which aircraft is in the way.
+func (c *Controller) free(r *reservations, a *aircraft, k, j int) (uint32, bool) {
+	for i := k + 1; i <= j; i++ {
+		n := a.m.Route[i]
+		if holder, held := r.nodes[n]; held && holder != a.id {
+			return holder, false
+		}
+		if rwy, ok := c.g.RunwayOf(n); ok {
+			if holder, held := r.runways[rwy]; held && holder != a.id {
+				return holder, false
+			}
+		}
+		if blocker, ok := c.freeEdge(r, a, i-1); !ok {
+			return blocker, false
+		}
+	}
+	if j < len(a.edges) && c.intersection(a.m.Route[j]) {
+		return c.freeEdge(r, a, j)
+	}
+	return 0, true
+}
+
+// freeEdge reports whether the stretch of edges[k] is free in a's
+// direction of travel.
+func (c *Controller) freeEdge(r *reservations, a *aircraft, k int) (uint32, bool) {
+	s, dir, ok := c.direction(a, k)
+	if !ok {
+		return 0, true
+	}
+	if u, held := r.stretches[s]; held && u.dir != dir && u.holder != a.id {
+		return u.holder, false
+	}
+	return 0, true
+}
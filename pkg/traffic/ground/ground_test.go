@@ -0,0 +1,287 @@
+package ground
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/airportgraph/airporttest"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+)
+
+// Fixture taxi point indices. Runway 09/27 runs along the x axis; taxiway
+// A is parallel to it and B crosses A at A2. The runway can be crossed at
+// R1 to S1:
+//
+//	                  B0
+//	                  |
+//	                  B1
+//	                  |
+//	A0 ---- A1 ------ A2 ------ A3 ---- A4
+//	|                 |                 |
+//	H0                H1                H2
+//	|                 |                 |
+//	R0 -------------- R1 -------------- R2     runway 09/27
+//	                  |
+//	                  S0
+//	                  |
+//	                  S1
+const (
+	r0 = iota
+	r1
+	r2
+	h0
+	h1
+	h2
+	a0
+	a1
+	a2
+	a3
+	a4
+	b0
+	b1
+	s0
+	s1
+)
+
+func fixture() *airportgraph.Graph {
+	pt, hold, taxi, runway := airporttest.Point, airporttest.Hold, airporttest.Taxi, airporttest.Runway
+	points := []facility.TaxiPoint{
+		r0: pt(-400, 0), r1: pt(0, 0), r2: pt(400, 0),
+		h0: hold(-400, 100), h1: hold(0, 100), h2: hold(400, 100),
+		a0: pt(-400, 200), a1: pt(-200, 200), a2: pt(0, 200), a3: pt(200, 200), a4: pt(400, 200),
+		b0: pt(0, 600), b1: pt(0, 400),
+		s0: hold(0, -100), s1: pt(0, -200),
+	}
+	paths := []facility.TaxiPath{
+		runway(r0, r1), runway(r1, r2),
+		taxi("A", a0, a1), taxi("A", a1, a2), taxi("A", a2, a3), taxi("A", a3, a4),
+		taxi("B", b0, b1), taxi("B", b1, a2),
+		taxi("C1", a0, h0), taxi("C1", h0, r0),
+		taxi("C2", a2, h1), taxi("C2", h1, r1),
+		taxi("C3", a4, h2), taxi("C3", h2, r2),
+		taxi("D", r1, s0), taxi("D", s0, s1),
+	}
+	return airportgraph.Build(airporttest.Airport(800, points, paths))
+}
+
+// move puts aircraft id at node n.
+func move(t *testing.T, c *Controller, g *airportgraph.Graph, id uint32, n int) {
+	t.Helper()
+	if err := c.Position(id, g.Nodes[n].X, g.Nodes[n].Z); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustAdd(t *testing.T, c *Controller, id uint32, m Movement) {
+	t.Helper()
+	if err := c.Add(id, m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func clearance(t *testing.T, c *Controller, id uint32) Clearance {
+	t.Helper()
+	clr, ok := c.Clearance(id)
+	if !ok {
+		t.Fatalf("aircraft %d unknown", id)
+	}
+	return clr
+}
+
+func TestIntersection(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{})
+	now := time.Unix(0, 0)
+	mustAdd(t, c, 1, Movement{Route: []int{a1, a2, a3}})
+	mustAdd(t, c, 2, Movement{Route: []int{b1, a2, h1}})
+
+	c.Update(now)
+	if clr := clearance(t, c, 1); clr.Limit != 2 || clr.Holding {
+		t.Fatalf("first: %+v, want cleared to A3", clr)
+	}
+	if clr := clearance(t, c, 2); clr.Limit != 0 || !clr.Holding || clr.Blocker != 1 {
+		t.Fatalf("second: %+v, want holding for 1", clr)
+	}
+
+	move(t, c, g, 1, a2)
+	c.Update(now.Add(time.Second))
+	move(t, c, g, 1, a3)
+	changes := c.Update(now.Add(2 * time.Second))
+	if clr := clearance(t, c, 2); clr.Limit != 2 || clr.Holding {
+		t.Fatalf("second after crossing: %+v, want cleared to H1", clr)
+	}
+	if len(changes) != 2 || !changes[0].Clearance.Done || changes[1].ID != 2 {
+		t.Errorf("changes = %+v, want 1 done and 2 cleared", changes)
+	}
+	if _, ok := c.Clearance(1); ok {
+		t.Error("aircraft 1 still under control after reaching the end")
+	}
+}
+
+func TestHeadOn(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{Lookahead: 2000})
+	now := time.Unix(0, 0)
+	mustAdd(t, c, 1, Movement{Route: []int{a1, a2, a3, a4}})
+	mustAdd(t, c, 2, Movement{Route: []int{a4, a3, a2, b1}})
+
+	c.Update(now)
+	// 2 holds the stretch A2–A4 westbound from the start; 1 must not stop
+	// on the intersection A2 it needs to leave by.
+	if clr := clearance(t, c, 1); clr.Limit != 0 || !clr.Holding || clr.Blocker != 2 {
+		t.Fatalf("eastbound: %+v, want holding at A1 for 2", clr)
+	}
+	if clr := clearance(t, c, 2); clr.Limit != 3 {
+		t.Fatalf("westbound: %+v, want cleared to B1", clr)
+	}
+
+	for _, n := range []int{a3, a2, b1} {
+		move(t, c, g, 2, n)
+	}
+	c.Update(now.Add(time.Second))
+	if clr := clearance(t, c, 1); clr.Limit != 3 || clr.Holding {
+		t.Errorf("eastbound after: %+v, want cleared to A4", clr)
+	}
+}
+
+func TestInTrail(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{Lookahead: 2000})
+	now := time.Unix(0, 0)
+	mustAdd(t, c, 1, Movement{Route: []int{a1, a2, a3, a4}})
+	mustAdd(t, c, 2, Movement{Route: []int{a0, a1, a2, a3, a4}})
+
+	c.Update(now)
+	if clr := clearance(t, c, 2); clr.Limit != 0 || clr.Blocker != 1 {
+		t.Fatalf("follower: %+v, want holding at A0 for 1", clr)
+	}
+	move(t, c, g, 1, a2)
+	move(t, c, g, 1, a3)
+	c.Update(now.Add(time.Second))
+	// Same direction: the follower may use the stretch, up to the node
+	// behind the leader.
+	if clr := clearance(t, c, 2); clr.Limit != 2 || clr.Blocker != 1 {
+		t.Errorf("follower: %+v, want cleared to A2 behind 1", clr)
+	}
+}
+
+func TestRunwayCrossing(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{})
+	now := time.Unix(0, 0)
+	mustAdd(t, c, 1, Movement{Route: []int{a0, h0}, Runway: "09"})
+	mustAdd(t, c, 2, Movement{Route: []int{h1, r1, s0, s1}})
+
+	c.Update(now)
+	if clr := clearance(t, c, 1); !clr.Lineup {
+		t.Fatalf("departure: %+v, want lined up", clr)
+	}
+	if clr := clearance(t, c, 2); clr.Limit != 0 || clr.Blocker != 1 {
+		t.Fatalf("crossing: %+v, want holding short for 1", clr)
+	}
+
+	c.Remove(1)
+	c.Update(now.Add(time.Second))
+	// Cleared across in one piece, to the first node off the runway.
+	if clr := clearance(t, c, 2); clr.Limit < 2 || clr.Holding {
+		t.Errorf("crossing: %+v, want cleared past the runway", clr)
+	}
+}
+
+func TestLineupSequence(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{})
+	now := time.Unix(0, 0)
+	mustAdd(t, c, 1, Movement{Route: []int{a4, h2}, Runway: "27"})
+	mustAdd(t, c, 2, Movement{Route: []int{a0, h0}, Runway: "09"})
+
+	c.Update(now)
+	c.Update(now.Add(time.Second))
+	first, second := clearance(t, c, 1), clearance(t, c, 2)
+	if !first.Lineup || second.Lineup || !second.Holding || second.Blocker != 1 {
+		t.Fatalf("first %+v, second %+v, want 1 lined up and 2 holding", first, second)
+	}
+	c.Remove(1)
+	c.Update(now.Add(2 * time.Second))
+	if clr := clearance(t, c, 2); !clr.Lineup {
+		t.Errorf("second: %+v, want lined up", clr)
+	}
+}
+
+func TestFirstCome(t *testing.T) {
+	g := fixture()
+	c := New(g, Options{Lookahead: 150})
+	now := time.Unix(0, 0)
+	// 1 stands on the intersection A2 until it moves on to H1.
+	mustAdd(t, c, 1, Movement{Route: []int{a2, h1}, Runway: "09"})
+	mustAdd(t, c, 3, Movement{Route: []int{b0, b1, a2, a1}})
+	mustAdd(t, c, 2, Movement{Route: []int{a3, a2, a1}})
+	c.Update(now)
+	if clr := clearance(t, c, 2); !clr.Holding || clr.Blocker != 1 {
+		t.Fatalf("2: %+v, want holding for 1", clr)
+	}
+
+	// 3 was added before 2 but starts holding after it.
+	move(t, c, g, 3, b1)
+	c.Update(now.Add(time.Second))
+	if clr := clearance(t, c, 3); !clr.Holding || clr.Blocker != 1 {
+		t.Fatalf("3: %+v, want holding for 1", clr)
+	}
+
+	move(t, c, g, 1, h1)
+	c.Update(now.Add(2 * time.Second))
+	if clr := clearance(t, c, 2); clr.Holding || clr.Limit != 1 {
+		t.Errorf("2: %+v, want cleared onto A2", clr)
+	}
+	if clr := clearance(t, c, 3); !clr.Holding || clr.Blocker != 2 {
+		t.Errorf("3: %+v, want holding for 2", clr)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	c := New(fixture(), Options{})
+	if err := c.Add(1, Movement{Route: []int{a0}}); !errors.Is(err, ErrShortRoute) {
+		t.Errorf("short route: err = %v", err)
+	}
+	if err := c.Add(1, Movement{Route: []int{a0, 99}}); !errors.Is(err, airportgraph.ErrUnknownNode) {
+		t.Errorf("unknown node: err = %v", err)
+	}
+	if err := c.Add(1, Movement{Route: []int{a0, h0}, Runway: "18"}); !errors.Is(err, airportgraph.ErrUnknownRunway) {
+		t.Errorf("unknown runway: err = %v", err)
+	}
+	if err := c.Position(9, 0, 0); !errors.Is(err, ErrUnknownAircraft) {
+		t.Errorf("unknown aircraft: err = %v", err)
+	}
+}
+
+func TestAddRunwayWithoutAccess(t *testing.T) {
+	// A runway the taxi network does not join has no access points.
+	g := airportgraph.Build(&facility.Airport{
+		Latitude:  50,
+		Longitude: 14,
+		Runways: []facility.Runway{{
+			Latitude:  50,
+			Longitude: 14,
+			Heading:   90,
+			Length:    800,
+			Primary:   facility.RunwayEnd{Number: 9},
+			Secondary: facility.RunwayEnd{Number: 27},
+		}},
+		TaxiPoints: []facility.TaxiPoint{{Type: 1, BiasX: -400}, {Type: 1}, {Type: 1, BiasX: 400}},
+		TaxiPaths: []facility.TaxiPath{
+			{Type: facility.TaxiPathRunway, RunwayNumber: 9, Start: 0, End: 1},
+			{Type: facility.TaxiPathRunway, RunwayNumber: 9, Start: 1, End: 2},
+		},
+	})
+	if access, err := g.RunwayAccess("09"); err != nil || len(access) != 0 {
+		t.Fatalf("RunwayAccess = %v, %v, want no access points", access, err)
+	}
+
+	c := New(g, Options{})
+	mustAdd(t, c, 1, Movement{Route: []int{0, 1, 2}, Runway: "27"})
+	if got := c.aircraft[1].lineup; got != "09/27" {
+		t.Errorf("lineup = %q, want 09/27", got)
+	}
+}
@@ -0,0 +1,155 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"math"
+
+	"github.com/mrlm-net/simconnect/pkg/airportgraph"
+	"github.com/mrlm-net/simconnect/pkg/calc"
+	"github.com/mrlm-net/simconnect/pkg/convert"
+	"github.com/mrlm-net/simconnect/pkg/facility"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// Default ground speeds, in knots, used by the route planners when the
+// options leave them zero.
+const (
+	DefaultPushbackSpeed = 3.0
+	DefaultTaxiSpeed     = 15.0
+	DefaultTurnSpeed     = 8.0
+)
+
+// turnAngle is the heading change at a taxi node, in degrees, above which
+// the planners slow to the turn speed.
+const turnAngle = 30.0
+
+// AircraftCategory sizes the airborne part of a planned route. The zero
+// value is CategoryJet.
+type AircraftCategory uint8
+
+const (
+	// CategoryJet — narrow-body airliners and business jets.
+	CategoryJet AircraftCategory = iota
+	// CategoryLight — single- and light twin-engine piston aircraft.
+	CategoryLight
+	// CategoryTurboprop — regional turboprops.
+	CategoryTurboprop
+	// CategoryHeavy — wide-body airliners.
+	CategoryHeavy
+)
+
+// climbStep is one waypoint of a climb-out profile.
+type climbStep struct {
+	meters   float64 // distance from the runway threshold
+	altAGL   float64 // feet
+	kts      float64
+	throttle float64 // percent
+}
+
+// climbProfiles holds the climb-out of each category. The jet profile is the
+// one TakeoffClimb uses.
+var climbProfiles = map[AircraftCategory][]climbStep{
+	CategoryLight: {
+		{1852, 1000, 80, 100},
+		{5556, 2500, 90, 90},
+		{14816, 4500, 100, 85},
+	},
+	CategoryTurboprop: {
+		{2778, 1500, 140, 100},
+		{9260, 4000, 170, 90},
+		{18520, 7000, 200, 85},
+	},
+	CategoryJet: {
+		{2778, 1500, 200, 100},
+		{9260, 4000, 240, 90},
+		{22224, 9000, 280, 85},
+	},
+	CategoryHeavy: {
+		{3704, 1500, 180, 100},
+		{11112, 4000, 230, 90},
+		{27780, 10000, 270, 85},
+	},
+}
+
+// TaxiSpeeds is the ground speed profile of a planned route, in knots. Zero
+// fields take the Default*Speed constants.
+type TaxiSpeeds struct {
+	Pushback float64 // reverse from the gate
+	Taxi     float64 // straight taxiway segments
+	Turn     float64 // nodes with a turn over 30°, and the runway hold-short point
+}
+
+func (s TaxiSpeeds) withDefaults() TaxiSpeeds {
+	if s.Pushback <= 0 {
+		s.Pushback = DefaultPushbackSpeed
+	}
+	if s.Taxi <= 0 {
+		s.Taxi = DefaultTaxiSpeed
+	}
+	if s.Turn <= 0 {
+		s.Turn = DefaultTurnSpeed
+	}
+	return s
+}
+
+// groundPlan carries the state shared by one route planning call.
+type groundPlan struct {
+	ap     *facility.Airport
+	g      *airportgraph.Graph
+	altFt  float64 // airport elevation
+	speeds TaxiSpeeds
+}
+
+func newGroundPlan(ap *facility.Airport, speeds TaxiSpeeds) *groundPlan {
+	return &groundPlan{
+		ap:     ap,
+		g:      airportgraph.Build(ap),
+		altFt:  convert.MetersToFeet(ap.Altitude),
+		speeds: speeds.withDefaults(),
+	}
+}
+
+// latLon converts a graph offset to a position.
+func (p *groundPlan) latLon(x, z float64) (float64, float64) {
+	return convert.OffsetToLatLon(p.ap.Latitude, p.ap.Longitude, x, z)
+}
+
+// taxi returns a TaxiWaypoint for each node of nodes after the first, which
+// the aircraft is already at. Nodes where the route turns by more than
+// turnAngle, and the last node, use the turn speed.
+func (p *groundPlan) taxi(nodes []int) []types.SIMCONNECT_DATA_WAYPOINT {
+	wps := make([]types.SIMCONNECT_DATA_WAYPOINT, 0, len(nodes))
+	for i := 1; i < len(nodes); i++ {
+		kts := p.speeds.Taxi
+		if i == len(nodes)-1 {
+			kts = p.speeds.Turn
+		} else if turn := convert.AngleDifference(p.g.Heading(nodes[i-1], nodes[i]), p.g.Heading(nodes[i], nodes[i+1])); math.Abs(turn) > turnAngle {
+			kts = p.speeds.Turn
+		}
+		lat, lon, _ := p.g.LatLon(nodes[i])
+		wps = append(wps, TaxiWaypoint(lat, lon, p.altFt, kts))
+	}
+	return wps
+}
+
+// climb returns the climb-out of category c from the threshold position
+// along hdgDeg. A positive ceilingAGL caps every waypoint and sets the
+// altitude of the last one.
+func climb(c AircraftCategory, lat, lon, hdgDeg, ceilingAGL float64) []types.SIMCONNECT_DATA_WAYPOINT {
+	steps, ok := climbProfiles[c]
+	if !ok {
+		steps = climbProfiles[CategoryJet]
+	}
+	wps := make([]types.SIMCONNECT_DATA_WAYPOINT, len(steps))
+	for i, s := range steps {
+		alt := s.altAGL
+		if ceilingAGL > 0 && (alt > ceilingAGL || i == len(steps)-1) {
+			alt = ceilingAGL
+		}
+		wlat, wlon := calc.DisplaceByHeading(lat, lon, hdgDeg, s.meters)
+		wps[i] = ClimbWaypoint(wlat, wlon, alt, s.kts, s.throttle)
+	}
+	return wps
+}
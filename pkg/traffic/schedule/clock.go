@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock reports the simulator time the Scheduler runs on: a continuous
+// time since midnight zulu of day 0, so t/Day is the day and t%Day the
+// zulu time of day. running is false while the simulation is paused or
+// the time is not known yet.
+type Clock interface {
+	Now() (t time.Duration, running bool)
+}
+
+// SimClock is a Clock following the simulator's zulu time. Feed it the
+// ZULU TIME, SIMULATION RATE and pause state with Sync, e.g. from the
+// manager's SimState; between samples it advances at the simulation rate.
+// Crossing midnight moves it to the next day, and a zulu time set more than
+// twelve hours back or forward is taken as a jump across midnight.
+type SimClock struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	synced  bool
+	day     int
+	zulu    time.Duration // zulu time of the last sample
+	sampled time.Time     // real time of the last sample
+	rate    float64
+	paused  bool
+}
+
+// NewSimClock returns a clock that is not running until the first Sync.
+// now reads the real time; nil means time.Now. Tests pass a fake to step
+// the clock.
+func NewSimClock(now func() time.Time) *SimClock {
+	if now == nil {
+		now = time.Now
+	}
+	return &SimClock{now: now, rate: 1}
+}
+
+// Sync records a sample: zuluSeconds since midnight zulu, the simulation
+// rate (values <= 0 count as 1) and whether the simulation is paused.
+func (c *SimClock) Sync(zuluSeconds, rate float64, paused bool) {
+	zulu := time.Duration(zuluSeconds * float64(time.Second))
+	if rate <= 0 {
+		rate = 1
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.synced {
+		switch {
+		case zulu < c.zulu-Day/2:
+			c.day++
+		case zulu > c.zulu+Day/2:
+			c.day--
+		}
+	}
+	c.synced = true
+	c.zulu, c.sampled, c.rate, c.paused = zulu, c.now(), rate, paused
+}
+
+// Now returns the time of the last sample, advanced by the real time since
+// then times the simulation rate unless paused.
+func (c *SimClock) Now() (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.synced {
+		return 0, false
+	}
+	t := time.Duration(c.day)*Day + c.zulu
+	if c.paused {
+		return t, false
+	}
+	return t + time.Duration(float64(c.now().Sub(c.sampled))*c.rate), true
+}
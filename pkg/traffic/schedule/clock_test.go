@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeTime is a settable real time.
+type fakeTime struct{ t time.Time }
+
+func (f *fakeTime) now() time.Time          { return f.t }
+func (f *fakeTime) advance(d time.Duration) { f.t = f.t.Add(d) }
+func newFakeTime() *fakeTime                { return &fakeTime{t: time.Unix(0, 0)} }
+
+func TestSimClock(t *testing.T) {
+	rt := newFakeTime()
+	c := NewSimClock(rt.now)
+	if _, running := c.Now(); running {
+		t.Fatal("clock running before Sync")
+	}
+
+	c.Sync(3600, 1, false)
+	rt.advance(10 * time.Second)
+	if now, _ := c.Now(); now != time.Hour+10*time.Second {
+		t.Errorf("rate 1: now = %v", now)
+	}
+
+	// Sim rate 4 runs four times as fast between samples.
+	c.Sync(3610, 4, false)
+	rt.advance(10 * time.Second)
+	if now, _ := c.Now(); now != time.Hour+50*time.Second {
+		t.Errorf("rate 4: now = %v", now)
+	}
+
+	// Paused, the clock stands still.
+	c.Sync(3650, 4, true)
+	rt.advance(time.Minute)
+	if now, running := c.Now(); running || now != time.Hour+50*time.Second {
+		t.Errorf("paused: now = %v, running = %v", now, running)
+	}
+
+	// Crossing midnight moves to the next day, and back.
+	c.Sync(40000, 1, false)
+	c.Sync(70000, 1, false)
+	c.Sync(86390, 1, false)
+	c.Sync(5, 1, false)
+	if now, _ := c.Now(); now != Day+5*time.Second {
+		t.Errorf("after midnight: now = %v", now)
+	}
+	c.Sync(86000, 1, false)
+	if now, _ := c.Now(); now != 86000*time.Second {
+		t.Errorf("set back: now = %v", now)
+	}
+}
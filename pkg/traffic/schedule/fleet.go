@@ -0,0 +1,121 @@
+//go:build windows
+// +build windows
+
+package schedule
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+)
+
+// FleetDriver is a Driver creating ATC aircraft in a traffic.Fleet, such as
+// the manager's Fleet(). Parked legs are created with RequestParked and get
+// their plan with SetFlightPlan; airborne legs are created with
+// RequestEnroute. Aircraft are found in the fleet by tail number, so each
+// flight of a timetable needs a distinct TailNumber.
+type FleetDriver struct {
+	fleet  *traffic.Fleet
+	plans  PlanFunc
+	reqIDs *traffic.RequestIDs
+
+	mu      sync.Mutex
+	created map[string]uint32 // tail → request ID of its last creation
+}
+
+// NewFleetDriver returns a driver for fleet. plans provides the flight plan
+// of each leg, e.g. DirectPlans. Request IDs are taken in turn from
+// reqIDMin to reqIDMax; keep the range clear of other requests.
+func NewFleetDriver(fleet *traffic.Fleet, plans PlanFunc, reqIDMin, reqIDMax uint32) *FleetDriver {
+	return &FleetDriver{
+		fleet:   fleet,
+		plans:   plans,
+		reqIDs:  traffic.NewRequestIDs(reqIDMin, reqIDMax),
+		created: make(map[string]uint32),
+	}
+}
+
+// find returns the fleet member of leg.
+func (d *FleetDriver) find(leg Leg) (*traffic.Aircraft, bool) {
+	tail := leg.Flight.TailNumber()
+	for _, a := range d.fleet.List() {
+		if a.Tail == tail {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// record notes reqID as the creation of leg's aircraft if err is nil, and
+// returns err.
+func (d *FleetDriver) record(leg Leg, reqID uint32, err error) error {
+	if err == nil {
+		d.mu.Lock()
+		d.created[leg.Flight.TailNumber()] = reqID
+		d.mu.Unlock()
+	}
+	return err
+}
+
+// Park requests the leg's aircraft parked at its origin.
+func (d *FleetDriver) Park(leg Leg) error {
+	f := leg.Flight
+	reqID := d.reqIDs.Next()
+	return d.record(leg, reqID, d.fleet.RequestParked(traffic.ParkedOpts{
+		Model:   f.Type,
+		Livery:  f.Livery,
+		Tail:    f.TailNumber(),
+		Airport: f.Origin,
+	}, reqID))
+}
+
+// Depart sets the leg's flight plan on its parked aircraft. Returns
+// ErrNotSpawned while the aircraft is not in the fleet.
+func (d *FleetDriver) Depart(leg Leg) error {
+	a, ok := d.find(leg)
+	if !ok {
+		return ErrNotSpawned
+	}
+	plan, err := d.plans(leg)
+	if err != nil {
+		return fmt.Errorf("flight plan: %w", err)
+	}
+	return d.fleet.SetFlightPlan(a.ObjectID, plan, d.reqIDs.Next())
+}
+
+// Enroute requests the leg's aircraft along its flight plan at phase.
+func (d *FleetDriver) Enroute(leg Leg, phase float64) error {
+	plan, err := d.plans(leg)
+	if err != nil {
+		return fmt.Errorf("flight plan: %w", err)
+	}
+	f := leg.Flight
+	reqID := d.reqIDs.Next()
+	return d.record(leg, reqID, d.fleet.RequestEnroute(traffic.EnrouteOpts{
+		Model:        f.Type,
+		Livery:       f.Livery,
+		Tail:         f.TailNumber(),
+		FlightNumber: f.FlightNumber(),
+		FlightPlan:   plan,
+		Phase:        phase,
+	}, reqID))
+}
+
+// Remove removes the leg's aircraft. An aircraft still awaiting its
+// ObjectID is cancelled, so it is removed as soon as it is created. A leg
+// whose aircraft already left the fleet is not an error.
+func (d *FleetDriver) Remove(leg Leg) error {
+	d.mu.Lock()
+	reqID, ok := d.created[leg.Flight.TailNumber()]
+	delete(d.created, leg.Flight.TailNumber())
+	d.mu.Unlock()
+	if ok && d.fleet.Cancel(reqID, d.reqIDs.Next()) {
+		return nil
+	}
+	a, ok := d.find(leg)
+	if !ok {
+		return nil
+	}
+	return d.fleet.Remove(a.ObjectID, d.reqIDs.Next())
+}
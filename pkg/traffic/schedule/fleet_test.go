@@ -0,0 +1,66 @@
+//go:build windows
+
+package schedule
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mrlm-net/simconnect/pkg/engine"
+	"github.com/mrlm-net/simconnect/pkg/traffic"
+)
+
+// fakeClient records the creations and removals made through a fleet.
+type fakeClient struct {
+	engine.Client
+	mu      sync.Mutex
+	sendID  uint32
+	created map[string]uint32 // tail → request ID
+	removed []uint32          // object IDs
+}
+
+func (c *fakeClient) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.created[szTailNumber] = RequestID
+	return nil
+}
+
+func (c *fakeClient) AIRemoveObject(objectID uint32, requestID uint32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sendID++
+	c.removed = append(c.removed, objectID)
+	return nil
+}
+
+func (c *fakeClient) GetLastSentPacketID() (uint32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sendID, nil
+}
+
+func TestFleetDriverRemoveBeforeAcknowledge(t *testing.T) {
+	c := &fakeClient{created: make(map[string]uint32)}
+	f := traffic.NewFleet(c)
+	f.SetPendingTimeout(0)
+	d := NewFleetDriver(f, nil, 100, 199)
+	leg := Leg{Flight: Flight{Airline: "CSA", Number: "1", Type: "A320", Origin: "LKPR", Destination: "EGLL", Tail: "OK-AAA"}}
+
+	if err := d.Park(leg); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Remove(leg); err != nil {
+		t.Fatal(err)
+	}
+
+	// The creation is answered after the leg was removed.
+	f.Acknowledge(c.created["OK-AAA"], 7)
+	if f.Len() != 0 {
+		t.Fatalf("Len = %d, want the late aircraft removed", f.Len())
+	}
+	if len(c.removed) != 1 || c.removed[0] != 7 {
+		t.Fatalf("removed %v, want [7]", c.removed)
+	}
+}
@@ -0,0 +1,91 @@
+package schedule
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mrlm-net/simconnect/pkg/flightplan"
+)
+
+// DefaultCruiseAltitude is the cruise altitude of DirectPlans, in feet,
+// when none is given.
+const DefaultCruiseAltitude = 33000
+
+// PlanFunc returns the flight plan path of a leg, as passed to
+// traffic.Fleet.SetFlightPlan and traffic.EnrouteOpts.FlightPlan.
+type PlanFunc func(leg Leg) (string, error)
+
+// Airport is the position of an airport, for generated flight plans.
+type Airport struct {
+	ICAO      string
+	Name      string
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Elevation float64 // feet
+}
+
+// AirportFunc looks up an airport by ICAO code.
+type AirportFunc func(icao string) (Airport, bool)
+
+// DirectPlans returns a PlanFunc writing, into dir, an IFR plan flying
+// direct from the origin to the destination of each leg at cruise feet
+// (DefaultCruiseAltitude if zero). ATC routes the aircraft from there. The
+// file is named after the leg and overwritten for later legs of the same
+// flight. The returned path has no extension, as SimConnect expects.
+// Returns ErrUnknownAirport for an airport airports does not know.
+func DirectPlans(dir string, airports AirportFunc, cruise float64) PlanFunc {
+	if cruise <= 0 {
+		cruise = DefaultCruiseAltitude
+	}
+	return func(leg Leg) (string, error) {
+		f := leg.Flight
+		dep, ok := airports(f.Origin)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownAirport, f.Origin)
+		}
+		dest, ok := airports(f.Destination)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownAirport, f.Destination)
+		}
+		base := filepath.Join(dir, fmt.Sprintf("%s_%s_%s", sanitize(f.TailNumber()), f.Origin, f.Destination))
+		file, err := os.Create(base + ".pln")
+		if err != nil {
+			return "", err
+		}
+		if err := WriteDirectPlan(file, dep, dest, cruise); err != nil {
+			file.Close()
+			return "", err
+		}
+		return base, file.Close()
+	}
+}
+
+// sanitize keeps letters, digits, '-' and '_' of a file name part.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// WriteDirectPlan writes an IFR .PLN flight plan from dep direct to dest
+// at cruise feet.
+func WriteDirectPlan(w io.Writer, dep, dest Airport, cruise float64) error {
+	p := flightplan.NewBuilder().
+		Cruise(cruise).
+		Departure(dep.ICAO, dep.Name, dep.position()).
+		Destination(dest.ICAO, dest.Name, dest.position()).
+		Build()
+	return p.Write(w)
+}
+
+// position returns the position of a, at its elevation.
+func (a Airport) position() flightplan.Position {
+	return flightplan.Position{Latitude: a.Latitude, Longitude: a.Longitude, Altitude: a.Elevation}
+}
@@ -0,0 +1,45 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectPlans(t *testing.T) {
+	airports := map[string]Airport{
+		"LKPR": {ICAO: "LKPR", Name: "Praha", Latitude: 50.1, Longitude: 14.26, Elevation: 1247},
+		"EDDF": {ICAO: "EDDF", Name: "Frankfurt", Latitude: 50.03, Longitude: 8.57, Elevation: 364},
+	}
+	lookup := func(icao string) (Airport, bool) {
+		a, ok := airports[icao]
+		return a, ok
+	}
+	dir := t.TempDir()
+	plans := DirectPlans(dir, lookup, 0)
+
+	leg := Leg{Flight: testFlights()[0]}
+	path, err := plans(leg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "CSA1_LKPR_EDDF"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+	data, err := os.ReadFile(path + ".pln")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"<DepartureID>LKPR</DepartureID>", "<CruisingAlt>33000.000</CruisingAlt>", `<ATCWaypoint id="EDDF">`, "<ICAOIdent>EDDF</ICAOIdent>"} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("plan lacks %s:\n%s", s, data)
+		}
+	}
+
+	leg.Flight.Destination = "XXXX"
+	if _, err := plans(leg); !errors.Is(err, ErrUnknownAirport) {
+		t.Errorf("err = %v, want ErrUnknownAirport", err)
+	}
+}
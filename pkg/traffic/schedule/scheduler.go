@@ -0,0 +1,272 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLead is how long before departure a flight is parked at its
+	// origin when Options.Lead is zero.
+	DefaultLead = 30 * time.Minute
+
+	// DefaultLinger is how long after arrival a flight is removed when
+	// Options.Linger is zero, leaving it time to land and taxi in.
+	DefaultLinger = 15 * time.Minute
+)
+
+// Driver performs the steps of a leg. FleetDriver implements it on a
+// traffic.Fleet; tests and other backends provide their own.
+type Driver interface {
+	// Park creates the leg's aircraft parked at its origin.
+	Park(leg Leg) error
+	// Depart gives the parked aircraft its flight plan.
+	Depart(leg Leg) error
+	// Enroute creates the aircraft of a leg that is already airborne.
+	// phase is the elapsed part of the flight, from 0 to 1.
+	Enroute(leg Leg, phase float64) error
+	// Remove deletes the leg's aircraft.
+	Remove(leg Leg) error
+}
+
+// Options tune a Scheduler. The zero value uses the defaults.
+type Options struct {
+	// Lead is how long before departure the aircraft is parked.
+	Lead time.Duration
+	// Linger is how long after arrival the aircraft is removed.
+	Linger time.Duration
+	// NoEnroute skips legs that are already airborne instead of creating
+	// them enroute.
+	NoEnroute bool
+	// MaxSpawns limits the aircraft created per Tick; the rest follow on
+	// later ticks. Zero means no limit.
+	MaxSpawns int
+}
+
+// LegState is the progress of a leg.
+type LegState uint8
+
+const (
+	// LegParked — the aircraft waits at its origin.
+	LegParked LegState = iota + 1
+	// LegAirborne — the aircraft has its flight plan, or was created
+	// enroute.
+	LegAirborne
+	// LegFailed — creating the aircraft failed; the leg is skipped.
+	LegFailed
+)
+
+// String returns "parked", "airborne" or "failed".
+func (s LegState) String() string {
+	switch s {
+	case LegParked:
+		return "parked"
+	case LegAirborne:
+		return "airborne"
+	case LegFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("LegState(%d)", uint8(s))
+}
+
+// LegStatus is a leg the Scheduler is running and its state.
+type LegStatus struct {
+	Leg   Leg
+	State LegState
+}
+
+// legKey identifies a leg: a flight of the timetable on a day.
+type legKey struct {
+	flight, day int
+}
+
+// Scheduler runs a timetable against a Clock. Call Tick regularly, or Run.
+// Each Tick compares every leg with the time and calls the Driver for the
+// steps due:
+//
+//   - Lead before departure: Park.
+//   - At departure: Depart.
+//   - Between departure and arrival, for a leg that was not parked (the
+//     scheduler started late or the time jumped): Enroute.
+//   - Linger after arrival: Remove.
+//
+// A leg that is not due any more, for instance because the simulator time
+// was set back, is removed. Nothing happens while the clock is not
+// running, and the sim rate applies through the clock.
+type Scheduler struct {
+	mu      sync.Mutex
+	flights []Flight
+	clock   Clock
+	driver  Driver
+	opts    Options
+	legs    map[legKey]LegState
+}
+
+// New returns a scheduler for flights. Returns ErrTimetable for an invalid
+// flight.
+func New(flights []Flight, clock Clock, driver Driver, opts Options) (*Scheduler, error) {
+	for i, f := range flights {
+		if err := f.validate(); err != nil {
+			return nil, fmt.Errorf("flight %d: %w", i, err)
+		}
+	}
+	if opts.Lead <= 0 {
+		opts.Lead = DefaultLead
+	}
+	if opts.Linger <= 0 {
+		opts.Linger = DefaultLinger
+	}
+	return &Scheduler{
+		flights: append([]Flight(nil), flights...),
+		clock:   clock,
+		driver:  driver,
+		opts:    opts,
+		legs:    make(map[legKey]LegState),
+	}, nil
+}
+
+// Tick performs the steps due at the clock's current time. Returns the
+// joined Driver errors, each prefixed with its leg. A failed Park or
+// Enroute marks the leg LegFailed; a failed Depart is retried on the next
+// Tick.
+func (s *Scheduler) Tick() error {
+	now, running := s.clock.Now()
+	if !running {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	spawns := 0
+	for _, k := range s.dueKeys(now) {
+		leg := Leg{Flight: s.flights[k.flight], Day: k.day}
+		state, active := s.legs[k]
+		dep, arr := leg.Departure(), leg.Arrival()
+
+		var err error
+		switch {
+		case now < dep-s.opts.Lead || now >= arr+s.opts.Linger:
+			// Not due (any more).
+			if active {
+				delete(s.legs, k)
+				if state != LegFailed {
+					err = s.driver.Remove(leg)
+				}
+			}
+		case now < dep:
+			if !active {
+				if s.opts.MaxSpawns > 0 && spawns >= s.opts.MaxSpawns {
+					continue
+				}
+				spawns++
+				s.legs[k] = LegParked
+				if err = s.driver.Park(leg); err != nil {
+					s.legs[k] = LegFailed
+				}
+			} else if state == LegAirborne {
+				// Time was set back to before departure.
+				delete(s.legs, k)
+				err = s.driver.Remove(leg)
+			}
+		default:
+			switch {
+			case active && state == LegParked:
+				if err = s.driver.Depart(leg); err == nil {
+					s.legs[k] = LegAirborne
+				}
+			case !active && now < arr && !s.opts.NoEnroute:
+				if s.opts.MaxSpawns > 0 && spawns >= s.opts.MaxSpawns {
+					continue
+				}
+				spawns++
+				s.legs[k] = LegAirborne
+				phase := float64(now-dep) / float64(arr-dep)
+				if err = s.driver.Enroute(leg, phase); err != nil {
+					s.legs[k] = LegFailed
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", leg, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// dueKeys returns the legs to look at, at now: every running leg and every
+// leg departing on the days around now, ordered by departure.
+func (s *Scheduler) dueKeys(now time.Duration) []legKey {
+	day := int(now / Day)
+	if now < 0 {
+		day--
+	}
+	seen := make(map[legKey]bool, len(s.legs))
+	keys := make([]legKey, 0, len(s.legs)+4*len(s.flights))
+	for k := range s.legs {
+		seen[k] = true
+		keys = append(keys, k)
+	}
+	// A flight takes less than a day, so legs departing two days back can
+	// still be lingering and legs departing tomorrow can already be parked.
+	for i := range s.flights {
+		for d := day - 2; d <= day+1; d++ {
+			if k := (legKey{i, d}); !seen[k] {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		da := time.Duration(keys[a].day)*Day + time.Duration(s.flights[keys[a].flight].Departure)
+		db := time.Duration(keys[b].day)*Day + time.Duration(s.flights[keys[b].flight].Departure)
+		if da != db {
+			return da < db
+		}
+		return keys[a].flight < keys[b].flight
+	})
+	return keys
+}
+
+// Run calls Tick every interval until ctx is done, passing Tick errors to
+// onError if it is not nil. Returns ctx.Err().
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration, onError func(error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Tick(); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Legs returns the legs the scheduler is running, ordered by departure.
+func (s *Scheduler) Legs() []LegStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]LegStatus, 0, len(s.legs))
+	for k, state := range s.legs {
+		out = append(out, LegStatus{Leg: Leg{Flight: s.flights[k.flight], Day: k.day}, State: state})
+	}
+	sort.Slice(out, func(a, b int) bool {
+		return out[a].Leg.Departure() < out[b].Leg.Departure()
+	})
+	return out
+}
+
+// Clear forgets every running leg without calling the Driver, e.g. after
+// the connection was lost and the fleet emptied. Legs that are still due
+// are created again on the next Tick.
+func (s *Scheduler) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.legs = make(map[legKey]LegState)
+}
@@ -0,0 +1,158 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// manualClock is a Clock set by the test.
+type manualClock struct {
+	t       time.Duration
+	running bool
+}
+
+func (c *manualClock) Now() (time.Duration, bool) { return c.t, c.running }
+
+// recordingDriver records the steps it is asked to perform.
+type recordingDriver struct {
+	calls    []string
+	failPark bool
+}
+
+func (d *recordingDriver) Park(leg Leg) error {
+	d.calls = append(d.calls, "park "+leg.String())
+	if d.failPark {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func (d *recordingDriver) Depart(leg Leg) error {
+	d.calls = append(d.calls, "depart "+leg.String())
+	return nil
+}
+
+func (d *recordingDriver) Enroute(leg Leg, phase float64) error {
+	d.calls = append(d.calls, fmt.Sprintf("enroute %s %.2f", leg, phase))
+	return nil
+}
+
+func (d *recordingDriver) Remove(leg Leg) error {
+	d.calls = append(d.calls, "remove "+leg.String())
+	return nil
+}
+
+func (d *recordingDriver) take() []string {
+	calls := d.calls
+	d.calls = nil
+	return calls
+}
+
+func hm(h, m int) time.Duration { return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute }
+
+func testFlights() []Flight {
+	return []Flight{
+		{Airline: "CSA", Number: "1", Type: "A320", Origin: "LKPR", Destination: "EDDF", Departure: TimeOfDay(hm(10, 0)), Arrival: TimeOfDay(hm(11, 0))},
+		{Airline: "DLH", Number: "2", Type: "A320", Origin: "EDDF", Destination: "LKPR", Departure: TimeOfDay(hm(23, 30)), Arrival: TimeOfDay(hm(0, 30))},
+	}
+}
+
+func TestSchedulerLifecycle(t *testing.T) {
+	clock := &manualClock{running: true}
+	drv := &recordingDriver{}
+	s, err := New(testFlights(), clock, drv, Options{Lead: 30 * time.Minute, Linger: 10 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		at    time.Duration
+		calls []string
+	}{
+		{hm(9, 0), nil},
+		{hm(9, 40), []string{"park CSA1 LKPR-EDDF day 0"}},
+		{hm(9, 50), nil},
+		{hm(10, 0), []string{"depart CSA1 LKPR-EDDF day 0"}},
+		{hm(11, 5), nil},
+		{hm(11, 10), []string{"remove CSA1 LKPR-EDDF day 0"}},
+		// The overnight flight parks on day 0 and is removed on day 1.
+		{hm(23, 0), []string{"park DLH2 EDDF-LKPR day 0"}},
+		{Day + hm(0, 0), []string{"depart DLH2 EDDF-LKPR day 0"}},
+		{Day + hm(0, 45), []string{"remove DLH2 EDDF-LKPR day 0"}},
+		// The next day repeats.
+		{Day + hm(9, 45), []string{"park CSA1 LKPR-EDDF day 1"}},
+	}
+	for _, st := range steps {
+		clock.t = st.at
+		if err := s.Tick(); err != nil {
+			t.Fatalf("%v: %v", st.at, err)
+		}
+		if got := drv.take(); !reflect.DeepEqual(got, st.calls) {
+			t.Errorf("%v: calls = %q, want %q", st.at, got, st.calls)
+		}
+	}
+}
+
+func TestSchedulerLateStartAndJumps(t *testing.T) {
+	clock := &manualClock{t: hm(10, 15), running: true}
+	drv := &recordingDriver{}
+	s, _ := New(testFlights()[:1], clock, drv, Options{})
+
+	// Starting mid-flight creates the aircraft enroute.
+	s.Tick()
+	if got, want := drv.take(), []string{"enroute CSA1 LKPR-EDDF day 0 0.25"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("late start: calls = %q, want %q", got, want)
+	}
+	if legs := s.Legs(); len(legs) != 1 || legs[0].State != LegAirborne {
+		t.Errorf("legs = %+v", legs)
+	}
+
+	// Setting the time back before the parking window removes it.
+	clock.t = hm(8, 0)
+	s.Tick()
+	if got, want := drv.take(), []string{"remove CSA1 LKPR-EDDF day 0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("set back: calls = %q, want %q", got, want)
+	}
+
+	// Nothing happens while the clock is stopped.
+	clock.t, clock.running = hm(9, 45), false
+	s.Tick()
+	if got := drv.take(); got != nil {
+		t.Errorf("stopped: calls = %q", got)
+	}
+}
+
+func TestSchedulerOptions(t *testing.T) {
+	flights := testFlights()
+	flights = append(flights, flights[0])
+	flights[2].Number = "3"
+
+	clock := &manualClock{t: hm(9, 45), running: true}
+	drv := &recordingDriver{}
+	s, _ := New(flights, clock, drv, Options{MaxSpawns: 1})
+	s.Tick()
+	s.Tick()
+	want := []string{"park CSA1 LKPR-EDDF day 0", "park CSA3 LKPR-EDDF day 0"}
+	if got := drv.take(); !reflect.DeepEqual(got, want) {
+		t.Errorf("MaxSpawns: calls = %q, want %q", got, want)
+	}
+
+	// A failed creation is reported once and not retried.
+	clock.t = hm(10, 30)
+	drv = &recordingDriver{failPark: true}
+	s, _ = New(flights[:1], clock, drv, Options{NoEnroute: true})
+	if err := s.Tick(); err != nil || drv.take() != nil {
+		t.Errorf("NoEnroute: err = %v", err)
+	}
+	clock.t = Day + hm(9, 45)
+	if err := s.Tick(); err == nil {
+		t.Error("failed park: no error")
+	}
+	s.Tick()
+	if got := drv.take(); len(got) != 1 {
+		t.Errorf("failed park: calls = %q, want one park", got)
+	}
+}
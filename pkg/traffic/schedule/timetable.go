@@ -0,0 +1,251 @@
+// Package schedule drives recurring AI traffic from an airline timetable.
+//
+// A timetable is a list of daily flights, loaded from CSV or JSON. A
+// Scheduler follows the simulator's zulu time through a Clock and, for each
+// flight, parks the aircraft at its origin before departure, hands it a
+// flight plan at departure time, creates it enroute when the flight is
+// already airborne, and removes it after arrival. What those steps do is up
+// to a Driver; FleetDriver performs them on a traffic.Fleet.
+//
+// Apart from FleetDriver, the package has no simulator dependency.
+package schedule
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Day is the length of a timetable day.
+const Day = 24 * time.Hour
+
+var (
+	// ErrTimetable is returned for a timetable that cannot be read or has
+	// an invalid flight.
+	ErrTimetable = errors.New("schedule: invalid timetable")
+
+	// ErrUnknownAirport is returned by DirectPlans for an airport the
+	// AirportFunc does not know.
+	ErrUnknownAirport = errors.New("schedule: unknown airport")
+
+	// ErrNotSpawned is returned by FleetDriver for a leg whose aircraft is
+	// not (yet) in the fleet.
+	ErrNotSpawned = errors.New("schedule: aircraft not in fleet")
+)
+
+// TimeOfDay is a zulu time of day, as an offset from midnight. It is
+// written as "HH:MM" (or "HH:MM:SS" with seconds) in timetables.
+type TimeOfDay time.Duration
+
+// ParseTimeOfDay parses "HH:MM" or "HH:MM:SS".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("%w: time %q is not HH:MM", ErrTimetable, s)
+	}
+	limits := []int{23, 59, 59}
+	var d time.Duration
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 || n > limits[i] {
+			return 0, fmt.Errorf("%w: time %q is not HH:MM", ErrTimetable, s)
+		}
+		d += time.Duration(n) * []time.Duration{time.Hour, time.Minute, time.Second}[i]
+	}
+	return TimeOfDay(d), nil
+}
+
+// String returns the time as "HH:MM", with ":SS" if seconds are set.
+func (t TimeOfDay) String() string {
+	d := time.Duration(t)
+	h, m, s := int(d/time.Hour), int(d%time.Hour/time.Minute), int(d%time.Minute/time.Second)
+	if s != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", h, m)
+}
+
+// MarshalText encodes the time as String does.
+func (t TimeOfDay) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+// UnmarshalText decodes a time written by MarshalText.
+func (t *TimeOfDay) UnmarshalText(b []byte) error {
+	v, err := ParseTimeOfDay(string(b))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
+
+// Flight is a daily flight of the timetable. An Arrival at or before the
+// Departure is on the next day.
+type Flight struct {
+	Airline     string    `json:"airline"`          // ICAO airline code, e.g. "DLH"
+	Number      string    `json:"flightNumber"`     // flight number without the airline, e.g. "1394"
+	Type        string    `json:"type"`             // aircraft container title
+	Livery      string    `json:"livery,omitempty"` // livery folder name; "" selects the default livery
+	Tail        string    `json:"tail,omitempty"`   // ATC tail number; "" uses Callsign
+	Origin      string    `json:"origin"`           // ICAO code of the departure airport
+	Destination string    `json:"destination"`      // ICAO code of the arrival airport
+	Departure   TimeOfDay `json:"departure"`        // zulu departure time
+	Arrival     TimeOfDay `json:"arrival"`          // zulu arrival time
+}
+
+// Callsign returns Airline followed by Number, e.g. "DLH1394".
+func (f Flight) Callsign() string { return f.Airline + f.Number }
+
+// TailNumber returns Tail, or Callsign if Tail is empty.
+func (f Flight) TailNumber() string {
+	if f.Tail != "" {
+		return f.Tail
+	}
+	return f.Callsign()
+}
+
+// FlightNumber returns Number as an ATC flight number, or 0 if it is not
+// numeric.
+func (f Flight) FlightNumber() uint32 {
+	n, err := strconv.ParseUint(f.Number, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(n)
+}
+
+// Duration returns the block time from departure to arrival.
+func (f Flight) Duration() time.Duration {
+	d := time.Duration(f.Arrival - f.Departure)
+	if d <= 0 {
+		d += Day
+	}
+	return d
+}
+
+func (f Flight) validate() error {
+	switch {
+	case f.Airline == "" && f.Tail == "":
+		return fmt.Errorf("%w: flight %q has neither airline nor tail", ErrTimetable, f.Number)
+	case f.Type == "":
+		return fmt.Errorf("%w: flight %s has no aircraft type", ErrTimetable, f.Callsign())
+	case f.Origin == "" || f.Destination == "":
+		return fmt.Errorf("%w: flight %s has no origin or destination", ErrTimetable, f.Callsign())
+	case f.Departure < 0 || time.Duration(f.Departure) >= Day || f.Arrival < 0 || time.Duration(f.Arrival) >= Day:
+		return fmt.Errorf("%w: flight %s times out of range", ErrTimetable, f.Callsign())
+	}
+	return nil
+}
+
+// Leg is one day's instance of a Flight. Day counts days of the Clock the
+// Scheduler runs on.
+type Leg struct {
+	Flight Flight
+	Day    int
+}
+
+// Departure returns the departure time on the clock.
+func (l Leg) Departure() time.Duration {
+	return time.Duration(l.Day)*Day + time.Duration(l.Flight.Departure)
+}
+
+// Arrival returns the arrival time on the clock.
+func (l Leg) Arrival() time.Duration { return l.Departure() + l.Flight.Duration() }
+
+// String returns e.g. "DLH1394 LKPR-EDDF day 2".
+func (l Leg) String() string {
+	return fmt.Sprintf("%s %s-%s day %d", l.Flight.TailNumber(), l.Flight.Origin, l.Flight.Destination, l.Day)
+}
+
+// csvColumns maps CSV header names to Flight fields.
+var csvColumns = map[string]func(f *Flight, v string) error{
+	"airline":      func(f *Flight, v string) error { f.Airline = v; return nil },
+	"flight":       func(f *Flight, v string) error { f.Number = v; return nil },
+	"flightnumber": func(f *Flight, v string) error { f.Number = v; return nil },
+	"type":         func(f *Flight, v string) error { f.Type = v; return nil },
+	"livery":       func(f *Flight, v string) error { f.Livery = v; return nil },
+	"tail":         func(f *Flight, v string) error { f.Tail = v; return nil },
+	"origin":       func(f *Flight, v string) error { f.Origin = strings.ToUpper(v); return nil },
+	"destination":  func(f *Flight, v string) error { f.Destination = strings.ToUpper(v); return nil },
+	"departure":    func(f *Flight, v string) error { return f.Departure.UnmarshalText([]byte(v)) },
+	"arrival":      func(f *Flight, v string) error { return f.Arrival.UnmarshalText([]byte(v)) },
+}
+
+// LoadCSV reads a timetable from CSV. The first record is a header naming
+// the columns: airline, flight (or flightNumber), type, livery, tail,
+// origin, destination, departure and arrival, in any order and case. Other
+// columns are ignored; livery and tail may be omitted.
+func LoadCSV(r io.Reader) ([]Flight, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading header: %v", ErrTimetable, err)
+	}
+	setters := make([]func(*Flight, string) error, len(header))
+	for i, name := range header {
+		setters[i] = csvColumns[strings.ToLower(strings.TrimSpace(name))]
+	}
+
+	var flights []Flight
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTimetable, err)
+		}
+		var f Flight
+		for i, v := range rec {
+			if i >= len(setters) || setters[i] == nil {
+				continue
+			}
+			if err := setters[i](&f, strings.TrimSpace(v)); err != nil {
+				line, _ := cr.FieldPos(i)
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if err := f.validate(); err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		flights = append(flights, f)
+	}
+	return flights, nil
+}
+
+// LoadJSON reads a timetable written as a JSON array of Flight.
+func LoadJSON(r io.Reader) ([]Flight, error) {
+	var flights []Flight
+	if err := json.NewDecoder(r).Decode(&flights); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTimetable, err)
+	}
+	for i, f := range flights {
+		if err := f.validate(); err != nil {
+			return nil, fmt.Errorf("flight %d: %w", i, err)
+		}
+	}
+	return flights, nil
+}
+
+// Load reads a timetable file, as JSON if its extension is ".json" and as
+// CSV otherwise.
+func Load(path string) ([]Flight, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return LoadJSON(file)
+	}
+	return LoadCSV(file)
+}
@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const csvTimetable = `# airline timetable
+Airline,Flight,Type,Livery,Origin,Destination,Departure,Arrival
+CSA,123,A320 Asobo,,lkpr,EDDF,06:30,07:40
+DLH,1394,A320 Asobo,Lufthansa,EDDF,LKPR,23:10,00:15
+`
+
+func TestLoadCSV(t *testing.T) {
+	flights, err := LoadCSV(strings.NewReader(csvTimetable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flights) != 2 {
+		t.Fatalf("got %d flights, want 2", len(flights))
+	}
+	f := flights[0]
+	if f.Callsign() != "CSA123" || f.Origin != "LKPR" || f.FlightNumber() != 123 {
+		t.Errorf("flight 0 = %+v", f)
+	}
+	if f.Duration() != 70*time.Minute {
+		t.Errorf("duration = %v, want 1h10m", f.Duration())
+	}
+	if d := flights[1].Duration(); d != 65*time.Minute {
+		t.Errorf("overnight duration = %v, want 1h5m", d)
+	}
+	if flights[1].Livery != "Lufthansa" {
+		t.Errorf("livery = %q", flights[1].Livery)
+	}
+}
+
+func TestLoadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name, csv string
+	}{
+		{"bad time", "airline,flight,type,origin,destination,departure,arrival\nCSA,1,A320,LKPR,EDDF,25:00,07:00\n"},
+		{"missing type", "airline,flight,origin,destination,departure,arrival\nCSA,1,LKPR,EDDF,06:00,07:00\n"},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		if _, err := LoadCSV(strings.NewReader(tt.csv)); !errors.Is(err, ErrTimetable) {
+			t.Errorf("%s: err = %v, want ErrTimetable", tt.name, err)
+		}
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	const js = `[{"airline":"CSA","flightNumber":"123","type":"A320","origin":"LKPR","destination":"EDDF","departure":"06:30","arrival":"07:40:30"}]`
+	flights, err := LoadJSON(strings.NewReader(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := flights[0].Arrival.String(); got != "07:40:30" {
+		t.Errorf("arrival = %s, want 07:40:30", got)
+	}
+	if got := (Leg{Flight: flights[0], Day: 1}).Departure(); got != Day+6*time.Hour+30*time.Minute {
+		t.Errorf("leg departure = %v", got)
+	}
+}
@@ -0,0 +1,191 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// SnapshotVersion is the format version written by Snapshot.Save.
+const SnapshotVersion = 1
+
+// Snapshot is a serialisable record of a fleet: enough to create every
+// aircraft again with Fleet.Restore, on a new connection or in a later
+// session. Build one with Fleet.Snapshot or by hand, e.g. as a traffic
+// scenario file.
+type Snapshot struct {
+	Version  int                `json:"version"`
+	Taken    time.Time          `json:"taken"`
+	Aircraft []AircraftSnapshot `json:"aircraft"`
+}
+
+// AircraftSnapshot holds the creation options of one aircraft and the
+// waypoint chain or flight plan it was given afterwards.
+type AircraftSnapshot struct {
+	Kind   AircraftKind `json:"kind"`
+	Model  string       `json:"model"`
+	Livery string       `json:"livery,omitempty"`
+	Tail   string       `json:"tail"`
+
+	// Airport is the ICAO code a KindParked aircraft is parked at.
+	Airport string `json:"airport,omitempty"`
+
+	// Enroute creation options. FlightPlan is also the plan last set with
+	// SetFlightPlan on a parked aircraft.
+	FlightNumber uint32  `json:"flightNumber,omitempty"`
+	FlightPlan   string  `json:"flightPlan,omitempty"`
+	Phase        float64 `json:"phase,omitempty"`
+	TouchAndGo   bool    `json:"touchAndGo,omitempty"`
+
+	// Position is where a KindNonATC aircraft was created.
+	Position *types.SIMCONNECT_DATA_INITPOSITION `json:"position,omitempty"`
+	// LastPosition is the last position reported with Fleet.UpdatePosition,
+	// if any.
+	LastPosition *types.SIMCONNECT_DATA_INITPOSITION `json:"lastPosition,omitempty"`
+
+	// Waypoints is the chain last set with SetWaypoints, under the data
+	// definition WaypointDefID.
+	Waypoints     []types.SIMCONNECT_DATA_WAYPOINT `json:"waypoints,omitempty"`
+	WaypointDefID uint32                           `json:"waypointDefId,omitempty"`
+}
+
+// LoadSnapshot reads a snapshot saved with Snapshot.Save.
+func LoadSnapshot(path string) (Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Snapshot{}, fmt.Errorf("traffic: decoding snapshot %s: %w", path, err)
+	}
+	if s.Version > SnapshotVersion {
+		return Snapshot{}, fmt.Errorf("%w: version %d", ErrSnapshotVersion, s.Version)
+	}
+	return s, nil
+}
+
+// Save writes the snapshot to path as indented JSON.
+func (s Snapshot) Save(path string) error {
+	if s.Version == 0 {
+		s.Version = SnapshotVersion
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Snapshot records every aircraft of the fleet, pending creations included
+// unless they were cancelled. Aircraft are listed in no particular order.
+func (f *Fleet) Snapshot() Snapshot {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.snapshotLocked()
+}
+
+// snapshotLocked records the fleet. Caller must hold f.mu.
+func (f *Fleet) snapshotLocked() Snapshot {
+	s := Snapshot{
+		Version:  SnapshotVersion,
+		Taken:    time.Now(),
+		Aircraft: make([]AircraftSnapshot, 0, len(f.members)+len(f.pending)),
+	}
+	for _, a := range f.members {
+		s.Aircraft = append(s.Aircraft, a.spec)
+	}
+	for _, p := range f.pending {
+		if !p.cancel {
+			s.Aircraft = append(s.Aircraft, p.spec)
+		}
+	}
+	return s
+}
+
+// Discarded returns the snapshot taken the last time SetClient or Clear
+// discarded a non-empty fleet. After a reconnect, pass it to Restore to
+// bring the traffic back.
+func (f *Fleet) Discarded() Snapshot {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.discarded
+}
+
+// UpdatePosition records the last known position of objectID, e.g. from a
+// periodic SimObject data request, for Snapshot. Reports whether objectID
+// is a member.
+func (f *Fleet) UpdatePosition(objectID uint32, pos types.SIMCONNECT_DATA_INITPOSITION) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	a, ok := f.members[objectID]
+	if ok {
+		a.spec.LastPosition = &pos
+	}
+	return ok
+}
+
+// RestoreOpts configures Fleet.Restore.
+type RestoreOpts struct {
+	// ReqIDBase is the request ID of the first creation; aircraft i of the
+	// snapshot is created with ReqIDBase + i.
+	ReqIDBase uint32
+	// AtLastPosition creates KindNonATC aircraft at their LastPosition,
+	// where known, instead of where they were first created.
+	AtLastPosition bool
+}
+
+// Restore creates every aircraft of snap again on the current connection.
+// Once an aircraft is acknowledged, its flight plan is set again (parked
+// aircraft) or control is released and its waypoint chain is set again
+// (non-ATC aircraft), before the FleetSpawned event is published. Failures
+// of these follow-up calls are reported in the event's Err.
+//
+// The waypoint data definition must be registered on the connection before
+// the aircraft are acknowledged. A waypoint chain is set again from its
+// first waypoint, including with AtLastPosition.
+//
+// Returns ErrNotConnected if the fleet has no active client, or the joined
+// errors of the creation calls that failed.
+func (f *Fleet) Restore(snap Snapshot, opts RestoreOpts) error {
+	f.mu.RLock()
+	connected := f.client != nil
+	f.mu.RUnlock()
+	if !connected {
+		return ErrNotConnected
+	}
+
+	var errs []error
+	for i, spec := range snap.Aircraft {
+		reqID := opts.ReqIDBase + uint32(i)
+		if spec.Kind == KindNonATC && opts.AtLastPosition && spec.LastPosition != nil {
+			spec.Position = spec.LastPosition
+		}
+		if err := f.request(spec, reqID, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", spec.Kind, spec.Tail, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reapply sets the flight plan or waypoint chain of a restored aircraft
+// again. reqID is reused for the follow-up calls.
+func (f *Fleet) reapply(a *Aircraft, spec AircraftSnapshot, reqID uint32) error {
+	switch {
+	case spec.Kind == KindParked && spec.FlightPlan != "":
+		return f.SetFlightPlan(a.ObjectID, spec.FlightPlan, reqID)
+	case spec.Kind == KindNonATC && len(spec.Waypoints) > 0:
+		if err := f.ReleaseControl(a.ObjectID, reqID); err != nil {
+			return err
+		}
+		return f.SetWaypoints(a.ObjectID, spec.WaypointDefID, spec.Waypoints)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+//go:build windows
+
+package traffic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	created := types.SIMCONNECT_DATA_INITPOSITION{Latitude: 50.1, Longitude: 14.26, Altitude: 1200, OnGround: 1}
+	last := types.SIMCONNECT_DATA_INITPOSITION{Latitude: 50.2, Longitude: 14.1, Altitude: 3500, Heading: 240}
+	wps := []types.SIMCONNECT_DATA_WAYPOINT{
+		{Latitude: 50.2, Longitude: 14.1, Altitude: 3500, KtsSpeed: 180},
+		{Latitude: 50.3, Longitude: 13.9, Altitude: 6000, KtsSpeed: 220},
+	}
+
+	f := NewFleet(newFakeClient())
+	f.SetPendingTimeout(0)
+	if err := f.RequestParked(ParkedOpts{Model: "A320", Tail: "OK-PAR", Airport: "LKPR"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RequestNonATC(NonATCOpts{Model: "C172", Tail: "OK-NON", Position: created}, 2); err != nil {
+		t.Fatal(err)
+	}
+	f.Acknowledge(1, 101)
+	f.Acknowledge(2, 102)
+	if err := f.SetFlightPlan(101, `C:\plans\LKPRLFPG`, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetWaypoints(102, 77, wps); err != nil {
+		t.Fatal(err)
+	}
+	f.UpdatePosition(102, last)
+
+	// A reconnect discards the fleet and keeps it for Restore.
+	c := newFakeClient()
+	f.SetClient(c)
+	if f.Len() != 0 {
+		t.Fatalf("fleet not emptied on reconnect: %d members", f.Len())
+	}
+	snap := f.Discarded()
+	if len(snap.Aircraft) != 2 {
+		t.Fatalf("discarded %d aircraft, want 2", len(snap.Aircraft))
+	}
+
+	sub := f.Subscribe(4)
+	defer sub.Unsubscribe()
+	if err := f.Restore(snap, RestoreOpts{ReqIDBase: 500, AtLastPosition: true}); err != nil {
+		t.Fatal(err)
+	}
+	if f.PendingCount() != 2 {
+		t.Fatalf("PendingCount = %d, want 2", f.PendingCount())
+	}
+
+	// Acknowledge the creations with new object IDs.
+	objects := make(map[string]uint32)
+	for reqID, tail := range c.tails {
+		objectID := 200 + reqID
+		objects[tail] = objectID
+		if _, ok := f.Acknowledge(reqID, objectID); !ok {
+			t.Fatalf("request %d of %s not pending", reqID, tail)
+		}
+		select {
+		case ev := <-sub.Events():
+			if ev.Kind != FleetSpawned || ev.Err != nil {
+				t.Fatalf("event = %+v, want spawned without error", ev)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for fleet event")
+		}
+	}
+	if len(objects) != 2 {
+		t.Fatalf("restored %v, want OK-PAR and OK-NON", objects)
+	}
+
+	for reqID, pos := range c.positions {
+		if c.tails[reqID] != "OK-NON" || pos != last {
+			t.Errorf("non-ATC %s created at %+v, want last position %+v", c.tails[reqID], pos, last)
+		}
+	}
+	if got := c.plans[objects["OK-PAR"]]; got != `C:\plans\LKPRLFPG` {
+		t.Errorf("flight plan of OK-PAR = %q, want it re-applied", got)
+	}
+	if !c.released[objects["OK-NON"]] {
+		t.Error("control of OK-NON not released")
+	}
+	if got := c.waypoints[objects["OK-NON"]]; got != [2]uint32{77, 2} {
+		t.Errorf("waypoints of OK-NON = %v, want definition 77 with 2 waypoints", got)
+	}
+	if got, defID, ok := f.Waypoints(objects["OK-NON"]); !ok || defID != 77 || !reflect.DeepEqual(got, wps) {
+		t.Errorf("Waypoints = %v, %d, %v, want the restored chain", got, defID, ok)
+	}
+}
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	pos := types.SIMCONNECT_DATA_INITPOSITION{Latitude: 50.1, Longitude: 14.26, Altitude: 1200}
+	snap := Snapshot{
+		Taken: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+		Aircraft: []AircraftSnapshot{
+			{Kind: KindParked, Model: "A320", Tail: "OK-PAR", Airport: "LKPR", FlightPlan: `C:\plans\LKPRLFPG`},
+			{Kind: KindEnroute, Model: "B738", Tail: "OK-ENR", FlightNumber: 123, FlightPlan: `C:\plans\LKPREDDM`, Phase: 0.4, TouchAndGo: true},
+			{
+				Kind: KindNonATC, Model: "C172", Tail: "OK-NON", Position: &pos, LastPosition: &pos,
+				Waypoints:     []types.SIMCONNECT_DATA_WAYPOINT{{Latitude: 50.2, Longitude: 14.1, Altitude: 3500, KtsSpeed: 120}},
+				WaypointDefID: 77,
+			},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "traffic.json")
+	if err := snap.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Save fills in the current version.
+	snap.Version = SnapshotVersion
+	if !reflect.DeepEqual(got, snap) {
+		t.Errorf("LoadSnapshot(Save(s)) = %+v, want %+v", got, snap)
+	}
+
+	newer := []byte(`{"version": ` + strconv.Itoa(SnapshotVersion+1) + `, "aircraft": []}`)
+	if err := os.WriteFile(path, newer, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); !errors.Is(err, ErrSnapshotVersion) {
+		t.Errorf("newer version: err = %v, want ErrSnapshotVersion", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"aircraft": [{"kind": "glider"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSnapshot(path); err == nil {
+		t.Error("unknown aircraft kind loaded")
+	}
+}
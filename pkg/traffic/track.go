@@ -0,0 +1,330 @@
+//go:build windows
+// +build windows
+
+package traffic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/mrlm-net/simconnect/pkg/datasets"
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
+
+// Telemetry is the latest state of a tracked aircraft, decoded from the
+// dataset passed to Fleet.Track. Fields the dataset does not define stay
+// zero. Units are those of the dataset; the ones below are those of
+// datasets/traffic.NewAircraftDataset.
+type Telemetry struct {
+	Latitude      float64 // PLANE LATITUDE, degrees
+	Longitude     float64 // PLANE LONGITUDE, degrees
+	Altitude      float64 // PLANE ALTITUDE, feet MSL
+	AltitudeAGL   float64 // PLANE ALT ABOVE GROUND, feet
+	Heading       float64 // PLANE HEADING DEGREES TRUE, degrees
+	GroundSpeed   float64 // GROUND VELOCITY, knots
+	VerticalSpeed float64 // VERTICAL SPEED, feet per minute
+	OnGround      bool    // SIM ON GROUND
+	Updated       time.Time
+}
+
+// trackField is a simulation variable Telemetry is decoded from.
+type trackField uint8
+
+const (
+	fieldLatitude trackField = iota
+	fieldLongitude
+	fieldAltitude
+	fieldAltitudeAGL
+	fieldHeading
+	fieldGroundSpeed
+	fieldVerticalSpeed
+	fieldOnGround
+	fieldCount
+)
+
+var trackFieldNames = map[string]trackField{
+	"PLANE LATITUDE":             fieldLatitude,
+	"PLANE LONGITUDE":            fieldLongitude,
+	"PLANE ALTITUDE":             fieldAltitude,
+	"PLANE ALT ABOVE GROUND":     fieldAltitudeAGL,
+	"PLANE HEADING DEGREES TRUE": fieldHeading,
+	"GROUND VELOCITY":            fieldGroundSpeed,
+	"VERTICAL SPEED":             fieldVerticalSpeed,
+	"SIM ON GROUND":              fieldOnGround,
+}
+
+// datumSizes holds the packed size, in bytes, of each fixed-size datum type.
+var datumSizes = map[types.SIMCONNECT_DATATYPE]int{
+	types.SIMCONNECT_DATATYPE_INT32:        4,
+	types.SIMCONNECT_DATATYPE_INT64:        8,
+	types.SIMCONNECT_DATATYPE_FLOAT32:      4,
+	types.SIMCONNECT_DATATYPE_FLOAT64:      8,
+	types.SIMCONNECT_DATATYPE_STRING8:      8,
+	types.SIMCONNECT_DATATYPE_STRING32:     32,
+	types.SIMCONNECT_DATATYPE_STRING64:     64,
+	types.SIMCONNECT_DATATYPE_STRING128:    128,
+	types.SIMCONNECT_DATATYPE_STRING256:    256,
+	types.SIMCONNECT_DATATYPE_STRING260:    260,
+	types.SIMCONNECT_DATATYPE_INITPOSITION: 56,
+	types.SIMCONNECT_DATATYPE_MARKERSTATE:  68,
+	types.SIMCONNECT_DATATYPE_WAYPOINT:     44,
+	types.SIMCONNECT_DATATYPE_LATLONALT:    24,
+	types.SIMCONNECT_DATATYPE_XYZ:          24,
+}
+
+// trackDatum locates one Telemetry field in the packed data.
+type trackDatum struct {
+	offset int
+	kind   types.SIMCONNECT_DATATYPE
+}
+
+// trackLayout maps the Telemetry fields to their place in a dataset.
+type trackLayout struct {
+	fields [fieldCount]*trackDatum
+	size   int
+}
+
+// newTrackLayout computes the layout of ds. Every datum must have a fixed
+// size and PLANE LATITUDE and PLANE LONGITUDE must be numeric datums.
+func newTrackLayout(ds *datasets.DataSet) (trackLayout, error) {
+	var l trackLayout
+	for _, def := range ds.Definitions {
+		size, ok := datumSizes[def.Type]
+		if !ok {
+			return trackLayout{}, fmt.Errorf("%w: %s has no fixed size", ErrTrackDataset, def.Name)
+		}
+		if f, ok := trackFieldNames[def.Name]; ok && numericDatum(def.Type) {
+			l.fields[f] = &trackDatum{offset: l.size, kind: def.Type}
+		}
+		l.size += size
+	}
+	if l.fields[fieldLatitude] == nil || l.fields[fieldLongitude] == nil {
+		return trackLayout{}, fmt.Errorf("%w: PLANE LATITUDE and PLANE LONGITUDE are required", ErrTrackDataset)
+	}
+	return l, nil
+}
+
+func numericDatum(t types.SIMCONNECT_DATATYPE) bool {
+	switch t {
+	case types.SIMCONNECT_DATATYPE_INT32, types.SIMCONNECT_DATATYPE_INT64,
+		types.SIMCONNECT_DATATYPE_FLOAT32, types.SIMCONNECT_DATATYPE_FLOAT64:
+		return true
+	}
+	return false
+}
+
+// decode reads the Telemetry fields from packed data.
+func (l *trackLayout) decode(data []byte) Telemetry {
+	value := func(f trackField) float64 {
+		d := l.fields[f]
+		if d == nil {
+			return 0
+		}
+		b := data[d.offset:]
+		switch d.kind {
+		case types.SIMCONNECT_DATATYPE_INT32:
+			return float64(int32(binary.LittleEndian.Uint32(b)))
+		case types.SIMCONNECT_DATATYPE_INT64:
+			return float64(int64(binary.LittleEndian.Uint64(b)))
+		case types.SIMCONNECT_DATATYPE_FLOAT32:
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		default:
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+	}
+	return Telemetry{
+		Latitude:      value(fieldLatitude),
+		Longitude:     value(fieldLongitude),
+		Altitude:      value(fieldAltitude),
+		AltitudeAGL:   value(fieldAltitudeAGL),
+		Heading:       value(fieldHeading),
+		GroundSpeed:   value(fieldGroundSpeed),
+		VerticalSpeed: value(fieldVerticalSpeed),
+		OnGround:      value(fieldOnGround) != 0,
+		Updated:       time.Now(),
+	}
+}
+
+// tracking is the telemetry configuration of a Fleet.
+type tracking struct {
+	defID          uint32
+	reqMin, reqMax uint32
+	dataset        *datasets.DataSet // nil while not tracking
+	layout         trackLayout
+	period         types.SIMCONNECT_PERIOD
+	requests       map[uint32]uint32 // objectID → request ID
+	objects        map[uint32]uint32 // request ID → objectID
+}
+
+// SetTrackIDs sets the data definition and the request ID range Track
+// uses. Each tracked aircraft takes one request ID, so at most
+// reqIDMax-reqIDMin+1 aircraft are tracked at once. The manager sets these
+// for its Fleet() from its reserved ranges.
+func (f *Fleet) SetTrackIDs(defID, reqIDMin, reqIDMax uint32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.track.defID, f.track.reqMin, f.track.reqMax = defID, reqIDMin, reqIDMax
+}
+
+// Track requests dataset every period for every member, now and as they
+// are acknowledged, and keeps the decoded Telemetry on each Aircraft.
+// Requests are cancelled when an aircraft leaves the fleet and issued again
+// after SetClient binds a new connection. Use
+// datasets/traffic.NewAircraftDataset, or any dataset of fixed-size datums
+// that includes PLANE LATITUDE and PLANE LONGITUDE. Calling Track again
+// replaces the dataset and period.
+//
+// Every update replaces the member's Aircraft with a copy carrying the new
+// State and publishes a FleetUpdated event to SubscribeUpdates
+// subscriptions. Feed SIMCONNECT_RECV_SIMOBJECT_DATA messages to
+// HandleData; the manager does this for its Fleet().
+//
+// Returns ErrTrackIDs if SetTrackIDs was not called, ErrTrackDataset for an
+// unsuitable dataset, and ErrNotConnected if the fleet has no active client.
+func (f *Fleet) Track(dataset *datasets.DataSet, period types.SIMCONNECT_PERIOD) error {
+	layout, err := newTrackLayout(dataset)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.track.reqMax < f.track.reqMin || f.track.reqMax == 0 {
+		return ErrTrackIDs
+	}
+	if f.client == nil {
+		return ErrNotConnected
+	}
+	if f.track.dataset != nil {
+		f.untrackAllLocked()
+		if err := f.client.ClearDataDefinition(f.track.defID); err != nil {
+			return err
+		}
+	}
+	f.track.dataset, f.track.layout, f.track.period = dataset, layout, period
+	return f.startTrackingLocked()
+}
+
+// Untrack cancels every telemetry request. The last State of each
+// Aircraft is kept.
+func (f *Fleet) Untrack() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.track.dataset == nil {
+		return nil
+	}
+	f.untrackAllLocked()
+	f.track.dataset = nil
+	if f.client == nil {
+		return nil
+	}
+	return f.client.ClearDataDefinition(f.track.defID)
+}
+
+// HandleData applies a SIMCONNECT_RECV_SIMOBJECT_DATA message answering a
+// Track request. Reports whether msg belonged to the fleet.
+func (f *Fleet) HandleData(msg *types.SIMCONNECT_RECV_SIMOBJECT_DATA) bool {
+	reqID := uint32(msg.DwRequestID)
+	f.mu.Lock()
+	objectID, ok := f.track.objects[reqID]
+	if !ok || f.track.dataset == nil {
+		f.mu.Unlock()
+		return false
+	}
+	header := int(unsafe.Offsetof(msg.DwData))
+	if int(msg.DwSize)-header < f.track.layout.size {
+		f.mu.Unlock()
+		return true
+	}
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&msg.DwData)), f.track.layout.size)
+	state := f.track.layout.decode(data)
+
+	old, ok := f.members[objectID]
+	if !ok {
+		f.mu.Unlock()
+		return true
+	}
+	a := *old
+	a.State = state
+	a.spec.LastPosition = &types.SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  state.Latitude,
+		Longitude: state.Longitude,
+		Altitude:  state.Altitude,
+		Heading:   state.Heading,
+		OnGround:  boolDWORD(state.OnGround),
+		Airspeed:  types.SIMCONNECT_DATA_INITPOSITION_AIRSPEED(state.GroundSpeed),
+	}
+	f.members[objectID] = &a
+	subs := f.subscribersLocked()
+	f.mu.Unlock()
+
+	publish(subs, FleetEvent{Kind: FleetUpdated, Aircraft: &a})
+	return true
+}
+
+func boolDWORD(b bool) types.DWORD {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// startTrackingLocked registers the dataset and requests it for every
+// member. Caller must hold f.mu with a client and a dataset set.
+func (f *Fleet) startTrackingLocked() error {
+	if err := f.client.RegisterDataset(f.track.defID, f.track.dataset); err != nil {
+		return err
+	}
+	f.track.requests = make(map[uint32]uint32)
+	f.track.objects = make(map[uint32]uint32)
+	for id := range f.members {
+		if err := f.trackLocked(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trackLocked requests telemetry for objectID on a free request ID. An
+// aircraft beyond the request ID range is left untracked. Caller must hold
+// f.mu with a client and a dataset set.
+func (f *Fleet) trackLocked(objectID uint32) error {
+	if _, ok := f.track.requests[objectID]; ok {
+		return nil
+	}
+	for reqID := f.track.reqMin; reqID <= f.track.reqMax && reqID >= f.track.reqMin; reqID++ {
+		if _, used := f.track.objects[reqID]; used {
+			continue
+		}
+		if err := f.client.RequestDataOnSimObject(reqID, f.track.defID, objectID, f.track.period, types.SIMCONNECT_DATA_REQUEST_FLAG_DEFAULT, 0, 0, 0); err != nil {
+			return err
+		}
+		f.track.requests[objectID] = reqID
+		f.track.objects[reqID] = objectID
+		return nil
+	}
+	return nil
+}
+
+// untrackLocked cancels the telemetry request of objectID, if any.
+// Caller must hold f.mu.
+func (f *Fleet) untrackLocked(objectID uint32) {
+	reqID, ok := f.track.requests[objectID]
+	if !ok {
+		return
+	}
+	delete(f.track.requests, objectID)
+	delete(f.track.objects, reqID)
+	if f.client != nil {
+		_ = f.client.RequestDataOnSimObject(reqID, f.track.defID, objectID, types.SIMCONNECT_PERIOD_NEVER, types.SIMCONNECT_DATA_REQUEST_FLAG_DEFAULT, 0, 0, 0)
+	}
+}
+
+// untrackAllLocked cancels every telemetry request. Caller must hold f.mu.
+func (f *Fleet) untrackAllLocked() {
+	for id := range f.track.requests {
+		f.untrackLocked(id)
+	}
+}
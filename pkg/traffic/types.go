@@ -3,7 +3,12 @@
 
 package traffic
 
-import "github.com/mrlm-net/simconnect/pkg/types"
+import (
+	"fmt"
+	"time"
+
+	"github.com/mrlm-net/simconnect/pkg/types"
+)
 
 // AircraftKind identifies how an AI aircraft was created.
 type AircraftKind uint8
@@ -18,6 +23,36 @@ const (
 	KindNonATC
 )
 
+// kindNames are the names of the AircraftKinds, as used in snapshots.
+var kindNames = [...]string{KindParked: "parked", KindEnroute: "enroute", KindNonATC: "nonatc"}
+
+// String returns "parked", "enroute" or "nonatc".
+func (k AircraftKind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("AircraftKind(%d)", uint8(k))
+}
+
+// MarshalText encodes the kind by name.
+func (k AircraftKind) MarshalText() ([]byte, error) {
+	if int(k) >= len(kindNames) {
+		return nil, fmt.Errorf("traffic: unknown aircraft kind %d", uint8(k))
+	}
+	return []byte(kindNames[k]), nil
+}
+
+// UnmarshalText decodes a kind name written by MarshalText.
+func (k *AircraftKind) UnmarshalText(text []byte) error {
+	for i, name := range kindNames {
+		if string(text) == name {
+			*k = AircraftKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("traffic: unknown aircraft kind %q", text)
+}
+
 // ParkedOpts configures a parked ATC aircraft at an airport gate.
 type ParkedOpts struct {
 	Model   string // container title (e.g. "FSLTL A320 Air France SL")
@@ -49,11 +84,20 @@ type NonATCOpts struct {
 
 // Pending records the metadata for an aircraft creation request that is still
 // awaiting an ObjectID from the simulator. Created internally by Fleet.Request*
-// and resolved to an Aircraft by Fleet.Acknowledge.
+// and resolved to an Aircraft by Fleet.Acknowledge, or dropped by Fleet.Fail,
+// Fleet.FailSend or the pending timeout.
 type Pending struct {
-	ReqID  uint32
-	Kind   AircraftKind
-	Model  string
-	Livery string
-	Tail   string
+	ReqID     uint32
+	Kind      AircraftKind
+	Model     string
+	Livery    string
+	Tail      string
+	SendID    uint32    // packet ID of the creation call; 0 if unknown
+	Requested time.Time // when the creation call was made
+
+	timer   *time.Timer      // pending timeout, nil when disabled
+	spec    AircraftSnapshot // creation options
+	restore bool             // re-apply spec's waypoints or flight plan on Acknowledge
+	cancel  bool             // withdrawn by Cancel
+	removal uint32           // request ID of the removal after Cancel
 }
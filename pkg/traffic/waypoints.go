@@ -3,10 +3,7 @@
 
 package traffic
 
-import (
-	"github.com/mrlm-net/simconnect/pkg/calc"
-	"github.com/mrlm-net/simconnect/pkg/types"
-)
+import "github.com/mrlm-net/simconnect/pkg/types"
 
 // PushbackWaypoint creates a waypoint for a reverse pushback manoeuvre.
 //
@@ -82,6 +79,37 @@ func ClimbWaypoint(lat, lon, altAGL, ktsSpeed, throttlePct float64) types.SIMCON
 	}
 }
 
+// DescentWaypoint creates an airborne approach waypoint.
+//
+// Flags: SPEED_REQUESTED | COMPUTE_VERTICAL_SPEED.
+//
+// altFt is feet MSL, so procedure altitude constraints can be used as they
+// are. The simulator derives the vertical speed needed to reach each
+// altitude at the waypoint.
+func DescentWaypoint(lat, lon, altFt, ktsSpeed float64) types.SIMCONNECT_DATA_WAYPOINT {
+	return types.SIMCONNECT_DATA_WAYPOINT{
+		Latitude:  lat,
+		Longitude: lon,
+		Altitude:  altFt,
+		Flags: uint32(
+			types.SIMCONNECT_WAYPOINT_SPEED_REQUESTED |
+				types.SIMCONNECT_WAYPOINT_COMPUTE_VERTICAL_SPEED,
+		),
+		KtsSpeed: ktsSpeed,
+	}
+}
+
+// TouchdownWaypoint creates the touchdown point on the runway.
+//
+// Flags: ON_GROUND | SPEED_REQUESTED.
+//
+// The transition from the last DescentWaypoint to the first ON_GROUND
+// waypoint makes the AI land. ktsSpeed is the touchdown speed; follow it with
+// slower TaxiWaypoints for the rollout.
+func TouchdownWaypoint(lat, lon, altFt, ktsSpeed float64) types.SIMCONNECT_DATA_WAYPOINT {
+	return TaxiWaypoint(lat, lon, altFt, ktsSpeed)
+}
+
 // TakeoffClimb builds the standard 3-waypoint climb chain from a runway threshold.
 //
 // rwyLat/rwyLon is the primary runway threshold. hdgDeg is the primary runway
@@ -95,12 +123,5 @@ func ClimbWaypoint(lat, lon, altAGL, ktsSpeed, throttlePct float64) types.SIMCON
 // Append these directly after a LineupWaypoint to complete a full departure
 // sequence: [PushbackWaypoint] [TaxiWaypoint...] [LineupWaypoint] [TakeoffClimb...]
 func TakeoffClimb(rwyLat, rwyLon, hdgDeg float64) []types.SIMCONNECT_DATA_WAYPOINT {
-	c1Lat, c1Lon := calc.DisplaceByHeading(rwyLat, rwyLon, hdgDeg, 2778)  // 1.5 nm
-	c2Lat, c2Lon := calc.DisplaceByHeading(rwyLat, rwyLon, hdgDeg, 9260)  // 5 nm
-	c3Lat, c3Lon := calc.DisplaceByHeading(rwyLat, rwyLon, hdgDeg, 22224) // 12 nm
-	return []types.SIMCONNECT_DATA_WAYPOINT{
-		ClimbWaypoint(c1Lat, c1Lon, 1500, 200, 100),
-		ClimbWaypoint(c2Lat, c2Lon, 4000, 240, 90),
-		ClimbWaypoint(c3Lat, c3Lon, 9000, 280, 85),
-	}
+	return climb(CategoryJet, rwyLat, rwyLon, hdgDeg, 0)
 }
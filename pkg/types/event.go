@@ -13,11 +13,14 @@ const (
 )
 
 // https://docs.flightsimulator.com/msfs2024/html/6_Programming_APIs/SimConnect/API_Reference/Structures_And_Enumerations/SIMCONNECT_INPUT_EVENT_DESCRIPTOR.htm
+// Note: HashBytes [8]byte is used instead of uint64 for the UINT64 Hash field so the
+// struct keeps 4-byte alignment and a Go size equal to the 1100-byte wire stride.
+// Use engine.InputEventDescriptors to decode the entries of an enumeration page.
 type SIMCONNECT_INPUT_EVENT_DESCRIPTOR struct {
-	Name      [64]byte            // SIMCONNECT_STRING(Name, 64);
-	Hash      DWORD               // DWORD Hash;
-	Type      SIMCONNECT_DATATYPE // SIMCONNECT_DATATYPE Type;
-	NodeNames [1024]byte          // SIMCONNECT_STRING(NodeNames, 1024);
+	Name      [64]byte                    // SIMCONNECT_STRING(Name, 64);
+	HashBytes [8]byte                     // UINT64 Hash as raw bytes; use binary.LittleEndian.Uint64(HashBytes[:])
+	Type      SIMCONNECT_INPUT_EVENT_TYPE // SIMCONNECT_INPUT_EVENT_TYPE eType;
+	NodeNames [1024]byte                  // SIMCONNECT_STRING(NodeNames, 1024);
 }
 
 type SIMCONNECT_EVENT_FLAG uint32
@@ -43,7 +43,6 @@ const (
 	SIMCONNECT_RECV_ID_EVENT_RACE_END
 	SIMCONNECT_RECV_ID_EVENT_RACE_LAP
 	SIMCONNECT_RECV_ID_PICK
-	//SIMCONNECT_RECV_ID_EVENT_EX1 ??? there is some issue with probably this ID in the docs
 	SIMCONNECT_RECV_ID_FACILITY_DATA
 	SIMCONNECT_RECV_ID_FACILITY_DATA_END
 	SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST
@@ -58,6 +57,12 @@ const (
 	SIMCONNECT_RECV_ID_FLOW_EVENT
 )
 
+// SIMCONNECT_RECV_ID_EVENT_EX1 carries key events transmitted with
+// TransmitClientEventEx1. The documentation lists it after PICK, but
+// SimConnect.h only declares PICK for experimental builds, so both share
+// the same wire value.
+const SIMCONNECT_RECV_ID_EVENT_EX1 = SIMCONNECT_RECV_ID_PICK
+
 // https://docs.flightsimulator.com/msfs2024/html/6_Programming_APIs/SimConnect/API_Reference/Structures_And_Enumerations/SIMCONNECT_RECV_OPEN.htm
 type SIMCONNECT_RECV_OPEN struct {
 	SIMCONNECT_RECV